@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
+)
+
+func (s *Server) CarryOverConfiguration(ctx context.Context, request *idl.CarryOverConfigurationRequest) (*idl.CarryOverConfigurationReply, error) {
+	gplog.Info("agent starting %s", idl.Substep_CARRY_OVER_CONFIGURATION)
+
+	carryOvers, err := CarryOverConfiguration(request)
+	return &idl.CarryOverConfigurationReply{CarryOvers: carryOvers}, err
+}
+
+// CarryOverConfiguration carries the configuration of each source segment in
+// the request over to its target segment. Segments that fail do not prevent
+// the others from being processed.
+func CarryOverConfiguration(request *idl.CarryOverConfigurationRequest) ([]*idl.ConfigurationCarryOver, error) {
+	var carryOvers []*idl.ConfigurationCarryOver
+	var mErr *multierror.Error
+
+	for _, pair := range request.DataDirPairs {
+		result, err := pgconf.CarryOver(pair.SourceDataDir, pair.TargetDataDir,
+			request.SourceMajorVersion, request.TargetMajorVersion)
+		if err != nil {
+			mErr = multierror.Append(mErr, xerrors.Errorf("content %d: %w", pair.Content, err))
+			continue
+		}
+
+		carryOvers = append(carryOvers, result.Proto(pair.Content))
+	}
+
+	return carryOvers, mErr.ErrorOrNil()
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"bufio"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/archive"
+)
+
+func (s *Server) CollectLogs(in *idl.CollectLogsRequest, stream idl.Agent_CollectLogsServer) error {
+	gplog.Info("agent collecting logs")
+
+	return CollectLogs(s.conf.StateDir, utils.LogDirs(), in.MaxBytesPerHost, stream)
+}
+
+// CollectLogs sends a gzipped tarball of the agent's logs over the given
+// stream. See archive.WriteHostLogs.
+func CollectLogs(stateDir string, logDirs []string, maxBytes uint64, sender idl.CollectLogsSender) error {
+	buf := bufio.NewWriterSize(idl.CollectLogsWriter{Sender: sender}, archive.ChunkSize)
+
+	if err := archive.WriteHostLogs(buf, stateDir, logDirs, maxBytes); err != nil {
+		return err
+	}
+
+	if err := buf.Flush(); err != nil {
+		return xerrors.Errorf("sending logs: %w", err)
+	}
+
+	return nil
+}
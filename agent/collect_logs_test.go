@@ -0,0 +1,117 @@
+package agent_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+type bufferSender struct {
+	bytes.Buffer
+	err error
+}
+
+func (b *bufferSender) Send(reply *idl.CollectLogsReply) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	_, err := b.Write(reply.Data)
+	return err
+}
+
+func TestCollectLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stateDir := filepath.Join(dir, ".gpupgrade")
+	logDirs := []string{filepath.Join(dir, "gpAdminLogs"), filepath.Join(dir, "var", "log", "gpupgrade")}
+
+	files := []string{
+		filepath.Join(stateDir, "pg_upgrade", "seg0", "pg_upgrade_server.log"),
+		filepath.Join(logDirs[0], "gpinitsystem_20191231.log"),
+		filepath.Join(logDirs[1], "gpupgrade_agent_20191231.log"),
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f), 0700); err != nil {
+			t.Fatalf("creating directory: %+v", err)
+		}
+		if err := ioutil.WriteFile(f, []byte("log"), 0600); err != nil {
+			t.Fatalf("writing file: %+v", err)
+		}
+	}
+
+	t.Run("sends a tarball of the state and log directories", func(t *testing.T) {
+		sender := &bufferSender{}
+
+		err := agent.CollectLogs(stateDir, logDirs, 0, sender)
+		if err != nil {
+			t.Fatalf("CollectLogs() returned error %+v", err)
+		}
+
+		names := archiveFiles(t, &sender.Buffer)
+		expected := []string{
+			"gpAdminLogs/gpinitsystem_20191231.log",
+			"gpupgrade/gpupgrade_agent_20191231.log",
+			"state/pg_upgrade/seg0/pg_upgrade_server.log",
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("archive contained %q, want %q", names, expected)
+		}
+	})
+
+	t.Run("returns send errors", func(t *testing.T) {
+		expected := xerrors.New("stream closed")
+		sender := &bufferSender{err: expected}
+
+		err := agent.CollectLogs(stateDir, logDirs, 0, sender)
+		if !xerrors.Is(err, expected) {
+			t.Errorf("returned error %#v, want %#v", err, expected)
+		}
+	})
+}
+
+// archiveFiles returns the sorted names of the regular files in a gzipped
+// tarball.
+func archiveFiles(t *testing.T, r io.Reader) []string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("opening gzip stream: %+v", err)
+	}
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading archive: %+v", err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			names = append(names, hdr.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
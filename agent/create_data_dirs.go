@@ -20,5 +20,9 @@ func (s *Server) CreateSegmentDataDirectories(ctx context.Context, in *idl.Creat
 		}
 
 	}
+
+	// The new segments' data directories may be scanned once upgraded.
+	s.addDataDirs(datadirs)
+
 	return &idl.CreateSegmentDataDirReply{}, nil
 }
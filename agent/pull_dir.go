@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
+)
+
+// progressInterval is the least time between the progress reports of a
+// PullDir.
+var progressInterval = time.Second
+
+// PullDir copies a directory to this host from the FileServer of the hub or
+// of another agent, reporting progress to the hub as it goes.
+func (s *Server) PullDir(request *idl.PullDirRequest, stream idl.Agent_PullDirServer) error {
+	gplog.Info("agent pulling %s from %s into %s", request.Dir, request.Source, request.TargetDir)
+
+	conn, err := grpc.DialContext(stream.Context(), request.Source, grpc.WithInsecure())
+	if err != nil {
+		return xerrors.Errorf("connecting to %s: %w", request.Source, err)
+	}
+	defer conn.Close()
+
+	var lastReport time.Time
+	var reportErr error
+
+	start := time.Now()
+	stats, err := dircopy.Pull(stream.Context(), idl.NewFileServerClient(conn), request.Dir, request.TargetDir, dircopy.Options{
+		Exclude:        request.Excludes,
+		Delete:         request.Delete,
+		BandwidthLimit: request.BandwidthLimit,
+		Progress: func(p dircopy.Progress) {
+			if reportErr != nil || time.Since(lastReport) < progressInterval {
+				return
+			}
+
+			lastReport = time.Now()
+			reportErr = stream.Send(&idl.PullDirReply{Files: int64(p.Files), Bytes: p.Bytes})
+		},
+	})
+	metrics.ObserveCopy("pull_dir", start, stats.Bytes, err)
+
+	if err != nil {
+		gplog.Error("pulling %s from %s: %+v", request.Dir, request.Source, err)
+		return err
+	}
+
+	if reportErr != nil {
+		return reportErr
+	}
+
+	gplog.Info("agent pulled %d files (%d bytes) into %s", stats.Files, stats.Bytes, request.TargetDir)
+	return stream.Send(&idl.PullDirReply{
+		Files:   int64(stats.Files),
+		Bytes:   stats.Bytes,
+		Deleted: int64(stats.Deleted),
+	})
+}
@@ -2,8 +2,11 @@ package agent
 
 import (
 	"os/exec"
+	"time"
 
 	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 )
 
 var rsyncCommand = exec.Command
@@ -18,11 +21,15 @@ func (e RsyncError) Error() string {
 
 func Rsync(sourceDir, targetDir string, excludedFiles []string) error {
 	arguments := append([]string{
-		"--archive", "--delete",
+		"--archive", "--delete", "--stats",
 		sourceDir + "/", targetDir,
 	}, makeExclusionList(excludedFiles)...)
 
-	if _, err := rsyncCommand("rsync", arguments...).Output(); err != nil {
+	start := time.Now()
+	stats, err := rsyncCommand("rsync", arguments...).Output()
+	metrics.ObserveRsync("restore_master_backup", start, stats, err)
+
+	if err != nil {
 		return RsyncError{
 			errorText: extractTextFromError(err),
 		}
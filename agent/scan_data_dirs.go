@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/disk"
+)
+
+// ScanDataDirs measures the requested data directories, which must be those
+// of segments on this host; see Config.DataDirs. A request for no directories
+// reports only the agent's default parallelism.
+func (s *Server) ScanDataDirs(ctx context.Context, in *idl.ScanDataDirsRequest) (*idl.ScanDataDirsReply, error) {
+	for _, dir := range in.DataDirs {
+		if !s.isDataDir(dir) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s is not a segment data directory on this host", dir)
+		}
+	}
+
+	reply := &idl.ScanDataDirsReply{DefaultParallelism: int32(DefaultParallelism)}
+
+	for _, dir := range in.DataDirs {
+		bytes, files, err := disk.Scan(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		reply.Scans = append(reply.Scans, &idl.DataDirScan{DataDir: dir, Bytes: bytes, Files: files})
+	}
+
+	return reply, nil
+}
+
+// addDataDirs allows ScanDataDirs to scan dirs.
+func (s *Server) addDataDirs(dirs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dataDirs == nil {
+		s.dataDirs = make(map[string]bool)
+	}
+	for _, dir := range dirs {
+		s.dataDirs[filepath.Clean(dir)] = true
+	}
+}
+
+func (s *Server) isDataDir(dir string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dataDirs[filepath.Clean(dir)]
+}
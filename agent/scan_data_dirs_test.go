@@ -0,0 +1,76 @@
+package agent_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+func TestScanDataDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "gpseg0")
+	if err := os.MkdirAll(source, 0700); err != nil {
+		t.Fatalf("creating data directory: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "PG_VERSION"), []byte("9.4"), 0600); err != nil {
+		t.Fatalf("writing PG_VERSION: %+v", err)
+	}
+
+	server := agent.NewServer(agent.Config{DataDirs: []string{source}})
+
+	t.Run("scans the segment data directories", func(t *testing.T) {
+		reply, err := server.ScanDataDirs(context.Background(), &idl.ScanDataDirsRequest{DataDirs: []string{source + "/"}})
+		if err != nil {
+			t.Fatalf("ScanDataDirs() returned error %+v", err)
+		}
+
+		if len(reply.Scans) != 1 || reply.Scans[0].Bytes != 3 || reply.Scans[0].Files != 1 {
+			t.Errorf("got scans %v, want 3 bytes in 1 file", reply.Scans)
+		}
+	})
+
+	t.Run("reports the default parallelism for an empty request", func(t *testing.T) {
+		reply, err := server.ScanDataDirs(context.Background(), &idl.ScanDataDirsRequest{})
+		if err != nil {
+			t.Fatalf("ScanDataDirs() returned error %+v", err)
+		}
+
+		if reply.DefaultParallelism != agent.DefaultParallelism || len(reply.Scans) != 0 {
+			t.Errorf("got reply %v", reply)
+		}
+	})
+
+	t.Run("refuses other directories", func(t *testing.T) {
+		for _, other := range []string{dir, "/etc", filepath.Join(source, "..", "gpseg1")} {
+			_, err := server.ScanDataDirs(context.Background(), &idl.ScanDataDirsRequest{DataDirs: []string{source, other}})
+			if status.Code(err) != codes.PermissionDenied {
+				t.Errorf("scanning %s returned error %#v, want code %v", other, err, codes.PermissionDenied)
+			}
+		}
+	})
+
+	t.Run("scans the data directories that it creates", func(t *testing.T) {
+		target := filepath.Join(dir, "gpseg0_upgrade")
+		_, err := server.CreateSegmentDataDirectories(context.Background(), &idl.CreateSegmentDataDirRequest{Datadirs: []string{target}})
+		if err != nil {
+			t.Fatalf("CreateSegmentDataDirectories() returned error %+v", err)
+		}
+
+		if _, err := server.ScanDataDirs(context.Background(), &idl.ScanDataDirsRequest{DataDirs: []string{target}}); err != nil {
+			t.Errorf("ScanDataDirs() returned error %+v", err)
+		}
+	})
+}
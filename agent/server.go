@@ -4,39 +4,78 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/utils/daemon"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
 	"github.com/greenplum-db/gpupgrade/utils/log"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 )
 
+// HealthService is the name under which the agent reports its readiness to
+// grpc.health.v1 checkers.
+const HealthService = "idl.Agent"
+
+// MasterBackupName is the directory in the state directory into which the
+// upgraded master data directory is copied during execute. It is the only
+// directory that the agent's FileServer serves, to other agents that pull the
+// copy from this host.
+const MasterBackupName = "upgraded-master.bak"
+
 type Server struct {
 	conf Config
 
 	mu      sync.Mutex
 	server  *grpc.Server
 	lis     net.Listener
+	metrics *metrics.Server
+	health  *health.Server
 	stopped chan struct{}
 	daemon  bool
+
+	dataDirs map[string]bool // that ScanDataDirs may scan
 }
 
 type Config struct {
 	Port     int
 	StateDir string
+
+	// MetricsPort is the port on which Prometheus metrics are served. Metrics
+	// are disabled when it is zero.
+	MetricsPort int
+
+	// DataDirs are the data directories of the segments on this host. They,
+	// and those that the hub has the agent create, are the only directories
+	// that ScanDataDirs will scan.
+	DataDirs []string
 }
 
 func NewServer(conf Config) *Server {
-	return &Server{
+	s := &Server{
 		conf:    conf,
 		stopped: make(chan struct{}, 1),
 	}
+	s.addDataDirs(conf.DataDirs)
+
+	return s
+}
+
+// servesDir decides which directories the agent's FileServer may serve: only
+// its copy of the upgraded master data directory. The rest of the state
+// directory, which on the master host is also the hub's, holds secrets.
+func (s *Server) servesDir(dir string) bool {
+	return dir == filepath.Join(s.conf.StateDir, MasterBackupName)
 }
 
 // MakeDaemon tells the Server to disconnect its stdout/stderr streams after
@@ -52,21 +91,47 @@ func (s *Server) Start() {
 		gplog.Fatal(err, "failed to listen")
 	}
 
-	// Set up an interceptor function to log any panics we get from request
-	// handlers.
-	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		defer log.WritePanics()
-		return handler(ctx, req)
+	var metricsServer *metrics.Server
+	if s.conf.MetricsPort != 0 {
+		metricsServer, err = metrics.Listen(s.conf.MetricsPort)
+		if err != nil {
+			gplog.Fatal(err, "failed to listen for metrics")
+		}
 	}
-	server := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+
+	// Set up interceptors to log requests and any panics we get from their
+	// handlers, and to record request metrics.
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(log.UnaryServerInterceptor(metrics.GRPCServer.UnaryServerInterceptor())),
+		grpc.StreamInterceptor(log.StreamServerInterceptor(metrics.GRPCServer.StreamServerInterceptor())),
+	)
+
+	// The agent is ready as soon as it serves; it needs nothing else to handle
+	// requests.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(HealthService, healthpb.HealthCheckResponse_SERVING)
 
 	s.mu.Lock()
 	s.server = server
 	s.lis = lis
+	s.metrics = metricsServer
+	s.health = healthServer
 	s.mu.Unlock()
 
 	idl.RegisterAgentServer(server, s)
+	healthpb.RegisterHealthServer(server, healthServer)
+	idl.RegisterFileServerServer(server, &dircopy.FileServer{Allow: s.servesDir})
 	reflection.Register(server)
+	metrics.GRPCServer.InitializeMetrics(server)
+
+	if metricsServer != nil {
+		go func() {
+			err := metricsServer.Serve()
+			if err != http.ErrServerClosed {
+				gplog.Error("metrics server stopped: %v", err)
+			}
+		}()
+	}
 
 	if s.daemon {
 		// Send an identifier string back to the hub, and log it locally for
@@ -95,6 +160,16 @@ func (s *Server) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+
+	if s.metrics != nil {
+		if err := s.metrics.Stop(); err != nil {
+			gplog.Error("stopping metrics server: %v", err)
+		}
+	}
+
 	if s.server != nil {
 		s.server.Stop()
 		<-s.stopped
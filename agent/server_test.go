@@ -1,12 +1,21 @@
 package agent_test
 
 import (
+	"context"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/testutils"
 	"github.com/greenplum-db/gpupgrade/utils"
 
@@ -70,4 +79,58 @@ var _ = Describe("Server", func() {
 		Eventually(exists).Should(BeTrue())
 		os.RemoveAll(dir)
 	})
+
+	It("reports that it is ready to health checks", func() {
+		server := agent.NewServer(agentConf)
+		go server.Start()
+		defer server.Stop()
+
+		conn, err := grpc.Dial("localhost:"+strconv.Itoa(agentConf.Port), grpc.WithInsecure(), grpc.WithBlock())
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		reply, err := healthpb.NewHealthClient(conn).Check(context.Background(),
+			&healthpb.HealthCheckRequest{Service: agent.HealthService})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply.Status).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+		os.RemoveAll(agentConf.StateDir)
+	})
+
+	It("serves only its copy of the upgraded master data directory", func() {
+		backup := filepath.Join(agentConf.StateDir, agent.MasterBackupName)
+		Expect(os.MkdirAll(backup, 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(agentConf.StateDir, "config.json"), []byte("{}"), 0600)).To(Succeed())
+		defer os.RemoveAll(agentConf.StateDir)
+
+		server := agent.NewServer(agentConf)
+		go server.Start()
+		defer server.Stop()
+
+		conn, err := grpc.Dial("localhost:"+strconv.Itoa(agentConf.Port), grpc.WithInsecure(), grpc.WithBlock())
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		serve := func(dir string) error {
+			stream, err := idl.NewFileServerClient(conn).ServeFiles(context.Background(), &idl.ServeFilesRequest{Dir: dir})
+			if err != nil {
+				return err
+			}
+
+			for {
+				_, err := stream.Recv()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		Expect(serve(backup)).To(Succeed())
+		for _, dir := range []string{agentConf.StateDir, filepath.Join(backup, "..")} {
+			Expect(status.Code(serve(dir))).To(Equal(codes.PermissionDenied), dir)
+		}
+	})
 })
@@ -1,9 +1,9 @@
 package agent
 
 import (
-	"context"
 	"os"
 	"os/exec"
+	"sync"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	multierror "github.com/hashicorp/go-multierror"
@@ -14,14 +14,36 @@ import (
 	"github.com/greenplum-db/gpupgrade/utils"
 )
 
-func (s *Server) UpgradePrimaries(ctx context.Context, request *idl.UpgradePrimariesRequest) (*idl.UpgradePrimariesReply, error) {
+func (s *Server) UpgradePrimaries(request *idl.UpgradePrimariesRequest, stream idl.Agent_UpgradePrimariesServer) error {
 	gplog.Info("agent starting %s", idl.Substep_UPGRADE_PRIMARIES)
 
-	err := UpgradePrimaries(s.conf.StateDir, request)
+	// Segments are upgraded concurrently, but a stream may only be sent to by
+	// one goroutine at a time.
+	var mu sync.Mutex
+	progress := func(content int32, phase idl.SegmentProgress_Phase) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Progress is only informational; the upgrade continues even if the
+		// hub has stopped listening.
+		err := stream.Send(&idl.UpgradePrimariesReply{
+			Progress: &idl.SegmentProgress{Content: content, Phase: phase},
+		})
+		if err != nil {
+			gplog.Debug("sending progress of content %d: %v", content, err)
+		}
+	}
 
-	return &idl.UpgradePrimariesReply{}, err
+	err := UpgradePrimaries(s.conf.StateDir, request, progress)
+
+	// Send any pg_upgrade failures to the hub in a structured form.
+	return upgrade.StatusError(err)
 }
 
+// ProgressFunc is told as each segment enters a new phase of its upgrade. It
+// is called by several goroutines at once.
+type ProgressFunc func(content int32, phase idl.SegmentProgress_Phase)
+
 // Allow exec.Command to be mocked out by exectest.NewCommand.
 var execCommand = exec.Command
 
@@ -31,7 +53,17 @@ type Segment struct {
 	WorkDir string // the pg_upgrade working directory, where logs are stored
 }
 
-func UpgradePrimaries(stateDir string, request *idl.UpgradePrimariesRequest) error {
+// DefaultParallelism is the number of segments that are upgraded at once on
+// this host when the hub does not ask for a particular number. Each pg_upgrade
+// runs both servers of its segment and restores the segment's schema, and a
+// host's segments already share its memory with each other, so the default is
+// kept small rather than scaled with the CPUs. The host-parallelism setting
+// raises it for hosts with the memory to spare.
+const DefaultParallelism = 2
+
+// UpgradePrimaries upgrades the segments of the request, telling progress of
+// each segment's phases if it is not nil.
+func UpgradePrimaries(stateDir string, request *idl.UpgradePrimariesRequest, progress ProgressFunc) error {
 	segments, err := buildSegments(request, stateDir)
 
 	if err != nil {
@@ -43,19 +75,34 @@ func UpgradePrimaries(stateDir string, request *idl.UpgradePrimariesRequest) err
 		return err
 	}
 
+	if progress == nil {
+		progress = func(int32, idl.SegmentProgress_Phase) {}
+	}
+
 	//
-	// Upgrade each segment concurrently
+	// Upgrade the segments concurrently, at most Parallelism at a time
 	//
-	upgradeResponse := make(chan error, len(segments))
+	parallelism := int(request.Parallelism)
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
 
-	for _, segment := range segments {
-		segment := segment // capture the range variable
+	work := make(chan Segment)
+	upgradeResponse := make(chan error, len(segments))
 
+	for i := 0; i < parallelism && i < len(segments); i++ {
 		go func() {
-			upgradeResponse <- upgradeSegment(segment, request, host)
+			for segment := range work {
+				upgradeResponse <- upgradeSegment(segment, request, host, progress)
+			}
 		}()
 	}
 
+	for _, segment := range segments {
+		work <- segment
+	}
+	close(work)
+
 	for range segments {
 		response := <-upgradeResponse
 		if response != nil {
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"golang.org/x/xerrors"
@@ -86,7 +87,7 @@ func TestUpgradePrimary(t *testing.T) {
 			CheckOnly:    true,
 			UseLinkMode:  false,
 		}
-		err := agent.UpgradePrimaries(tempDir, request)
+		err := agent.UpgradePrimaries(tempDir, request, nil)
 		if err == nil {
 			t.Fatal("UpgradeSegments() returned no error")
 		}
@@ -112,7 +113,7 @@ func TestUpgradePrimary(t *testing.T) {
 			DataDirPairs: pairs,
 			CheckOnly:    false,
 			UseLinkMode:  false}
-		err := agent.UpgradePrimaries(tempDir, request)
+		err := agent.UpgradePrimaries(tempDir, request, nil)
 		if err == nil {
 			t.Fatal("UpgradeSegments() returned no error")
 		}
@@ -142,7 +143,7 @@ func TestUpgradePrimary(t *testing.T) {
 				}
 			}))
 
-		_ = agent.UpgradePrimaries(tempDir, request)
+		_ = agent.UpgradePrimaries(tempDir, request, nil)
 	})
 
 	t.Run("it returns errors in parallel if the copy step fails", func(t *testing.T) {
@@ -150,7 +151,7 @@ func TestUpgradePrimary(t *testing.T) {
 		agent.SetExecCommand(exectest.NewCommand(agent.Success))
 
 		request := buildRequest(pairs)
-		err = agent.UpgradePrimaries(tempDir, request)
+		err = agent.UpgradePrimaries(tempDir, request, nil)
 
 		// We expect each part of the request to return its own ExitError,
 		// containing the expected message from FailedRsync.
@@ -171,6 +172,25 @@ func TestUpgradePrimary(t *testing.T) {
 		}
 	})
 
+	t.Run("it upgrades every segment when limited to one at a time", func(t *testing.T) {
+		agent.SetRsyncCommand(exectest.NewCommand(agent.FailedRsync))
+		agent.SetExecCommand(exectest.NewCommand(agent.Success))
+		defer ResetCommands()
+
+		request := buildRequest(pairs)
+		request.Parallelism = 1
+		err = agent.UpgradePrimaries(tempDir, request, nil)
+
+		var multiErr *multierror.Error
+		if !xerrors.As(err, &multiErr) {
+			t.Fatalf("got error %#v, want type %T", err, multiErr)
+		}
+
+		if len(multiErr.Errors) != len(pairs) {
+			t.Errorf("received %d errors, want %d", len(multiErr.Errors), len(pairs))
+		}
+	})
+
 	t.Run("it grabs a copy of the master backup directory before running upgrade", func(t *testing.T) {
 		defer ResetCommands()
 
@@ -219,7 +239,7 @@ func TestUpgradePrimary(t *testing.T) {
 		request := buildRequest(pairs)
 		request.MasterBackupDir = "/some/master/backup/dir"
 
-		err := agent.UpgradePrimaries(tempDir, request)
+		err := agent.UpgradePrimaries(tempDir, request, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -245,6 +265,53 @@ func TestUpgradePrimary(t *testing.T) {
 				targetDataDirsUsed)
 		}
 	})
+
+	t.Run("it reports the phases of each segment", func(t *testing.T) {
+		agent.SetRsyncCommand(exectest.NewCommand(agent.Success))
+		agent.SetExecCommand(exectest.NewCommand(agent.Success))
+		defer ResetCommands()
+
+		var mu sync.Mutex
+		phases := make(map[int32][]idl.SegmentProgress_Phase)
+		progress := func(content int32, phase idl.SegmentProgress_Phase) {
+			mu.Lock()
+			defer mu.Unlock()
+			phases[content] = append(phases[content], phase)
+		}
+
+		request := buildRequest(pairs)
+		err := agent.UpgradePrimaries(tempDir, request, progress)
+		if err != nil {
+			t.Fatalf("UpgradePrimaries() returned error %+v", err)
+		}
+
+		expected := []idl.SegmentProgress_Phase{
+			idl.SegmentProgress_RESTORE_BACKUP,
+			idl.SegmentProgress_UPGRADE,
+			idl.SegmentProgress_DONE,
+		}
+		for _, pair := range pairs {
+			if !reflect.DeepEqual(phases[pair.Content], expected) {
+				t.Errorf("content %d went through phases %v, want %v", pair.Content, phases[pair.Content], expected)
+			}
+		}
+
+		phases = make(map[int32][]idl.SegmentProgress_Phase)
+		agent.SetExecCommand(exectest.NewCommand(agent.FailedMain))
+
+		request.CheckOnly = true
+		_ = agent.UpgradePrimaries(tempDir, request, progress)
+
+		expected = []idl.SegmentProgress_Phase{
+			idl.SegmentProgress_CHECK,
+			idl.SegmentProgress_FAILED,
+		}
+		for _, pair := range pairs {
+			if !reflect.DeepEqual(phases[pair.Content], expected) {
+				t.Errorf("content %d went through phases %v, want %v", pair.Content, phases[pair.Content], expected)
+			}
+		}
+	})
 }
 
 type rsyncRequest struct {
@@ -253,6 +320,7 @@ type rsyncRequest struct {
 	targetDir   string
 	archiveFlag string
 	deleteFlag  string
+	statsFlag   string
 	exclusions  []string
 }
 
@@ -269,15 +337,19 @@ func rsyncCall(utility string, arguments []string) rsyncRequest {
 	}
 
 	if len(arguments) > 2 {
-		r.sourceDir = arguments[2]
+		r.statsFlag = arguments[2]
 	}
 
 	if len(arguments) > 3 {
-		r.targetDir = arguments[3]
+		r.sourceDir = arguments[3]
 	}
 
 	if len(arguments) > 4 {
-		r.exclusions = arguments[4:]
+		r.targetDir = arguments[4]
+	}
+
+	if len(arguments) > 5 {
+		r.exclusions = arguments[5:]
 	}
 
 	return r
@@ -1,23 +1,52 @@
 package agent
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/upgrade"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 )
 
-func upgradeSegment(segment Segment, request *idl.UpgradePrimariesRequest, host string) error {
-	err := restoreBackup(request, segment)
+func upgradeSegment(segment Segment, request *idl.UpgradePrimariesRequest, host string, progress ProgressFunc) (err error) {
+	defer func() {
+		phase := idl.SegmentProgress_DONE
+		if err != nil {
+			phase = idl.SegmentProgress_FAILED
+		}
+		progress(segment.Content, phase)
+	}()
+
+	if !request.CheckOnly {
+		progress(segment.Content, idl.SegmentProgress_RESTORE_BACKUP)
+	}
+
+	err = restoreBackup(request, segment)
 
 	if err != nil {
 		return errors.Wrapf(err, "failed to restore master data directory backup on host %s for content id %d: %s",
 			host, segment.Content, err)
 	}
 
+	phase := idl.SegmentProgress_UPGRADE
+	if request.CheckOnly {
+		phase = idl.SegmentProgress_CHECK
+	}
+	progress(segment.Content, phase)
+
 	err = performUpgrade(segment, request)
 
 	if err != nil {
+		var upgradeErr *upgrade.Error
+		if xerrors.As(err, &upgradeErr) {
+			upgradeErr.Host = host
+		}
+
 		failedAction := "upgrade"
 		if request.CheckOnly {
 			failedAction = "check"
@@ -49,7 +78,22 @@ func performUpgrade(segment Segment, request *idl.UpgradePrimariesRequest) error
 		options = append(options, upgrade.WithLinkMode())
 	}
 
-	return upgrade.Run(segmentPair, options...)
+	start := time.Now()
+	err := upgrade.Run(segmentPair, options...)
+	recordUpgrade(segment, request.CheckOnly, time.Since(start), err)
+
+	return err
+}
+
+func recordUpgrade(segment Segment, checkOnly bool, elapsed time.Duration, err error) {
+	content := strconv.Itoa(int(segment.Content))
+	mode := "upgrade"
+	if checkOnly {
+		mode = "check"
+	}
+
+	metrics.SegmentUpgradeDuration.WithLabelValues(content, mode).Observe(elapsed.Seconds())
+	metrics.SegmentUpgradeExitStatus.WithLabelValues(content, mode).Set(float64(metrics.ExitStatus(err)))
 }
 
 func restoreBackup(request *idl.UpgradePrimariesRequest, segment Segment) error {
@@ -57,7 +101,7 @@ func restoreBackup(request *idl.UpgradePrimariesRequest, segment Segment) error
 		return nil
 	}
 
-	return Rsync(request.MasterBackupDir, segment.TargetDataDir, []string{
+	excludes := []string{
 		"internal.auto.conf",
 		"postgresql.conf",
 		"pg_hba.conf",
@@ -65,5 +109,18 @@ func restoreBackup(request *idl.UpgradePrimariesRequest, segment Segment) error
 		"gp_dbid",
 		"gpssh.conf",
 		"gpperfmon",
+	}
+
+	if request.CopyEngine != dircopy.EngineNative {
+		return Rsync(request.MasterBackupDir, segment.TargetDataDir, excludes)
+	}
+
+	start := time.Now()
+	stats, err := dircopy.Copy(request.MasterBackupDir, segment.TargetDataDir, dircopy.Options{
+		Exclude: excludes,
+		Delete:  true,
 	})
+	metrics.ObserveCopy("restore_master_backup", start, stats.Bytes, err)
+
+	return err
 }
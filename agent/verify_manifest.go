@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+)
+
+// VerifyManifest checks a directory on this host, such as the copy of the
+// upgraded master data directory, against the manifest sent by the hub.
+func (s *Server) VerifyManifest(stream idl.Agent_VerifyManifestServer) error {
+	mismatches, err := dircopy.VerifyManifest(stream)
+	if err != nil {
+		gplog.Error("verifying manifest: %+v", err)
+		return err
+	}
+
+	reply := &idl.VerifyManifestReply{}
+	for _, m := range mismatches {
+		gplog.Error("%s does not match the manifest: %s", m.Path, m.Reason)
+		reply.Mismatches = append(reply.Mismatches, &idl.ManifestMismatch{Path: m.Path, Reason: m.Reason})
+	}
+
+	return stream.SendAndClose(reply)
+}
@@ -0,0 +1,65 @@
+package commanders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// CollectLogs retrieves a diagnostic bundle from the hub and writes it to a
+// timestamped archive in outputDir. The path to the archive is returned.
+func CollectLogs(client idl.CliToHubClient, outputDir string, maxBytesPerHost uint64) (_ string, err error) {
+	s := Substep("Collecting logs...")
+	defer s.Finish(&err)
+
+	name := fmt.Sprintf("gpupgrade_logs_%s.tar.gz", time.Now().Format("20060102T150405"))
+	path := filepath.Join(outputDir, name)
+
+	stream, err := client.CollectLogs(context.Background(), &idl.CollectLogsRequest{
+		MaxBytesPerHost: maxBytesPerHost,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("collecting logs: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", xerrors.Errorf("creating log archive: %w", err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil {
+			err = multierror.Append(err, xerrors.Errorf("closing log archive: %w", cerr)).ErrorOrNil()
+		}
+
+		// Don't leave a truncated archive lying around.
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	for {
+		var reply *idl.CollectLogsReply
+		reply, err = stream.Recv()
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			return "", xerrors.Errorf("receiving logs: %w", err)
+		}
+
+		if _, err = file.Write(reply.Data); err != nil {
+			return "", xerrors.Errorf("writing log archive: %w", err)
+		}
+	}
+
+	return path, nil
+}
@@ -0,0 +1,381 @@
+package commanders
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// DashboardRefresh is how often the dashboard is redrawn, so that the elapsed
+// times of segments keep counting while no messages arrive.
+var DashboardRefresh = 500 * time.Millisecond
+
+// dashboardTail is the number of lines of output that the dashboard keeps; as
+// many of them as fit are shown.
+const dashboardTail = 100
+
+var phases = map[idl.SegmentProgress_Phase]string{
+	idl.SegmentProgress_RESTORE_BACKUP: "restoring backup",
+	idl.SegmentProgress_CHECK:          "pg_upgrade --check",
+	idl.SegmentProgress_UPGRADE:        "pg_upgrade",
+	idl.SegmentProgress_DONE:           "done",
+	idl.SegmentProgress_FAILED:         "failed",
+}
+
+// UseDashboard returns true if the dashboard can be drawn on stdout, and the
+// size of the terminal to draw it in.
+func UseDashboard() (width, height int, ok bool) {
+	fd := int(os.Stdout.Fd())
+	if !terminal.IsTerminal(fd) {
+		return 0, 0, false
+	}
+
+	width, height, err := terminal.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// DashboardLoop is UILoop for a terminal of the given size. Rather than a line
+// per substep, it repeatedly redraws, over its last drawing, a dashboard of the
+// substeps, of the phase of each primary segment that the agents have reported,
+// and of the last lines of output. It returns the same errors as UILoop.
+func DashboardLoop(stream receiver, out io.Writer, width, height int) error {
+	type result struct {
+		msg *idl.Message
+		err error
+	}
+
+	results := make(chan result)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			results <- result{msg, err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(DashboardRefresh)
+	defer ticker.Stop()
+
+	d := newDashboard(width, height)
+
+	var err error
+	for err == nil {
+		select {
+		case r := <-results:
+			err = r.err
+			if err == nil {
+				d.update(r.msg)
+			}
+
+		case <-ticker.C:
+			d.draw(out)
+		}
+	}
+
+	d.draw(out)
+
+	if err != io.EOF {
+		printUpgradeFailures(err)
+		return err
+	}
+
+	if d.paused {
+		return ErrPaused
+	}
+
+	return nil
+}
+
+type segmentKey struct {
+	host    string
+	content int32
+}
+
+type segmentState struct {
+	segmentKey
+	phase      idl.SegmentProgress_Phase
+	start, end int64 // seconds since the Unix epoch; end is zero until finished
+}
+
+func (s *segmentState) finished() bool {
+	return s.phase == idl.SegmentProgress_DONE || s.phase == idl.SegmentProgress_FAILED
+}
+
+// rank orders failed segments first, then those in progress, then those done.
+func (s *segmentState) rank() int {
+	switch s.phase {
+	case idl.SegmentProgress_FAILED:
+		return 0
+	case idl.SegmentProgress_DONE:
+		return 2
+	default:
+		return 1
+	}
+}
+
+type dashboard struct {
+	width, height int
+	now           func() time.Time
+
+	substeps []*idl.SubstepStatus // in the order they started
+	segments map[segmentKey]*segmentState
+	tail     []string
+	partial  string // the last line of output, until it is ended
+	paused   bool
+
+	// estimate is the last estimate of execute's duration, received at
+	// estimated.
+	estimate  *idl.Estimate
+	estimated time.Time
+
+	drawn int // the number of lines last drawn
+}
+
+func newDashboard(width, height int) *dashboard {
+	return &dashboard{
+		width:    width,
+		height:   height,
+		now:      time.Now,
+		segments: make(map[segmentKey]*segmentState),
+	}
+}
+
+func (d *dashboard) update(msg *idl.Message) {
+	switch x := msg.Contents.(type) {
+	case *idl.Message_Chunk:
+		d.addOutput(x.Chunk.Buffer)
+
+	case *idl.Message_Status:
+		// Check that the status can be shown before keeping it.
+		FormatStatus(x.Status)
+
+		d.paused = x.Status.Status == idl.Status_PAUSED
+		for _, s := range d.substeps {
+			if s.Step == x.Status.Step {
+				s.Status = x.Status.Status
+				return
+			}
+		}
+		d.substeps = append(d.substeps, x.Status)
+
+	case *idl.Message_Progress:
+		p := x.Progress
+		key := segmentKey{p.Host, p.Content}
+
+		s, ok := d.segments[key]
+		if !ok || s.finished() {
+			// A segment that finished in an earlier substep or run is
+			// starting again.
+			s = &segmentState{segmentKey: key, start: p.Time}
+			d.segments[key] = s
+		}
+
+		s.phase = p.Phase
+		if s.finished() {
+			s.end = p.Time
+		}
+
+	case *idl.Message_Estimate:
+		d.estimate = x.Estimate
+		d.estimated = d.now()
+
+	default:
+		panic(fmt.Sprintf("unknown message type: %T", x))
+	}
+}
+
+func (d *dashboard) addOutput(buf []byte) {
+	lines := strings.Split(d.partial+string(buf), "\n")
+	d.partial = lines[len(lines)-1]
+
+	d.tail = append(d.tail, lines[:len(lines)-1]...)
+	if len(d.tail) > dashboardTail {
+		d.tail = d.tail[len(d.tail)-dashboardTail:]
+	}
+}
+
+// frame returns the lines of the dashboard, fitted to its size.
+func (d *dashboard) frame() []string {
+	var lines []string
+	if d.estimate != nil {
+		// Count down between estimates.
+		remaining := time.Duration(d.estimate.Remaining)*time.Second - d.now().Sub(d.estimated)
+		lines = append(lines, FormatRemaining(maxDuration(remaining, 0)), "")
+	}
+
+	for _, s := range d.substeps {
+		lines = append(lines, FormatStatus(s))
+	}
+
+	tail := d.tail
+	if d.partial != "" {
+		tail = append(tail[:len(tail):len(tail)], d.partial)
+	}
+
+	// Leave the last line of the terminal free, so that drawing the frame
+	// never scrolls it.
+	room := max(d.height-1-len(lines), 0)
+
+	tailLines := min(len(tail), 10)
+	if len(d.segments) > 0 {
+		tailLines = min(tailLines, room/3)
+	}
+
+	if len(d.segments) > 0 {
+		rows := room - 3 // the blank line, the summary and the header
+		if tailLines > 0 {
+			rows -= tailLines + 1
+		}
+
+		lines = append(lines, "")
+		lines = append(lines, d.segmentLines(rows)...)
+	}
+
+	if tailLines > 0 {
+		lines = append(lines, "")
+		for _, line := range tail[len(tail)-tailLines:] {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = fitLine(line, d.width)
+	}
+
+	return lines
+}
+
+// segmentLines returns a summary of the segments, and a table of their phases
+// with at most rows rows.
+func (d *dashboard) segmentLines(rows int) []string {
+	segments := make([]*segmentState, 0, len(d.segments))
+	counts := make(map[int]int)
+	for _, s := range d.segments {
+		segments = append(segments, s)
+		counts[s.rank()]++
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		a, b := segments[i], segments[j]
+		if a.rank() != b.rank() {
+			return a.rank() < b.rank()
+		}
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		return a.content < b.content
+	})
+
+	lines := []string{fmt.Sprintf("Segments: %d in progress, %d done, %d failed", counts[1], counts[2], counts[0])}
+
+	more := 0
+	if len(segments) > rows {
+		rows = max(rows-1, 0)
+		more = len(segments) - rows
+		segments = segments[:rows]
+	}
+
+	var buf bytes.Buffer
+	var t tabwriter.Writer
+	t.Init(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(&t, "HOST\tCONTENT\tPHASE\tELAPSED\t")
+	for _, s := range segments {
+		end := s.end
+		if end == 0 {
+			end = d.now().Unix()
+		}
+		elapsed := time.Duration(max64(end-s.start, 0)) * time.Second
+
+		fmt.Fprintf(&t, "%s\t%d\t%s\t%s\t\n", s.host, s.content, phases[s.phase], elapsed)
+	}
+	t.Flush()
+
+	lines = append(lines, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")...)
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("... and %d more", more))
+	}
+
+	return lines
+}
+
+// draw writes the dashboard over the one last drawn.
+func (d *dashboard) draw(out io.Writer) {
+	var buf bytes.Buffer
+
+	if d.drawn > 0 {
+		// Move to the start of the first line last drawn.
+		fmt.Fprintf(&buf, "\r\x1b[%dA", d.drawn)
+	}
+
+	lines := d.frame()
+	for _, line := range lines {
+		// Clear each line before writing it, since a line that fills the
+		// terminal leaves the cursor on its last column. Then clear anything
+		// left below.
+		fmt.Fprintf(&buf, "\x1b[K%s\n", line)
+	}
+	buf.WriteString("\x1b[J")
+
+	d.drawn = len(lines)
+	out.Write(buf.Bytes())
+}
+
+// fitLine makes line printable on a single line of width columns.
+func fitLine(line string, width int) string {
+	// Only the text after a carriage return would be visible.
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.Replace(line, "\t", "    ", -1)
+
+	runes := []rune(line)
+	if width > 0 && len(runes) > width {
+		runes = runes[:width]
+	}
+
+	return string(runes)
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,175 @@
+package commanders_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+func statusMsg(substep idl.Substep, status idl.Status) *idl.Message {
+	return &idl.Message{Contents: &idl.Message_Status{&idl.SubstepStatus{
+		Step:   substep,
+		Status: status,
+	}}}
+}
+
+func progressMsg(host string, content int32, phase idl.SegmentProgress_Phase, time int64) *idl.Message {
+	return &idl.Message{Contents: &idl.Message_Progress{&idl.SegmentProgress{
+		Host:    host,
+		Content: content,
+		Phase:   phase,
+		Time:    time,
+	}}}
+}
+
+func outputMsg(output string) *idl.Message {
+	return &idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{
+		Buffer: []byte(output),
+		Type:   idl.Chunk_STDOUT,
+	}}}
+}
+
+// dashboardLines runs DashboardLoop on msgs, and returns the lines of the one
+// dashboard that it draws.
+func dashboardLines(t *testing.T, msgs msgStream, width, height int) ([]string, error) {
+	t.Helper()
+
+	// Draw only once the stream has ended.
+	refresh := commanders.DashboardRefresh
+	commanders.DashboardRefresh = time.Hour
+	defer func() { commanders.DashboardRefresh = refresh }()
+
+	var out bytes.Buffer
+	err := commanders.DashboardLoop(&msgs, &out, width, height)
+
+	drawing := out.String()
+	if !strings.HasSuffix(drawing, "\n\x1b[J") {
+		t.Fatalf("dashboard %q does not end by clearing the rest of the screen", drawing)
+	}
+	drawing = strings.TrimSuffix(drawing, "\n\x1b[J")
+
+	lines := strings.Split(drawing, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "\x1b[K") {
+			t.Errorf("line %q is not cleared before it is written", line)
+		}
+		lines[i] = strings.TrimPrefix(line, "\x1b[K")
+
+		if len(lines[i]) > width {
+			t.Errorf("line %q is wider than %d", lines[i], width)
+		}
+	}
+
+	return lines, err
+}
+
+func TestDashboardLoop(t *testing.T) {
+	t.Run("shows substeps, segments and the last lines of output", func(t *testing.T) {
+		msgs := msgStream{
+			statusMsg(idl.Substep_UPGRADE_MASTER, idl.Status_COMPLETE),
+			statusMsg(idl.Substep_UPGRADE_PRIMARIES, idl.Status_RUNNING),
+			outputMsg("upgrading wave 1 of 1: contents [0 1 2]\n"),
+			progressMsg("sdw1", 0, idl.SegmentProgress_RESTORE_BACKUP, 100),
+			progressMsg("sdw2", 2, idl.SegmentProgress_UPGRADE, 100),
+			progressMsg("sdw1", 1, idl.SegmentProgress_RESTORE_BACKUP, 105),
+			progressMsg("sdw1", 0, idl.SegmentProgress_UPGRADE, 110),
+			progressMsg("sdw2", 2, idl.SegmentProgress_FAILED, 130),
+			progressMsg("sdw1", 1, idl.SegmentProgress_DONE, 165),
+			progressMsg("sdw1", 0, idl.SegmentProgress_DONE, 190),
+			outputMsg("still "),
+			outputMsg("going"),
+		}
+
+		lines, err := dashboardLines(t, msgs, 80, 24)
+		if err != nil {
+			t.Errorf("DashboardLoop() returned error %+v", err)
+		}
+
+		expected := [][]string{
+			strings.Fields(commanders.FormatStatus(msgs[0].GetStatus())),
+			strings.Fields(commanders.FormatStatus(msgs[1].GetStatus())),
+			{},
+			strings.Fields("Segments: 0 in progress, 2 done, 1 failed"),
+			{"HOST", "CONTENT", "PHASE", "ELAPSED"},
+			{"sdw2", "2", "failed", "30s"},
+			{"sdw1", "0", "done", "1m30s"},
+			{"sdw1", "1", "done", "1m0s"},
+			{},
+			strings.Fields("upgrading wave 1 of 1: contents [0 1 2]"),
+			{"still", "going"},
+		}
+
+		var actual [][]string
+		for _, line := range lines {
+			actual = append(actual, strings.Fields(line))
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got dashboard\n%s\nwant lines %q", strings.Join(lines, "\n"), expected)
+		}
+	})
+
+	t.Run("shows the estimated time remaining", func(t *testing.T) {
+		msgs := msgStream{
+			{Contents: &idl.Message_Estimate{&idl.Estimate{Remaining: 3600}}},
+			statusMsg(idl.Substep_UPGRADE_MASTER, idl.Status_RUNNING),
+		}
+
+		lines, err := dashboardLines(t, msgs, 80, 24)
+		if err != nil {
+			t.Errorf("DashboardLoop() returned error %+v", err)
+		}
+
+		expected := []string{"Estimated time remaining: 1h00m", "", commanders.FormatStatus(msgs[1].GetStatus())}
+		if !reflect.DeepEqual(lines, expected) {
+			t.Errorf("got dashboard %q, want %q", lines, expected)
+		}
+	})
+
+	t.Run("fits the segments to the terminal", func(t *testing.T) {
+		msgs := msgStream{statusMsg(idl.Substep_UPGRADE_PRIMARIES, idl.Status_RUNNING)}
+		for content := int32(0); content < 10; content++ {
+			msgs = append(msgs, progressMsg("sdw1", content, idl.SegmentProgress_UPGRADE, time.Now().Unix()))
+		}
+
+		lines, err := dashboardLines(t, msgs, 20, 8)
+		if err != nil {
+			t.Errorf("DashboardLoop() returned error %+v", err)
+		}
+
+		if len(lines) != 7 {
+			t.Errorf("got %d lines, want 7:\n%s", len(lines), strings.Join(lines, "\n"))
+		}
+
+		last := lines[len(lines)-1]
+		if last != "... and 8 more" {
+			t.Errorf("last line is %q, want %q", last, "... and 8 more")
+		}
+	})
+
+	t.Run("returns ErrPaused when the stream ends with a paused substep", func(t *testing.T) {
+		msgs := msgStream{statusMsg(idl.Substep_UPGRADE_PRIMARIES, idl.Status_PAUSED)}
+
+		_, err := dashboardLines(t, msgs, 80, 24)
+		if !xerrors.Is(err, commanders.ErrPaused) {
+			t.Errorf("returned %#v want %#v", err, commanders.ErrPaused)
+		}
+	})
+
+	t.Run("returns an error when a non io.EOF error is encountered", func(t *testing.T) {
+		expected := xerrors.New("bengie")
+
+		var out bytes.Buffer
+		err := commanders.DashboardLoop(&errStream{expected}, &out, 80, 24)
+		if err != expected {
+			t.Errorf("returned %#v want %#v", err, expected)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package commanders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// PrintEstimate asks the hub for the estimate of execute's duration and prints
+// it. Nothing is printed if the source cluster was not measured, since the
+// estimate is only advisory.
+func PrintEstimate(client idl.CliToHubClient) error {
+	reply, err := client.Estimate(context.Background(), &idl.EstimateRequest{})
+	if status.Code(err) == codes.FailedPrecondition {
+		gplog.Debug("not printing the estimate of execute: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	writeEstimate(os.Stdout, reply.Estimate)
+	return nil
+}
+
+func writeEstimate(w io.Writer, estimate *idl.Estimate) {
+	fmt.Fprintln(w, "Estimated duration of execute:")
+
+	var t tabwriter.Writer
+	t.Init(w, 0, 0, 2, ' ', 0)
+
+	var total time.Duration
+	for _, s := range estimate.Substeps {
+		d := time.Duration(s.Seconds) * time.Second
+		total += d
+
+		fmt.Fprintf(&t, "  %s\t%s\t\n", strings.TrimSuffix(lines[s.Step], "..."), FormatEstimate(d))
+	}
+	fmt.Fprintf(&t, "  Total\t%s\t\n", FormatEstimate(total))
+
+	t.Flush()
+}
+
+// FormatEstimate returns a duration rounded to the precision that an estimate
+// deserves: whole minutes, or seconds under a minute.
+func FormatEstimate(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int64(d.Round(time.Second)/time.Second))
+	}
+
+	d = d.Round(time.Minute)
+	hours, minutes := int64(d/time.Hour), int64(d%time.Hour/time.Minute)
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
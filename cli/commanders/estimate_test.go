@@ -0,0 +1,95 @@
+package commanders_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+)
+
+func TestPrintEstimate(t *testing.T) {
+	t.Run("prints the duration of each substep and the total", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := mock_idl.NewMockCliToHubClient(ctrl)
+		client.EXPECT().Estimate(gomock.Any(), &idl.EstimateRequest{}).Return(&idl.EstimateReply{
+			Estimate: &idl.Estimate{Substeps: []*idl.Estimate_Substep{
+				{Step: idl.Substep_UPGRADE_MASTER, Seconds: 130},
+				{Step: idl.Substep_COPY_MASTER, Seconds: 45},
+				{Step: idl.Substep_UPGRADE_PRIMARIES, Seconds: 3600},
+			}},
+		}, nil)
+
+		d := bufferStandardDescriptors(t)
+		err := commanders.PrintEstimate(client)
+		d.Close()
+		if err != nil {
+			t.Errorf("PrintEstimate() returned error %+v", err)
+		}
+
+		stdout, _ := d.Collect()
+
+		var actual []string
+		for _, line := range strings.Split(strings.TrimSpace(string(stdout)), "\n") {
+			actual = append(actual, strings.Join(strings.Fields(line), " "))
+		}
+
+		expected := []string{
+			"Estimated duration of execute:",
+			"Upgrading master 2m",
+			"Copying master to segments 45s",
+			"Upgrading segments 1h00m",
+			"Total 1h03m",
+		}
+		if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("got output\n%s\nwant\n%s", stdout, strings.Join(expected, "\n"))
+		}
+	})
+
+	t.Run("prints nothing if the source cluster was not measured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := mock_idl.NewMockCliToHubClient(ctrl)
+		client.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.FailedPrecondition, "not measured"))
+
+		d := bufferStandardDescriptors(t)
+		err := commanders.PrintEstimate(client)
+		d.Close()
+		if err != nil {
+			t.Errorf("PrintEstimate() returned error %+v", err)
+		}
+
+		if stdout, _ := d.Collect(); len(stdout) != 0 {
+			t.Errorf("printed %q", stdout)
+		}
+	})
+}
+
+func TestFormatEstimate(t *testing.T) {
+	cases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{59 * time.Second, "59s"},
+		{90 * time.Second, "2m"},
+		{59*time.Minute + 20*time.Second, "59m"},
+		{26*time.Hour + 5*time.Minute, "26h05m"},
+	}
+
+	for _, c := range cases {
+		if actual := commanders.FormatEstimate(c.duration); actual != c.expected {
+			t.Errorf("FormatEstimate(%v) returned %q, want %q", c.duration, actual, c.expected)
+		}
+	}
+}
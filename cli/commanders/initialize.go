@@ -9,16 +9,19 @@ import (
 	"github.com/greenplum-db/gpupgrade/hub"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/pidfile"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
 )
 
 // introduce this variable to allow exec.Command to be mocked out in tests
 var execCommandHubStart = exec.Command
-var execCommandHubCount = exec.Command
 
-// we create the state directory in the cli to ensure that at most one gpupgrade is occurring
-// at the same time.
+// CreateStateDir creates the state directory if it does not already exist.
+// Concurrent upgrades against the same state directory are prevented by the
+// hub, which holds an exclusive lock on its pidfile while it runs.
 func CreateStateDir() (err error) {
 	s := Substep("Creating state directory...")
 	defer s.Finish(&err)
@@ -58,17 +61,14 @@ func CreateInitialClusterConfigs() (err error) {
 		return err
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	fmt.Fprintf(file, "{}") // the hub will fill this in during initialization
-	return nil
+	// the hub will fill this in during initialization
+	contents := fmt.Sprintf("{%q: %d}\n", schema.VersionKey, hub.ConfigSchemaVersion)
+	return utils.AtomicallyWriteFile(filename, []byte(contents), 0600)
 }
 
-func StartHub() (err error) {
+// StartHub starts the hub in the background, if it's not already running. Any
+// additional args are passed to the "gpupgrade hub" command.
+func StartHub(args ...string) (err error) {
 	s := Substep("Starting hub...")
 	defer s.Finish(&err)
 
@@ -82,7 +82,7 @@ func StartHub() (err error) {
 		return nil
 	}
 
-	cmd := execCommandHubStart("gpupgrade", "hub", "--daemonize")
+	cmd := execCommandHubStart("gpupgrade", append([]string{"hub", "--daemonize"}, args...)...)
 	stdout, cmdErr := cmd.Output()
 	if cmdErr != nil {
 		err := fmt.Errorf("failed to start hub (%s)", cmdErr)
@@ -96,18 +96,19 @@ func StartHub() (err error) {
 	return nil
 }
 
+// IsHubRunning reports whether a hub holds the lock on the state directory.
 func IsHubRunning() (bool, error) {
-	script := `ps -ef | grep -wGc "[g]pupgrade hub"` // use square brackets to avoid finding yourself in matches
-	_, err := execCommandHubCount("bash", "-c", script).Output()
+	_, running, err := HubInfo()
+	return running, err
+}
 
-	if exitError, ok := err.(*exec.ExitError); ok {
-		if exitError.ProcessState.ExitCode() == 1 { // hub not found
-			return false, nil
-		}
-	}
-	if err != nil { // grep failed
-		return false, err
+// HubInfo returns the pid, port and start time recorded by the hub that holds
+// the lock on the state directory, and whether such a hub is running.
+func HubInfo() (pidfile.Info, bool, error) {
+	info, running, err := pidfile.Read(utils.GetStateDir())
+	if err != nil {
+		return pidfile.Info{}, false, xerrors.Errorf("checking hub pidfile: %w", err)
 	}
 
-	return true, nil
+	return info, running, nil
 }
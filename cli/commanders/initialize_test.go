@@ -13,25 +13,9 @@ import (
 
 	"github.com/greenplum-db/gpupgrade/hub"
 	"github.com/greenplum-db/gpupgrade/testutils/exectest"
+	"github.com/greenplum-db/gpupgrade/utils/pidfile"
 )
 
-// Streams the above stdout/err constants to the corresponding standard file
-// descriptors, alternately interleaving five-byte chunks.
-func IsHubRunning_True() {
-	fmt.Print("1")
-	os.Exit(0)
-}
-
-func IsHubRunning_False() {
-	fmt.Print("0")
-	os.Exit(1)
-}
-
-func IsHubRunning_Error() {
-	fmt.Print("bengie")
-	os.Exit(2)
-}
-
 func GpupgradeHub_good_Main() {
 	fmt.Print("Hi, Hub started.")
 }
@@ -43,9 +27,6 @@ func GpupgradeHub_bad_Main() {
 
 func init() {
 	exectest.RegisterMains(
-		IsHubRunning_True,
-		IsHubRunning_False,
-		IsHubRunning_Error,
 		GpupgradeHub_good_Main,
 		GpupgradeHub_bad_Main,
 	)
@@ -55,42 +36,101 @@ var (
 	g *GomegaWithT
 )
 
-func setup(t *testing.T) {
+// setup points GPUPGRADE_HOME at a new temporary directory, which is returned.
+func setup(t *testing.T) string {
 	g = NewGomegaWithT(t)
 	execCommandHubStart = nil
-	execCommandHubCount = nil
+
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+
+	oldStateDir, isSet := os.LookupEnv("GPUPGRADE_HOME")
+	restoreEnv = func() {
+		if isSet {
+			os.Setenv("GPUPGRADE_HOME", oldStateDir)
+		} else {
+			os.Unsetenv("GPUPGRADE_HOME")
+		}
+		os.RemoveAll(dir)
+	}
+
+	if err := os.Setenv("GPUPGRADE_HOME", dir); err != nil {
+		t.Fatalf("setting GPUPGRADE_HOME: %+v", err)
+	}
+
+	return dir
 }
 
+var restoreEnv = func() {}
+
 func teardown() {
 	execCommandHubStart = exec.Command
-	execCommandHubCount = exec.Command
+	restoreEnv()
+	restoreEnv = func() {}
+}
+
+// lockStateDir simulates a running hub by taking the pidfile lock.
+func lockStateDir(t *testing.T, stateDir string) *pidfile.File {
+	t.Helper()
+
+	lock, err := pidfile.Acquire(stateDir, 7527)
+	if err != nil {
+		t.Fatalf("locking state directory: %+v", err)
+	}
+
+	return lock
 }
 
 func TestIsHubRunning_ReturnsFalseWhenNotRunning(t *testing.T) {
 	setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_False)
+	running, err := IsHubRunning()
+	g.Expect(err).To(BeNil())
+	g.Expect(running).To(BeFalse())
+}
+
+func TestIsHubRunning_ReturnsFalseForAStalePidfile(t *testing.T) {
+	stateDir := setup(t)
+	defer teardown()
+
+	err := ioutil.WriteFile(pidfile.Path(stateDir), []byte(`{"Pid": 12345, "Port": 7527}`), 0600)
+	g.Expect(err).To(BeNil())
+
 	running, err := IsHubRunning()
 	g.Expect(err).To(BeNil())
 	g.Expect(running).To(BeFalse())
 }
 
 func TestIsHubRunning_ReturnsTrueWhenRunning(t *testing.T) {
-	setup(t)
+	stateDir := setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_True)
+	lock := lockStateDir(t, stateDir)
+	defer lock.Release()
+
 	running, err := IsHubRunning()
 	g.Expect(err).To(BeNil())
 	g.Expect(running).To(BeTrue())
+
+	info, _, err := HubInfo()
+	g.Expect(err).To(BeNil())
+	g.Expect(info.Pid).To(Equal(os.Getpid()))
+	g.Expect(info.Port).To(Equal(7527))
 }
 
 func TestIsHubRunning_ErrorsWhenCheckFails(t *testing.T) {
-	setup(t)
+	stateDir := setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_Error)
+	// Make the state directory a regular file, so the pidfile can't be opened.
+	notADir := filepath.Join(stateDir, "file")
+	err := ioutil.WriteFile(notADir, nil, 0600)
+	g.Expect(err).To(BeNil())
+	os.Setenv("GPUPGRADE_HOME", notADir)
+
 	running, err := IsHubRunning()
 	g.Expect(running).To(BeFalse())
 	g.Expect(err).ToNot(BeNil())
@@ -100,27 +140,32 @@ func TestStartHub_Succeeds(t *testing.T) {
 	setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_False)
 	execCommandHubStart = exectest.NewCommand(GpupgradeHub_good_Main)
 	err := StartHub()
 	g.Expect(err).To(BeNil())
 }
 
 func TestStartHub_FailsToStartWhenHubIsRunningErrors(t *testing.T) {
-	setup(t)
+	stateDir := setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_Error)
+	notADir := filepath.Join(stateDir, "file")
+	err := ioutil.WriteFile(notADir, nil, 0600)
+	g.Expect(err).To(BeNil())
+	os.Setenv("GPUPGRADE_HOME", notADir)
+
 	execCommandHubStart = exectest.NewCommand(GpupgradeHub_good_Main) // should not hit this, but fail it we do
-	err := StartHub()
+	err = StartHub()
 	g.Expect(err).ToNot(BeNil())
 }
 
 func TestStartHub_ReturnsWhenHubIsRunning(t *testing.T) {
-	setup(t)
+	stateDir := setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_True)
+	lock := lockStateDir(t, stateDir)
+	defer lock.Release()
+
 	execCommandHubStart = exectest.NewCommand(GpupgradeHub_bad_Main) // should not hit this, but fail if we do
 	err := StartHub()
 	g.Expect(err).To(BeNil())
@@ -130,7 +175,6 @@ func TestStartHub_FailsWhenStartingTheHubErrors(t *testing.T) {
 	setup(t)
 	defer teardown()
 
-	execCommandHubCount = exectest.NewCommand(IsHubRunning_False)
 	execCommandHubStart = exectest.NewCommand(GpupgradeHub_bad_Main)
 	err := StartHub()
 	g.Expect(err).ToNot(BeNil())
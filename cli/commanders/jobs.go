@@ -0,0 +1,150 @@
+package commanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// jobJSON is the form in which Jobs writes a job as JSON, for scripts that
+// poll the hub.
+type jobJSON struct {
+	ID            int32      `json:"id"`
+	Step          string     `json:"step"`
+	Status        string     `json:"status"`
+	Substep       string     `json:"substep,omitempty"`
+	SubstepStatus string     `json:"substep_status,omitempty"`
+	Started       time.Time  `json:"started"`
+	Finished      *time.Time `json:"finished,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	Detached      bool       `json:"detached"`
+}
+
+func newJobJSON(job *idl.Job) jobJSON {
+	j := jobJSON{
+		ID:       job.ID,
+		Step:     job.Step,
+		Status:   job.Status.String(),
+		Started:  time.Unix(job.StartTime, 0).UTC(),
+		Error:    job.Error,
+		Detached: job.Detached,
+	}
+
+	if job.Substep != idl.Substep_UNKNOWN_STEP {
+		j.Substep = job.Substep.String()
+		j.SubstepStatus = job.SubstepStatus.String()
+	}
+
+	if job.EndTime != 0 {
+		finished := time.Unix(job.EndTime, 0).UTC()
+		j.Finished = &finished
+	}
+
+	return j
+}
+
+// Jobs prints the hub's running and past jobs, or only the job with the given
+// ID if it is not zero, as a table or as JSON.
+func Jobs(client idl.CliToHubClient, id int32, asJSON bool) error {
+	reply, err := client.Jobs(context.Background(), &idl.JobsRequest{})
+	if err != nil {
+		return errors.Wrap(err, "listing jobs")
+	}
+
+	jobs := reply.Jobs
+	if id != 0 {
+		jobs = nil
+		for _, job := range reply.Jobs {
+			if job.ID == id {
+				jobs = append(jobs, job)
+			}
+		}
+
+		if len(jobs) == 0 {
+			return xerrors.Errorf("no job %d since the hub started", id)
+		}
+	}
+
+	if asJSON {
+		return writeJobsJSON(os.Stdout, jobs, id != 0)
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No steps have run since the hub started.")
+		return nil
+	}
+
+	writeJobsTable(os.Stdout, jobs)
+	return nil
+}
+
+// writeJobsJSON writes the jobs as a JSON array, or a single job as an object.
+func writeJobsJSON(w io.Writer, jobs []*idl.Job, single bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if single {
+		return encoder.Encode(newJobJSON(jobs[0]))
+	}
+
+	list := make([]jobJSON, 0, len(jobs))
+	for _, job := range jobs {
+		list = append(list, newJobJSON(job))
+	}
+
+	return encoder.Encode(list)
+}
+
+func writeJobsTable(w io.Writer, jobs []*idl.Job) {
+	// Pretty-print our output with tab-alignment.
+	var t tabwriter.Writer
+	t.Init(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(&t, "JOB\tSTEP\tSTATUS\tSUBSTEP\tSTARTED\tFINISHED\t")
+	for _, job := range jobs {
+		j := newJobJSON(job)
+
+		id := strconv.Itoa(int(j.ID))
+		if j.Detached {
+			id += "*"
+		}
+
+		substep := "-"
+		if j.Substep != "" {
+			substep = fmt.Sprintf("%s (%s)", j.Substep, j.SubstepStatus)
+		}
+
+		finished := "-"
+		if j.Finished != nil {
+			finished = j.Finished.Local().Format(jobTimeFormat)
+		}
+
+		fmt.Fprintf(&t, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			id, j.Step, j.Status, substep, j.Started.Local().Format(jobTimeFormat), finished)
+	}
+	t.Flush()
+
+	var detached bool
+	for _, job := range jobs {
+		if job.Error != "" {
+			fmt.Fprintf(w, "\nJob %d failed: %s\n", job.ID, job.Error)
+		}
+		detached = detached || job.Detached
+	}
+
+	if detached {
+		fmt.Fprintln(w, "\n* runs detached from the command that started it")
+	}
+}
+
+const jobTimeFormat = "2006-01-02 15:04:05"
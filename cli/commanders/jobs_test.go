@@ -0,0 +1,137 @@
+package commanders_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+)
+
+func TestJobs(t *testing.T) {
+	start := time.Date(2019, time.December, 25, 12, 0, 0, 0, time.UTC)
+	reply := &idl.JobsReply{Jobs: []*idl.Job{{
+		ID:            1,
+		Step:          "initialize",
+		Status:        idl.Status_COMPLETE,
+		Substep:       idl.Substep_INIT_TARGET_CLUSTER,
+		SubstepStatus: idl.Status_COMPLETE,
+		StartTime:     start.Unix(),
+		EndTime:       start.Add(time.Hour).Unix(),
+	}, {
+		ID:            2,
+		Step:          "execute",
+		Status:        idl.Status_FAILED,
+		Substep:       idl.Substep_UPGRADE_MASTER,
+		SubstepStatus: idl.Status_FAILED,
+		StartTime:     start.Add(2 * time.Hour).Unix(),
+		EndTime:       start.Add(3 * time.Hour).Unix(),
+		Error:         "pg_upgrade failed",
+		Detached:      true,
+	}}}
+
+	listJobs := func(t *testing.T, id int32, asJSON bool) (string, error) {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := mock_idl.NewMockCliToHubClient(ctrl)
+		client.EXPECT().Jobs(gomock.Any(), &idl.JobsRequest{}).Return(reply, nil)
+
+		d := bufferStandardDescriptors(t)
+		defer d.Close()
+
+		err := commanders.Jobs(client, id, asJSON)
+		stdout, _ := d.Collect()
+		return string(stdout), err
+	}
+
+	t.Run("lists jobs in a table", func(t *testing.T) {
+		out, err := listJobs(t, 0, false)
+		if err != nil {
+			t.Fatalf("Jobs() returned error %+v", err)
+		}
+
+		for _, expected := range []string{
+			"UPGRADE_MASTER (FAILED)",
+			"2*",
+			"Job 2 failed: pg_upgrade failed",
+			"* runs detached",
+		} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("output %q does not contain %q", out, expected)
+			}
+		}
+	})
+
+	t.Run("writes a single job as JSON", func(t *testing.T) {
+		out, err := listJobs(t, 2, true)
+		if err != nil {
+			t.Fatalf("Jobs() returned error %+v", err)
+		}
+
+		var job map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &job); err != nil {
+			t.Fatalf("decoding %q: %+v", out, err)
+		}
+
+		if job["id"] != 2.0 || job["status"] != "FAILED" || job["substep"] != "UPGRADE_MASTER" {
+			t.Errorf("got job %v", job)
+		}
+		if job["finished"] != "2019-12-25T15:00:00Z" {
+			t.Errorf("got finish time %v", job["finished"])
+		}
+	})
+
+	t.Run("returns an error for an unknown job", func(t *testing.T) {
+		_, err := listJobs(t, 3, false)
+		if err == nil {
+			t.Errorf("expected an error for an unknown job")
+		}
+	})
+}
+
+func TestExecuteDetached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stream := mock_idl.NewMockCliToHub_ExecuteClient(ctrl)
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	stream.EXPECT().Header().Return(metadata.Pairs(hub.JobHeader, "3"), nil)
+
+	request := &idl.ExecuteRequest{}
+	client := mock_idl.NewMockCliToHubClient(ctrl)
+	client.EXPECT().Execute(gomock.Any(), request).DoAndReturn(
+		func(ctx context.Context, _ *idl.ExecuteRequest, _ ...grpc.CallOption) (idl.CliToHub_ExecuteClient, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			if values := md.Get(hub.DetachKey); len(values) != 1 || values[0] != "true" {
+				t.Errorf("request metadata %v does not ask to detach", md)
+			}
+			return stream, nil
+		})
+
+	d := bufferStandardDescriptors(t)
+	defer d.Close()
+
+	err := commanders.Execute(client, request, false, true, false)
+	stdout, _ := d.Collect()
+
+	if err != nil {
+		t.Errorf("Execute() returned error %+v", err)
+	}
+
+	if !strings.Contains(string(stdout), `"gpupgrade attach --job 3"`) {
+		t.Errorf("output %q does not say how to follow job 3", stdout)
+	}
+}
@@ -0,0 +1,193 @@
+package commanders
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// DefaultAgentPort is the port agents listen on when the hub configuration does
+// not say otherwise.
+const DefaultAgentPort = 6416
+
+// allow the agent dialer and remote pkill to be mocked out in tests
+var dialAgent = grpc.DialContext
+var execCommandKillAgent = exec.Command
+
+var agentDialTimeout = 3 * time.Second
+
+// AgentStopOutcome describes what KillAgents did on a single host.
+type AgentStopOutcome int
+
+const (
+	AgentNotRunning AgentStopOutcome = iota
+	AgentStopped                     // stopped cleanly with the StopAgent RPC
+	AgentKilled                      // killed with pkill over ssh
+	AgentStopFailed
+)
+
+// AgentStopResult is the outcome of stopping the agent on a single host.
+type AgentStopResult struct {
+	Host    string
+	Outcome AgentStopOutcome
+	Err     error // set when Outcome is AgentStopFailed
+}
+
+func (r AgentStopResult) String() string {
+	switch r.Outcome {
+	case AgentNotRunning:
+		return fmt.Sprintf("%s: no agent running", r.Host)
+	case AgentStopped:
+		return fmt.Sprintf("%s: stopped agent", r.Host)
+	case AgentKilled:
+		return fmt.Sprintf("%s: killed unresponsive agent", r.Host)
+	default:
+		return fmt.Sprintf("%s: failed to stop agent: %v", r.Host, r.Err)
+	}
+}
+
+// KillAgents stops the agents on the given hosts without the help of a hub.
+// Each agent is first asked to stop with the StopAgent RPC; if it can't be
+// reached, any agent process on the host is killed with pkill over ssh. Results
+// are returned in host order.
+func KillAgents(hosts []string, port int) []AgentStopResult {
+	var wg sync.WaitGroup
+	results := make(chan AgentStopResult, len(hosts))
+
+	for _, host := range hosts {
+		host := host // capture range variable
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- killAgent(host, port)
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	var sorted []AgentStopResult
+	for r := range results {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Host < sorted[j].Host })
+
+	return sorted
+}
+
+func killAgent(host string, port int) AgentStopResult {
+	result := AgentStopResult{Host: host}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentDialTimeout)
+	defer cancel()
+
+	conn, err := dialAgent(ctx, host+":"+strconv.Itoa(port), grpc.WithInsecure(), grpc.WithBlock())
+	if err == nil {
+		defer conn.Close()
+
+		_, err = idl.NewAgentClient(conn).StopAgent(context.Background(), &idl.StopAgentRequest{})
+		if hub.AgentStopped(err) {
+			result.Outcome = AgentStopped
+			return result
+		}
+
+		gplog.Debug("StopAgent on %s did not stop the agent: %v", host, err)
+	} else {
+		gplog.Debug("failed to dial agent on %s: %v", host, err)
+	}
+
+	// Fall back to killing the process. The brackets keep pkill from matching
+	// the remote shell that runs it.
+	cmd := execCommandKillAgent("ssh", host, `pkill -f "[g]pupgrade agent"`)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Outcome = AgentKilled
+	case xerrors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// pkill found no matching processes.
+		result.Outcome = AgentNotRunning
+	default:
+		result.Outcome = AgentStopFailed
+		result.Err = xerrors.Errorf("ssh pkill: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return result
+}
+
+// AgentHosts returns the hosts whose agents should be stopped, along with the
+// agent port. If hostsFile is set, hosts are read from it, one per line.
+// Otherwise the hosts of the source cluster are read from the hub's
+// configuration in the state directory. If neither is available, no hosts are
+// returned.
+func AgentHosts(hostsFile string) ([]string, int, error) {
+	if hostsFile != "" {
+		hosts, err := readHostsFile(hostsFile)
+		return hosts, DefaultAgentPort, err
+	}
+
+	file, err := os.Open(filepath.Join(utils.GetStateDir(), hub.ConfigFileName))
+	if os.IsNotExist(err) {
+		return nil, DefaultAgentPort, nil
+	}
+	if err != nil {
+		return nil, 0, xerrors.Errorf("opening hub configuration: %w", err)
+	}
+	defer file.Close()
+
+	conf := &hub.Config{AgentPort: DefaultAgentPort}
+	if err := conf.Load(file); err != nil {
+		return nil, 0, xerrors.Errorf("reading hub configuration: %w", err)
+	}
+
+	if conf.Source == nil {
+		// initialize has not gotten far enough to record the cluster.
+		return nil, conf.AgentPort, nil
+	}
+
+	return conf.Source.GetHostnames(), conf.AgentPort, nil
+}
+
+// readHostsFile reads one hostname per line, ignoring blank lines and comments
+// beginning with '#'.
+func readHostsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("opening hosts file: %w", err)
+	}
+	defer file.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("reading hosts file: %w", err)
+	}
+
+	return hosts, nil
+}
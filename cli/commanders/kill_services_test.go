@@ -0,0 +1,191 @@
+package commanders
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/testutils/exectest"
+)
+
+func Pkill_Killed() {}
+
+func Pkill_NoMatch() {
+	os.Exit(1)
+}
+
+func Ssh_Failed() {
+	os.Stderr.WriteString("ssh: connect to host sdw3 port 22: Connection refused")
+	os.Exit(255)
+}
+
+func init() {
+	exectest.RegisterMains(
+		Pkill_Killed,
+		Pkill_NoMatch,
+		Ssh_Failed,
+	)
+}
+
+func TestKillAgents(t *testing.T) {
+	dialAgent = func(context.Context, string, ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return nil, errors.New("connection refused")
+	}
+	defer func() {
+		dialAgent = grpc.DialContext
+		execCommandKillAgent = exec.Command
+	}()
+
+	t.Run("falls back to pkill over ssh when an agent is unreachable", func(t *testing.T) {
+		execCommandKillAgent = exectest.NewCommandWithVerifier(Pkill_Killed, func(name string, args ...string) {
+			expected := []string{"sdw1", `pkill -f "[g]pupgrade agent"`}
+			if name != "ssh" || !reflect.DeepEqual(args, expected) {
+				t.Errorf("ran %q with args %q, want ssh with args %q", name, args, expected)
+			}
+		})
+
+		results := KillAgents([]string{"sdw1"}, DefaultAgentPort)
+
+		expected := []AgentStopResult{{Host: "sdw1", Outcome: AgentKilled}}
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("got %v, want %v", results, expected)
+		}
+	})
+
+	t.Run("reports hosts with no agent running", func(t *testing.T) {
+		execCommandKillAgent = exectest.NewCommand(Pkill_NoMatch)
+
+		results := KillAgents([]string{"sdw2", "sdw1"}, DefaultAgentPort)
+
+		expected := []AgentStopResult{
+			{Host: "sdw1", Outcome: AgentNotRunning},
+			{Host: "sdw2", Outcome: AgentNotRunning},
+		}
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("got %v, want %v", results, expected)
+		}
+	})
+
+	t.Run("reports ssh failures", func(t *testing.T) {
+		execCommandKillAgent = exectest.NewCommand(Ssh_Failed)
+
+		results := KillAgents([]string{"sdw3"}, DefaultAgentPort)
+
+		if len(results) != 1 || results[0].Outcome != AgentStopFailed || results[0].Err == nil {
+			t.Errorf("got %v, want a single failure", results)
+		}
+	})
+}
+
+func TestAgentHosts(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldStateDir, isSet := os.LookupEnv("GPUPGRADE_HOME")
+	defer func() {
+		if isSet {
+			os.Setenv("GPUPGRADE_HOME", oldStateDir)
+		} else {
+			os.Unsetenv("GPUPGRADE_HOME")
+		}
+	}()
+
+	if err := os.Setenv("GPUPGRADE_HOME", dir); err != nil {
+		t.Fatalf("setting GPUPGRADE_HOME: %+v", err)
+	}
+
+	t.Run("reads hosts from a file", func(t *testing.T) {
+		path := filepath.Join(dir, "hosts")
+		contents := "# segment hosts\nsdw1\n\n  sdw2  \n"
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("writing hosts file: %+v", err)
+		}
+
+		hosts, port, err := AgentHosts(path)
+		if err != nil {
+			t.Fatalf("AgentHosts returned error %+v", err)
+		}
+
+		expected := []string{"sdw1", "sdw2"}
+		if !reflect.DeepEqual(hosts, expected) {
+			t.Errorf("got hosts %q, want %q", hosts, expected)
+		}
+		if port != DefaultAgentPort {
+			t.Errorf("got port %d, want %d", port, DefaultAgentPort)
+		}
+	})
+
+	t.Run("returns no hosts when there is no configuration", func(t *testing.T) {
+		hosts, _, err := AgentHosts("")
+		if err != nil {
+			t.Fatalf("AgentHosts returned error %+v", err)
+		}
+
+		if len(hosts) != 0 {
+			t.Errorf("got hosts %q, want none", hosts)
+		}
+	})
+
+	t.Run("returns no hosts when initialize has not recorded the cluster", func(t *testing.T) {
+		writeConfig(t, dir, "{}")
+
+		hosts, port, err := AgentHosts("")
+		if err != nil {
+			t.Fatalf("AgentHosts returned error %+v", err)
+		}
+
+		if len(hosts) != 0 {
+			t.Errorf("got hosts %q, want none", hosts)
+		}
+		if port != DefaultAgentPort {
+			t.Errorf("got port %d, want %d", port, DefaultAgentPort)
+		}
+	})
+
+	t.Run("reads the source cluster hosts from the hub configuration", func(t *testing.T) {
+		writeConfig(t, dir, `{
+			"Source": {
+				"Primaries": {
+					"-1": {"ContentID": -1, "Hostname": "mdw"},
+					"0": {"ContentID": 0, "Hostname": "sdw1"}
+				}
+			},
+			"AgentPort": 7000
+		}`)
+
+		hosts, port, err := AgentHosts("")
+		if err != nil {
+			t.Fatalf("AgentHosts returned error %+v", err)
+		}
+
+		sort.Strings(hosts)
+		expected := []string{"mdw", "sdw1"}
+		if !reflect.DeepEqual(hosts, expected) {
+			t.Errorf("got hosts %q, want %q", hosts, expected)
+		}
+		if port != 7000 {
+			t.Errorf("got port %d, want %d", port, 7000)
+		}
+	})
+}
+
+func writeConfig(t *testing.T, stateDir, contents string) {
+	t.Helper()
+
+	path := filepath.Join(stateDir, hub.ConfigFileName)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("writing configuration: %+v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package commanders
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// Report has the hub regenerate the upgrade report, and prints either where
+// it was written or, with print set, its Markdown.
+func Report(client idl.CliToHubClient, print bool) error {
+	reply, err := client.Report(context.Background(), &idl.ReportRequest{})
+	if err != nil {
+		return xerrors.Errorf("generating report: %w", err)
+	}
+
+	if print {
+		fmt.Print(reply.Markdown)
+		return nil
+	}
+
+	fmt.Printf("Wrote the upgrade report to\n  %s\n  %s\n", reply.MarkdownPath, reply.HtmlPath)
+	return nil
+}
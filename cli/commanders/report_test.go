@@ -0,0 +1,65 @@
+package commanders_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+)
+
+func TestReport(t *testing.T) {
+	reply := &idl.ReportReply{
+		MarkdownPath: "/state/report.md",
+		HtmlPath:     "/state/report.html",
+		Markdown:     "# gpupgrade report\n",
+	}
+
+	cases := []struct {
+		name     string
+		print    bool
+		expected string
+	}{
+		{"prints where the report was written", false, "Wrote the upgrade report to\n  /state/report.md\n  /state/report.html\n"},
+		{"prints the Markdown with print set", true, "# gpupgrade report\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_idl.NewMockCliToHubClient(ctrl)
+			client.EXPECT().Report(gomock.Any(), &idl.ReportRequest{}).Return(reply, nil)
+
+			d := bufferStandardDescriptors(t)
+			err := commanders.Report(client, c.print)
+			d.Close()
+			if err != nil {
+				t.Errorf("Report() returned error %+v", err)
+			}
+
+			if stdout, _ := d.Collect(); string(stdout) != c.expected {
+				t.Errorf("printed %q, want %q", stdout, c.expected)
+			}
+		})
+	}
+
+	t.Run("returns the hub's error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := errors.New("permission denied")
+		client := mock_idl.NewMockCliToHubClient(ctrl)
+		client.EXPECT().Report(gomock.Any(), gomock.Any()).Return(nil, expected)
+
+		err := commanders.Report(client, false)
+		if !xerrors.Is(err, expected) {
+			t.Errorf("returned error %#v, want %#v", err, expected)
+		}
+	})
+}
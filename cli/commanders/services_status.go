@@ -0,0 +1,120 @@
+package commanders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/hub"
+)
+
+// ErrNotReady is returned by ServicesStatus when the hub or an agent is not
+// ready.
+var ErrNotReady = xerrors.New("not all services are ready")
+
+// serviceStatus is the result of checking the health of the hub or an agent.
+type serviceStatus struct {
+	name    string
+	address string
+	status  healthpb.HealthCheckResponse_ServingStatus
+	err     error
+}
+
+func (s serviceStatus) String() string {
+	switch {
+	case s.err != nil:
+		return fmt.Sprintf("unreachable (%v)", s.err)
+	case s.status == healthpb.HealthCheckResponse_SERVING:
+		return "ready"
+	default:
+		return "not ready"
+	}
+}
+
+// CheckHealth asks the grpc.health.v1 service at address for the status of
+// service.
+func CheckHealth(address, service string, timeout time.Duration) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+	)
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	defer conn.Close()
+
+	reply, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+
+	return reply.Status, nil
+}
+
+// ServicesStatus checks the readiness of the hub and of the agents on each of
+// agentHosts, and prints a table of the results. It returns ErrNotReady if any
+// of them is not ready.
+func ServicesStatus(hubAddress string, agentHosts []string, agentPort int, timeout time.Duration) error {
+	sort.Strings(agentHosts)
+
+	statuses := make([]serviceStatus, len(agentHosts)+1)
+	statuses[0] = serviceStatus{name: "hub", address: hubAddress}
+	for i, host := range agentHosts {
+		statuses[i+1] = serviceStatus{name: "agent", address: net.JoinHostPort(host, strconv.Itoa(agentPort))}
+	}
+
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func(s *serviceStatus) {
+			defer wg.Done()
+
+			service := agent.HealthService
+			if s.name == "hub" {
+				service = hub.HealthService
+			}
+			s.status, s.err = CheckHealth(s.address, service, timeout)
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	writeServicesTable(os.Stdout, statuses)
+	if len(agentHosts) == 0 {
+		fmt.Println("\nNo agents are configured; run \"gpupgrade initialize\" first.")
+	}
+
+	for _, s := range statuses {
+		if s.err != nil || s.status != healthpb.HealthCheckResponse_SERVING {
+			return ErrNotReady
+		}
+	}
+
+	return nil
+}
+
+func writeServicesTable(w io.Writer, statuses []serviceStatus) {
+	// Pretty-print our output with tab-alignment.
+	var t tabwriter.Writer
+	t.Init(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(&t, "SERVICE\tADDRESS\tSTATUS\t")
+	for _, s := range statuses {
+		fmt.Fprintf(&t, "%s\t%s\t%s\t\n", s.name, s.address, s)
+	}
+
+	t.Flush()
+}
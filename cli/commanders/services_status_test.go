@@ -0,0 +1,97 @@
+package commanders_test
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/hub"
+)
+
+// serveHealth serves the health of a single service on a local port, and
+// returns the port and a function to stop serving.
+func serveHealth(t *testing.T, service string, status healthpb.HealthCheckResponse_ServingStatus) (int, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("listening: %+v", err)
+	}
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(service, status)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+
+	return lis.Addr().(*net.TCPAddr).Port, server.Stop
+}
+
+func TestServicesStatus(t *testing.T) {
+	hubPort, stopHub := serveHealth(t, hub.HealthService, healthpb.HealthCheckResponse_SERVING)
+	defer stopHub()
+	hubAddress := "localhost:" + strconv.Itoa(hubPort)
+
+	servicesStatus := func(t *testing.T, agentPort int) (string, error) {
+		t.Helper()
+
+		d := bufferStandardDescriptors(t)
+		defer d.Close()
+
+		err := commanders.ServicesStatus(hubAddress, []string{"localhost"}, agentPort, time.Second)
+		stdout, _ := d.Collect()
+		return string(stdout), err
+	}
+
+	t.Run("succeeds when every service is ready", func(t *testing.T) {
+		agentPort, stopAgent := serveHealth(t, agent.HealthService, healthpb.HealthCheckResponse_SERVING)
+		defer stopAgent()
+
+		out, err := servicesStatus(t, agentPort)
+		if err != nil {
+			t.Errorf("ServicesStatus() returned error %+v", err)
+		}
+
+		if strings.Count(out, "ready") != 2 {
+			t.Errorf("output %q does not show two ready services", out)
+		}
+	})
+
+	t.Run("reports agents that are not ready", func(t *testing.T) {
+		agentPort, stopAgent := serveHealth(t, agent.HealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+		defer stopAgent()
+
+		out, err := servicesStatus(t, agentPort)
+		if !xerrors.Is(err, commanders.ErrNotReady) {
+			t.Errorf("ServicesStatus() returned error %#v, want %#v", err, commanders.ErrNotReady)
+		}
+
+		if !strings.Contains(out, "not ready") {
+			t.Errorf("output %q does not show the agent as not ready", out)
+		}
+	})
+
+	t.Run("reports agents that cannot be reached", func(t *testing.T) {
+		agentPort, stopAgent := serveHealth(t, agent.HealthService, healthpb.HealthCheckResponse_SERVING)
+		stopAgent()
+
+		out, err := servicesStatus(t, agentPort)
+		if !xerrors.Is(err, commanders.ErrNotReady) {
+			t.Errorf("ServicesStatus() returned error %#v, want %#v", err, commanders.ErrNotReady)
+		}
+
+		if !strings.Contains(out, "unreachable") {
+			t.Errorf("output %q does not show the agent as unreachable", out)
+		}
+	})
+}
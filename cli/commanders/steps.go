@@ -5,21 +5,32 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/greenplum-db/gpupgrade/hub"
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/upgrade"
 )
 
 type receiver interface {
 	Recv() (*idl.Message, error)
 }
 
+type headerReceiver interface {
+	receiver
+	Header() (metadata.MD, error)
+}
+
 var lines = map[idl.Substep]string{
 	idl.Substep_CONFIG:                            "Retrieving configs...",
 	idl.Substep_START_AGENTS:                      "Starting agents...",
+	idl.Substep_MEASURE_SOURCE_CLUSTER:            "Measuring old cluster to estimate execute...",
 	idl.Substep_CREATE_TARGET_CONFIG:              "Generating new cluster configuration...",
 	idl.Substep_SHUTDOWN_SOURCE_CLUSTER:           "Stopping old cluster...",
 	idl.Substep_INIT_TARGET_CLUSTER:               "Creating new cluster...",
@@ -28,7 +39,9 @@ var lines = map[idl.Substep]string{
 	idl.Substep_CHECK_UPGRADE:                     "Running pg_upgrade checks...",
 	idl.Substep_UPGRADE_MASTER:                    "Upgrading master...",
 	idl.Substep_COPY_MASTER:                       "Copying master to segments...",
+	idl.Substep_VERIFY_MASTER_COPY:                "Verifying master copies on segments...",
 	idl.Substep_UPGRADE_PRIMARIES:                 "Upgrading segments...",
+	idl.Substep_CARRY_OVER_CONFIGURATION:          "Carrying over configuration to new cluster...",
 	idl.Substep_START_TARGET_CLUSTER:              "Starting new cluster...",
 	idl.Substep_FINALIZE_SHUTDOWN_TARGET_CLUSTER:  "Stopping new cluster",
 	idl.Substep_FINALIZE_START_TARGET_MASTER:      "Starting new master...",
@@ -43,8 +56,13 @@ var indicators = map[idl.Status]string{
 	idl.Status_RUNNING:  "[IN PROGRESS]",
 	idl.Status_COMPLETE: "[COMPLETE]",
 	idl.Status_FAILED:   "[FAILED]",
+	idl.Status_PAUSED:   "[PAUSED]",
 }
 
+// ErrPaused is returned by UILoop when the hub paused a substep, to be resumed
+// by running the same command again.
+var ErrPaused = xerrors.New("paused")
+
 func Initialize(client idl.CliToHubClient, request *idl.InitializeRequest, verbose bool) (err error) {
 	stream, err := client.Initialize(context.Background(), request)
 	if err != nil {
@@ -59,14 +77,18 @@ func Initialize(client idl.CliToHubClient, request *idl.InitializeRequest, verbo
 	return nil
 }
 
-func InitializeCreateCluster(client idl.CliToHubClient, verbose bool) (err error) {
-	stream, err := client.InitializeCreateCluster(context.Background(),
+func InitializeCreateCluster(client idl.CliToHubClient, verbose bool, detach bool) (err error) {
+	stream, err := client.InitializeCreateCluster(stepContext(detach),
 		&idl.InitializeCreateClusterRequest{},
 	)
 	if err != nil {
 		return errors.Wrap(err, "initializing hub2")
 	}
 
+	if detach {
+		return waitDetached("initialize", stream)
+	}
+
 	err = UILoop(stream, verbose)
 	if err != nil {
 		return xerrors.Errorf("InitializeCreateCluster: %w", err)
@@ -75,19 +97,27 @@ func InitializeCreateCluster(client idl.CliToHubClient, verbose bool) (err error
 	return nil
 }
 
-func Execute(client idl.CliToHubClient, verbose bool) error {
+func Execute(client idl.CliToHubClient, request *idl.ExecuteRequest, verbose bool, detach bool, dashboard bool) error {
 	fmt.Println()
 	fmt.Println("Execute in progress.")
 	fmt.Println()
 
-	stream, err := client.Execute(context.Background(), &idl.ExecuteRequest{})
+	stream, err := client.Execute(stepContext(detach), request)
 	if err != nil {
 		// TODO: Change the logging message?
 		gplog.Error("ERROR - Unable to connect to hub")
 		return err
 	}
 
-	err = UILoop(stream, verbose)
+	if detach {
+		return waitDetached("execute", stream)
+	}
+
+	err = uiLoop(stream, verbose, dashboard)
+	if xerrors.Is(err, ErrPaused) {
+		fmt.Println(executePausedMessage)
+		return nil
+	}
 	if err != nil {
 		return xerrors.Errorf("Execute: %w", err)
 	}
@@ -108,17 +138,107 @@ If you would like to return the cluster to its original state, run
 	return nil
 }
 
-func Finalize(client idl.CliToHubClient, verbose bool) error {
+const executePausedMessage = `
+Execute has paused after upgrading a wave of primary segments. Neither cluster
+is running: the new cluster starts only once every wave has been upgraded.
+Check the upgrade of the wave in the execute log and in the pg_upgrade
+directory of the state directory on each of its hosts, then run
+"gpupgrade execute" again to continue with the next wave.`
+
+// stepContext returns the context for a step request, which asks the hub to
+// run the step in the background if detach is set.
+func stepContext(detach bool) context.Context {
+	ctx := context.Background()
+	if detach {
+		ctx = metadata.AppendToOutgoingContext(ctx, hub.DetachKey, "true")
+	}
+
+	return ctx
+}
+
+// waitDetached waits for the hub to start a step in the background, and tells
+// the user how to follow its job.
+func waitDetached(step string, stream headerReceiver) error {
+	for {
+		// Nothing is sent to the client of a detached step; the stream ends
+		// once the step has started.
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return xerrors.Errorf("%s: %w", strings.Title(step), err)
+		}
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return xerrors.Errorf("%s: %w", strings.Title(step), err)
+	}
+
+	ids := header.Get(hub.JobHeader)
+	if len(ids) == 0 {
+		return xerrors.Errorf("%s: hub did not start a job", strings.Title(step))
+	}
+
+	fmt.Printf(detachedMessage, strings.Title(step), ids[0], ids[0])
+	return nil
+}
+
+const detachedMessage = `%s is running in the background as job %s. Follow it with
+"gpupgrade attach --job %s", or check on it with "gpupgrade jobs".
+`
+
+// Attach replays the output of a job, or of the step that the hub is running
+// or last ran if job is zero, and follows it until the step finishes.
+func Attach(client idl.CliToHubClient, job int32, verbose bool, dashboard bool) error {
+	stream, err := client.Watch(context.Background(), &idl.WatchRequest{Job: job})
+	if err != nil {
+		return errors.Wrap(err, "attaching to hub")
+	}
+
+	step := "the last step"
+	if header, err := stream.Header(); err == nil {
+		if names := header.Get(hub.StepHeader); len(names) > 0 {
+			step = names[0]
+		}
+		if ids := header.Get(hub.JobHeader); len(ids) > 0 {
+			step = fmt.Sprintf("%s (job %s)", step, ids[0])
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Attached to %s.\n", step)
+	fmt.Println()
+
+	err = uiLoop(stream, verbose, dashboard)
+	if xerrors.Is(err, ErrPaused) {
+		fmt.Println(executePausedMessage)
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("Attach: %w", err)
+	}
+
+	fmt.Printf("\n%s has finished.\n", strings.Title(step))
+	return nil
+}
+
+func Finalize(client idl.CliToHubClient, verbose bool, detach bool) error {
 	fmt.Println()
 	fmt.Println("Finalize in progress.")
 	fmt.Println()
 
-	stream, err := client.Finalize(context.Background(), &idl.FinalizeRequest{})
+	stream, err := client.Finalize(stepContext(detach), &idl.FinalizeRequest{})
 	if err != nil {
 		gplog.Error(err.Error())
 		return err
 	}
 
+	if detach {
+		return waitDetached("finalize", stream)
+	}
+
 	err = UILoop(stream, verbose)
 	if err != nil {
 		return xerrors.Errorf("Finalize: %w", err)
@@ -131,8 +251,21 @@ The cluster is now upgraded and is ready to be used.`)
 	return nil
 }
 
+// uiLoop runs DashboardLoop if dashboard is set and stdout is a terminal, and
+// UILoop otherwise.
+func uiLoop(stream receiver, verbose bool, dashboard bool) error {
+	if dashboard {
+		if width, height, ok := UseDashboard(); ok {
+			return DashboardLoop(stream, os.Stdout, width, height)
+		}
+	}
+
+	return UILoop(stream, verbose)
+}
+
 func UILoop(stream receiver, verbose bool) error {
 	var lastStep idl.Substep
+	var paused bool
 	var err error
 
 	for {
@@ -169,12 +302,25 @@ func UILoop(stream receiver, verbose bool) error {
 				}
 			}
 			lastStep = x.Status.Step
+			paused = x.Status.Status == idl.Status_PAUSED
 
 			fmt.Printf(FormatStatus(x.Status))
 			if verbose {
 				fmt.Println()
 			}
 
+		case *idl.Message_Progress:
+			// The progress of each segment is shown by the dashboard; here
+			// it is only worth printing alongside the rest of the output.
+			if verbose {
+				fmt.Println(FormatProgress(x.Progress))
+			}
+
+		case *idl.Message_Estimate:
+			if verbose {
+				fmt.Println(FormatRemaining(time.Duration(x.Estimate.Remaining) * time.Second))
+			}
+
 		default:
 			panic(fmt.Sprintf("unknown message type: %T", x))
 		}
@@ -185,12 +331,26 @@ func UILoop(stream receiver, verbose bool) error {
 	}
 
 	if err != io.EOF {
+		printUpgradeFailures(err)
 		return err
 	}
 
+	if paused {
+		return ErrPaused
+	}
+
 	return nil
 }
 
+// printUpgradeFailures displays the details of any pg_upgrade failures sent
+// by the hub, which are otherwise buried in the error chain.
+func printUpgradeFailures(err error) {
+	for _, failure := range upgrade.Errors(err) {
+		fmt.Println()
+		fmt.Print(failure.Details())
+	}
+}
+
 // FormatStatus returns a status string based on the upgrade status message.
 // It's exported for ease of testing.
 //
@@ -205,6 +365,16 @@ func FormatStatus(status *idl.SubstepStatus) string {
 	return Format(line, status.Status)
 }
 
+// FormatProgress returns a line describing the progress of a segment.
+func FormatProgress(progress *idl.SegmentProgress) string {
+	return fmt.Sprintf("segment %d on %s: %s", progress.Content, progress.Host, phases[progress.Phase])
+}
+
+// FormatRemaining returns a line with the estimated time remaining.
+func FormatRemaining(remaining time.Duration) string {
+	return "Estimated time remaining: " + FormatEstimate(remaining)
+}
+
 // Format is also exported for ease of testing (see FormatStatus). Use Substep
 // instead.
 func Format(description string, status idl.Status) string {
@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 
@@ -83,6 +84,28 @@ func TestUILoop(t *testing.T) {
 		}
 	})
 
+	t.Run("returns ErrPaused when the stream ends with a paused substep", func(t *testing.T) {
+		msgs := msgStream{
+			{Contents: &idl.Message_Status{&idl.SubstepStatus{
+				Step:   idl.Substep_UPGRADE_PRIMARIES,
+				Status: idl.Status_PAUSED,
+			}}},
+		}
+
+		d := bufferStandardDescriptors(t)
+		defer d.Close()
+
+		err := commanders.UILoop(&msgs, false)
+		if !xerrors.Is(err, commanders.ErrPaused) {
+			t.Errorf("returned %#v want %#v", err, commanders.ErrPaused)
+		}
+
+		actualOut, _ := d.Collect()
+		if !strings.Contains(string(actualOut), "[PAUSED]") {
+			t.Errorf("output %q does not show the paused status", actualOut)
+		}
+	})
+
 	t.Run("writes status and stdout chunks serially in verbose mode", func(t *testing.T) {
 		msgs := msgStream{
 			{Contents: &idl.Message_Status{&idl.SubstepStatus{
@@ -128,6 +151,54 @@ func TestUILoop(t *testing.T) {
 		}
 	})
 
+	t.Run("prints segment progress only in verbose mode", func(t *testing.T) {
+		progress := &idl.SegmentProgress{Host: "sdw1", Content: 3, Phase: idl.SegmentProgress_UPGRADE}
+
+		for _, verbose := range []bool{true, false} {
+			msgs := msgStream{{Contents: &idl.Message_Progress{progress}}}
+
+			d := bufferStandardDescriptors(t)
+			err := commanders.UILoop(&msgs, verbose)
+			d.Close()
+			if err != nil {
+				t.Errorf("UILoop() returned %#v", err)
+			}
+
+			actualOut, _ := d.Collect()
+
+			expected := "\n"
+			if verbose {
+				expected = "segment 3 on sdw1: pg_upgrade\n"
+			}
+			if string(actualOut) != expected {
+				t.Errorf("verbose %t: output %q want %q", verbose, actualOut, expected)
+			}
+		}
+	})
+
+	t.Run("prints the estimated time remaining only in verbose mode", func(t *testing.T) {
+		for _, verbose := range []bool{true, false} {
+			msgs := msgStream{{Contents: &idl.Message_Estimate{&idl.Estimate{Remaining: 150}}}}
+
+			d := bufferStandardDescriptors(t)
+			err := commanders.UILoop(&msgs, verbose)
+			d.Close()
+			if err != nil {
+				t.Errorf("UILoop() returned %#v", err)
+			}
+
+			actualOut, _ := d.Collect()
+
+			expected := "\n"
+			if verbose {
+				expected = "Estimated time remaining: 3m\n"
+			}
+			if string(actualOut) != expected {
+				t.Errorf("verbose %t: output %q want %q", verbose, actualOut, expected)
+			}
+		}
+	})
+
 	t.Run("overwrites status lines and ignores chunks in non-verbose mode", func(t *testing.T) {
 		msgs := msgStream{
 			{Contents: &idl.Message_Status{&idl.SubstepStatus{
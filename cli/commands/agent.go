@@ -12,6 +12,8 @@ import (
 
 func Agent() *cobra.Command {
 	var logdir, statedir string
+	var metricsPort int
+	var dataDirs []string
 	var shouldDaemonize bool
 
 	var cmd = &cobra.Command{
@@ -25,8 +27,10 @@ func Agent() *cobra.Command {
 			defer log.WritePanics()
 
 			conf := agent.Config{
-				Port:     6416,
-				StateDir: statedir,
+				Port:        6416,
+				StateDir:    statedir,
+				MetricsPort: metricsPort,
+				DataDirs:    dataDirs,
 			}
 
 			agentServer := agent.NewServer(conf)
@@ -43,6 +47,8 @@ func Agent() *cobra.Command {
 
 	cmd.Flags().StringVar(&logdir, "log-directory", "", "command_listener log directory")
 	cmd.Flags().StringVar(&statedir, "state-directory", utils.GetStateDir(), "Agent state directory")
+	cmd.Flags().IntVar(&metricsPort, "metrics-port", 0, "serve Prometheus metrics on this port (disabled when 0)")
+	cmd.Flags().StringSliceVar(&dataDirs, "data-directories", nil, "comma-separated data directories of the segments on this host, which the hub may have the agent scan")
 
 	daemon.MakeDaemonizable(cmd, &shouldDaemonize)
 
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+)
+
+func auditLog() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "subcommands to inspect the audit log of operator actions",
+		Long:  "subcommands to inspect the audit log of operator actions",
+	}
+
+	cmd.AddCommand(auditVerify())
+
+	return cmd
+}
+
+func auditVerify() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "checks that the audit log has not been tampered with",
+		Long: fmt.Sprintf(`
+Checks the hash chain of the audit log (%s) in the state directory, which
+records every request made to the hub and every configuration change. Any
+entry that has been edited, removed or reordered is reported.
+
+The chain can be rebuilt by anyone who can write the log, and entries removed
+from its end cannot be detected this way. Keep the head that is printed, the
+sequence number and hash of the last entry, somewhere other than this host;
+the hub also sends it with each webhook event. A later log whose entry with
+that sequence number still has that hash has not been altered up to it.
+`, hub.AuditLogFileName),
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path := filepath.Join(utils.GetStateDir(), hub.AuditLogFileName)
+			head, err := audit.Verify(path)
+			if err != nil {
+				return xerrors.Errorf("verifying %s after %d intact entries: %w", path, head.Seq, err)
+			}
+
+			fmt.Printf("Audit log %s is intact: %d entries\n", path, head.Seq)
+			fmt.Printf("Head: %s\n", head)
+			return nil
+		},
+	}
+}
@@ -44,8 +44,13 @@ import (
 	"google.golang.org/grpc/codes"
 	grpcStatus "google.golang.org/grpc/status"
 
+	"github.com/greenplum-db/gpupgrade/agent"
 	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/hub"
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/webhook"
 )
 
 func BuildRootCommand() *cobra.Command {
@@ -57,8 +62,15 @@ func BuildRootCommand() *cobra.Command {
 	root.AddCommand(initialize())
 	root.AddCommand(execute())
 	root.AddCommand(finalize())
+	root.AddCommand(attach())
+	root.AddCommand(jobs())
+	root.AddCommand(report())
 	root.AddCommand(restartServices)
-	root.AddCommand(killServices)
+	root.AddCommand(services())
+	root.AddCommand(killServices())
+	root.AddCommand(collectLogs())
+	root.AddCommand(state())
+	root.AddCommand(auditLog())
 	root.AddCommand(Agent())
 	root.AddCommand(Hub())
 
@@ -93,23 +105,42 @@ func connTimeout() time.Duration {
 	return time.Duration(duration * float64(time.Second))
 }
 
-// connectToHub() performs a blocking connection to the hub, and returns a
-// CliToHubClient which wraps the resulting gRPC channel. Any errors result in
-// an os.Exit(1).
-func connectToHub() idl.CliToHubClient {
+// hubAddress returns the address of the hub: on this host, at the port in
+// GPUPGRADE_HUB_PORT, or else the one recorded by the running hub, or else the
+// default.
+func hubAddress() string {
 	upgradePort := os.Getenv("GPUPGRADE_HUB_PORT")
 	if upgradePort == "" {
 		upgradePort = "7527"
+
+		// Prefer the port recorded by the running hub, if any.
+		info, running, err := commanders.HubInfo()
+		if err != nil {
+			gplog.Debug("failed to read hub pidfile: %v", err)
+		} else if running && info.Port != 0 {
+			upgradePort = strconv.Itoa(info.Port)
+		}
 	}
 
-	hubAddr := "localhost:" + upgradePort
+	return "localhost:" + upgradePort
+}
+
+// connectToHub() performs a blocking connection to the hub, and returns a
+// CliToHubClient which wraps the resulting gRPC channel. Any errors result in
+// an os.Exit(1).
+func connectToHub() idl.CliToHubClient {
+	hubAddr := hubAddress()
 
 	// Set up our timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), connTimeout())
 	defer cancel()
 
 	// Attempt a connection.
-	conn, err := grpc.DialContext(ctx, hubAddr, grpc.WithInsecure(), grpc.WithBlock())
+	// Identify the operator in every request, for the hub's audit log.
+	conn, err := grpc.DialContext(ctx, hubAddr, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(audit.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(audit.StreamClientInterceptor),
+	)
 	if err != nil {
 		// Print a nicer error message if we can't connect to the hub.
 		if ctx.Err() == context.DeadlineExceeded {
@@ -155,7 +186,12 @@ func createConfigSetSubcommand() *cobra.Command {
 				if err != nil {
 					return err
 				}
-				gplog.Info("Successfully set %s to %s", request.Name, request.Value)
+
+				value := request.Value
+				if (request.Name == "webhook-secret" || request.Name == "gateway-token") && value != "" {
+					value = "(set)" // don't log the secret
+				}
+				gplog.Info("Successfully set %s to %s", request.Name, value)
 			}
 
 			return nil
@@ -164,6 +200,14 @@ func createConfigSetSubcommand() *cobra.Command {
 
 	subSet.Flags().String("old-bindir", "", "install directory for old gpdb version")
 	subSet.Flags().String("new-bindir", "", "install directory for new gpdb version")
+	subSet.Flags().String("copy-fanout", "", "number of hosts to which the master data directory is copied at once from each source (default 4)")
+	subSet.Flags().String("copy-bandwidth", "", "limit on the rate of each copy of the master data directory, in bytes per second with an optional K, M or G suffix; 0 for no limit")
+	subSet.Flags().String("host-parallelism", "", fmt.Sprintf("number of segments upgraded at once on each host; 0 for the default of %d, which is kept low to bound each host's memory use", agent.DefaultParallelism))
+	subSet.Flags().String("cluster-parallelism", "", "number of segments upgraded at once across the cluster; 0 for no limit")
+	subSet.Flags().String("webhooks", "", "comma-separated URLs to which step and substep status changes are posted; empty for none")
+	subSet.Flags().String("webhook-secret", "", "key with which webhook requests are signed in the "+webhook.SignatureHeader+" header; empty for no signature")
+	subSet.Flags().String("webhook-format", "", `body of webhook requests: "json" (default) or "slack"`)
+	subSet.Flags().String("gateway-token", "", "token that REST/JSON API clients send as \"Authorization: Bearer <token>\" to name their user in the "+hub.GatewayUserHeader+" header; empty to refuse that header")
 
 	return subSet
 }
@@ -180,7 +224,8 @@ func createConfigShowSubcommand() *cobra.Command {
 			// are passed, assume we want to retrieve all of them.
 			var requests []*idl.GetConfigRequest
 			getRequest := func(flag *pflag.Flag) {
-				if flag.Name != "help" {
+				// The gpinitsystem preview is only shown when asked for.
+				if flag.Name != "help" && (flag.Name != "gpinitsystem" || flag.Changed) {
 					requests = append(requests, &idl.GetConfigRequest{
 						Name: flag.Name,
 					})
@@ -215,6 +260,15 @@ func createConfigShowSubcommand() *cobra.Command {
 	subShow.Flags().Bool("old-bindir", false, "show install directory for old gpdb version")
 	subShow.Flags().Bool("new-bindir", false, "show install directory for new gpdb version")
 	subShow.Flags().Bool("new-datadir", false, "show temporary data directory for new gpdb cluster")
+	subShow.Flags().Bool("copy-fanout", false, "show number of hosts to which the master data directory is copied at once from each source")
+	subShow.Flags().Bool("copy-bandwidth", false, "show limit on the rate of each copy of the master data directory, in bytes per second")
+	subShow.Flags().Bool("host-parallelism", false, "show number of segments upgraded at once on each host")
+	subShow.Flags().Bool("cluster-parallelism", false, "show number of segments upgraded at once across the cluster")
+	subShow.Flags().Bool("webhooks", false, "show URLs to which status changes are posted")
+	subShow.Flags().Bool("webhook-secret", false, "show whether webhook requests are signed")
+	subShow.Flags().Bool("webhook-format", false, "show body of webhook requests")
+	subShow.Flags().Bool("gateway-token", false, "show whether REST/JSON API clients may name their user")
+	subShow.Flags().Bool("gpinitsystem", false, "preview the gpinitsystem_config generated for the new gpdb cluster, including any overrides in $GPUPGRADE_HOME/"+hub.InitsystemOverrideFileName)
 
 	return subShow
 }
@@ -241,6 +295,10 @@ func initialize() *cobra.Command {
 	var verbose bool
 	var ports string
 	var linkMode bool
+	var metricsPort, agentMetricsPort, gatewayPort int
+	var gatewayAddress string
+	var copyEngine string
+	var detach bool
 
 	subInit := &cobra.Command{
 		Use:   "initialize",
@@ -248,6 +306,9 @@ func initialize() *cobra.Command {
 		Long: `
 Runs through pre-upgrade checks and prepares the old and new clusters for upgrade.
 This step can be reverted.
+
+With --detach, the checks run as usual and the new cluster is then created in
+the background by the hub; see "gpupgrade jobs".
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if diskFreeRatio < 0.0 || diskFreeRatio > 1.0 {
@@ -263,6 +324,10 @@ This step can be reverted.
 				return err
 			}
 
+			if err := dircopy.ValidateEngine(copyEngine); err != nil {
+				return err
+			}
+
 			// If we got here, the args are okay and the user doesn't need a usage
 			// dump on failure.
 			cmd.SilenceUsage = true
@@ -281,7 +346,21 @@ This step can be reverted.
 				return errors.Wrap(err, "creating initial cluster configs")
 			}
 
-			err = commanders.StartHub()
+			var hubArgs []string
+			if metricsPort != 0 {
+				hubArgs = append(hubArgs, "--metrics-port", strconv.Itoa(metricsPort))
+			}
+			if agentMetricsPort != 0 {
+				hubArgs = append(hubArgs, "--agent-metrics-port", strconv.Itoa(agentMetricsPort))
+			}
+			if gatewayPort != 0 {
+				hubArgs = append(hubArgs, "--gateway-port", strconv.Itoa(gatewayPort))
+			}
+			if gatewayAddress != "" {
+				hubArgs = append(hubArgs, "--gateway-address", gatewayAddress)
+			}
+
+			err = commanders.StartHub(hubArgs...)
 			if err != nil {
 				return errors.Wrap(err, "starting hub")
 			}
@@ -294,6 +373,7 @@ This step can be reverted.
 				SourcePort:   int32(sourcePort),
 				UseLinkMode:  linkMode,
 				Ports:        ports,
+				CopyEngine:   copyEngine,
 			}
 			err = commanders.Initialize(client, request, verbose)
 			if err != nil {
@@ -309,11 +389,20 @@ This step can be reverted.
 				return nil
 			}
 
-			err = commanders.InitializeCreateCluster(client, verbose)
+			err = commanders.InitializeCreateCluster(client, verbose, detach)
 			if err != nil {
 				return errors.Wrap(err, "initializing cluster")
 			}
 
+			if detach {
+				return nil
+			}
+
+			err = commanders.PrintEstimate(client)
+			if err != nil {
+				gplog.Warn("estimating the duration of execute: %v", err)
+			}
+
 			fmt.Println(`
 Run "gpupgrade execute" on the command line to proceed with the upgrade.
 
@@ -337,12 +426,24 @@ If you would like to return the cluster to its original state, run
 	subInit.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "print the output stream from all substeps")
 	subInit.Flags().StringVar(&ports, "ports", "", "set of ports to use when initializing the new cluster")
 	subInit.PersistentFlags().BoolVar(&linkMode, "link", false, "performs upgrade in link mode")
+	subInit.Flags().IntVar(&metricsPort, "metrics-port", 0, "serve hub Prometheus metrics on this port (disabled when 0)")
+	subInit.Flags().IntVar(&agentMetricsPort, "agent-metrics-port", 0, "serve agent Prometheus metrics on this port (disabled when 0)")
+	subInit.Flags().IntVar(&gatewayPort, "gateway-port", 0, "serve the hub's REST/JSON API on this port (disabled when 0)")
+	subInit.Flags().StringVar(&gatewayAddress, "gateway-address", "", "serve the hub's REST/JSON API on this host name or IP address (default localhost)")
+	subInit.Flags().StringVar(&copyEngine, "copy-engine", dircopy.EngineRsync, `how data directories are copied: "rsync", or "native" to copy them without rsync or ssh`)
+	subInit.Flags().BoolVar(&detach, "detach", false, "create the new cluster in the background and return once it has started")
 
 	return subInit
 }
 
 func execute() *cobra.Command {
 	var verbose bool
+	var contents []int
+	var hosts []string
+	var batchSize int
+	var pause bool
+	var detach bool
+	var tui bool
 
 	cmd := &cobra.Command{
 		Use:   "execute",
@@ -350,22 +451,145 @@ func execute() *cobra.Command {
 		Long: `
 Upgrades the master and primary segments over to the new cluster.
 This step can be reverted.
+
+The primary segments may be upgraded in waves. Those chosen with --contents
+or --hosts are upgraded first; the rest follow in batches of --batch-size, or
+all at once. With --pause, execute stops after each wave, and running execute
+again continues with the next wave. The waves are fixed by the first run of
+execute.
+
+Neither cluster is running while execute is paused, since the new cluster is
+started only once every wave has been upgraded. What can be checked between
+waves is how pg_upgrade went for the wave: the execute log, the pg_upgrade
+logs and reports in the state directory on each of the wave's hosts, and the
+status of each wave in waves.json in the hub's state directory.
+
+With --detach, execute runs in the background on the hub and returns once it
+has started; see "gpupgrade jobs".
+
+When its output is a terminal, execute shows a dashboard of the substeps, of
+the phase and elapsed time of each primary segment being upgraded, and of the
+last lines of output. Use --tui=false for a line per substep instead.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			request := &idl.ExecuteRequest{
+				Hosts:     hosts,
+				BatchSize: int32(batchSize),
+				Pause:     pause,
+			}
+			for _, c := range contents {
+				request.Contents = append(request.Contents, int32(c))
+			}
+
+			client := connectToHub()
+			return commanders.Execute(client, request, verbose, detach, tui)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print the output stream from all substeps")
+	cmd.Flags().IntSliceVar(&contents, "contents", nil, "content IDs of the primary segments to upgrade first")
+	cmd.Flags().StringSliceVar(&hosts, "hosts", nil, "hosts whose primary segments are upgraded first")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 0, "upgrade the remaining primary segments this many at a time")
+	cmd.Flags().BoolVar(&pause, "pause", false, "pause after each wave of primary segments until execute is run again")
+	cmd.Flags().BoolVar(&detach, "detach", false, "run in the background and return once execute has started")
+	cmd.Flags().BoolVar(&tui, "tui", true, "show a dashboard of the upgrade when output is a terminal")
+
+	return cmd
+}
+
+func attach() *cobra.Command {
+	var verbose bool
+	var job int
+	var tui bool
+
+	cmd := &cobra.Command{
+		Use:   "attach",
+		Short: "follows the step that the hub is running",
+		Long: `
+Shows the progress of the step that the hub is running, or of the last step it
+ran, for instance after the session running "gpupgrade execute" was
+disconnected or a step was started with --detach. Recent output is replayed
+before following the step until it finishes. Use --job to follow a particular
+job listed by "gpupgrade jobs".
 `,
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 
 			client := connectToHub()
-			return commanders.Execute(client, verbose)
+			return commanders.Attach(client, int32(job), verbose, tui)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print the output stream from all substeps")
+	cmd.Flags().IntVar(&job, "job", 0, "ID of the job to follow (defaults to the most recent)")
+	cmd.Flags().BoolVar(&tui, "tui", true, "show a dashboard of the upgrade when output is a terminal")
+
+	return cmd
+}
+
+func jobs() *cobra.Command {
+	var job int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "lists the steps that the hub is running and has run",
+		Long: `
+Lists the jobs in which the hub has run steps since it started, with the
+status of each and of its current substep. Steps started with --detach keep
+running after the command that started them returns; poll them here, or
+follow one with "gpupgrade attach --job".
+`,
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			client := connectToHub()
+			return commanders.Jobs(client, int32(job), asJSON)
+		},
+	}
+
+	cmd.Flags().IntVar(&job, "job", 0, "show only the job with this ID")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the jobs as JSON")
+
+	return cmd
+}
+
+func report() *cobra.Command {
+	var printMarkdown bool
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "regenerates the upgrade report",
+		Long: `
+Regenerates the upgrade report, which the hub also writes at the end of each
+step: the source and target versions, the topology of both clusters, the
+timings of the substeps, warnings from the step logs, the disk usage of the
+data directories, and the reports that pg_upgrade produced. It is written to
+the state directory as Markdown and HTML.
+
+With --print, the Markdown is printed rather than the paths of the report.
+`,
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			client := connectToHub()
+			return commanders.Report(client, printMarkdown)
+		},
+	}
+
+	cmd.Flags().BoolVar(&printMarkdown, "print", false, "print the report as Markdown")
 
 	return cmd
 }
 
 func finalize() *cobra.Command {
 	var verbose bool
+	var detach bool
 
 	cmd := &cobra.Command{
 		Use:   "finalize",
@@ -373,10 +597,13 @@ func finalize() *cobra.Command {
 		Long: `
 Updates the port of the new cluster.
 This step can not be reverted.
+
+With --detach, finalize runs in the background on the hub and returns once it
+has started; see "gpupgrade jobs".
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 			client := connectToHub()
-			err := commanders.Finalize(client, verbose)
+			err := commanders.Finalize(client, verbose, detach)
 			if err != nil {
 				gplog.Error(err.Error())
 				os.Exit(1)
@@ -385,6 +612,7 @@ This step can not be reverted.
 	}
 
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print the output stream from all substeps")
+	cmd.Flags().BoolVar(&detach, "detach", false, "run in the background and return once finalize has started")
 
 	return cmd
 }
@@ -431,6 +659,45 @@ func parsePorts(val string) ([]uint32, error) {
 	return ports, nil
 }
 
+func services() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "services",
+		Short: "subcommands to inspect the hub and agents",
+		Long:  "subcommands to inspect the hub and agents",
+	}
+
+	cmd.AddCommand(servicesStatus())
+
+	return cmd
+}
+
+func servicesStatus() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "shows whether the hub and agents are ready",
+		Long: `
+Asks the hub and the agent on each host of the source cluster whether they are
+ready, using the standard gRPC health checking protocol (grpc.health.v1). The
+hub is ready once its configuration records the source cluster and it can reach
+every agent. Exits with an error unless every service is ready.
+
+External checkers may query the same services: "idl.CliToHub" on the hub and
+"idl.Agent" on the agents, or "" for whether the process is serving at all.
+`,
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			hosts, agentPort, err := commanders.AgentHosts("")
+			if err != nil {
+				return err
+			}
+
+			return commanders.ServicesStatus(hubAddress(), hosts, agentPort, connTimeout())
+		},
+	}
+}
+
 var restartServices = &cobra.Command{
 	Use:   "restart-services",
 	Short: "restarts hub/agents that are not currently running",
@@ -462,40 +729,95 @@ var restartServices = &cobra.Command{
 	},
 }
 
-var killServices = &cobra.Command{
-	Use:   "kill-services",
-	Short: "Abruptly stops the hub and agents that are currently running.",
-	Long: "Abruptly stops the hub and agents that are currently running.\n" +
-		"Return if no hub is running, which may leave spurious agents running.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		running, err := commanders.IsHubRunning()
-		if err != nil {
-			return xerrors.Errorf("failed to determine if there is a hub running: %w", err)
-		}
+func collectLogs() *cobra.Command {
+	var outputDir string
+	var maxHostMB uint64
 
-		if !running {
-			// FIXME: Returning early if the hub is not running, means that we
-			//  cannot kill spurious agents.
-			// We cannot simply start the hub in order to kill spurious agents
-			// since this requires initialize to have been run and the source
-			// cluster config to exist. The main use case for kill-services is
-			// at the start of BATS testing where we do not want to make any
-			// assumption about the state of the cluster or environment.
+	cmd := &cobra.Command{
+		Use:   "collect-logs",
+		Short: "collects logs from the hub and all agents into a single archive",
+		Long: `
+Collects the hub and agent state directories, including pg_upgrade working
+directories, and the log directories from every host, gpAdminLogs and any
+chosen with --log-directory, into a single timestamped archive suitable for
+sending to support. Credentials are redacted from configuration
+files, and master backups are not included.
+`,
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path, err := commanders.CollectLogs(connectToHub(), outputDir, maxHostMB*1024*1024)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\nLogs written to %s\n", path)
 			return nil
-		}
+		},
+	}
 
-		_, err = connectToHub().StopServices(context.Background(), &idl.StopServicesRequest{})
-		if err != nil {
-			errCode := grpcStatus.Code(err)
-			errMsg := grpcStatus.Convert(err).Message()
-			// XXX: "transport is closing" is not documented but is needed to uniquely interpret codes.Unavailable
-			// https://github.com/grpc/grpc/blob/v1.24.0/doc/statuscodes.md
-			if errCode != codes.Unavailable || errMsg != "transport is closing" {
+	cmd.Flags().StringVar(&outputDir, "output-dir", ".", "directory in which to write the archive")
+	cmd.Flags().Uint64Var(&maxHostMB, "max-host-size", 1024, "maximum uncompressed megabytes to collect from each host (0 for no limit)")
+
+	return cmd
+}
+
+func killServices() *cobra.Command {
+	var hostsFile string
+
+	cmd := &cobra.Command{
+		Use:   "kill-services",
+		Short: "Abruptly stops the hub and agents that are currently running.",
+		Long: "Abruptly stops the hub and agents that are currently running.\n" +
+			"Agents are stopped even if no hub is running. They are found using\n" +
+			"--hosts, or the source cluster recorded by initialize if --hosts is not\n" +
+			"given.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			running, err := commanders.IsHubRunning()
+			if err != nil {
+				return xerrors.Errorf("failed to determine if there is a hub running: %w", err)
+			}
+
+			if running {
+				_, err = connectToHub().StopServices(context.Background(), &idl.StopServicesRequest{})
+				if err != nil {
+					errCode := grpcStatus.Code(err)
+					errMsg := grpcStatus.Convert(err).Message()
+					// XXX: "transport is closing" is not documented but is needed to uniquely interpret codes.Unavailable
+					// https://github.com/grpc/grpc/blob/v1.24.0/doc/statuscodes.md
+					if errCode != codes.Unavailable || errMsg != "transport is closing" {
+						return err
+					}
+				}
+			}
+
+			// Stop the agents directly as well, since the hub may not have
+			// known about them. We cannot simply start the hub in order to
+			// kill spurious agents, since that requires initialize to have
+			// been run and the source cluster config to exist.
+			hosts, port, err := commanders.AgentHosts(hostsFile)
+			if err != nil {
 				return err
 			}
+
+			var failed int
+			for _, result := range commanders.KillAgents(hosts, port) {
+				fmt.Println(result)
+				if result.Outcome == commanders.AgentStopFailed {
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				return xerrors.Errorf("failed to stop agents on %d host(s)", failed)
+			}
+
 			return nil
-		}
+		},
+	}
 
-		return nil
-	},
+	cmd.Flags().StringVar(&hostsFile, "hosts", "", "file listing the hosts on which to stop agents, one per line")
+
+	return cmd
 }
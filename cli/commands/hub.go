@@ -15,6 +15,7 @@ import (
 	"github.com/greenplum-db/gpupgrade/utils"
 	"github.com/greenplum-db/gpupgrade/utils/daemon"
 	"github.com/greenplum-db/gpupgrade/utils/log"
+	"github.com/greenplum-db/gpupgrade/utils/pidfile"
 )
 
 // This directory to have the implementation code for the gRPC server to serve
@@ -22,6 +23,8 @@ import (
 
 func Hub() *cobra.Command {
 	var logdir string
+	var metricsPort, agentMetricsPort, gatewayPort int
+	var gatewayAddress string
 	var shouldDaemonize bool
 
 	var cmd = &cobra.Command{
@@ -61,6 +64,30 @@ func Hub() *cobra.Command {
 				return err
 			}
 
+			// Explicit flags override the persisted configuration, and will be
+			// saved along with it.
+			if cmd.Flags().Changed("metrics-port") {
+				conf.MetricsPort = metricsPort
+			}
+			if cmd.Flags().Changed("agent-metrics-port") {
+				conf.AgentMetricsPort = agentMetricsPort
+			}
+			if cmd.Flags().Changed("gateway-port") {
+				conf.GatewayPort = gatewayPort
+			}
+			if cmd.Flags().Changed("gateway-address") {
+				conf.GatewayAddress = gatewayAddress
+			}
+
+			// Only one hub may use a state directory at a time. The lock is
+			// held until the hub exits, and is released by the kernel if it
+			// crashes.
+			lock, err := pidfile.Acquire(stateDir, conf.Port)
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+
 			h := hub.New(conf, grpc.DialContext, stateDir)
 
 			if shouldDaemonize {
@@ -77,6 +104,10 @@ func Hub() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&logdir, "log-directory", "", "gpupgrade hub log directory")
+	cmd.Flags().IntVar(&metricsPort, "metrics-port", 0, "serve Prometheus metrics on this port (disabled when 0)")
+	cmd.Flags().IntVar(&agentMetricsPort, "agent-metrics-port", 0, "port on which agents serve Prometheus metrics (disabled when 0)")
+	cmd.Flags().IntVar(&gatewayPort, "gateway-port", 0, "serve the hub's REST/JSON API on this port (disabled when 0)")
+	cmd.Flags().StringVar(&gatewayAddress, "gateway-address", "", "serve the hub's REST/JSON API on this host name or IP address (default localhost)")
 
 	daemon.MakeDaemonizable(cmd, &shouldDaemonize)
 
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/cli/commanders"
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+func state() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "subcommands to manage the gpupgrade state directory",
+		Long:  "subcommands to manage the gpupgrade state directory",
+	}
+
+	cmd.AddCommand(stateRestore())
+
+	return cmd
+}
+
+func stateRestore() *cobra.Command {
+	var generation int
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restores the hub configuration from a backup",
+		Long: fmt.Sprintf(`
+Restores the hub configuration (config.json) from one of the last %d
+generations that were saved before it was changed, for instance after the
+configuration has been corrupted. Generation 1 is the most recent. The
+configuration being replaced is itself backed up first, so a restore can be
+undone by restoring generation 1.
+
+The hub must not be running; stop it with kill-services first.
+`, hub.ConfigBackups),
+		Args: cobra.MaximumNArgs(0), //no positional args allowed
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			running, err := commanders.IsHubRunning()
+			if err != nil {
+				return xerrors.Errorf("failed to determine if there is a hub running: %w", err)
+			}
+			if running {
+				return xerrors.New("the hub is running; stop it with 'gpupgrade kill-services' before restoring its configuration")
+			}
+
+			err = hub.RestoreConfig(utils.GetStateDir(), generation)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Restored %s from backup generation %d\n", hub.ConfigFileName, generation)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&generation, "generation", 1, "backup generation to restore, where 1 is the most recent")
+
+	return cmd
+}
@@ -0,0 +1,128 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+)
+
+// AuditLogFileName is the file in the state directory that records every
+// operator request to the hub; see package audit.
+const AuditLogFileName = "audit.log"
+
+// cliToHubPrefix starts the full method name of every CliToHub request.
+// Requests from agents, such as those to the FileServer, are not audited.
+const cliToHubPrefix = "/idl.CliToHub/"
+
+// auditStarted is the result recorded for a streaming request that has been
+// received but has not yet finished.
+const auditStarted = "STARTED"
+
+// auditCall records an operator request and its result in the audit log.
+// Failing to record it is logged but does not fail the request, which has
+// already run.
+func (s *Server) auditCall(ctx context.Context, method string, request interface{}, err error) {
+	result := "OK"
+	if err != nil {
+		result = err.Error()
+	}
+
+	s.auditRequest(ctx, method, request, result)
+}
+
+// auditStream returns ss wrapped to record the request of the streaming RPC
+// method as soon as its handler receives it. Steps run for a long time and
+// may outlive the hub, so they are audited as they start as well as by
+// auditCall once they finish.
+func (s *Server) auditStream(ss grpc.ServerStream, method string) *requestRecorder {
+	return &requestRecorder{
+		ServerStream: ss,
+		received: func(request interface{}) {
+			s.auditRequest(ss.Context(), method, request, auditStarted)
+		},
+	}
+}
+
+func (s *Server) auditRequest(ctx context.Context, method string, request interface{}, result string) {
+	if !strings.HasPrefix(method, cliToHubPrefix) {
+		return
+	}
+
+	e := s.auditEntry(ctx, strings.TrimPrefix(method, cliToHubPrefix))
+	e.Result = result
+
+	if request != nil {
+		args, jerr := json.Marshal(redactRequest(request))
+		if jerr != nil {
+			gplog.Error("encoding %s request for the audit log: %v", e.Method, jerr)
+		}
+		e.Args = args
+	}
+
+	s.appendAudit(e)
+}
+
+// auditChange records a change to a configuration setting.
+func (s *Server) auditChange(ctx context.Context, name, before, after string) {
+	e := s.auditEntry(ctx, "SetConfig")
+	e.Change = &audit.Change{Name: name, Before: before, After: after}
+
+	s.appendAudit(e)
+}
+
+func (s *Server) auditEntry(ctx context.Context, method string) audit.Entry {
+	e := audit.Entry{Method: method}
+	e.User, e.Host = audit.Caller(ctx)
+
+	if p, ok := peer.FromContext(ctx); ok {
+		e.Peer = p.Addr.String()
+	}
+
+	return e
+}
+
+func (s *Server) appendAudit(e audit.Entry) {
+	if s.audit == nil {
+		return // not serving
+	}
+
+	if err := s.audit.Append(e); err != nil {
+		gplog.Error("recording %s in the audit log: %v", e.Method, err)
+	}
+}
+
+// redactRequest hides secrets in a request before it is recorded.
+func redactRequest(request interface{}) interface{} {
+	if r, ok := request.(*idl.SetConfigRequest); ok && secretSettings[r.Name] {
+		return &idl.SetConfigRequest{Name: r.Name, Value: redacted(r.Value)}
+	}
+
+	return request
+}
+
+// requestRecorder remembers the request received by a server-streaming RPC so
+// that it can be audited, passing it to received, if set, as it arrives.
+type requestRecorder struct {
+	grpc.ServerStream
+	request  interface{}
+	received func(request interface{})
+}
+
+func (r *requestRecorder) RecvMsg(m interface{}) error {
+	err := r.ServerStream.RecvMsg(m)
+	if err == nil && r.request == nil {
+		r.request = m
+		if r.received != nil {
+			r.received(m)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,84 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+)
+
+func TestAudit(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	path := filepath.Join(stateDir, AuditLogFileName)
+	log, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("opening audit log: %+v", err)
+	}
+
+	s := &Server{Config: &Config{}, StateDir: stateDir, audit: log}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(audit.UserKey, "gpadmin", audit.HostKey, "mdw"))
+
+	// A streaming request is recorded when it is received, before it runs.
+	stream := s.auditStream(&requestStream{ctx: ctx, request: &idl.ExecuteRequest{BatchSize: 2}}, "/idl.CliToHub/Execute")
+	if err := stream.RecvMsg(&idl.ExecuteRequest{}); err != nil {
+		t.Fatalf("RecvMsg() returned error %+v", err)
+	}
+	s.auditCall(ctx, "/idl.CliToHub/Execute", stream.request, xerrors.New("oops"))
+	s.auditCall(ctx, "/idl.FileServer/ServeFiles", &idl.ServeFilesRequest{Dir: "/data"}, nil)
+	s.auditCall(ctx, "/idl.CliToHub/SetConfig", &idl.SetConfigRequest{Name: "webhook-secret", Value: "shh"}, nil)
+
+	if _, err := s.SetConfig(ctx, &idl.SetConfigRequest{Name: "copy-fanout", Value: "8"}); err != nil {
+		t.Fatalf("SetConfig() returned error %+v", err)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading audit log: %+v", err)
+	}
+
+	var entries []audit.Entry
+	for _, line := range bytes.Split(bytes.TrimSpace(contents), []byte("\n")) {
+		var e audit.Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatalf("decoding %q: %+v", line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4 (agent requests are not audited)", len(entries))
+	}
+
+	for i, result := range []string{auditStarted, "oops"} {
+		if e := entries[i]; e.Method != "Execute" || e.User != "gpadmin" || e.Host != "mdw" || e.Result != result || string(e.Args) != `{"BatchSize":2}` {
+			t.Errorf("got request entry %+v, want result %q", e, result)
+		}
+	}
+
+	if e := entries[2]; string(e.Args) != `{"name":"webhook-secret","value":"(set)"}` {
+		t.Errorf("got secret entry with args %s", e.Args)
+	}
+
+	expected := audit.Change{Name: "copy-fanout", Before: "4", After: "8"}
+	if e := entries[3]; e.Method != "SetConfig" || e.User != "gpadmin" || e.Change == nil || *e.Change != expected {
+		t.Errorf("got change entry %+v, want change %+v", e, expected)
+	}
+
+	if head, err := audit.Verify(path); err != nil || head.Seq != 4 || head.Hash != entries[3].Hash {
+		t.Errorf("Verify() returned %v, %v", head, err)
+	}
+}
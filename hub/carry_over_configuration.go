@@ -0,0 +1,145 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
+)
+
+func (s *Server) CarryOverConfiguration(streams step.OutStreams) error {
+	agentConns, err := s.AgentConns()
+	if err != nil {
+		return xerrors.Errorf("connecting to gpupgrade agents: %w", err)
+	}
+
+	dataDirPairs, err := s.GetDataDirPairs()
+	if err != nil {
+		return xerrors.Errorf("getting old and new primary data directories: %w", err)
+	}
+
+	return CarryOverConfiguration(streams, agentConns, dataDirPairs, s.Source, s.Target)
+}
+
+// CarryOverConfiguration copies the portable postgresql.conf settings and the
+// pg_hba.conf entries of each source primary to its target. The master is
+// handled locally, and the segments by their agents. Settings that were renamed
+// or removed in the target's major version, or that refer to libraries or paths
+// of the source installation, are not applied, but are reported to streams
+// along with everything that was.
+func CarryOverConfiguration(streams step.OutStreams, agentConns []*Connection, dataDirPairs map[string][]*idl.DataDirPair, source, target *utils.Cluster) error {
+	sourceMajor := source.Version.SemVer.Major
+	targetMajor := target.Version.SemVer.Major
+
+	var mErr *multierror.Error
+	var carryOvers []*idl.ConfigurationCarryOver
+
+	result, err := pgconf.CarryOver(source.MasterDataDir(), target.MasterDataDir(), sourceMajor, targetMajor)
+	if err != nil {
+		mErr = multierror.Append(mErr, xerrors.Errorf("carrying over master configuration: %w", err))
+	} else {
+		carryOvers = append(carryOvers, result.Proto(-1))
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, conn := range agentConns {
+		conn := conn // capture range variable
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			reply, err := conn.AgentClient.CarryOverConfiguration(context.Background(), &idl.CarryOverConfigurationRequest{
+				DataDirPairs:       dataDirPairs[conn.Hostname],
+				SourceMajorVersion: sourceMajor,
+				TargetMajorVersion: targetMajor,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			// An agent that failed on some segments still reports the rest.
+			carryOvers = append(carryOvers, reply.GetCarryOvers()...)
+			if err != nil {
+				mErr = multierror.Append(mErr, xerrors.Errorf("carrying over configuration on host %s: %w", conn.Hostname, err))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Slice(carryOvers, func(i, j int) bool { return carryOvers[i].Content < carryOvers[j].Content })
+	for _, c := range carryOvers {
+		if err := writeCarryOver(streams.Stdout(), c, source); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+func writeCarryOver(w io.Writer, c *idl.ConfigurationCarryOver, source *utils.Cluster) error {
+	applied := 0
+	for _, change := range c.Changes {
+		if change.Class == idl.GUCChange_PORTABLE {
+			applied++
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "content %d (%s): applied %d settings, added %d pg_hba.conf entries\n",
+		c.Content, source.Primaries[int(c.Content)].Hostname, applied, c.HBAEntries)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range c.Changes {
+		setting := fmt.Sprintf("%s = %s", change.Name, displayValue(change.Name, change.SourceValue))
+
+		var line string
+		switch change.Class {
+		case idl.GUCChange_PORTABLE:
+			previous := "unset"
+			if change.TargetValue != "" {
+				previous = displayValue(change.Name, change.TargetValue)
+			}
+			line = fmt.Sprintf("  applied: %s (was %s)", setting, previous)
+
+		case idl.GUCChange_RENAMED:
+			line = fmt.Sprintf("  not applied: %s was renamed to %s; review its value and set it manually", setting, change.NewName)
+
+		case idl.GUCChange_REMOVED:
+			line = fmt.Sprintf("  not applied: %s is not supported by the new cluster", setting)
+
+		case idl.GUCChange_UNPORTABLE:
+			line = fmt.Sprintf("  not applied: %s may refer to libraries or paths of the old installation; check them and set it manually", setting)
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// displayValue formats a setting's value for the report, hiding the values of
+// settings that hold passwords.
+func displayValue(name, value string) string {
+	if strings.Contains(name, "password") {
+		return "<redacted>"
+	}
+
+	return pgconf.Quote(value)
+}
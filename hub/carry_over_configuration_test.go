@@ -0,0 +1,121 @@
+package hub
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+func TestCarryOverConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sourceMaster := filepath.Join(dir, "qddir", "seg-1")
+	targetMaster := filepath.Join(dir, "qddir_upgrade", "seg-1")
+	for path, contents := range map[string]string{
+		filepath.Join(sourceMaster, "postgresql.conf"): "port = 5432\nstatement_mem = 250MB\nshared_preload_libraries = 'metrics'\n",
+		filepath.Join(sourceMaster, "pg_hba.conf"):     "local all gpadmin ident\n",
+		filepath.Join(targetMaster, "postgresql.conf"): "port = 6000\n",
+		filepath.Join(targetMaster, "pg_hba.conf"):     "local all gpadmin ident\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("creating %s: %+v", filepath.Dir(path), err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("writing %s: %+v", path, err)
+		}
+	}
+
+	source := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Port: 5432, Hostname: "mdw", DataDir: sourceMaster, Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Port: 25432, Hostname: "sdw1", DataDir: "/data/dbfast1/seg0", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Port: 25433, Hostname: "sdw2", DataDir: "/data/dbfast2/seg1", Role: "p", PreferredRole: "p"},
+	})
+	source.Version = dbconn.NewVersion("5.28.0")
+
+	target := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Port: 6000, Hostname: "mdw", DataDir: targetMaster, Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Port: 26432, Hostname: "sdw1", DataDir: "/data/dbfast1/seg0_upgrade", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Port: 26433, Hostname: "sdw2", DataDir: "/data/dbfast2/seg1_upgrade", Role: "p", PreferredRole: "p"},
+	})
+	target.Version = dbconn.NewVersion("6.9.0")
+
+	dataDirPairs := map[string][]*idl.DataDirPair{
+		"sdw1": {{SourceDataDir: "/data/dbfast1/seg0", TargetDataDir: "/data/dbfast1/seg0_upgrade", Content: 0}},
+		"sdw2": {{SourceDataDir: "/data/dbfast2/seg1", TargetDataDir: "/data/dbfast2/seg1_upgrade", Content: 1}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sdw1 := mock_idl.NewMockAgentClient(ctrl)
+	sdw1.EXPECT().CarryOverConfiguration(gomock.Any(), &idl.CarryOverConfigurationRequest{
+		DataDirPairs:       dataDirPairs["sdw1"],
+		SourceMajorVersion: 5,
+		TargetMajorVersion: 6,
+	}).Return(&idl.CarryOverConfigurationReply{CarryOvers: []*idl.ConfigurationCarryOver{{
+		Content: 0,
+		Changes: []*idl.GUCChange{
+			{Name: "gp_max_databases", SourceValue: "32", Class: idl.GUCChange_REMOVED},
+			{Name: "unix_socket_directory", SourceValue: "/tmp", Class: idl.GUCChange_RENAMED, NewName: "unix_socket_directories"},
+		},
+		HBAEntries: 2,
+	}}}, nil)
+
+	expected := errors.New("permission denied")
+	sdw2 := mock_idl.NewMockAgentClient(ctrl)
+	sdw2.EXPECT().CarryOverConfiguration(gomock.Any(), gomock.Any()).Return(nil, expected)
+
+	agentConns := []*Connection{
+		{nil, sdw1, "sdw1", nil},
+		{nil, sdw2, "sdw2", nil},
+	}
+
+	streams := new(bufferedStreams)
+	err = CarryOverConfiguration(streams, agentConns, dataDirPairs, source, target)
+
+	var merr *multierror.Error
+	if !xerrors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Fatalf("returned %#v, want one error", err)
+	}
+	if !xerrors.Is(merr.Errors[0], expected) || !strings.Contains(merr.Errors[0].Error(), "host sdw2") {
+		t.Errorf("returned error %q, want %q for sdw2", merr.Errors[0], expected)
+	}
+
+	report := streams.stdout.String()
+	for _, line := range []string{
+		"content -1 (mdw): applied 1 settings, added 0 pg_hba.conf entries",
+		"  applied: statement_mem = 250MB (was unset)",
+		"  not applied: shared_preload_libraries = metrics may refer to libraries or paths of the old installation",
+		"content 0 (sdw1): applied 0 settings, added 2 pg_hba.conf entries",
+		"  not applied: gp_max_databases = 32 is not supported by the new cluster",
+		"  not applied: unix_socket_directory = /tmp was renamed to unix_socket_directories",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report has no line %q:\n%s", line, report)
+		}
+	}
+
+	conf, err := ioutil.ReadFile(filepath.Join(targetMaster, "postgresql.conf"))
+	if err != nil {
+		t.Fatalf("reading target configuration: %+v", err)
+	}
+	if string(conf) != "port = 6000\nstatement_mem = 250MB\n" {
+		t.Errorf("target master configuration is\n%s", conf)
+	}
+}
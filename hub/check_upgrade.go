@@ -1,9 +1,13 @@
 package hub
 
 import (
+	"fmt"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
 
 	"github.com/hashicorp/go-multierror"
 
@@ -14,12 +18,18 @@ func (s *Server) CheckUpgrade(stream step.OutStreams) error {
 	var wg sync.WaitGroup
 	checkErrs := make(chan error, 2)
 
+	// The durations of the checks refine the estimate of execute.
+	var masterTime, primariesTime time.Duration
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
+		start := time.Now()
+		defer func() { masterTime = time.Since(start) }()
+
 		stateDir := s.StateDir
-		err := UpgradeMaster(s.Source, s.Target, stateDir, stream, true, false)
+		err := UpgradeMaster(s.Source, s.Target, stateDir, stream, true, false, s.CopyEngine)
 		if err != nil {
 			checkErrs <- err
 		}
@@ -41,7 +51,22 @@ func (s *Server) CheckUpgrade(stream step.OutStreams) error {
 			checkErrs <- errors.Wrap(dataDirPairsErr, "failed to get old and new primary data directories")
 		}
 
-		upgradeErr := UpgradePrimaries(true, "", agentConns, dataDirPairMap, s.Source, s.Target, s.UseLinkMode)
+		start := time.Now()
+		defer func() { primariesTime = time.Since(start) }()
+
+		upgradeErr := UpgradePrimaries(UpgradePrimaryArgs{
+			CheckOnly:          true,
+			MasterBackupDir:    "",
+			AgentConns:         agentConns,
+			DataDirPairMap:     dataDirPairMap,
+			Source:             s.Source,
+			Target:             s.Target,
+			UseLinkMode:        s.UseLinkMode,
+			CopyEngine:         s.CopyEngine,
+			HostParallelism:    s.HostParallelism,
+			ClusterParallelism: s.ClusterParallelism,
+			Progress:           sendProgress(stream),
+		})
 
 		if upgradeErr != nil {
 			checkErrs <- upgradeErr
@@ -56,5 +81,29 @@ func (s *Server) CheckUpgrade(stream step.OutStreams) error {
 		multiErr = multierror.Append(multiErr, err)
 	}
 
+	if multiErr.ErrorOrNil() == nil {
+		s.recordCheckDurations(stream, masterTime, primariesTime)
+	}
+
 	return multiErr.ErrorOrNil()
 }
+
+// recordCheckDurations adds the durations of the checks to the measurements of
+// the source cluster, if it was measured. Like the measurements, they are only
+// advisory, so failing to record them is only a warning.
+func (s *Server) recordCheckDurations(stream step.OutStreams, master, primaries time.Duration) {
+	m, err := LoadMeasurements(s.StateDir)
+	if xerrors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	if err == nil {
+		m.CheckMasterSeconds = master.Seconds()
+		m.CheckPrimariesSeconds = primaries.Seconds()
+		err = m.Save(s.StateDir)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stream.Stderr(), "warning: the durations of the checks were not recorded: %v\n", err)
+	}
+}
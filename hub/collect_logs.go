@@ -0,0 +1,211 @@
+package hub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/archive"
+)
+
+// CollectErrorsFileName is the name of the entry in the collected archive that
+// lists any hosts whose logs could not be collected.
+const CollectErrorsFileName = "ERRORS.txt"
+
+// CollectLogs streams a single gzipped tarball containing a tarball of the
+// hub's logs along with one from each agent. Failure to reach an agent is not
+// fatal; it is recorded in the archive instead, since a partial bundle is still
+// useful when diagnosing a failed upgrade.
+func (s *Server) CollectLogs(in *idl.CollectLogsRequest, stream idl.CliToHub_CollectLogsServer) error {
+	gplog.Info("collecting logs")
+
+	var errs []string
+	var bundles []*hostBundle
+	defer func() {
+		for _, b := range bundles {
+			b.remove()
+		}
+	}()
+
+	hubBundle, err := collectHubLogs(s.StateDir, in.MaxBytesPerHost)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("hub: %v", err))
+	} else {
+		bundles = append(bundles, hubBundle)
+	}
+
+	agentBundles, err := s.collectAgentLogs(in.MaxBytesPerHost)
+	bundles = append(bundles, agentBundles...)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	buf := bufio.NewWriterSize(idl.CollectLogsWriter{Sender: stream}, archive.ChunkSize)
+	tarball := archive.NewWriter(buf, 0) // each bundle is already size-limited
+
+	for _, b := range bundles {
+		if err := b.addTo(tarball); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		if err := tarball.AddBytes(CollectErrorsFileName, []byte(strings.Join(errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := tarball.Close(); err != nil {
+		return xerrors.Errorf("collecting logs: %w", err)
+	}
+
+	if err := buf.Flush(); err != nil {
+		return xerrors.Errorf("sending logs: %w", err)
+	}
+
+	return nil
+}
+
+// hostBundle is a tarball of one host's logs, spooled to a temporary file.
+type hostBundle struct {
+	name string // the name of the bundle within the collected archive
+	file *os.File
+}
+
+func newHostBundle(name string) (*hostBundle, error) {
+	file, err := ioutil.TempFile("", "gpupgrade-logs-")
+	if err != nil {
+		return nil, xerrors.Errorf("creating temporary file: %w", err)
+	}
+
+	return &hostBundle{name: name, file: file}, nil
+}
+
+func (b *hostBundle) addTo(tarball *archive.Writer) error {
+	info, err := b.file.Stat()
+	if err != nil {
+		return xerrors.Errorf("collecting %s: %w", b.name, err)
+	}
+
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return xerrors.Errorf("collecting %s: %w", b.name, err)
+	}
+
+	return tarball.AddReader(b.name, b.file, info.Size())
+}
+
+func (b *hostBundle) remove() {
+	b.file.Close()
+	if err := os.Remove(b.file.Name()); err != nil {
+		gplog.Error("removing temporary log bundle: %v", err)
+	}
+}
+
+func collectHubLogs(stateDir string, maxBytes uint64) (*hostBundle, error) {
+	bundle, err := newHostBundle("hub.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.WriteHostLogs(bundle.file, stateDir, utils.LogDirs(), maxBytes)
+	if err != nil {
+		bundle.remove()
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// collectAgentLogs returns the bundles from every agent that could be reached,
+// along with an error describing any that could not.
+func (s *Server) collectAgentLogs(maxBytes uint64) ([]*hostBundle, error) {
+	conns, err := s.AgentConns()
+	if err != nil {
+		return nil, xerrors.Errorf("connecting to agents: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	bundles := make(chan *hostBundle, len(conns))
+	errs := make(chan error, len(conns))
+
+	for _, conn := range conns {
+		conn := conn // capture range variable
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			bundle, err := collectAgentBundle(conn, maxBytes)
+			if err != nil {
+				errs <- xerrors.Errorf("host %s: %w", conn.Hostname, err)
+				return
+			}
+
+			bundles <- bundle
+		}()
+	}
+
+	wg.Wait()
+	close(bundles)
+	close(errs)
+
+	var result []*hostBundle
+	for b := range bundles {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+
+	var multiErr *multierror.Error
+	for err := range errs {
+		multiErr = multierror.Append(multiErr, err)
+	}
+
+	return result, multiErr.ErrorOrNil()
+}
+
+func collectAgentBundle(conn *Connection, maxBytes uint64) (_ *hostBundle, err error) {
+	bundle, err := newHostBundle(fmt.Sprintf("hosts/%s.tar.gz", conn.Hostname))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			bundle.remove()
+		}
+	}()
+
+	stream, err := conn.AgentClient.CollectLogs(context.Background(), &idl.CollectLogsRequest{
+		MaxBytesPerHost: maxBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := bundle.file.Write(reply.Data); err != nil {
+			return nil, xerrors.Errorf("spooling logs: %w", err)
+		}
+	}
+
+	return bundle, nil
+}
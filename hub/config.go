@@ -1,23 +1,82 @@
 package hub
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/greenplum-db/gpupgrade/db"
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/webhook"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"golang.org/x/net/context"
+	"golang.org/x/xerrors"
 )
 
 const ConfigFileName = "config.json"
 
 func (s *Server) SetConfig(ctx context.Context, in *idl.SetConfigRequest) (*idl.SetConfigReply, error) {
+	before, _ := s.GetConfig(ctx, &idl.GetConfigRequest{Name: in.Name})
+
 	switch in.Name {
 	case "old-bindir":
 		s.Source.BinDir = in.Value
 	case "new-bindir":
 		s.Target.BinDir = in.Value
+	case "copy-fanout":
+		fanout, err := parseCount(in)
+		if err != nil {
+			return nil, err
+		}
+		s.CopyFanout = fanout
+	case "copy-bandwidth":
+		bandwidth, err := dircopy.ParseBandwidth(in.Value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		s.CopyBandwidth = bandwidth
+	case "host-parallelism":
+		parallelism, err := parseCount(in)
+		if err != nil {
+			return nil, err
+		}
+		s.HostParallelism = parallelism
+	case "cluster-parallelism":
+		parallelism, err := parseCount(in)
+		if err != nil {
+			return nil, err
+		}
+		s.ClusterParallelism = parallelism
+	case "webhooks":
+		s.Webhooks = nil
+		for _, url := range strings.Split(in.Value, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				s.Webhooks = append(s.Webhooks, url)
+			}
+		}
+	case "webhook-secret":
+		s.WebhookSecret = in.Value
+	case "gateway-token":
+		// The gateway checks the token as it serves each request.
+		s.mu.Lock()
+		s.GatewayToken = in.Value
+		s.mu.Unlock()
+	case "webhook-format":
+		format, err := webhook.ParseFormat(in.Value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		s.WebhookFormat = format
 	default:
 		return nil, status.Errorf(codes.NotFound, "%s is not a valid configuration key", in.Name)
 	}
@@ -26,10 +85,53 @@ func (s *Server) SetConfig(ctx context.Context, in *idl.SetConfigRequest) (*idl.
 		return &idl.SetConfigReply{}, err
 	}
 
-	gplog.Info("Successfully set %s to %s", in.Name, in.Value)
+	after, _ := s.GetConfig(ctx, &idl.GetConfigRequest{Name: in.Name})
+	s.auditChange(ctx, in.Name, before.GetValue(), after.GetValue())
+
+	value := in.Value
+	if secretSettings[in.Name] {
+		value = redacted(value)
+	}
+
+	gplog.Info("Successfully set %s to %s", in.Name, value)
 	return &idl.SetConfigReply{}, nil
 }
 
+// parseCount parses the value of a setting that must be a non-negative
+// integer.
+func parseCount(in *idl.SetConfigRequest) (int, error) {
+	count, err := strconv.Atoi(in.Value)
+	if err != nil || count < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s %q: must be a non-negative integer", in.Name, in.Value)
+	}
+
+	return count, nil
+}
+
+// secretSettings are the settings whose values are never shown or logged.
+var secretSettings = map[string]bool{
+	"webhook-secret": true,
+	"gateway-token":  true,
+}
+
+// redacted hides a secret setting, showing only whether it is set.
+func redacted(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return "(set)"
+}
+
+// gatewayToken returns GatewayToken, which SetConfig may change while the
+// gateway is serving.
+func (s *Server) gatewayToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.GatewayToken
+}
+
 func (s *Server) GetConfig(ctx context.Context, in *idl.GetConfigRequest) (*idl.GetConfigReply, error) {
 	resp := &idl.GetConfigReply{}
 
@@ -40,9 +142,113 @@ func (s *Server) GetConfig(ctx context.Context, in *idl.GetConfigRequest) (*idl.
 		resp.Value = s.Target.BinDir
 	case "new-datadir":
 		resp.Value = s.Target.MasterDataDir()
+	case "copy-fanout":
+		resp.Value = strconv.Itoa(s.copyFanout())
+	case "copy-bandwidth":
+		resp.Value = strconv.FormatInt(s.CopyBandwidth, 10)
+	case "host-parallelism":
+		resp.Value = strconv.Itoa(s.HostParallelism)
+	case "cluster-parallelism":
+		resp.Value = strconv.Itoa(s.ClusterParallelism)
+	case "webhooks":
+		resp.Value = strings.Join(s.Webhooks, ",")
+	case "webhook-secret":
+		resp.Value = redacted(s.WebhookSecret)
+	case "gateway-token":
+		resp.Value = redacted(s.gatewayToken())
+	case "webhook-format":
+		format, _ := webhook.ParseFormat(string(s.WebhookFormat))
+		resp.Value = string(format)
+	case "gpinitsystem":
+		sourceDBConn := db.NewDBConn("localhost", int(s.Source.MasterPort()), "template1")
+		config, err := s.initsystemConfig(sourceDBConn)
+		if err != nil {
+			return nil, err
+		}
+		resp.Value = strings.Join(config, "\n")
 	default:
 		return nil, status.Errorf(codes.NotFound, "%s is not a valid configuration key", in.Name)
 	}
 
 	return resp, nil
 }
+
+// ConfigBackups is the number of previous generations of the configuration
+// that are kept by SaveConfig.
+const ConfigBackups = 5
+
+// ConfigBackupDir is the directory within the state directory that holds
+// configuration backups.
+const ConfigBackupDir = "backups"
+
+// ConfigBackupPath returns the path of the given backup generation of the
+// configuration. Generation 1 is the most recent.
+func ConfigBackupPath(stateDir string, generation int) string {
+	name := fmt.Sprintf("config.%d.json", generation)
+	return filepath.Join(stateDir, ConfigBackupDir, name)
+}
+
+// rotateConfigBackups shifts each backup generation of the configuration down
+// by one, discarding the oldest, and makes the current configuration the most
+// recent backup. The current configuration is hard-linked rather than copied,
+// so it is never missing from the state directory.
+func rotateConfigBackups(stateDir string) error {
+	current := filepath.Join(stateDir, ConfigFileName)
+	if _, err := os.Stat(current); os.IsNotExist(err) {
+		return nil // nothing to back up
+	}
+
+	err := os.MkdirAll(filepath.Join(stateDir, ConfigBackupDir), 0700)
+	if err != nil {
+		return err
+	}
+
+	for gen := ConfigBackups - 1; gen >= 1; gen-- {
+		err := os.Rename(ConfigBackupPath(stateDir, gen), ConfigBackupPath(stateDir, gen+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	newest := ConfigBackupPath(stateDir, 1)
+	if err := os.Remove(newest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Link(current, newest); err != nil {
+		return err
+	}
+
+	return utils.SyncDir(filepath.Join(stateDir, ConfigBackupDir))
+}
+
+// RestoreConfig replaces the configuration in stateDir with the given backup
+// generation. The backup must be a configuration that this gpupgrade can load.
+// The configuration being replaced becomes the newest backup, so a restore can
+// itself be undone by restoring generation 1. The hub must not be running.
+func RestoreConfig(stateDir string, generation int) error {
+	if generation < 1 || generation > ConfigBackups {
+		return xerrors.Errorf("backup generation must be between 1 and %d", ConfigBackups)
+	}
+
+	backup := ConfigBackupPath(stateDir, generation)
+	contents, err := ioutil.ReadFile(backup)
+	if err != nil {
+		return xerrors.Errorf("reading configuration backup: %w", err)
+	}
+
+	if err := new(Config).Load(bytes.NewReader(contents)); err != nil {
+		return xerrors.Errorf("configuration backup %s is not usable: %w", backup, err)
+	}
+
+	if err := rotateConfigBackups(stateDir); err != nil {
+		return xerrors.Errorf("backing up hub configuration: %w", err)
+	}
+
+	path := filepath.Join(stateDir, ConfigFileName)
+	if err := utils.AtomicallyWriteFile(path, contents, 0600); err != nil {
+		return xerrors.Errorf("restoring hub configuration: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package hub
+
+import (
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+// ConfigSchemaVersion is the version of the config.json format written by this
+// version of gpupgrade. Any change to the serialized form of Config must bump
+// it and register a migration from the previous version in configSchema.
+//
+// History:
+//   0: unversioned. Written by initialize as "{}" and filled in by the hub.
+//   1: adds SchemaVersion; no other changes.
+const ConfigSchemaVersion = 1
+
+var configSchema = schema.NewRegistry(ConfigFileName, ConfigSchemaVersion)
+
+func init() {
+	configSchema.Register(0, func(doc []byte) ([]byte, error) {
+		return doc, nil
+	})
+}
+
+// versionedConfig is the serialized form of Config.
+type versionedConfig struct {
+	SchemaVersion int
+	*Config
+}
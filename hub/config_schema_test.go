@@ -0,0 +1,141 @@
+package hub_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/hub"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden file for the current config.json format")
+
+// goldenConfig is the configuration stored in the testdata/config golden
+// files.
+func goldenConfig() *hub.Config {
+	cluster := func(binDir, version string, offset int) *utils.Cluster {
+		return &utils.Cluster{
+			ContentIDs: []int{-1, 0},
+			Primaries: map[int]utils.SegConfig{
+				-1: {DbID: 1, ContentID: -1, Port: 5432 + offset, Hostname: "mdw", DataDir: "/data/master/gpseg-1", Role: "p", PreferredRole: "p"},
+				0:  {DbID: 2, ContentID: 0, Port: 25432 + offset, Hostname: "sdw1", DataDir: "/data/primary/gpseg0", Role: "p", PreferredRole: "p"},
+			},
+			Mirrors: map[int]utils.SegConfig{
+				0: {DbID: 3, ContentID: 0, Port: 35432 + offset, Hostname: "sdw2", DataDir: "/data/mirror/gpseg0", Role: "m", PreferredRole: "m"},
+			},
+			BinDir:  binDir,
+			Version: dbconn.NewVersion(version),
+		}
+	}
+
+	return &hub.Config{
+		Source:      cluster("/usr/local/gpdb5/bin", "5.28.0", 0),
+		Target:      cluster("/usr/local/gpdb6/bin", "6.9.0", 1),
+		TargetPorts: hub.PortAssignments{Master: 6432, Standby: 6433, Primaries: []int{6434}},
+		Port:        7527,
+		AgentPort:   6416,
+		UseLinkMode: true,
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	cases := []struct {
+		name     string
+		file     string
+		defaults *hub.Config // the values in place before Load
+		expected *hub.Config
+	}{
+		{
+			name:     "loads the unversioned file created by initialize",
+			file:     "v0_initial.json",
+			defaults: &hub.Config{Port: 7527, AgentPort: 6416},
+			expected: &hub.Config{Port: 7527, AgentPort: 6416},
+		},
+		{
+			name:     "loads an unversioned configuration",
+			file:     "v0.json",
+			defaults: &hub.Config{},
+			expected: goldenConfig(),
+		},
+		{
+			name:     "loads a version 1 configuration",
+			file:     "v1.json",
+			defaults: &hub.Config{},
+			expected: goldenConfig(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", "config", c.file))
+			if err != nil {
+				t.Fatalf("opening golden file: %+v", err)
+			}
+			defer file.Close()
+
+			actual := c.defaults
+			if err := actual.Load(file); err != nil {
+				t.Fatalf("Load() returned error %+v", err)
+			}
+
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("loaded %#v, want %#v", actual, c.expected)
+			}
+		})
+	}
+
+	t.Run("saves the current format", func(t *testing.T) {
+		path := filepath.Join("testdata", "config", "v1.json")
+
+		buf := new(bytes.Buffer)
+		if err := goldenConfig().Save(buf); err != nil {
+			t.Fatalf("Save() returned error %+v", err)
+		}
+
+		if *update {
+			if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("updating golden file: %+v", err)
+			}
+		}
+
+		expected, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading golden file: %+v", err)
+		}
+
+		// If this fails, the config.json format has changed. Bump
+		// ConfigSchemaVersion, register a migration from the previous version,
+		// and add a golden file for the new version.
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("saved\n%s\nwant\n%s", buf.Bytes(), expected)
+		}
+	})
+
+	t.Run("refuses a configuration written by a newer gpupgrade", func(t *testing.T) {
+		future := `{"SchemaVersion": 999, "Port": 1234}`
+
+		conf := &hub.Config{Port: 7527}
+		err := conf.Load(bytes.NewBufferString(future))
+
+		var versionErr *schema.NewerVersionError
+		if !xerrors.As(err, &versionErr) {
+			t.Fatalf("returned error %#v, want type %T", err, versionErr)
+		}
+		if versionErr.Version != 999 || versionErr.Supported != hub.ConfigSchemaVersion {
+			t.Errorf("got error %#v", versionErr)
+		}
+
+		if conf.Port != 7527 {
+			t.Errorf("configuration was modified to %#v", conf)
+		}
+	})
+}
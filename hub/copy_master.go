@@ -2,12 +2,18 @@ package hub
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 
 	"github.com/hashicorp/go-multierror"
 	"golang.org/x/xerrors"
@@ -19,22 +25,54 @@ type Result struct {
 	err    error
 }
 
+// DefaultCopyFanout is the number of hosts to which the master data directory
+// is copied at once from each source when Config.CopyFanout is not set.
+const DefaultCopyFanout = 4
+
+func (c *Config) copyFanout() int {
+	if c.CopyFanout > 0 {
+		return c.CopyFanout
+	}
+
+	return DefaultCopyFanout
+}
+
 func (s *Server) CopyMasterDataDir(streams step.OutStreams, destinationDir string) error {
+	if s.CopyEngine == dircopy.EngineNative {
+		agentConns, err := s.AgentConns()
+		if err != nil {
+			return xerrors.Errorf("connecting to gpupgrade agents: %w", err)
+		}
+
+		hubAddr := fmt.Sprintf("%s:%d", s.Target.MasterHostname(), s.Port)
+		return PullMasterDataDir(streams, agentConns, s.Target, hubAddr, s.AgentPort, destinationDir, s.copyFanout(), s.CopyBandwidth)
+	}
+
 	// Make sure sourceDir ends with a trailing slash so that rsync will
 	// transfer the directory contents and not the directory itself.
 	sourceDir := filepath.Clean(s.Target.MasterDataDir()) + string(filepath.Separator)
 
+	args := []string{"--archive", "--compress", "--delete", "--stats"}
+	if s.CopyBandwidth > 0 {
+		// rsync's limit is in units of 1024 bytes per second.
+		args = append(args, fmt.Sprintf("--bwlimit=%d", (s.CopyBandwidth+1023)/1024))
+	}
+
 	/*
 	 * Copy the directory once per host.
 	 *
 	 * We don't need to copy the master directory on the master host
 	 * If there are primaries on the same host, the hostname will be
 	 * added for the corresponding primaries.
+	 *
+	 * Every copy comes from the master, so only copyFanout() of them run
+	 * at once to avoid saturating its network.
 	 */
 	var wg sync.WaitGroup
 
 	hosts := s.Target.PrimaryHostnames()
 	results := make(chan *Result, len(hosts))
+	running := make(chan struct{}, s.copyFanout())
 
 	for _, hostname := range hosts {
 		hostname := hostname // capture range variable
@@ -43,16 +81,21 @@ func (s *Server) CopyMasterDataDir(streams step.OutStreams, destinationDir strin
 		go func() {
 			defer wg.Done()
 
+			running <- struct{}{}
+			defer func() { <-running }()
+
 			dest := fmt.Sprintf("%s:%s", hostname, destinationDir)
-			cmd := execCommand("rsync",
-				"--archive", "--compress", "--delete", "--stats",
-				sourceDir, dest)
+			cmdArgs := append(append([]string{}, args...), sourceDir, dest)
+			cmd := execCommand("rsync", cmdArgs...)
 
 			result := Result{}
 			cmd.Stdout = &result.stdout
 			cmd.Stderr = &result.stderr
 
+			start := time.Now()
 			err := cmd.Run()
+			metrics.ObserveRsync("copy_master", start, result.stdout.Bytes(), err)
+
 			if err != nil {
 				err = xerrors.Errorf("copying master data directory to host %s: %w", hostname, err)
 				result.err = err
@@ -82,3 +125,188 @@ func (s *Server) CopyMasterDataDir(streams step.OutStreams, destinationDir strin
 
 	return multierr.ErrorOrNil()
 }
+
+// copySource is a FileServer from which an agent can pull the master data
+// directory.
+type copySource struct {
+	name string // for messages
+	addr string // host:port
+	dir  string
+}
+
+// PullMasterDataDir has the agent on each primary host of the target cluster
+// pull the master data directory into destinationDir. The hub, at hubAddr,
+// serves the first hosts; each host that finishes then serves others from its
+// copy, so the copy spreads through the cluster as a tree instead of all
+// coming from the master. Each source serves at most fanout hosts at once,
+// and each host receives at most bandwidth bytes per second, if set. Progress
+// is written to the stdout stream.
+func PullMasterDataDir(streams step.OutStreams, agentConns []*Connection, target *utils.Cluster, hubAddr string, agentPort int, destinationDir string, fanout int, bandwidth int64) error {
+	conns := primaryHostConns(agentConns, target)
+
+	// Each value is a free slot of a source, so there are at most fanout
+	// values for each possible source.
+	sources := make(chan copySource, fanout*(len(conns)+1))
+	for i := 0; i < fanout; i++ {
+		sources <- copySource{name: "hub", addr: hubAddr, dir: target.MasterDataDir()}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var multierr *multierror.Error
+
+	for _, conn := range conns {
+		conn := conn // capture range variable
+		source := <-sources
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			reply, err := pullDataDir(streams, &mu, conn, source, destinationDir, bandwidth)
+			metrics.ObserveCopy("copy_master", start, reply.GetBytes(), err)
+
+			sources <- source
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				multierr = multierror.Append(multierr, xerrors.Errorf("copying master data directory to host %s from %s: %w", conn.Hostname, source.name, err))
+				return
+			}
+
+			for i := 0; i < fanout; i++ {
+				sources <- copySource{
+					name: conn.Hostname,
+					addr: fmt.Sprintf("%s:%d", conn.Hostname, agentPort),
+					dir:  destinationDir,
+				}
+			}
+
+			_, err = fmt.Fprintf(streams.Stdout(), "copied %d files (%d bytes) to %s:%s from %s, deleted %d\n",
+				reply.Files, reply.Bytes, conn.Hostname, destinationDir, source.name, reply.Deleted)
+			if err != nil {
+				multierr = multierror.Append(multierr, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return multierr.ErrorOrNil()
+}
+
+// pullDataDir has the agent of conn pull the master data directory from
+// source, writing its progress to streams under mu. It returns the final
+// reply of the agent.
+func pullDataDir(streams step.OutStreams, mu *sync.Mutex, conn *Connection, source copySource, destinationDir string, bandwidth int64) (*idl.PullDirReply, error) {
+	stream, err := conn.AgentClient.PullDir(context.Background(), &idl.PullDirRequest{
+		Source:         source.addr,
+		Dir:            source.dir,
+		TargetDir:      destinationDir,
+		Delete:         true,
+		BandwidthLimit: bandwidth,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var reply *idl.PullDirReply
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return reply, err
+		}
+
+		if reply != nil {
+			mu.Lock()
+			fmt.Fprintf(streams.Stdout(), "copying to %s from %s: %d files (%d bytes)\n",
+				conn.Hostname, source.name, reply.Files, reply.Bytes)
+			mu.Unlock()
+		}
+		reply = r
+	}
+
+	if reply == nil {
+		return nil, xerrors.New("agent did not report the result of the copy")
+	}
+
+	return reply, nil
+}
+
+// VerifyMasterDataDir checks the copy of the target master data directory in
+// destinationDir on each primary host against a manifest of the original, so
+// that a corrupted copy is caught before it is restored into the primaries.
+func (s *Server) VerifyMasterDataDir(streams step.OutStreams, destinationDir string) error {
+	agentConns, err := s.AgentConns()
+	if err != nil {
+		return xerrors.Errorf("connecting to gpupgrade agents: %w", err)
+	}
+
+	manifest, err := dircopy.ComputeManifest(s.Target.MasterDataDir())
+	if err != nil {
+		return xerrors.Errorf("computing manifest of master data directory: %w", err)
+	}
+
+	return VerifyMasterCopies(streams, agentConns, s.Target, manifest, destinationDir)
+}
+
+// VerifyMasterCopies has the agent on each primary host of the target cluster
+// verify destinationDir against manifest. Every file that does not match is
+// reported, grouped by host.
+func VerifyMasterCopies(streams step.OutStreams, agentConns []*Connection, target *utils.Cluster, manifest []dircopy.ManifestEntry, destinationDir string) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var multierr *multierror.Error
+
+	for _, conn := range primaryHostConns(agentConns, target) {
+		conn := conn // capture range variable
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := dircopy.VerifyRemote(context.Background(), conn.AgentClient, destinationDir, manifest)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				multierr = multierror.Append(multierr, xerrors.Errorf("verifying master data directory copy on host %s: %w", conn.Hostname, err))
+				return
+			}
+
+			_, err = fmt.Fprintf(streams.Stdout(), "verified %d files in %s:%s\n", len(manifest), conn.Hostname, destinationDir)
+			if err != nil {
+				multierr = multierror.Append(multierr, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return multierr.ErrorOrNil()
+}
+
+// primaryHostConns returns the connections to the agents on hosts with
+// primaries of the given cluster.
+func primaryHostConns(agentConns []*Connection, cluster *utils.Cluster) []*Connection {
+	hosts := make(map[string]bool)
+	for _, hostname := range cluster.PrimaryHostnames() {
+		hosts[hostname] = true
+	}
+
+	var conns []*Connection
+	for _, conn := range agentConns {
+		if hosts[conn.Hostname] {
+			conns = append(conns, conn)
+		}
+	}
+
+	return conns
+}
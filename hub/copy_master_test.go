@@ -1,22 +1,29 @@
 package hub
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/greenplum-db/gpupgrade/utils"
 
+	"github.com/golang/mock/gomock"
 	multierror "github.com/hashicorp/go-multierror"
 	"golang.org/x/xerrors"
 	"google.golang.org/grpc"
 
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
 	"github.com/greenplum-db/gpupgrade/testutils/exectest"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
 )
 
 const (
@@ -110,6 +117,26 @@ func TestCopyMaster(t *testing.T) {
 		}
 	})
 
+	t.Run("limits the bandwidth of rsync", func(t *testing.T) {
+		hub.CopyBandwidth = 1 << 20
+		defer func() { hub.CopyBandwidth = 0 }()
+
+		execCommand = exectest.NewCommandWithVerifier(Success, func(name string, args ...string) {
+			expectedArgs := []string{
+				"--archive", "--compress", "--delete", "--stats", "--bwlimit=1024",
+				"/data/qddir/seg-1/",
+			}
+			if !reflect.DeepEqual(args[:len(args)-1], expectedArgs) {
+				t.Errorf("rsync invoked with %q, want %q", args, expectedArgs)
+			}
+		})
+
+		err := hub.CopyMasterDataDir(DevNull, "foobar/path")
+		if err != nil {
+			t.Errorf("copying master data directory: %+v", err)
+		}
+	})
+
 	t.Run("copies the master data directory only once per host", func(t *testing.T) {
 		// Create a one-host cluster.
 		oneHostTargetCluster := MustCreateCluster(t, []utils.SegConfig{
@@ -195,3 +222,152 @@ func TestCopyMaster(t *testing.T) {
 		}
 	})
 }
+
+func TestPullMasterDataDir(t *testing.T) {
+	target := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Port: 15432, Hostname: "mdw", DataDir: "/data/qddir/seg-1", Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Port: 25432, Hostname: "host1", DataDir: "/data/dbfast1/seg1", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Port: 25433, Hostname: "host2", DataDir: "/data/dbfast2/seg2", Role: "p", PreferredRole: "p"},
+		{ContentID: 2, DbID: 4, Port: 25434, Hostname: "host3", DataDir: "/data/dbfast3/seg3", Role: "p", PreferredRole: "p"},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	requests := make(map[string]*idl.PullDirRequest)
+
+	// pullingClient returns an agent client whose PullDir reports progress and
+	// then succeeds, or fails with the given error.
+	pullingClient := func(host string, pullErr error) *mock_idl.MockAgentClient {
+		client := mock_idl.NewMockAgentClient(ctrl)
+		client.EXPECT().PullDir(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, request *idl.PullDirRequest, _ ...grpc.CallOption) (idl.Agent_PullDirClient, error) {
+				mu.Lock()
+				requests[host] = request
+				mu.Unlock()
+
+				if pullErr != nil {
+					return nil, pullErr
+				}
+
+				stream := mock_idl.NewMockAgent_PullDirClient(ctrl)
+				gomock.InOrder(
+					stream.EXPECT().Recv().Return(&idl.PullDirReply{Files: 1, Bytes: 10}, nil),
+					stream.EXPECT().Recv().Return(&idl.PullDirReply{Files: 2, Bytes: 20, Deleted: 1}, nil),
+					stream.EXPECT().Recv().Return(nil, io.EOF),
+				)
+				return stream, nil
+			})
+		return client
+	}
+
+	expected := errors.New("disk full")
+	agentConns := []*Connection{
+		{nil, pullingClient("host1", expected), "host1", nil},
+		{nil, pullingClient("host2", nil), "host2", nil},
+		{nil, pullingClient("host3", nil), "host3", nil},
+	}
+
+	streams := new(bufferedStreams)
+	err := PullMasterDataDir(streams, agentConns, target, "mdw:7527", 6416, "/data/master.bak", 1, 1024)
+
+	var merr *multierror.Error
+	if !xerrors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Fatalf("returned %#v, want one error", err)
+	}
+	if !xerrors.Is(merr.Errors[0], expected) || !strings.Contains(merr.Errors[0].Error(), "host1 from hub") {
+		t.Errorf("returned error %q, want %q for host1", merr.Errors[0], expected)
+	}
+
+	// host1 pulls first, from the hub. Having failed, it serves no one.
+	for host, request := range requests {
+		switch request.Source {
+		case "mdw:7527":
+			if request.Dir != "/data/qddir/seg-1" {
+				t.Errorf("%s pulled %q from the hub", host, request.Dir)
+			}
+		case "host2:6416", "host3:6416":
+			if request.Dir != "/data/master.bak" {
+				t.Errorf("%s pulled %q from a peer", host, request.Dir)
+			}
+		default:
+			t.Errorf("%s pulled from %q", host, request.Source)
+		}
+
+		if request.TargetDir != "/data/master.bak" || !request.Delete || request.BandwidthLimit != 1024 {
+			t.Errorf("%s got request %+v", host, request)
+		}
+	}
+
+	if requests["host1"].Source != "mdw:7527" {
+		t.Errorf("host1 pulled from %q, want the hub", requests["host1"].Source)
+	}
+
+	stdout := streams.stdout.String()
+	for _, line := range []string{
+		"copying to host2 from hub: 1 files (10 bytes)\n",
+		"copied 2 files (20 bytes) to host2:/data/master.bak from hub, deleted 1\n",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("stdout %q does not contain %q", stdout, line)
+		}
+	}
+}
+
+func TestVerifyMasterCopies(t *testing.T) {
+	target := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Port: 15432, Hostname: "mdw", DataDir: "/data/qddir/seg-1", Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Port: 25432, Hostname: "host1", DataDir: "/data/dbfast1/seg1", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Port: 25433, Hostname: "host2", DataDir: "/data/dbfast2/seg2", Role: "p", PreferredRole: "p"},
+	})
+
+	manifest := []dircopy.ManifestEntry{
+		{Path: "PG_VERSION", Size: 3, SHA256: "2b640621567d2a99502a5360c501dabf48c83144bda7829b9f7378f357b452fb"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// verifyingClient returns an agent client whose VerifyManifest replies with
+	// the given mismatches after receiving the manifest.
+	verifyingClient := func(mismatches ...*idl.ManifestMismatch) *mock_idl.MockAgentClient {
+		stream := mock_idl.NewMockAgent_VerifyManifestClient(ctrl)
+		stream.EXPECT().Send(&idl.VerifyManifestRequest{
+			Dir:     "/data/master.bak",
+			Entries: []*idl.ManifestEntry{{Path: "PG_VERSION", Size: 3, SHA256: manifest[0].SHA256}},
+		}).Return(nil)
+		stream.EXPECT().CloseAndRecv().Return(&idl.VerifyManifestReply{Mismatches: mismatches}, nil)
+
+		client := mock_idl.NewMockAgentClient(ctrl)
+		client.EXPECT().VerifyManifest(gomock.Any()).Return(stream, nil)
+		return client
+	}
+
+	agentConns := []*Connection{
+		{nil, verifyingClient(), "host1", nil},
+		{nil, verifyingClient(&idl.ManifestMismatch{Path: "PG_VERSION", Reason: "missing"}), "host2", nil},
+		{nil, mock_idl.NewMockAgentClient(ctrl), "mdw", nil},
+	}
+
+	err := VerifyMasterCopies(DevNull, agentConns, target, manifest, "/data/master.bak")
+
+	var merr *multierror.Error
+	if !xerrors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Fatalf("returned %#v, want one error", err)
+	}
+
+	var verifyErr *dircopy.VerifyError
+	if !xerrors.As(merr.Errors[0], &verifyErr) {
+		t.Fatalf("returned error %#v, want a VerifyError", merr.Errors[0])
+	}
+
+	expected := []dircopy.Mismatch{{Path: "PG_VERSION", Reason: "missing"}}
+	if !reflect.DeepEqual(verifyErr.Mismatches, expected) {
+		t.Errorf("got mismatches %+v, want %+v", verifyErr.Mismatches, expected)
+	}
+
+	if !strings.Contains(merr.Errors[0].Error(), "host2") {
+		t.Errorf("error %q does not name the host", merr.Errors[0])
+	}
+}
@@ -0,0 +1,364 @@
+package hub
+
+import (
+	"context"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+)
+
+// EstimateRates are the rates of work that the estimate of execute's duration
+// assumes.
+type EstimateRates struct {
+	CopyBytesPerSecond float64 // copying data files, locally or to another host
+	LinkFilesPerSecond float64 // linking data files in link mode
+	RelationsPerSecond float64 // dumping and restoring the catalog of each relation
+	OverheadSeconds    float64 // each run of pg_upgrade, unless CHECK_UPGRADE was measured
+}
+
+// DefaultEstimateRates are deliberately conservative, since an upgrade that
+// overruns its change window is worse than one that finishes early.
+var DefaultEstimateRates = EstimateRates{
+	CopyBytesPerSecond: 100 << 20,
+	LinkFilesPerSecond: 10000,
+	RelationsPerSecond: 100,
+	OverheadSeconds:    60,
+}
+
+// EstimatedSubsteps are the substeps of execute whose durations are estimated,
+// in the order they run. The others take little time in comparison.
+var EstimatedSubsteps = []idl.Substep{
+	idl.Substep_UPGRADE_MASTER,
+	idl.Substep_COPY_MASTER,
+	idl.Substep_UPGRADE_PRIMARIES,
+}
+
+// EstimateOptions are the settings of the upgrade that affect its duration.
+// The parallelism is that of UpgradePrimaryArgs.
+type EstimateOptions struct {
+	LinkMode           bool
+	HostParallelism    int
+	ClusterParallelism int
+	Rates              EstimateRates
+}
+
+// Estimate predicts the duration of each of EstimatedSubsteps.
+//
+// pg_upgrade dumps and restores the catalog of every relation, and then copies
+// the data files or, in link mode, links them. The data copied is the size of
+// the segment's databases where the server reported it, which leaves out the
+// WAL and logs in its data directory. Each segment's data directory
+// is first restored from the upgraded master, which is copied to every host by
+// COPY_MASTER. The checks that pg_upgrade runs before upgrading take as long as
+// they did in CHECK_UPGRADE, if that has been measured.
+func (m *Measurements) Estimate(opts EstimateOptions) map[idl.Substep]time.Duration {
+	rates := opts.Rates
+
+	catalog := float64(m.Relations) / rates.RelationsPerSecond
+	data := func(seg SegmentMeasurements) float64 {
+		if opts.LinkMode {
+			return float64(seg.Files) / rates.LinkFilesPerSecond
+		}
+		if seg.DatabaseBytes > 0 {
+			return float64(seg.DatabaseBytes) / rates.CopyBytesPerSecond
+		}
+		return float64(seg.DirBytes) / rates.CopyBytesPerSecond
+	}
+	copyMaster := float64(m.Master.DirBytes) / rates.CopyBytesPerSecond
+
+	masterOverhead := rates.OverheadSeconds
+	if m.CheckMasterSeconds > 0 {
+		masterOverhead = m.CheckMasterSeconds
+	}
+
+	// The checks of the primaries were scheduled as their upgrade will be, so
+	// their measured duration is added once for all of them.
+	segmentOverhead := rates.OverheadSeconds
+	primariesOverhead := 0.0
+	if m.CheckPrimariesSeconds > 0 {
+		segmentOverhead = 0
+		primariesOverhead = m.CheckPrimariesSeconds
+	}
+
+	hosts := make(map[string][]float64)
+	var all []float64
+	for _, seg := range m.Primaries {
+		d := copyMaster + segmentOverhead + catalog + data(seg)
+		hosts[seg.Host] = append(hosts[seg.Host], d)
+		all = append(all, d)
+	}
+
+	var primaries float64
+	for host, durations := range hosts {
+		parallelism := opts.HostParallelism
+		if parallelism <= 0 {
+			parallelism = m.DefaultParallelism[host]
+		}
+		primaries = math.Max(primaries, schedule(durations, parallelism))
+	}
+	if opts.ClusterParallelism > 0 {
+		primaries = math.Max(primaries, schedule(all, opts.ClusterParallelism))
+	}
+
+	return map[idl.Substep]time.Duration{
+		idl.Substep_UPGRADE_MASTER:    seconds(masterOverhead + catalog + data(m.Master)),
+		idl.Substep_COPY_MASTER:       seconds(copyMaster),
+		idl.Substep_UPGRADE_PRIMARIES: seconds(primariesOverhead + primaries),
+	}
+}
+
+// schedule approximates how long it takes to run tasks of the given durations
+// at most parallelism at a time: no less than the longest task, and no less
+// than their total divided among the slots.
+func schedule(durations []float64, parallelism int) float64 {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	var longest, total float64
+	for _, d := range durations {
+		longest = math.Max(longest, d)
+		total += d
+	}
+
+	return math.Max(longest, total/float64(parallelism))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Second)
+}
+
+// executeEstimate refines the estimate of execute's duration while it runs:
+// it replaces the prediction of each substep with its duration once it has
+// finished, and extrapolates the remaining time of UPGRADE_PRIMARIES from the
+// segments upgraded so far. Each change is sent to the client of execute. A nil
+// executeEstimate does nothing, for when there are no measurements.
+type executeEstimate struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	predicted map[idl.Substep]time.Duration
+	durations map[idl.Substep]time.Duration // of substeps that have finished
+	statuses  map[idl.Substep]idl.Status
+
+	running idl.Substep
+	started time.Time
+
+	// Of the primaries, doneBefore were upgraded by earlier waves, and done
+	// by this run.
+	primaries, doneBefore, done int
+
+	sender idl.MessageSender
+}
+
+// newExecuteEstimate returns the estimate of execute, starting from the status
+// of its substeps, or nil if initialize did not measure the source cluster.
+func (s *Server) newExecuteEstimate() *executeEstimate {
+	m, err := LoadMeasurements(s.StateDir)
+	if err != nil {
+		if !xerrors.Is(err, os.ErrNotExist) {
+			gplog.Warn("not estimating the duration of execute: %v", err)
+		}
+		return nil
+	}
+
+	e := &executeEstimate{
+		now: time.Now,
+		predicted: m.Estimate(EstimateOptions{
+			LinkMode:           s.UseLinkMode,
+			HostParallelism:    s.HostParallelism,
+			ClusterParallelism: s.ClusterParallelism,
+			Rates:              DefaultEstimateRates,
+		}),
+		durations: make(map[idl.Substep]time.Duration),
+		statuses:  make(map[idl.Substep]idl.Status),
+		primaries: len(m.Primaries),
+	}
+
+	if path, err := getStatusFile(s.StateDir); err == nil {
+		store := step.NewFileStore(path)
+		for _, substep := range EstimatedSubsteps {
+			e.statuses[substep], _ = store.Read(substep)
+		}
+	}
+
+	if plan, err := readWavePlan(s.StateDir); err == nil && plan != nil {
+		for _, wave := range plan.Waves {
+			if wave.Status.Status == idl.Status_COMPLETE {
+				e.doneBefore += len(wave.Contents)
+			}
+		}
+	}
+
+	return e
+}
+
+// run wraps the function of an estimated substep, to time it and to send the
+// estimate as it starts and finishes.
+func (e *executeEstimate) run(substep idl.Substep, f func(step.OutStreams) error) func(step.OutStreams) error {
+	if e == nil {
+		return f
+	}
+
+	return func(streams step.OutStreams) error {
+		e.mu.Lock()
+		if sender, ok := streams.(idl.MessageSender); ok {
+			e.sender = sender
+		}
+		e.running = substep
+		e.started = e.now()
+		e.statuses[substep] = idl.Status_RUNNING
+		e.sendLocked()
+		e.mu.Unlock()
+
+		err := f(streams)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.durations[substep] = e.now().Sub(e.started)
+		e.running = idl.Substep_UNKNOWN_STEP
+		switch {
+		case xerrors.Is(err, step.ErrPaused):
+			e.statuses[substep] = idl.Status_PAUSED
+		case err != nil:
+			e.statuses[substep] = idl.Status_FAILED
+		default:
+			e.statuses[substep] = idl.Status_COMPLETE
+		}
+		e.sendLocked()
+
+		return err
+	}
+}
+
+// progress wraps the Progress function of UpgradePrimaryArgs, to refine the
+// estimate as each segment is upgraded.
+func (e *executeEstimate) progress(next func(*idl.SegmentProgress)) func(*idl.SegmentProgress) {
+	if e == nil {
+		return next
+	}
+
+	return func(p *idl.SegmentProgress) {
+		if next != nil {
+			next(p)
+		}
+
+		if p.Phase != idl.SegmentProgress_DONE {
+			return
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.done++
+		e.sendLocked()
+	}
+}
+
+func (e *executeEstimate) sendLocked() {
+	if e.sender == nil {
+		return
+	}
+
+	e.sender.Send(&idl.Message{Contents: &idl.Message_Estimate{Estimate: e.estimateLocked()}})
+}
+
+func (e *executeEstimate) estimate() *idl.Estimate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.estimateLocked()
+}
+
+func (e *executeEstimate) estimateLocked() *idl.Estimate {
+	estimate := &idl.Estimate{}
+
+	var remaining time.Duration
+	for _, substep := range EstimatedSubsteps {
+		duration := e.predicted[substep]
+		left := duration
+
+		if d, ok := e.durations[substep]; ok {
+			duration = d
+		}
+
+		switch {
+		case e.statuses[substep] == idl.Status_COMPLETE:
+			left = 0
+
+		case substep == idl.Substep_UPGRADE_PRIMARIES:
+			left = e.primariesLeftLocked()
+
+		case substep == e.running:
+			left = maxDuration(duration-e.now().Sub(e.started), 0)
+		}
+
+		remaining += left
+		estimate.Substeps = append(estimate.Substeps, &idl.Estimate_Substep{
+			Step:    substep,
+			Status:  e.statuses[substep],
+			Seconds: int64(duration / time.Second),
+		})
+	}
+
+	estimate.Remaining = int64(remaining.Round(time.Second) / time.Second)
+	return estimate
+}
+
+// primariesLeftLocked returns how long the primaries that remain will take to
+// upgrade: once some have been upgraded by this run, at the rate that they
+// were; before then, the share of the prediction that is left.
+func (e *executeEstimate) primariesLeftLocked() time.Duration {
+	predicted := e.predicted[idl.Substep_UPGRADE_PRIMARIES]
+	if e.primaries == 0 {
+		return predicted
+	}
+
+	left := e.primaries - e.doneBefore - e.done
+	if left <= 0 {
+		return 0
+	}
+
+	var elapsed time.Duration
+	switch {
+	case e.running == idl.Substep_UPGRADE_PRIMARIES:
+		elapsed = e.now().Sub(e.started)
+	default:
+		elapsed = e.durations[idl.Substep_UPGRADE_PRIMARIES]
+	}
+
+	if e.done > 0 {
+		return elapsed * time.Duration(left) / time.Duration(e.done)
+	}
+
+	share := predicted * time.Duration(e.primaries-e.doneBefore) / time.Duration(e.primaries)
+	return maxDuration(share-elapsed, 0)
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Estimate returns the estimate of execute's duration, from the measurements
+// of the source cluster made by initialize and the status of execute.
+func (s *Server) Estimate(ctx context.Context, in *idl.EstimateRequest) (*idl.EstimateReply, error) {
+	e := s.newExecuteEstimate()
+	if e == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the source cluster has not been measured; run initialize first")
+	}
+
+	return &idl.EstimateReply{Estimate: e.estimate()}, nil
+}
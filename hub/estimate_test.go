@@ -0,0 +1,194 @@
+package hub
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+)
+
+func TestEstimate(t *testing.T) {
+	const mib = 1 << 20
+
+	m := &Measurements{
+		Relations: 1000,
+		Master:    SegmentMeasurements{Host: "mdw", Content: -1, DirBytes: 60 * 100 * mib, Files: 10000},
+		Primaries: []SegmentMeasurements{
+			{Host: "sdw1", Content: 0, DirBytes: 30 * 100 * mib, Files: 20000},
+			{Host: "sdw1", Content: 1, DirBytes: 30 * 100 * mib, Files: 20000},
+			{Host: "sdw2", Content: 2, DirBytes: 30 * 100 * mib, Files: 20000},
+		},
+		DefaultParallelism: map[string]int{"sdw1": 1, "sdw2": 1},
+	}
+
+	t.Run("estimates copy mode from the default rates", func(t *testing.T) {
+		actual := m.Estimate(EstimateOptions{Rates: DefaultEstimateRates})
+
+		// Each primary restores the master (60s) and runs pg_upgrade (60s of
+		// overhead, 10s of catalog and 30s of data); sdw1 upgrades its two
+		// one at a time.
+		expected := map[idl.Substep]time.Duration{
+			idl.Substep_UPGRADE_MASTER:    130 * time.Second,
+			idl.Substep_COPY_MASTER:       60 * time.Second,
+			idl.Substep_UPGRADE_PRIMARIES: 320 * time.Second,
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got %v, want %v", actual, expected)
+		}
+	})
+
+	t.Run("estimates copy mode from the size of the databases where known", func(t *testing.T) {
+		sized := *m
+		sized.Primaries = append([]SegmentMeasurements(nil), m.Primaries...)
+		for i := range sized.Primaries {
+			sized.Primaries[i].DatabaseBytes = 10 * 100 * mib
+		}
+
+		actual := sized.Estimate(EstimateOptions{Rates: DefaultEstimateRates})
+
+		// Each primary copies 10s of data rather than the 30s of its data
+		// directory; the master's database size is not known.
+		expected := map[idl.Substep]time.Duration{
+			idl.Substep_UPGRADE_MASTER:    130 * time.Second,
+			idl.Substep_COPY_MASTER:       60 * time.Second,
+			idl.Substep_UPGRADE_PRIMARIES: 280 * time.Second,
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got %v, want %v", actual, expected)
+		}
+	})
+
+	t.Run("estimates link mode from the measured checks and parallelism", func(t *testing.T) {
+		measured := *m
+		measured.CheckMasterSeconds = 20
+		measured.CheckPrimariesSeconds = 40
+
+		actual := measured.Estimate(EstimateOptions{LinkMode: true, HostParallelism: 2, Rates: DefaultEstimateRates})
+		expected := map[idl.Substep]time.Duration{
+			idl.Substep_UPGRADE_MASTER:    31 * time.Second,
+			idl.Substep_COPY_MASTER:       60 * time.Second,
+			idl.Substep_UPGRADE_PRIMARIES: 112 * time.Second,
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got %v, want %v", actual, expected)
+		}
+
+		actual = measured.Estimate(EstimateOptions{LinkMode: true, HostParallelism: 2, ClusterParallelism: 1, Rates: DefaultEstimateRates})
+		if actual[idl.Substep_UPGRADE_PRIMARIES] != 256*time.Second {
+			t.Errorf("with a cluster parallelism of 1, got %v, want %v", actual[idl.Substep_UPGRADE_PRIMARIES], 256*time.Second)
+		}
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	cases := []struct {
+		durations   []float64
+		parallelism int
+		expected    float64
+	}{
+		{nil, 2, 0},
+		{[]float64{10, 10, 10}, 1, 30},
+		{[]float64{10, 10, 10}, 3, 10},
+		{[]float64{40, 10, 10}, 2, 40},
+		{[]float64{10, 10, 10, 10}, 0, 40},
+	}
+
+	for _, c := range cases {
+		if actual := schedule(c.durations, c.parallelism); actual != c.expected {
+			t.Errorf("schedule(%v, %d) returned %v, want %v", c.durations, c.parallelism, actual, c.expected)
+		}
+	}
+}
+
+// estimateStreams records the estimates sent to the client.
+type estimateStreams struct {
+	estimates []*idl.Estimate
+}
+
+func (s *estimateStreams) Stdout() io.Writer { return ioutil.Discard }
+func (s *estimateStreams) Stderr() io.Writer { return ioutil.Discard }
+
+func (s *estimateStreams) Send(msg *idl.Message) error {
+	s.estimates = append(s.estimates, msg.GetEstimate())
+	return nil
+}
+
+func TestExecuteEstimate(t *testing.T) {
+	now := time.Unix(0, 0)
+	e := &executeEstimate{
+		now: func() time.Time { return now },
+		predicted: map[idl.Substep]time.Duration{
+			idl.Substep_UPGRADE_MASTER:    100 * time.Second,
+			idl.Substep_COPY_MASTER:       50 * time.Second,
+			idl.Substep_UPGRADE_PRIMARIES: 300 * time.Second,
+		},
+		durations: make(map[idl.Substep]time.Duration),
+		statuses:  make(map[idl.Substep]idl.Status),
+		primaries: 3,
+	}
+
+	if remaining := e.estimate().Remaining; remaining != 450 {
+		t.Errorf("before execute, got %ds remaining, want 450s", remaining)
+	}
+
+	streams := &estimateStreams{}
+
+	upgradeMaster := e.run(idl.Substep_UPGRADE_MASTER, func(streams step.OutStreams) error {
+		now = now.Add(40 * time.Second)
+		if remaining := e.estimate().Remaining; remaining != 410 {
+			t.Errorf("while upgrading the master, got %ds remaining, want 410s", remaining)
+		}
+		return nil
+	})
+	if err := upgradeMaster(streams); err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if len(streams.estimates) != 2 {
+		t.Fatalf("got %d estimates, want one at the start and end of the substep", len(streams.estimates))
+	}
+
+	last := streams.estimates[1]
+	if last.Remaining != 350 {
+		t.Errorf("after upgrading the master, got %ds remaining, want 350s", last.Remaining)
+	}
+	if s := last.Substeps[0]; s.Step != idl.Substep_UPGRADE_MASTER || s.Status != idl.Status_COMPLETE || s.Seconds != 40 {
+		t.Errorf("got master estimate %v, want its actual duration", s)
+	}
+
+	progress := e.progress(nil)
+	upgradePrimaries := e.run(idl.Substep_UPGRADE_PRIMARIES, func(streams step.OutStreams) error {
+		now = now.Add(60 * time.Second)
+		progress(&idl.SegmentProgress{Content: 0, Phase: idl.SegmentProgress_UPGRADE})
+		progress(&idl.SegmentProgress{Content: 0, Phase: idl.SegmentProgress_DONE})
+
+		// The two primaries left take as long as the first one did; the
+		// master has yet to be copied.
+		if remaining := e.estimate().Remaining; remaining != 50+120 {
+			t.Errorf("after upgrading a primary, got %ds remaining, want 170s", remaining)
+		}
+		return nil
+	})
+	if err := upgradePrimaries(streams); err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// Only the DONE phase refines the estimate.
+	if len(streams.estimates) != 5 {
+		t.Errorf("got %d estimates, want 5", len(streams.estimates))
+	}
+
+	var nilEstimate *executeEstimate
+	called := false
+	nilEstimate.run(idl.Substep_UPGRADE_MASTER, func(step.OutStreams) error {
+		called = true
+		return nil
+	})(streams)
+	if !called {
+		t.Errorf("nil estimate did not run the substep")
+	}
+}
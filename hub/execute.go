@@ -9,16 +9,20 @@ import (
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/hashicorp/go-multierror"
 
+	"github.com/greenplum-db/gpupgrade/agent"
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/upgrade"
 )
 
-const executeMasterBackupName = "upgraded-master.bak"
+// executeMasterBackupName is where, in the state directory of each host, the
+// upgraded master data directory is copied.
+const executeMasterBackupName = agent.MasterBackupName
 
 func (s *Server) Execute(request *idl.ExecuteRequest, stream idl.CliToHub_ExecuteServer) (err error) {
 	upgradedMasterBackupDir := filepath.Join(s.StateDir, executeMasterBackupName)
 
-	st, err := BeginStep(s.StateDir, "execute", stream)
+	st, err := s.beginStep("execute", stream)
 	if err != nil {
 		return err
 	}
@@ -28,25 +32,37 @@ func (s *Server) Execute(request *idl.ExecuteRequest, stream idl.CliToHub_Execut
 			err = multierror.Append(err, ferr).ErrorOrNil()
 		}
 
+		s.reportStep("execute")
+
 		if err != nil {
 			gplog.Error(fmt.Sprintf("execute: %s", err))
 		}
+
+		// Send any pg_upgrade failures to the CLI in a structured form.
+		err = upgrade.StatusError(err)
 	}()
 
+	// Refine the estimate of execute's duration as it runs.
+	eta := s.newExecuteEstimate()
+
 	st.Run(idl.Substep_SHUTDOWN_SOURCE_CLUSTER, func(streams step.OutStreams) error {
 		return StopCluster(streams, s.Source, true)
 	})
 
-	st.Run(idl.Substep_UPGRADE_MASTER, func(streams step.OutStreams) error {
+	st.Run(idl.Substep_UPGRADE_MASTER, eta.run(idl.Substep_UPGRADE_MASTER, func(streams step.OutStreams) error {
 		stateDir := s.StateDir
-		return UpgradeMaster(s.Source, s.Target, stateDir, streams, false, s.UseLinkMode)
-	})
+		return UpgradeMaster(s.Source, s.Target, stateDir, streams, false, s.UseLinkMode, s.CopyEngine)
+	}))
 
-	st.Run(idl.Substep_COPY_MASTER, func(streams step.OutStreams) error {
+	st.Run(idl.Substep_COPY_MASTER, eta.run(idl.Substep_COPY_MASTER, func(streams step.OutStreams) error {
 		return s.CopyMasterDataDir(streams, upgradedMasterBackupDir)
+	}))
+
+	st.Run(idl.Substep_VERIFY_MASTER_COPY, func(streams step.OutStreams) error {
+		return s.VerifyMasterDataDir(streams, upgradedMasterBackupDir)
 	})
 
-	st.Run(idl.Substep_UPGRADE_PRIMARIES, func(_ step.OutStreams) error {
+	st.Run(idl.Substep_UPGRADE_PRIMARIES, eta.run(idl.Substep_UPGRADE_PRIMARIES, func(streams step.OutStreams) error {
 		agentConns, err := s.AgentConns()
 
 		if err != nil {
@@ -59,7 +75,23 @@ func (s *Server) Execute(request *idl.ExecuteRequest, stream idl.CliToHub_Execut
 			return errors.Wrap(err, "failed to get old and new primary data directories")
 		}
 
-		return UpgradePrimaries(false, upgradedMasterBackupDir, agentConns, dataDirPair, s.Source, s.Target, s.UseLinkMode)
+		return s.upgradeWaves(streams, UpgradePrimaryArgs{
+			CheckOnly:          false,
+			MasterBackupDir:    upgradedMasterBackupDir,
+			AgentConns:         agentConns,
+			DataDirPairMap:     dataDirPair,
+			Source:             s.Source,
+			Target:             s.Target,
+			UseLinkMode:        s.UseLinkMode,
+			CopyEngine:         s.CopyEngine,
+			HostParallelism:    s.HostParallelism,
+			ClusterParallelism: s.ClusterParallelism,
+			Progress:           eta.progress(sendProgress(streams)),
+		}, WaveOptionsFromRequest(request))
+	}))
+
+	st.Run(idl.Substep_CARRY_OVER_CONFIGURATION, func(streams step.OutStreams) error {
+		return s.CarryOverConfiguration(streams)
 	})
 
 	st.Run(idl.Substep_START_TARGET_CLUSTER, func(streams step.OutStreams) error {
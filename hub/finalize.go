@@ -8,10 +8,11 @@ import (
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
 )
 
 func (s *Server) Finalize(_ *idl.FinalizeRequest, stream idl.CliToHub_FinalizeServer) (err error) {
-	st, err := BeginStep(s.StateDir, "finalize", stream)
+	st, err := s.beginStep("finalize", stream)
 	if err != nil {
 		return err
 	}
@@ -21,6 +22,8 @@ func (s *Server) Finalize(_ *idl.FinalizeRequest, stream idl.CliToHub_FinalizeSe
 			err = multierror.Append(err, ferr).ErrorOrNil()
 		}
 
+		s.reportStep("finalize")
+
 		if err != nil {
 			gplog.Error(fmt.Sprintf("finalize: %s", err))
 		}
@@ -38,7 +41,7 @@ func (s *Server) Finalize(_ *idl.FinalizeRequest, stream idl.CliToHub_FinalizeSe
 			return UpgradeStandby(greenplumRunner, StandbyConfig{
 				Port:          s.TargetPorts.Standby,
 				Hostname:      s.Source.StandbyHostname(),
-				DataDirectory: s.Source.StandbyDataDirectory() + "_upgrade",
+				DataDirectory: upgradedStandbyDataDir(s.Source),
 			})
 		})
 	}
@@ -74,3 +77,9 @@ func (s *Server) Finalize(_ *idl.FinalizeRequest, stream idl.CliToHub_FinalizeSe
 
 	return st.Err()
 }
+
+// upgradedStandbyDataDir is where finalize creates the standby of the target
+// cluster, next to the source's.
+func upgradedStandbyDataDir(source *utils.Cluster) string {
+	return source.StandbyDataDirectory() + "_upgrade"
+}
@@ -0,0 +1,583 @@
+package hub
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+)
+
+// OpenAPIPath is the path at which the gateway serves its OpenAPI document.
+const OpenAPIPath = "/openapi.json"
+
+// GatewayUserHeader is the HTTP header in which gateway clients may name the
+// operator on whose behalf they act, for the audit log. The audit log records
+// the client's host in any case. The header is only honoured from clients that
+// present the gateway token; see Config.GatewayToken.
+const GatewayUserHeader = "X-Gpupgrade-User"
+
+// gatewayTokenScheme is the Authorization scheme with which gateway clients
+// present the gateway token.
+const gatewayTokenScheme = "Bearer "
+
+// gatewayRoute maps an HTTP request to a CliToHub request. Path segments in
+// braces, and query parameters, set the request field of that name; the rest
+// of the request is read from a JSON body.
+type gatewayRoute struct {
+	method  string
+	path    string
+	rpc     string
+	summary string
+}
+
+// gatewayRoutes are the requests served by the gateway. CollectLogs, which
+// streams a binary archive, is not among them.
+var gatewayRoutes = []gatewayRoute{
+	{http.MethodPost, "/v1/steps/initialize", "Initialize", "Start the initialize step"},
+	{http.MethodPost, "/v1/steps/initialize-create-cluster", "InitializeCreateCluster", "Create the target cluster, finishing the initialize step"},
+	{http.MethodPost, "/v1/steps/execute", "Execute", "Start the execute step"},
+	{http.MethodPost, "/v1/steps/finalize", "Finalize", "Start the finalize step"},
+	{http.MethodGet, "/v1/jobs", "Jobs", "List the running and past steps"},
+	{http.MethodGet, "/v1/jobs/{Job}/events", "Watch", "Replay and follow the messages of a step; job 0 is the most recent"},
+	{http.MethodGet, "/v1/estimate", "Estimate", "Estimate the duration of the execute step"},
+	{http.MethodPost, "/v1/report", "Report", "Regenerate the upgrade report"},
+	{http.MethodGet, "/v1/config/{name}", "GetConfig", "Get a configuration setting"},
+	{http.MethodPut, "/v1/config/{name}", "SetConfig", "Change a configuration setting"},
+	{http.MethodPost, "/v1/check-version", "CheckVersion", "Check that the source cluster can be upgraded"},
+	{http.MethodPost, "/v1/check-disk-space", "CheckDiskSpace", "Check that there is enough disk space for the upgrade"},
+	{http.MethodPost, "/v1/restart-agents", "RestartAgents", "Start any agents that are not running"},
+	{http.MethodPost, "/v1/stop-services", "StopServices", "Stop the agents and the hub"},
+}
+
+// detachParam is the query parameter with which a step is started in the
+// background; see DetachKey.
+const detachParam = "detach"
+
+// match returns the path parameters of path if it matches the route's path.
+func (r gatewayRoute) match(path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(r.path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i := range want {
+		if strings.HasPrefix(want[i], "{") && strings.HasSuffix(want[i], "}") {
+			params[strings.Trim(want[i], "{}")] = got[i]
+		} else if want[i] != got[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// gatewayMethod describes a CliToHub request, as read from its descriptor.
+type gatewayMethod struct {
+	name            string
+	input, output   string       // fully qualified message names
+	request, reply  reflect.Type // pointers to the generated message types
+	serverStreaming bool
+}
+
+func (m *gatewayMethod) newRequest() proto.Message {
+	return reflect.New(m.request.Elem()).Interface().(proto.Message)
+}
+
+func (m *gatewayMethod) newReply() proto.Message {
+	return reflect.New(m.reply.Elem()).Interface().(proto.Message)
+}
+
+// cliToHubDescriptor returns the descriptor of the CliToHub proto, which the
+// generated code registers when the idl package is loaded.
+func cliToHubDescriptor() (*descpb.FileDescriptorProto, error) {
+	zipped := proto.FileDescriptor("cli_to_hub.proto")
+	if zipped == nil {
+		return nil, xerrors.New("cli_to_hub.proto is not registered")
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, xerrors.Errorf("reading cli_to_hub.proto descriptor: %w", err)
+	}
+
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("reading cli_to_hub.proto descriptor: %w", err)
+	}
+
+	fd := &descpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(contents, fd); err != nil {
+		return nil, xerrors.Errorf("decoding cli_to_hub.proto descriptor: %w", err)
+	}
+
+	return fd, nil
+}
+
+// cliToHubMethods returns the methods of the CliToHub service in fd.
+func cliToHubMethods(fd *descpb.FileDescriptorProto) (map[string]*gatewayMethod, error) {
+	methods := make(map[string]*gatewayMethod)
+
+	for _, service := range fd.Service {
+		if service.GetName() != "CliToHub" {
+			continue
+		}
+
+		for _, m := range service.Method {
+			method := &gatewayMethod{
+				name:            m.GetName(),
+				input:           strings.TrimPrefix(m.GetInputType(), "."),
+				output:          strings.TrimPrefix(m.GetOutputType(), "."),
+				serverStreaming: m.GetServerStreaming(),
+			}
+
+			method.request = proto.MessageType(method.input)
+			method.reply = proto.MessageType(method.output)
+			if method.request == nil || method.reply == nil {
+				return nil, xerrors.Errorf("no generated types for %s", method.name)
+			}
+
+			methods[method.name] = method
+		}
+	}
+
+	return methods, nil
+}
+
+// gateway serves the CliToHub service as a REST/JSON API, with streaming
+// requests served as Server-Sent Events. Each HTTP request is made as a gRPC
+// request to the hub, so it runs the same handler and is logged, audited and
+// recorded as a job just like a request from the CLI.
+type gateway struct {
+	conn    *grpc.ClientConn
+	methods map[string]*gatewayMethod
+	openAPI []byte
+
+	// token returns the token that clients must present to name a user in
+	// GatewayUserHeader; none is accepted if it is nil or returns "".
+	token func() string
+}
+
+var gatewayMarshaler = jsonpb.Marshaler{EmitDefaults: true}
+
+func newGateway(conn *grpc.ClientConn, token func() string) (*gateway, error) {
+	fd, err := cliToHubDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := cliToHubMethods(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, route := range gatewayRoutes {
+		if methods[route.rpc] == nil {
+			return nil, xerrors.Errorf("gateway route %s %s: no CliToHub method %s", route.method, route.path, route.rpc)
+		}
+	}
+
+	openAPI, err := openAPIDocument(fd, methods)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gateway{conn: conn, methods: methods, openAPI: openAPI, token: token}, nil
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == OpenAPIPath && r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(g.openAPI)
+		return
+	}
+
+	var pathFound bool
+	for _, route := range gatewayRoutes {
+		params, ok := route.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+
+		pathFound = true
+		if route.method == r.Method {
+			g.serve(w, r, g.methods[route.rpc], params)
+			return
+		}
+	}
+
+	if pathFound {
+		writeGatewayError(w, http.StatusMethodNotAllowed, status.Errorf(codes.Unimplemented, "%s is not allowed for %s", r.Method, r.URL.Path))
+		return
+	}
+
+	writeGatewayError(w, http.StatusNotFound, status.Errorf(codes.NotFound, "no such path %s", r.URL.Path))
+}
+
+func (g *gateway) serve(w http.ResponseWriter, r *http.Request, m *gatewayMethod, params map[string]string) {
+	query := r.URL.Query()
+	detach := query.Get(detachParam) == "true"
+	for name, values := range query {
+		if name != detachParam && len(values) > 0 {
+			params[name] = values[0]
+		}
+	}
+
+	if _, ok := stepMethods[cliToHubPrefix+m.name]; detach && !ok {
+		writeGatewayError(w, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "%s is not a step and cannot be detached", m.name))
+		return
+	}
+
+	request, err := decodeRequest(r.Body, m, params)
+	if err != nil {
+		writeGatewayError(w, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "decoding %s request: %v", m.name, err))
+		return
+	}
+
+	ctx, err := g.context(r, detach)
+	if err != nil {
+		writeGatewayError(w, 0, err)
+		return
+	}
+
+	if m.serverStreaming {
+		g.stream(ctx, w, m, request, detach)
+		return
+	}
+
+	reply := m.newReply()
+	err = g.conn.Invoke(ctx, cliToHubPrefix+m.name, request, reply)
+	if m.name == "StopServices" && AgentStopped(err) {
+		// The hub stops its server while handling the request, as the agents
+		// do, so the reply is never sent.
+		err = nil
+	}
+	if err != nil {
+		writeGatewayError(w, 0, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := gatewayMarshaler.Marshal(w, reply); err != nil {
+		gplog.Error("writing %s reply to gateway client: %v", m.name, err)
+	}
+}
+
+// stream serves a streaming request as Server-Sent Events: a "message" event
+// for each message, and then an "end" event with the request's status. Only
+// errors before the first message are returned as HTTP errors. A detached step
+// is not streamed; the ID of its job is returned instead.
+func (g *gateway) stream(ctx context.Context, w http.ResponseWriter, m *gatewayMethod, request proto.Message, detach bool) {
+	stream, err := g.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, cliToHubPrefix+m.name)
+	if err == nil {
+		err = stream.SendMsg(request)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		writeGatewayError(w, 0, err)
+		return
+	}
+
+	msg := m.newReply()
+	err = stream.RecvMsg(msg)
+	if err != nil && err != io.EOF {
+		writeGatewayError(w, 0, err)
+		return
+	}
+
+	header, _ := stream.Header()
+	for _, key := range []string{StepHeader, JobHeader} {
+		if values := header.Get(key); len(values) > 0 {
+			w.Header().Set(key, values[0])
+		}
+	}
+
+	if detach {
+		writeDetachedJob(w, header)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	for err == nil {
+		data, merr := gatewayMarshaler.MarshalToString(msg)
+		if merr != nil {
+			err = merr
+			break
+		}
+
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		msg = m.newReply()
+		err = stream.RecvMsg(msg)
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	end, _ := json.Marshal(gatewayStatus(err))
+	fmt.Fprintf(w, "event: end\ndata: %s\n\n", end)
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func writeDetachedJob(w http.ResponseWriter, header metadata.MD) {
+	ids := header.Get(JobHeader)
+	if len(ids) == 0 {
+		writeGatewayError(w, 0, status.Error(codes.Internal, "hub did not start a job"))
+		return
+	}
+
+	id, err := strconv.Atoi(ids[0])
+	if err != nil {
+		writeGatewayError(w, 0, status.Errorf(codes.Internal, "hub returned job ID %q", ids[0]))
+		return
+	}
+
+	events := fmt.Sprintf("/v1/jobs/%d/events", id)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", events)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"job": id, "events": events})
+}
+
+// decodeRequest reads a request message from a JSON body, with params setting
+// the fields of the same name.
+func decodeRequest(body io.Reader, m *gatewayMethod, params map[string]string) (proto.Message, error) {
+	fields := make(map[string]json.RawMessage)
+
+	if body != nil {
+		contents, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(contents)) > 0 {
+			if err := json.Unmarshal(contents, &fields); err != nil {
+				return nil, xerrors.Errorf("body is not a JSON object: %w", err)
+			}
+		}
+	}
+
+	props := proto.GetProperties(m.request.Elem())
+	for name, value := range params {
+		var prop *proto.Properties
+		for _, p := range props.Prop {
+			if p.OrigName == name || p.JSONName == name {
+				prop = p
+				break
+			}
+		}
+		if prop == nil {
+			return nil, xerrors.Errorf("unknown field %q", name)
+		}
+
+		// A parameter replaces the field however the body named it. Numbers
+		// and enums may be quoted in JSON; booleans may not.
+		delete(fields, prop.JSONName)
+		raw, _ := json.Marshal(value)
+		if value == "true" || value == "false" {
+			if field, ok := m.request.Elem().FieldByName(prop.Name); ok && field.Type.Kind() == reflect.Bool {
+				raw = []byte(value)
+			}
+		}
+		fields[prop.OrigName] = raw
+	}
+
+	doc, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	request := m.newRequest()
+	if err := jsonpb.Unmarshal(bytes.NewReader(doc), request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// context returns the context for the gRPC request made for an HTTP request,
+// identifying the caller for the audit log. A request that names a user
+// without presenting the gateway token is refused, rather than being audited
+// under a name that anyone could have sent.
+func (g *gateway) context(r *http.Request, detach bool) (context.Context, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	pairs := []string{audit.HostKey, host}
+	if user := r.Header.Get(GatewayUserHeader); user != "" {
+		if !g.authorized(r) {
+			return nil, status.Errorf(codes.Unauthenticated, "%s requires the gateway token", GatewayUserHeader)
+		}
+		pairs = append(pairs, audit.UserKey, user)
+	}
+	if detach {
+		pairs = append(pairs, DetachKey, "true")
+	}
+
+	return metadata.AppendToOutgoingContext(r.Context(), pairs...), nil
+}
+
+// authorized reports whether r presents the gateway token.
+func (g *gateway) authorized(r *http.Request) bool {
+	if g.token == nil {
+		return false
+	}
+
+	token := g.token()
+	auth := r.Header.Get("Authorization")
+	if token == "" || !strings.HasPrefix(auth, gatewayTokenScheme) {
+		return false
+	}
+
+	presented := strings.TrimPrefix(auth, gatewayTokenScheme)
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+// gatewayError is the JSON form of a failed request, and of the end of a
+// stream.
+type gatewayError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func gatewayStatus(err error) gatewayError {
+	s := status.Convert(err)
+	return gatewayError{Code: s.Code().String(), Message: s.Message()}
+}
+
+// writeGatewayError writes err as JSON with the given HTTP status, or, if that
+// is zero, the one that corresponds to its gRPC code.
+func writeGatewayError(w http.ResponseWriter, httpStatus int, err error) {
+	if httpStatus == 0 {
+		httpStatus = httpStatusFromCode(status.Code(err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(gatewayStatus(err))
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// gatewayServer serves the gateway over HTTP, making its requests to the hub
+// at hubAddr.
+type gatewayServer struct {
+	server *http.Server
+	lis    net.Listener
+	conn   *grpc.ClientConn
+}
+
+// listenGateway listens for gateway requests on port of address, or of
+// localhost if address is empty.
+func listenGateway(address string, port int, hubAddr string, token func() string) (*gatewayServer, error) {
+	// The hub is not serving yet, so the connection is made in the background.
+	conn, err := grpc.Dial(hubAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, xerrors.Errorf("connecting gateway to hub: %w", err)
+	}
+
+	g, err := newGateway(conn, token)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if address == "" {
+		address = "localhost"
+	}
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		conn.Close()
+		return nil, xerrors.Errorf("listening for gateway: %w", err)
+	}
+
+	return &gatewayServer{server: &http.Server{Handler: g}, lis: lis, conn: conn}, nil
+}
+
+// Serve blocks, serving the gateway until Stop is called. It always returns a
+// non-nil error; http.ErrServerClosed indicates a normal shutdown.
+func (g *gatewayServer) Serve() error {
+	return g.server.Serve(g.lis)
+}
+
+// Stop shuts down the gateway, closing any streams that are still open.
+func (g *gatewayServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := g.server.Shutdown(ctx)
+	if err != nil {
+		err = g.server.Close()
+	}
+
+	if cerr := g.conn.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
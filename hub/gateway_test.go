@@ -0,0 +1,282 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+)
+
+// gatewayTestHub serves the few CliToHub requests that the gateway tests
+// make; the rest panic.
+type gatewayTestHub struct {
+	idl.CliToHubServer
+
+	config     map[string]string
+	user       string // of the last GetConfig request
+	execute    *idl.ExecuteRequest
+	executeErr error
+}
+
+func (h *gatewayTestHub) GetConfig(ctx context.Context, request *idl.GetConfigRequest) (*idl.GetConfigReply, error) {
+	h.user, _ = audit.Caller(ctx)
+
+	value, ok := h.config[request.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s is not a valid configuration key", request.Name)
+	}
+
+	return &idl.GetConfigReply{Value: value}, nil
+}
+
+func (h *gatewayTestHub) SetConfig(ctx context.Context, request *idl.SetConfigRequest) (*idl.SetConfigReply, error) {
+	h.config[request.Name] = request.Value
+	return &idl.SetConfigReply{}, nil
+}
+
+func (h *gatewayTestHub) Execute(request *idl.ExecuteRequest, stream idl.CliToHub_ExecuteServer) error {
+	h.execute = request
+
+	stream.Send(statusMessage(idl.Substep_UPGRADE_MASTER, idl.Status_RUNNING))
+	stream.Send(chunkMessage("upgrading master\n"))
+	return h.executeErr
+}
+
+func (h *gatewayTestHub) Watch(request *idl.WatchRequest, stream idl.CliToHub_WatchServer) error {
+	return status.Errorf(codes.NotFound, "no job %d since the hub started", request.Job)
+}
+
+// gatewayTestToken is the gateway token of the gateway served by serveGateway.
+const gatewayTestToken = "open sesame"
+
+// serveGateway serves hub over gRPC, and the gateway to it over HTTP.
+func serveGateway(t *testing.T, hub idl.CliToHubServer) (*httptest.Server, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("listening: %+v", err)
+	}
+
+	server := grpc.NewServer()
+	idl.RegisterCliToHubServer(server, hub)
+	go server.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("dialing hub: %+v", err)
+	}
+
+	g, err := newGateway(conn, func() string { return gatewayTestToken })
+	if err != nil {
+		t.Fatalf("newGateway() returned error %+v", err)
+	}
+
+	httpServer := httptest.NewServer(g)
+	return httpServer, func() {
+		httpServer.Close()
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func gatewayRequest(t *testing.T, method, url, body string, header ...string) (*http.Response, string) {
+	t.Helper()
+
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("creating request: %+v", err)
+	}
+
+	for i := 0; i+1 < len(header); i += 2 {
+		request.Header.Set(header[i], header[i+1])
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s: %+v", method, url, err)
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("reading response: %+v", err)
+	}
+
+	return response, string(contents)
+}
+
+func TestGateway(t *testing.T) {
+	hub := &gatewayTestHub{config: map[string]string{"copy-fanout": "4"}}
+	server, stop := serveGateway(t, hub)
+	defer stop()
+
+	t.Run("gets and sets configuration", func(t *testing.T) {
+		response, body := gatewayRequest(t, "GET", server.URL+"/v1/config/copy-fanout", "")
+		if response.StatusCode != http.StatusOK || body != `{"value":"4"}` {
+			t.Errorf("got %d %s", response.StatusCode, body)
+		}
+
+		response, body = gatewayRequest(t, "PUT", server.URL+"/v1/config/copy-fanout", `{"value": "8"}`)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("got %d %s", response.StatusCode, body)
+		}
+		if hub.config["copy-fanout"] != "8" {
+			t.Errorf("copy-fanout is %q, want %q", hub.config["copy-fanout"], "8")
+		}
+	})
+
+	t.Run("names the user only for clients with the gateway token", func(t *testing.T) {
+		url := server.URL + "/v1/config/copy-fanout"
+
+		response, body := gatewayRequest(t, "GET", url, "", GatewayUserHeader, "alice")
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("without a token got %d %s, want %d", response.StatusCode, body, http.StatusUnauthorized)
+		}
+
+		response, body = gatewayRequest(t, "GET", url, "", GatewayUserHeader, "alice", "Authorization", "Bearer guess")
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("with the wrong token got %d %s, want %d", response.StatusCode, body, http.StatusUnauthorized)
+		}
+
+		response, body = gatewayRequest(t, "GET", url, "", GatewayUserHeader, "alice", "Authorization", "Bearer "+gatewayTestToken)
+		if response.StatusCode != http.StatusOK || hub.user != "alice" {
+			t.Errorf("with the token got %d %s for user %q, want user %q", response.StatusCode, body, hub.user, "alice")
+		}
+
+		// Without the user header, no token is needed.
+		response, body = gatewayRequest(t, "GET", url, "")
+		if response.StatusCode != http.StatusOK || hub.user != "" {
+			t.Errorf("without a user got %d %s for user %q", response.StatusCode, body, hub.user)
+		}
+	})
+
+	t.Run("returns gRPC errors with the corresponding HTTP status", func(t *testing.T) {
+		response, body := gatewayRequest(t, "GET", server.URL+"/v1/config/no-such-key", "")
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.StatusCode, http.StatusNotFound)
+		}
+
+		var e gatewayError
+		if err := json.Unmarshal([]byte(body), &e); err != nil || e.Code != "NotFound" {
+			t.Errorf("got body %s, want a NotFound error", body)
+		}
+	})
+
+	t.Run("streams steps as Server-Sent Events", func(t *testing.T) {
+		response, body := gatewayRequest(t, "POST", server.URL+"/v1/steps/execute", `{"BatchSize": 2}`)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("got %d %s", response.StatusCode, body)
+		}
+		if ct := response.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("got content type %q", ct)
+		}
+
+		if hub.execute.BatchSize != 2 {
+			t.Errorf("hub got request %v, want BatchSize 2", hub.execute)
+		}
+
+		expected := "event: message\n" +
+			`data: {"status":{"step":"UPGRADE_MASTER","status":"RUNNING"}}` + "\n\n" +
+			"event: message\n" +
+			`data: {"chunk":{"buffer":"dXBncmFkaW5nIG1hc3Rlcgo=","type":"STDOUT"}}` + "\n\n" +
+			"event: end\n" +
+			`data: {"code":"OK"}` + "\n\n"
+		if body != expected {
+			t.Errorf("got events\n%s\nwant\n%s", body, expected)
+		}
+	})
+
+	t.Run("ends streams with the step's error", func(t *testing.T) {
+		hub.executeErr = errors.New("pg_upgrade failed")
+		defer func() { hub.executeErr = nil }()
+
+		_, body := gatewayRequest(t, "POST", server.URL+"/v1/steps/execute", "")
+		if !strings.HasSuffix(body, "event: end\n"+`data: {"code":"Unknown","message":"pg_upgrade failed"}`+"\n\n") {
+			t.Errorf("events do not end with the error:\n%s", body)
+		}
+	})
+
+	t.Run("returns errors before the first event with an HTTP status", func(t *testing.T) {
+		response, body := gatewayRequest(t, "GET", server.URL+"/v1/jobs/3/events", "")
+		if response.StatusCode != http.StatusNotFound || !strings.Contains(body, "no job 3") {
+			t.Errorf("got %d %s", response.StatusCode, body)
+		}
+	})
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		cases := []struct {
+			method, path, body string
+			status             int
+		}{
+			{"GET", "/v1/no-such-path", "", http.StatusNotFound},
+			{"DELETE", "/v1/config/copy-fanout", "", http.StatusMethodNotAllowed},
+			{"POST", "/v1/steps/execute", `{"NoSuchField": 1}`, http.StatusBadRequest},
+			{"POST", "/v1/steps/execute", `[]`, http.StatusBadRequest},
+			{"POST", "/v1/check-version?detach=true", "", http.StatusBadRequest},
+		}
+
+		for _, c := range cases {
+			response, body := gatewayRequest(t, c.method, server.URL+c.path, c.body)
+			if response.StatusCode != c.status {
+				t.Errorf("%s %s returned %d %s, want %d", c.method, c.path, response.StatusCode, body, c.status)
+			}
+		}
+	})
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	g, err := newGateway(nil, nil)
+	if err != nil {
+		t.Fatalf("newGateway() returned error %+v", err)
+	}
+
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage
+		Components struct {
+			Schemas map[string]struct {
+				Properties           map[string]map[string]interface{}
+				Enum                 []string
+				AdditionalProperties interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal(g.openAPI, &doc); err != nil {
+		t.Fatalf("decoding OpenAPI document: %+v", err)
+	}
+
+	for _, route := range gatewayRoutes {
+		if _, ok := doc.Paths[route.path][strings.ToLower(route.method)]; !ok {
+			t.Errorf("document has no operation for %s %s", route.method, route.path)
+		}
+	}
+
+	schemas := doc.Components.Schemas
+	if _, ok := schemas["idl.ExecuteRequest"].Properties["BatchSize"]; !ok {
+		t.Errorf("ExecuteRequest schema has no BatchSize: %v", schemas["idl.ExecuteRequest"])
+	}
+
+	if schemas["idl.Status"].Enum[0] != "UNKNOWN_STATUS" {
+		t.Errorf("Status schema is %v", schemas["idl.Status"])
+	}
+
+	failed := schemas["idl.CheckDiskSpaceReply"].Properties["failed"]
+	if failed["type"] != "object" || failed["additionalProperties"] == nil {
+		t.Errorf("map field is described as %v", failed)
+	}
+	if _, ok := schemas["idl.CheckDiskSpaceReply.DiskUsage"]; !ok {
+		t.Errorf("document has no schema for nested message DiskUsage")
+	}
+}
@@ -0,0 +1,152 @@
+package hub
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+)
+
+// HealthService is the name under which the hub reports its readiness to
+// grpc.health.v1 checkers. The empty service name reports only that the hub
+// is serving.
+const HealthService = "idl.CliToHub"
+
+// HealthWatchInterval is how often the hub's readiness is checked for clients
+// that watch it.
+var HealthWatchInterval = 5 * time.Second
+
+// AgentProbeTimeout bounds the health check of each agent when the hub's
+// readiness is checked.
+var AgentProbeTimeout = time.Second
+
+// ready returns nil if the hub is ready to run steps: its configuration
+// records the source cluster, and every agent reports that it is serving.
+func (s *Server) ready() error {
+	s.mu.Lock()
+	if s.Config == nil || s.Source == nil {
+		s.mu.Unlock()
+		return xerrors.New("configuration does not yet record the source cluster")
+	}
+	hosts := s.Source.PrimaryHostnames()
+	port := s.AgentPort
+	dialer := s.grpcDialer
+	s.mu.Unlock()
+
+	// Each agent is checked over a connection of its own, rather than those
+	// of AgentConns, so that a slow agent holds up neither the other checks
+	// nor the hub's requests.
+	errs := make(chan error, len(hosts))
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+
+			if err := probeAgent(dialer, address); err != nil {
+				errs <- xerrors.Errorf("agent at %s is not ready: %w", address, err)
+			}
+		}(net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+	wg.Wait()
+	close(errs)
+
+	var err error
+	for e := range errs {
+		err = multierror.Append(err, e)
+	}
+
+	return err
+}
+
+// probeAgent asks the grpc.health.v1 service of the agent at address whether
+// the agent is serving.
+func probeAgent(dialer Dialer, address string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), AgentProbeTimeout)
+	defer cancel()
+
+	conn, err := dialer(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: agent.HealthService})
+	if err != nil {
+		return err
+	}
+
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		return xerrors.Errorf("agent reports %v", reply.Status)
+	}
+
+	return nil
+}
+
+// hubHealth is the grpc.health.v1 service of the hub, which checks the hub's
+// readiness each time it is asked.
+type hubHealth struct {
+	server *Server
+}
+
+func (h *hubHealth) status(service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	switch service {
+	case "":
+		return healthpb.HealthCheckResponse_SERVING, nil
+
+	case HealthService:
+		if err := h.server.ready(); err != nil {
+			gplog.Debug("hub is not ready: %v", err)
+			return healthpb.HealthCheckResponse_NOT_SERVING, nil
+		}
+		return healthpb.HealthCheckResponse_SERVING, nil
+
+	default:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %q", service)
+	}
+}
+
+func (h *hubHealth) Check(ctx context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, err := h.status(request.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch sends the status of the service, and then sends it again whenever it
+// changes, until the client goes away. As the protocol requires, an unknown
+// service is reported as SERVICE_UNKNOWN rather than failing the request.
+func (h *hubHealth) Watch(request *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(HealthWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		servingStatus, _ := h.status(request.Service)
+		if servingStatus != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			last = servingStatus
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
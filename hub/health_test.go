@@ -0,0 +1,147 @@
+package hub
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/agent"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// healthWatchStream passes on the statuses sent by Watch.
+type healthWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (h *healthWatchStream) Send(reply *healthpb.HealthCheckResponse) error {
+	h.sent <- reply.Status
+	return nil
+}
+
+func (h *healthWatchStream) Context() context.Context {
+	return h.ctx
+}
+
+func TestHealth(t *testing.T) {
+	h := &hubHealth{&Server{Config: &Config{}}}
+
+	check := func(t *testing.T, service string, expected healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+
+		reply, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) returned error %+v", service, err)
+		}
+		if reply.Status != expected {
+			t.Errorf("Check(%q) returned %v, want %v", service, reply.Status, expected)
+		}
+	}
+
+	t.Run("reports that the hub is serving", func(t *testing.T) {
+		check(t, "", healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("reports that the hub is not ready without a source cluster", func(t *testing.T) {
+		check(t, HealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+	})
+
+	t.Run("reports whether every agent is serving", func(t *testing.T) {
+		agentHealth := health.NewServer()
+		port, stop := serveAgentHealth(t, agentHealth)
+		defer stop()
+
+		source, err := utils.NewCluster([]utils.SegConfig{
+			{ContentID: -1, DbID: 1, Port: 15432, Hostname: "localhost", DataDir: "/data/master/gpseg-1", Role: "p", PreferredRole: "p"},
+			{ContentID: 0, DbID: 2, Port: 25432, Hostname: "localhost", DataDir: "/data/primary/gpseg0", Role: "p", PreferredRole: "p"},
+		})
+		if err != nil {
+			t.Fatalf("NewCluster() returned error %+v", err)
+		}
+
+		h := &hubHealth{New(&Config{Source: source, AgentPort: port}, grpc.DialContext, "")}
+		check := func(t *testing.T, expected healthpb.HealthCheckResponse_ServingStatus) {
+			t.Helper()
+
+			reply, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: HealthService})
+			if err != nil {
+				t.Fatalf("Check() returned error %+v", err)
+			}
+			if reply.Status != expected {
+				t.Errorf("Check() returned %v, want %v", reply.Status, expected)
+			}
+		}
+
+		agentHealth.SetServingStatus(agent.HealthService, healthpb.HealthCheckResponse_SERVING)
+		check(t, healthpb.HealthCheckResponse_SERVING)
+
+		agentHealth.SetServingStatus(agent.HealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+		check(t, healthpb.HealthCheckResponse_NOT_SERVING)
+
+		// The check does not leave the hub with connections to the agents.
+		if h.server.agentConns != nil {
+			t.Errorf("checking readiness connected the hub to its agents")
+		}
+	})
+
+	t.Run("returns NotFound for unknown services", func(t *testing.T) {
+		_, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "idl.Agent"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("returned error %#v, want code %v", err, codes.NotFound)
+		}
+	})
+
+	t.Run("sends the status to watchers until they go away", func(t *testing.T) {
+		interval := HealthWatchInterval
+		HealthWatchInterval = time.Millisecond
+		defer func() { HealthWatchInterval = interval }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := &healthWatchStream{ctx: ctx, sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 10)}
+
+		result := make(chan error)
+		go func() {
+			result <- h.Watch(&healthpb.HealthCheckRequest{Service: HealthService}, stream)
+		}()
+
+		if s := <-stream.sent; s != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("sent %v, want %v", s, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+
+		cancel()
+		if err := <-result; status.Code(err) != codes.Canceled {
+			t.Errorf("returned error %#v, want code %v", err, codes.Canceled)
+		}
+
+		// The status did not change, so it was sent only once.
+		if len(stream.sent) != 0 {
+			t.Errorf("sent %d more statuses, want none", len(stream.sent))
+		}
+	})
+}
+
+// serveAgentHealth serves health on a local port, which it returns along with
+// a function that stops serving.
+func serveAgentHealth(t *testing.T, health healthpb.HealthServer) (int, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Listen() returned error %+v", err)
+	}
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health)
+	go server.Serve(lis)
+
+	return lis.Addr().(*net.TCPAddr).Port, server.Stop
+}
@@ -0,0 +1,63 @@
+package hub
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// HooksDirName is the directory in the state directory that holds the hook
+// executables run around substeps; see step.Hooks.
+const HooksDirName = "hooks"
+
+// beginStep is BeginStep with the operator's hooks and webhooks attached.
+func (s *Server) beginStep(name string, sender idl.MessageSender) (*step.Step, error) {
+	st, err := BeginStep(s.StateDir, name, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	st.SetHooks(&step.Hooks{
+		Dir: filepath.Join(s.StateDir, HooksDirName),
+		Env: s.hookEnv,
+	})
+
+	if n := s.webhookNotifier(); n != nil {
+		st.SetNotifier(n)
+	}
+
+	return st, nil
+}
+
+// hookEnv describes the clusters to hooks. It is evaluated each time a hook
+// runs, since the clusters are not known until initialize has loaded them.
+func (s *Server) hookEnv() []string {
+	env := []string{"GPUPGRADE_STATE_DIR=" + s.StateDir}
+	env = append(env, clusterEnv("SOURCE", s.Source)...)
+	env = append(env, clusterEnv("TARGET", s.Target)...)
+	return env
+}
+
+// clusterEnv returns GPUPGRADE_<prefix>_* variables for a cluster, or nothing
+// if the cluster is not known yet.
+func clusterEnv(prefix string, c *utils.Cluster) []string {
+	if c == nil {
+		return nil
+	}
+
+	env := []string{fmt.Sprintf("GPUPGRADE_%s_BIN_DIR=%s", prefix, c.BinDir)}
+
+	// A cluster without a master has nothing more to describe.
+	if _, ok := c.Primaries[-1]; !ok {
+		return env
+	}
+
+	return append(env,
+		fmt.Sprintf("GPUPGRADE_%s_MASTER_HOST=%s", prefix, c.MasterHostname()),
+		fmt.Sprintf("GPUPGRADE_%s_MASTER_PORT=%d", prefix, c.MasterPort()),
+		fmt.Sprintf("GPUPGRADE_%s_MASTER_DATA_DIR=%s", prefix, c.MasterDataDir()),
+	)
+}
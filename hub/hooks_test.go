@@ -0,0 +1,31 @@
+package hub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+func TestHookEnv(t *testing.T) {
+	source := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Port: 15432, Hostname: "mdw", DataDir: "/data/qddir/seg-1", Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Port: 25432, Hostname: "sdw1", DataDir: "/data/dbfast1/seg1", Role: "p", PreferredRole: "p"},
+	})
+	source.BinDir = "/source/bindir"
+
+	s := &Server{Config: &Config{Source: source}, StateDir: "/home/gpadmin/.gpupgrade"}
+
+	expected := []string{
+		"GPUPGRADE_STATE_DIR=/home/gpadmin/.gpupgrade",
+		"GPUPGRADE_SOURCE_BIN_DIR=/source/bindir",
+		"GPUPGRADE_SOURCE_MASTER_HOST=mdw",
+		"GPUPGRADE_SOURCE_MASTER_PORT=15432",
+		"GPUPGRADE_SOURCE_MASTER_DATA_DIR=/data/qddir/seg-1",
+	}
+
+	env := s.hookEnv()
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("got environment %q, want %q", env, expected)
+	}
+}
@@ -62,7 +62,7 @@ func TestRestartAgent(t *testing.T) {
 			return listener.Dial()
 		}
 
-		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, port, stateDir)
+		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, nil, port, 0, stateDir)
 		if err != nil {
 			t.Errorf("returned %#v", err)
 		}
@@ -82,7 +82,7 @@ func TestRestartAgent(t *testing.T) {
 			return listener.Dial()
 		}
 
-		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, port, stateDir)
+		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, nil, port, 0, stateDir)
 		if err != nil {
 			t.Errorf("returned %#v", err)
 		}
@@ -105,7 +105,7 @@ func TestRestartAgent(t *testing.T) {
 			return nil, immediateFailure{}
 		}
 
-		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, port, stateDir)
+		restartedHosts, err := hub.RestartAgents(ctx, dialer, hostnames, nil, port, 0, stateDir)
 		if err == nil {
 			t.Errorf("expected restart agents to fail")
 		}
@@ -3,6 +3,7 @@ package hub
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -29,29 +30,65 @@ func (s *Server) initsystemConfPath() string {
 	return filepath.Join(s.StateDir, "gpinitsystem_config")
 }
 
+// InitsystemOverrideFileName is the name of the file in the state directory
+// whose settings are merged on top of the generated gpinitsystem_config.
+const InitsystemOverrideFileName = "gpinitsystem_config.override"
+
+func (s *Server) initsystemOverridePath() string {
+	return filepath.Join(s.StateDir, InitsystemOverrideFileName)
+}
+
 func (s *Server) writeConf(sourceDBConn *dbconn.DBConn) error {
+	gpinitsystemConfig, err := s.initsystemConfig(sourceDBConn)
+	if err != nil {
+		return err
+	}
+
+	return WriteInitsystemFile(gpinitsystemConfig, s.initsystemConfPath())
+}
+
+// initsystemConfig generates the lines of the gpinitsystem_config for the
+// target cluster from the source cluster, with any user overrides applied.
+func (s *Server) initsystemConfig(sourceDBConn *dbconn.DBConn) ([]string, error) {
 	err := sourceDBConn.Connect(1)
 	if err != nil {
-		return errors.Wrap(err, "could not connect to database")
+		return nil, errors.Wrap(err, "could not connect to database")
 	}
 	defer sourceDBConn.Close()
 
 	gpinitsystemConfig, err := CreateInitialInitsystemConfig(s.Source.MasterDataDir())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	gpinitsystemConfig, err = GetCheckpointSegmentsAndEncoding(gpinitsystemConfig, sourceDBConn)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	gpinitsystemConfig, err = GetSourceSettings(gpinitsystemConfig, sourceDBConn)
+	if err != nil {
+		return nil, err
 	}
 
 	gpinitsystemConfig, err = WriteSegmentArray(gpinitsystemConfig, s.Source, s.TargetPorts)
 	if err != nil {
-		return xerrors.Errorf("generating segment array: %w", err)
+		return nil, xerrors.Errorf("generating segment array: %w", err)
 	}
 
-	return WriteInitsystemFile(gpinitsystemConfig, s.initsystemConfPath())
+	overrides, err := ioutil.ReadFile(s.initsystemOverridePath())
+	if os.IsNotExist(err) {
+		return gpinitsystemConfig, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("reading gpinitsystem overrides: %w", err)
+	}
+
+	gpinitsystemConfig, err = MergeInitsystemOverrides(gpinitsystemConfig, string(overrides))
+	if err != nil {
+		return nil, xerrors.Errorf("%s: %w", s.initsystemOverridePath(), err)
+	}
+
+	return gpinitsystemConfig, nil
 }
 
 func (s *Server) CreateTargetCluster(stream step.OutStreams) error {
@@ -119,10 +156,126 @@ func CreateInitialInitsystemConfig(sourceMasterDataDir string) ([]string, error)
 	return gpinitsystemConfig, nil
 }
 
+// sourceSettings maps the source cluster settings that gpinitsystem cannot
+// infer to the gpinitsystem_config parameters that reproduce them. Locales and
+// checksums must match for pg_upgrade to accept the target cluster.
+var sourceSettings = []struct {
+	guc, param string
+}{
+	{"lc_collate", "LC_COLLATE"},
+	{"lc_ctype", "LC_CTYPE"},
+	{"lc_messages", "LC_MESSAGES"},
+	{"lc_monetary", "LC_MONETARY"},
+	{"lc_numeric", "LC_NUMERIC"},
+	{"lc_time", "LC_TIME"},
+	{"max_connections", "MASTER_MAX_CONNECT"},
+	{"data_checksums", "HEAP_CHECKSUM"},
+}
+
+// GetSourceSettings appends the gpinitsystem_config parameters for the
+// sourceSettings of the connected cluster. Settings that the source version
+// does not have are left to gpinitsystem's defaults.
+func GetSourceSettings(gpinitsystemConfig []string, dbConnector *dbconn.DBConn) ([]string, error) {
+	var names []string
+	for _, s := range sourceSettings {
+		names = append(names, fmt.Sprintf("'%s'", s.guc))
+	}
+
+	query := fmt.Sprintf("SELECT name, setting FROM pg_settings WHERE name IN (%s)", strings.Join(names, ", "))
+	var rows []struct {
+		Name    string
+		Setting string
+	}
+	if err := dbConnector.Select(&rows, query); err != nil {
+		return gpinitsystemConfig, errors.Wrap(err, "Could not retrieve source settings")
+	}
+
+	values := make(map[string]string)
+	for _, row := range rows {
+		values[row.Name] = row.Setting
+	}
+
+	for _, s := range sourceSettings {
+		value, ok := values[s.guc]
+		if !ok {
+			continue
+		}
+		gpinitsystemConfig = append(gpinitsystemConfig, fmt.Sprintf("%s=%s", s.param, shellQuote(value)))
+	}
+
+	return gpinitsystemConfig, nil
+}
+
+// managedInitsystemParams are generated from the source cluster's layout and
+// the target port assignments, and may not be overridden.
+var managedInitsystemParams = map[string]bool{
+	"QD_PRIMARY_ARRAY": true,
+	"PRIMARY_ARRAY":    true,
+	"MIRROR_ARRAY":     true,
+}
+
+// MergeInitsystemOverrides applies the NAME=value lines of overrides to the
+// generated gpinitsystem_config. A parameter that is already present is
+// replaced in place; any other is appended. Blank lines and comments in
+// overrides are ignored.
+func MergeInitsystemOverrides(gpinitsystemConfig []string, overrides string) ([]string, error) {
+	merged := append([]string(nil), gpinitsystemConfig...)
+
+	for i, line := range strings.Split(overrides, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, xerrors.Errorf("line %d: expected NAME=value, got %q", i+1, line)
+		}
+
+		name := strings.TrimSpace(line[:eq])
+		if managedInitsystemParams[name] || strings.HasPrefix(name, "declare") {
+			return nil, xerrors.Errorf("line %d: %s is generated by gpupgrade and cannot be overridden", i+1, name)
+		}
+
+		replaced := false
+		for j, existing := range merged {
+			if strings.HasPrefix(existing, name+"=") {
+				merged[j] = line
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, line)
+		}
+	}
+
+	return merged, nil
+}
+
+// shellQuote quotes value for the gpinitsystem_config, which is sourced by
+// bash, if it contains anything other than simple word characters.
+func shellQuote(value string) string {
+	safe := value != ""
+	for _, r := range value {
+		if !(r == '_' || r == '-' || r == '.' || r == '/' || r == '@' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			safe = false
+			break
+		}
+	}
+
+	if safe {
+		return value
+	}
+
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
 func WriteInitsystemFile(gpinitsystemConfig []string, gpinitsystemFilepath string) error {
 	gpinitsystemContents := []byte(strings.Join(gpinitsystemConfig, "\n"))
 
-	err := ioutil.WriteFile(gpinitsystemFilepath, gpinitsystemContents, 0644)
+	err := utils.AtomicallyWriteFile(gpinitsystemFilepath, gpinitsystemContents, 0644)
 	if err != nil {
 		return errors.Wrap(err, "Could not write gpinitsystem_config file")
 	}
@@ -198,6 +351,13 @@ func WriteSegmentArray(config []string, source *utils.Cluster, ports PortAssignm
 	}
 	config = append(config, ")")
 
+	if len(source.Mirrors) > 0 {
+		config = append(config,
+			"# The source cluster's mirrors are not created by gpinitsystem, since",
+			"# pg_upgrade requires a target cluster without mirrors.",
+		)
+	}
+
 	return config, nil
 }
 
@@ -81,6 +81,124 @@ func TestGetCheckpointSegmentsAndEncoding(t *testing.T) {
 	})
 }
 
+func TestGetSourceSettings(t *testing.T) {
+	t.Run("appends the settings that the source cluster has", func(t *testing.T) {
+		dbConn, sqlMock := testhelper.CreateAndConnectMockDB(1)
+
+		rows := sqlmock.NewRows([]string{"name", "setting"}).
+			AddRow("lc_collate", "en_US.utf8").
+			AddRow("lc_ctype", "en_US.utf8").
+			AddRow("lc_messages", "C").
+			AddRow("lc_monetary", "de_DE.UTF-8").
+			AddRow("lc_numeric", "C").
+			AddRow("lc_time", "C").
+			AddRow("max_connections", "250")
+		sqlMock.ExpectQuery("SELECT name, setting FROM pg_settings WHERE name IN .*").WillReturnRows(rows)
+
+		actualConfig, err := GetSourceSettings([]string{"ENCODING=UNICODE"}, dbConn)
+		if err != nil {
+			t.Fatalf("got %#v, want nil", err)
+		}
+
+		// data_checksums is missing, so HEAP_CHECKSUM is left to gpinitsystem.
+		expectedConfig := []string{
+			"ENCODING=UNICODE",
+			"LC_COLLATE=en_US.utf8",
+			"LC_CTYPE=en_US.utf8",
+			"LC_MESSAGES=C",
+			"LC_MONETARY=de_DE.UTF-8",
+			"LC_NUMERIC=C",
+			"LC_TIME=C",
+			"MASTER_MAX_CONNECT=250",
+		}
+		if !reflect.DeepEqual(actualConfig, expectedConfig) {
+			t.Errorf("got %v, want %v", actualConfig, expectedConfig)
+		}
+	})
+
+	t.Run("quotes values for the shell", func(t *testing.T) {
+		dbConn, sqlMock := testhelper.CreateAndConnectMockDB(1)
+
+		rows := sqlmock.NewRows([]string{"name", "setting"}).
+			AddRow("lc_collate", "English_United States.1252").
+			AddRow("data_checksums", "on")
+		sqlMock.ExpectQuery("SELECT .* FROM pg_settings .*").WillReturnRows(rows)
+
+		actualConfig, err := GetSourceSettings(nil, dbConn)
+		if err != nil {
+			t.Fatalf("got %#v, want nil", err)
+		}
+
+		expectedConfig := []string{"LC_COLLATE='English_United States.1252'", "HEAP_CHECKSUM=on"}
+		if !reflect.DeepEqual(actualConfig, expectedConfig) {
+			t.Errorf("got %v, want %v", actualConfig, expectedConfig)
+		}
+	})
+
+	t.Run("returns an error when the query fails", func(t *testing.T) {
+		dbConn, sqlMock := testhelper.CreateAndConnectMockDB(1)
+
+		expected := errors.New("connection reset")
+		sqlMock.ExpectQuery("SELECT .* FROM pg_settings .*").WillReturnError(expected)
+
+		_, err := GetSourceSettings(nil, dbConn)
+		if err == nil || !strings.Contains(err.Error(), expected.Error()) {
+			t.Errorf("got %#v, want %#v", err, expected)
+		}
+	})
+}
+
+func TestMergeInitsystemOverrides(t *testing.T) {
+	config := []string{
+		`ARRAY_NAME="gp_upgrade cluster"`,
+		"SEG_PREFIX=seg",
+		"MASTER_MAX_CONNECT=250",
+		"QD_PRIMARY_ARRAY=mdw~15433~/data/qddir_upgrade/seg-1~1~-1~0",
+	}
+
+	t.Run("replaces existing parameters and appends new ones", func(t *testing.T) {
+		overrides := "# site settings\n\nMASTER_MAX_CONNECT=500\n  ENABLE_ORCA=off  \n"
+
+		actual, err := MergeInitsystemOverrides(config, overrides)
+		if err != nil {
+			t.Fatalf("got %#v, want nil", err)
+		}
+
+		expected := []string{
+			`ARRAY_NAME="gp_upgrade cluster"`,
+			"SEG_PREFIX=seg",
+			"MASTER_MAX_CONNECT=500",
+			"QD_PRIMARY_ARRAY=mdw~15433~/data/qddir_upgrade/seg-1~1~-1~0",
+			"ENABLE_ORCA=off",
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got %v, want %v", actual, expected)
+		}
+
+		if config[2] != "MASTER_MAX_CONNECT=250" {
+			t.Errorf("generated config was modified: %v", config)
+		}
+	})
+
+	errorCases := []struct {
+		name      string
+		overrides string
+	}{
+		{"a line without an equals sign", "ENABLE_ORCA off"},
+		{"the master array", "QD_PRIMARY_ARRAY=mdw~5432~/data/seg-1~1~-1~0"},
+		{"the primary array", "declare -a PRIMARY_ARRAY=("},
+	}
+
+	for _, c := range errorCases {
+		t.Run("rejects "+c.name, func(t *testing.T) {
+			_, err := MergeInitsystemOverrides(config, c.overrides)
+			if err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
 func TestWriteSegmentArray(t *testing.T) {
 	test := func(t *testing.T, cluster *utils.Cluster, ports PortAssignments, expected []string) {
 		t.Helper()
@@ -141,6 +259,24 @@ func TestWriteSegmentArray(t *testing.T) {
 		})
 	})
 
+	t.Run("notes that the source cluster's mirrors are not created", func(t *testing.T) {
+		cluster := MustCreateCluster(t, []utils.SegConfig{
+			{ContentID: -1, DbID: 1, Hostname: "mdw", DataDir: "/data/qddir/seg-1", Role: "p", PreferredRole: "p"},
+			{ContentID: 0, DbID: 2, Hostname: "sdw1", DataDir: "/data/dbfast1/seg1", Role: "p", PreferredRole: "p"},
+			{ContentID: 0, DbID: 3, Hostname: "sdw2", DataDir: "/data/dbfast_mirror1/seg1", Role: "m", PreferredRole: "m"},
+		})
+		ports := PortAssignments{15433, 0, []int{15434}}
+
+		test(t, cluster, ports, []string{
+			"QD_PRIMARY_ARRAY=mdw~15433~/data/qddir_upgrade/seg-1~1~-1~0",
+			"declare -a PRIMARY_ARRAY=(",
+			"\tsdw1~15434~/data/dbfast1_upgrade/seg1~2~0~0",
+			")",
+			"# The source cluster's mirrors are not created by gpinitsystem, since",
+			"# pg_upgrade requires a target cluster without mirrors.",
+		})
+	})
+
 	t.Run("errors when old cluster contains no master segment", func(t *testing.T) {
 		cluster := MustCreateCluster(t, []utils.SegConfig{
 			{ContentID: 0, DbID: 2, Hostname: "sdw1", DataDir: "/data/dbfast1/seg1", Role: "p", PreferredRole: "p"},
@@ -14,11 +14,13 @@ import (
 	"github.com/greenplum-db/gpupgrade/db"
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/upgrade"
 	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
 )
 
 func (s *Server) Initialize(in *idl.InitializeRequest, stream idl.CliToHub_InitializeServer) (err error) {
-	st, err := BeginStep(s.StateDir, "initialize", stream)
+	st, err := s.beginStep("initialize", stream)
 	if err != nil {
 		return err
 	}
@@ -28,6 +30,8 @@ func (s *Server) Initialize(in *idl.InitializeRequest, stream idl.CliToHub_Initi
 			err = multierror.Append(err, ferr).ErrorOrNil()
 		}
 
+		s.reportStep("initialize")
+
 		if err != nil {
 			gplog.Error(fmt.Sprintf("initialize: %s", err))
 		}
@@ -38,15 +42,19 @@ func (s *Server) Initialize(in *idl.InitializeRequest, stream idl.CliToHub_Initi
 	})
 
 	st.Run(idl.Substep_START_AGENTS, func(_ step.OutStreams) error {
-		_, err := RestartAgents(context.Background(), nil, s.Source.GetHostnames(), s.AgentPort, s.StateDir)
+		_, err := RestartAgents(context.Background(), nil, s.Source.GetHostnames(), segmentDataDirs(s.Source, s.Target), s.AgentPort, s.AgentMetricsPort, s.StateDir)
 		return err
 	})
 
+	st.Run(idl.Substep_MEASURE_SOURCE_CLUSTER, func(stream step.OutStreams) error {
+		return s.MeasureSourceCluster(stream)
+	})
+
 	return st.Err()
 }
 
 func (s *Server) InitializeCreateCluster(in *idl.InitializeCreateClusterRequest, stream idl.CliToHub_InitializeCreateClusterServer) (err error) {
-	st, err := BeginStep(s.StateDir, "initialize", stream)
+	st, err := s.beginStep("initialize", stream)
 	if err != nil {
 		return err
 	}
@@ -56,9 +64,14 @@ func (s *Server) InitializeCreateCluster(in *idl.InitializeCreateClusterRequest,
 			err = multierror.Append(err, ferr).ErrorOrNil()
 		}
 
+		s.reportStep("initialize")
+
 		if err != nil {
 			gplog.Error(fmt.Sprintf("initialize: %s", err))
 		}
+
+		// Send any pg_upgrade failures to the CLI in a structured form.
+		err = upgrade.StatusError(err)
 	}()
 
 	st.Run(idl.Substep_CREATE_TARGET_CONFIG, func(_ step.OutStreams) error {
@@ -76,7 +89,7 @@ func (s *Server) InitializeCreateCluster(in *idl.InitializeCreateClusterRequest,
 	st.Run(idl.Substep_BACKUP_TARGET_MASTER, func(stream step.OutStreams) error {
 		sourceDir := s.Target.MasterDataDir()
 		targetDir := filepath.Join(s.StateDir, originalMasterBackupName)
-		return RsyncMasterDataDir(stream, sourceDir, targetDir)
+		return CopyDataDir(s.CopyEngine, stream, sourceDir, targetDir)
 	})
 
 	st.AlwaysRun(idl.Substep_CHECK_UPGRADE, func(stream step.OutStreams) error {
@@ -100,6 +113,11 @@ func (s *Server) fillClusterConfigsSubStep(_ step.OutStreams, request *idl.Initi
 	s.Target = &utils.Cluster{BinDir: request.TargetBinDir}
 	s.UseLinkMode = request.UseLinkMode
 
+	if err := dircopy.ValidateEngine(request.CopyEngine); err != nil {
+		return err
+	}
+	s.CopyEngine = request.CopyEngine
+
 	var ports []int
 	for _, p := range request.Ports {
 		ports = append(ports, int(p))
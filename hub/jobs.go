@@ -0,0 +1,180 @@
+package hub
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// DetachKey is the metadata key with which a client asks for a step to run in
+// the background as a job, rather than for the life of its request. The
+// request returns once the hub has started the step.
+const DetachKey = "gpupgrade-detach"
+
+// JobHeader is the metadata key in which the hub sends the ID of the job that
+// runs a step.
+const JobHeader = "gpupgrade-job"
+
+// MaxJobs bounds the jobs that are kept for Jobs and Watch. Jobs are kept in
+// memory, and are numbered from one each time the hub starts.
+const MaxJobs = 100
+
+// detachRequested reports whether a step's client asked for it to be detached.
+func detachRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	values := md.Get(DetachKey)
+	return len(values) > 0 && values[0] == "true"
+}
+
+// runDetached starts run, which handles a step request, in the background
+// with a stream that only records the step's messages. It returns once run has
+// received its request, having sent the client the job's ID.
+func runDetached(stream *recordingStream, run func(grpc.ServerStream) error) error {
+	stream.detached = true
+	stream.recording.setDetached()
+
+	d := &detachedStream{recordingStream: stream, received: make(chan error, 1)}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		stream.recording.finish(run(d))
+	}()
+
+	select {
+	case err := <-d.received:
+		if err != nil {
+			return err
+		}
+	case <-done:
+		// The handler returned without reading its request.
+		return stream.recording.result()
+	}
+
+	return stream.ServerStream.SendHeader(jobHeader(stream.recording))
+}
+
+func jobHeader(r *stepRecording) metadata.MD {
+	return metadata.Pairs(JobHeader, strconv.Itoa(int(r.id)))
+}
+
+// detachedStream is the stream of a step that runs as a job after its request
+// has returned. Only the request is read from the client; everything the step
+// sends is recorded.
+type detachedStream struct {
+	*recordingStream
+
+	once     sync.Once
+	received chan error
+}
+
+func (d *detachedStream) RecvMsg(m interface{}) error {
+	err := d.recordingStream.RecvMsg(m)
+	d.once.Do(func() { d.received <- err })
+	return err
+}
+
+func (d *detachedStream) Context() context.Context {
+	return detachedContext{d.recordingStream.Context()}
+}
+
+func (d *detachedStream) SetHeader(metadata.MD) error  { return nil }
+func (d *detachedStream) SendHeader(metadata.MD) error { return nil }
+func (d *detachedStream) SetTrailer(metadata.MD)       {}
+
+// detachedContext keeps the values of a request's context, such as its
+// metadata, but is not cancelled when the request returns.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// job returns the current state of the step's job.
+func (r *stepRecording) job() *idl.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := &idl.Job{
+		ID:            r.id,
+		Step:          r.name,
+		Status:        idl.Status_RUNNING,
+		Substep:       r.substep,
+		SubstepStatus: r.substepStatus,
+		StartTime:     r.started.Unix(),
+		Detached:      r.detachedJob,
+	}
+
+	if !r.done {
+		return job
+	}
+
+	job.EndTime = r.finished.Unix()
+	switch {
+	case r.err != nil:
+		job.Status = idl.Status_FAILED
+		job.Error = r.err.Error()
+	case r.substepStatus == idl.Status_PAUSED:
+		job.Status = idl.Status_PAUSED
+	default:
+		job.Status = idl.Status_COMPLETE
+	}
+
+	return job
+}
+
+func (r *stepRecording) setDetached() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.detachedJob = true
+}
+
+// findJob returns the job with the given ID, or the most recent job for an ID
+// of zero. It returns nil if there is no such job.
+func (s *Server) findJob(id int32) *stepRecording {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.jobs) == 0 {
+		return nil
+	}
+
+	if id == 0 {
+		return s.jobs[len(s.jobs)-1]
+	}
+
+	for _, job := range s.jobs {
+		if job.id == id {
+			return job
+		}
+	}
+
+	return nil
+}
+
+// Jobs lists the running and past jobs, oldest first.
+func (s *Server) Jobs(ctx context.Context, request *idl.JobsRequest) (*idl.JobsReply, error) {
+	s.mu.Lock()
+	jobs := append([]*stepRecording(nil), s.jobs...)
+	s.mu.Unlock()
+
+	reply := &idl.JobsReply{}
+	for _, job := range jobs {
+		reply.Jobs = append(reply.Jobs, job.job())
+	}
+
+	return reply, nil
+}
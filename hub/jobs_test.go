@@ -0,0 +1,301 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// requestStream is the server side of a step request, which cancels its
+// context when the request returns, as gRPC does. RecvMsg receives request, if
+// it is set.
+type requestStream struct {
+	clientStream
+	ctx     context.Context
+	header  metadata.MD
+	request proto.Message
+}
+
+func (r *requestStream) RecvMsg(m interface{}) error {
+	if r.request != nil {
+		proto.Merge(m.(proto.Message), r.request)
+	}
+	return nil
+}
+
+func (r *requestStream) SendHeader(md metadata.MD) error { r.header = md; return nil }
+func (r *requestStream) Context() context.Context        { return r.ctx }
+
+func TestRunDetached(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &Server{}
+	client := &requestStream{ctx: ctx}
+	stream := recordStep(t, s, cliToHubPrefix+"Execute", client)
+
+	running := statusMessage(idl.Substep_UPGRADE_MASTER, idl.Status_RUNNING)
+	release := make(chan struct{})
+	stepCtx := make(chan context.Context, 1)
+	expected := errors.New("pg_upgrade failed")
+
+	run := func(stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&idl.ExecuteRequest{}); err != nil {
+			return err
+		}
+
+		stream.SendMsg(running)
+		stepCtx <- stream.Context()
+
+		<-release
+		return expected
+	}
+
+	if err := runDetached(stream, run); err != nil {
+		t.Fatalf("runDetached() returned error %+v", err)
+	}
+
+	if ids := client.header.Get(JobHeader); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("sent job header %v, want job 1", client.header)
+	}
+
+	// The request has returned, but the step carries on.
+	cancel()
+	if err := (<-stepCtx).Err(); err != nil {
+		t.Errorf("step's context returned %v after its request returned", err)
+	}
+
+	job := stream.recording.job()
+	if job.Status != idl.Status_RUNNING || !job.Detached {
+		t.Errorf("got job %v, want a running detached job", job)
+	}
+	if job.Substep != idl.Substep_UPGRADE_MASTER || job.SubstepStatus != idl.Status_RUNNING {
+		t.Errorf("got substep %v %v, want UPGRADE_MASTER RUNNING", job.Substep, job.SubstepStatus)
+	}
+
+	close(release)
+	if err := s.findJob(1).waitForResult(); err != expected {
+		t.Errorf("job finished with %#v, want %#v", err, expected)
+	}
+
+	job = stream.recording.job()
+	if job.Status != idl.Status_FAILED || job.Error != expected.Error() || job.EndTime == 0 {
+		t.Errorf("got job %v, want a finished failed job", job)
+	}
+
+	if len(client.sent) != 0 {
+		t.Errorf("detached client was sent %v", client.sent)
+	}
+}
+
+func TestStopServicesRefusesWhileAStepRuns(t *testing.T) {
+	s := &Server{}
+	stream := recordStep(t, s, cliToHubPrefix+"Execute", &requestStream{ctx: context.Background()})
+
+	_, err := s.StopServices(context.Background(), &idl.StopServicesRequest{})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("StopServices() returned error %#v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err != nil && !strings.Contains(err.Error(), "job 1 ") {
+		t.Errorf("StopServices() returned error %q, want it to name job 1", err)
+	}
+
+	stream.recording.finish(nil)
+}
+
+func TestRunDetachedRefusesAnotherStep(t *testing.T) {
+	s := &Server{}
+	stream := recordStep(t, s, cliToHubPrefix+"Execute", &requestStream{ctx: context.Background()})
+
+	release := make(chan struct{})
+	run := func(stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&idl.ExecuteRequest{}); err != nil {
+			return err
+		}
+
+		<-release
+		return nil
+	}
+
+	if err := runDetached(stream, run); err != nil {
+		t.Fatalf("runDetached() returned error %+v", err)
+	}
+
+	_, err := s.recordStep(cliToHubPrefix+"Execute", &requestStream{ctx: context.Background()})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second Execute returned error %#v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err != nil && !strings.Contains(err.Error(), "job 1 ") {
+		t.Errorf("second Execute returned error %q, want it to name job 1", err)
+	}
+
+	close(release)
+	if err := s.findJob(1).waitForResult(); err != nil {
+		t.Errorf("job finished with %#v", err)
+	}
+
+	if _, err := s.recordStep(cliToHubPrefix+"Execute", &requestStream{ctx: context.Background()}); err != nil {
+		t.Errorf("Execute after the job finished returned error %+v", err)
+	}
+}
+
+// recordStep calls s.recordStep, failing the test if the step is refused.
+func recordStep(t *testing.T, s *Server, method string, stream grpc.ServerStream) *recordingStream {
+	t.Helper()
+
+	recording, err := s.recordStep(method, stream)
+	if err != nil {
+		t.Fatalf("recordStep() returned error %+v", err)
+	}
+
+	return recording
+}
+
+// waitForResult waits for the step to finish and returns its result.
+func (r *stepRecording) waitForResult() error {
+	_, w := r.watch()
+	if w != nil {
+		for range w.messages {
+		}
+	}
+
+	return r.result()
+}
+
+func TestJobs(t *testing.T) {
+	t.Run("reports the status of each job", func(t *testing.T) {
+		s := &Server{}
+
+		complete := recordStep(t, s, cliToHubPrefix+"Initialize", &clientStream{})
+		complete.SendMsg(statusMessage(idl.Substep_START_AGENTS, idl.Status_COMPLETE))
+		complete.recording.finish(nil)
+
+		failed := recordStep(t, s, cliToHubPrefix+"InitializeCreateCluster", &clientStream{})
+		failed.SendMsg(statusMessage(idl.Substep_INIT_TARGET_CLUSTER, idl.Status_FAILED))
+		failed.recording.finish(errors.New("gpinitsystem failed"))
+
+		paused := recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+		paused.SendMsg(statusMessage(idl.Substep_UPGRADE_PRIMARIES, idl.Status_PAUSED))
+		paused.recording.finish(nil)
+
+		running := recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+		running.SendMsg(statusMessage(idl.Substep_UPGRADE_PRIMARIES, idl.Status_RUNNING))
+
+		reply, err := s.Jobs(context.Background(), &idl.JobsRequest{})
+		if err != nil {
+			t.Fatalf("Jobs() returned error %+v", err)
+		}
+
+		expected := []struct {
+			step   string
+			status idl.Status
+		}{
+			{"initialize", idl.Status_COMPLETE},
+			{"initialize", idl.Status_FAILED},
+			{"execute", idl.Status_PAUSED},
+			{"execute", idl.Status_RUNNING},
+		}
+
+		if len(reply.Jobs) != len(expected) {
+			t.Fatalf("got %d jobs, want %d", len(reply.Jobs), len(expected))
+		}
+
+		for i, job := range reply.Jobs {
+			if job.ID != int32(i+1) || job.Step != expected[i].step || job.Status != expected[i].status {
+				t.Errorf("got job %v, want job %d of %s with status %v", job, i+1, expected[i].step, expected[i].status)
+			}
+		}
+
+		if reply.Jobs[1].Error != "gpinitsystem failed" {
+			t.Errorf("got error %q for the failed job", reply.Jobs[1].Error)
+		}
+		if reply.Jobs[3].EndTime != 0 {
+			t.Errorf("running job has an end time")
+		}
+	})
+
+	t.Run("keeps at most MaxJobs", func(t *testing.T) {
+		s := &Server{}
+		for i := 0; i < MaxJobs+5; i++ {
+			stream := recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+			stream.recording.finish(nil)
+		}
+
+		reply, err := s.Jobs(context.Background(), &idl.JobsRequest{})
+		if err != nil {
+			t.Fatalf("Jobs() returned error %+v", err)
+		}
+
+		if len(reply.Jobs) != MaxJobs {
+			t.Errorf("kept %d jobs, want %d", len(reply.Jobs), MaxJobs)
+		}
+		if reply.Jobs[0].ID != 6 {
+			t.Errorf("oldest job kept is %d, want 6", reply.Jobs[0].ID)
+		}
+	})
+
+	t.Run("keeps the history of the most recent finished jobs within HistoryBytes", func(t *testing.T) {
+		s := &Server{}
+
+		output := string(bytes.Repeat([]byte("x"), 1<<20))
+		var jobs []*stepRecording
+		for i := 0; i < 2*HistoryBytes/ReplayBytes; i++ {
+			stream := recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+			for j := 0; j < ReplayBytes>>21; j++ {
+				stream.SendMsg(chunkMessage(output))
+			}
+			stream.recording.finish(nil)
+			jobs = append(jobs, stream.recording)
+		}
+		recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+
+		total := 0
+		for _, job := range jobs {
+			total += job.historySize()
+		}
+		if total > HistoryBytes {
+			t.Errorf("kept %d bytes of history, want at most %d", total, HistoryBytes)
+		}
+
+		if jobs[0].historySize() != 0 {
+			t.Errorf("kept the history of the oldest job")
+		}
+		if jobs[len(jobs)-1].historySize() == 0 {
+			t.Errorf("dropped the history of the most recent job")
+		}
+	})
+
+	t.Run("Watch returns NotFound for an unknown job", func(t *testing.T) {
+		s := &Server{}
+		recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+
+		err := s.Watch(&idl.WatchRequest{Job: 2}, nil)
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("returned error %#v, want code %v", err, codes.NotFound)
+		}
+	})
+}
+
+func TestDetachRequested(t *testing.T) {
+	cases := map[string]bool{"true": true, "false": false}
+	for value, expected := range cases {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(DetachKey, value))
+		if detachRequested(ctx) != expected {
+			t.Errorf("detachRequested() with %q returned %t", value, !expected)
+		}
+	}
+
+	if detachRequested(context.Background()) {
+		t.Errorf("detachRequested() without metadata returned true")
+	}
+}
@@ -0,0 +1,300 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/db"
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/disk"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+// MeasurementsFileName is the file in the state directory that records the
+// sizes of the source cluster, from which the duration of execute is
+// estimated.
+const MeasurementsFileName = "measurements.json"
+
+// MeasurementsSchemaVersion is the version of the measurements.json format
+// written by this version of gpupgrade.
+//
+// History:
+//   1: the first version.
+const MeasurementsSchemaVersion = 1
+
+var measurementsSchema = schema.NewRegistry(MeasurementsFileName, MeasurementsSchemaVersion)
+
+// SegmentMeasurements are the sizes of a segment of the source cluster.
+// DatabaseBytes is the size of its databases as reported by the server, which
+// is not known for the master; DirBytes and Files are the total size and number
+// of the files in its data directory.
+type SegmentMeasurements struct {
+	Host          string
+	Content       int
+	DatabaseBytes int64 `json:",omitempty"`
+	DirBytes      int64
+	Files         int64
+}
+
+// Measurements is the serialized form of measurements.json, written by
+// initialize.
+type Measurements struct {
+	SchemaVersion int
+
+	// Relations is the number of relations in all databases. Every segment
+	// has the same catalog, and so the same relations.
+	Relations int64
+
+	Master    SegmentMeasurements
+	Primaries []SegmentMeasurements
+
+	// DefaultParallelism is the number of segments that the agent on each
+	// host upgrades at once by default.
+	DefaultParallelism map[string]int
+
+	// CheckMasterSeconds and CheckPrimariesSeconds are how long pg_upgrade
+	// --check ran on the master and on the primaries during CHECK_UPGRADE;
+	// they are zero until it has succeeded.
+	CheckMasterSeconds    float64 `json:",omitempty"`
+	CheckPrimariesSeconds float64 `json:",omitempty"`
+}
+
+// LoadMeasurements returns the measurements saved in the state directory. The
+// error wraps os.ErrNotExist if initialize has not measured the source
+// cluster.
+func LoadMeasurements(stateDir string) (*Measurements, error) {
+	path := filepath.Join(stateDir, MeasurementsFileName)
+
+	doc, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("reading measurements of the source cluster: %w", err)
+	}
+
+	doc, err = measurementsSchema.Migrate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Measurements{}
+	if err := json.Unmarshal(doc, m); err != nil {
+		return nil, xerrors.Errorf("reading %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Save atomically writes the measurements to the state directory.
+func (m *Measurements) Save(stateDir string) error {
+	m.SchemaVersion = MeasurementsSchemaVersion
+
+	path := filepath.Join(stateDir, MeasurementsFileName)
+	return utils.AtomicallyWrite(path, 0600, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	})
+}
+
+// MeasureSourceCluster records the sizes of the source cluster for the
+// estimate of execute's duration. The estimate is only advisory, so failing to
+// measure the cluster is reported as a warning rather than failing the
+// substep.
+func (s *Server) MeasureSourceCluster(streams step.OutStreams) error {
+	agents, err := s.AgentConns()
+	if err == nil {
+		var m *Measurements
+		m, err = measureSourceCluster(context.Background(), s.Source, agents)
+		if err == nil {
+			err = m.Save(s.StateDir)
+		}
+	}
+
+	if err != nil {
+		_, err = fmt.Fprintf(streams.Stderr(), "warning: the duration of execute cannot be estimated: %v\n", err)
+	}
+
+	return err
+}
+
+func measureSourceCluster(ctx context.Context, source *utils.Cluster, agents []*Connection) (*Measurements, error) {
+	m := &Measurements{}
+
+	conn := db.NewDBConn("localhost", source.MasterPort(), "template1")
+	if err := conn.Connect(1); err != nil {
+		return nil, xerrors.Errorf("connecting to the source cluster: %w", err)
+	}
+
+	databases, err := queryDatabases(conn)
+	var sizes map[int]int64
+	if err == nil {
+		sizes, err = querySegmentSizes(conn, databases)
+	}
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, database := range databases {
+		conn := db.NewDBConn("localhost", source.MasterPort(), database)
+		if err := conn.Connect(1); err != nil {
+			return nil, xerrors.Errorf("connecting to database %s: %w", database, err)
+		}
+
+		relations, err := countRelations(conn)
+		conn.Close()
+		if err != nil {
+			return nil, xerrors.Errorf("database %s: %w", database, err)
+		}
+
+		m.Relations += relations
+	}
+
+	master := SegmentMeasurements{Host: source.MasterHostname(), Content: -1}
+	master.DirBytes, master.Files, err = disk.Scan(source.MasterDataDir())
+	if err != nil {
+		return nil, err
+	}
+	m.Master = master
+
+	m.Primaries, m.DefaultParallelism, err = scanPrimaries(ctx, source, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range m.Primaries {
+		m.Primaries[i].DatabaseBytes = sizes[m.Primaries[i].Content]
+	}
+
+	return m, nil
+}
+
+// queryDatabases returns the names of the databases that can be connected to;
+// the others cannot hold any relations.
+func queryDatabases(conn *dbconn.DBConn) ([]string, error) {
+	var databases []string
+	if err := conn.Select(&databases, "SELECT datname FROM pg_database WHERE datallowconn ORDER BY datname"); err != nil {
+		return nil, xerrors.Errorf("querying databases: %w", err)
+	}
+
+	return databases, nil
+}
+
+// querySegmentSizes returns the total size of the given databases on each
+// primary segment, by content ID.
+func querySegmentSizes(conn *dbconn.DBConn, databases []string) (map[int]int64, error) {
+	sizes := make(map[int]int64)
+
+	for _, database := range databases {
+		// On the master pg_database_size() is the total over all segments, so
+		// it is run on each segment instead.
+		query := fmt.Sprintf("SELECT gp_segment_id AS content, pg_database_size(%s) AS bytes FROM gp_dist_random('gp_id')",
+			quoteLiteral(database))
+
+		var rows []struct {
+			Content int
+			Bytes   int64
+		}
+		if err := conn.Select(&rows, query); err != nil {
+			return nil, xerrors.Errorf("querying size of database %s: %w", database, err)
+		}
+
+		for _, row := range rows {
+			sizes[row.Content] += row.Bytes
+		}
+	}
+
+	return sizes, nil
+}
+
+// countRelations returns the number of relations in the connected database,
+// each of which pg_upgrade must dump and restore.
+func countRelations(conn *dbconn.DBConn) (int64, error) {
+	var count int64
+	if err := conn.Get(&count, "SELECT count(*) FROM pg_class"); err != nil {
+		return 0, xerrors.Errorf("counting relations: %w", err)
+	}
+
+	return count, nil
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// scanPrimaries has each agent scan the data directories of the primaries on
+// its host, and returns their measurements in content order along with the
+// default parallelism of each agent.
+func scanPrimaries(ctx context.Context, source *utils.Cluster, agents []*Connection) ([]SegmentMeasurements, map[string]int, error) {
+	var mu sync.Mutex
+	var primaries []SegmentMeasurements
+	parallelism := make(map[string]int)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(agents))
+
+	for _, agent := range agents {
+		wg.Add(1)
+		go func(agent *Connection) {
+			defer wg.Done()
+
+			segments, err := source.SegmentsOn(agent.Hostname)
+			if err != nil {
+				errs <- xerrors.Errorf("finding segments on host %s: %w", agent.Hostname, err)
+				return
+			}
+
+			request := &idl.ScanDataDirsRequest{}
+			contents := make(map[string]int)
+			for _, seg := range segments {
+				request.DataDirs = append(request.DataDirs, seg.DataDir)
+				contents[seg.DataDir] = seg.ContentID
+			}
+
+			reply, err := agent.AgentClient.ScanDataDirs(ctx, request)
+			if err != nil {
+				errs <- xerrors.Errorf("scanning data directories on host %s: %w", agent.Hostname, err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			parallelism[agent.Hostname] = int(reply.DefaultParallelism)
+			for _, scan := range reply.Scans {
+				primaries = append(primaries, SegmentMeasurements{
+					Host:     agent.Hostname,
+					Content:  contents[scan.DataDir],
+					DirBytes: scan.Bytes,
+					Files:    scan.Files,
+				})
+			}
+		}(agent)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var multiErr *multierror.Error
+	for err := range errs {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	if err := multiErr.ErrorOrNil(); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(primaries, func(i, j int) bool { return primaries[i].Content < primaries[j].Content })
+	return primaries, parallelism, nil
+}
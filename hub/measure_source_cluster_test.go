@@ -0,0 +1,99 @@
+package hub
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"golang.org/x/xerrors"
+)
+
+func TestQuerySourceCluster(t *testing.T) {
+	t.Run("queries the databases, their sizes and relations", func(t *testing.T) {
+		conn, mock := testhelper.CreateAndConnectMockDB(1)
+
+		mock.ExpectQuery("SELECT datname FROM pg_database WHERE datallowconn").
+			WillReturnRows(sqlmock.NewRows([]string{"datname"}).AddRow("postgres").AddRow("it's"))
+		mock.ExpectQuery(`pg_database_size\('postgres'\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"content", "bytes"}).AddRow(0, 100).AddRow(1, 200))
+		mock.ExpectQuery(`pg_database_size\('it''s'\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"content", "bytes"}).AddRow(0, 10).AddRow(1, 20))
+		mock.ExpectQuery(`SELECT count\(\*\) FROM pg_class`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+		databases, err := queryDatabases(conn)
+		if err != nil {
+			t.Fatalf("queryDatabases() returned error %+v", err)
+		}
+		if !reflect.DeepEqual(databases, []string{"postgres", "it's"}) {
+			t.Errorf("got databases %q", databases)
+		}
+
+		sizes, err := querySegmentSizes(conn, databases)
+		if err != nil {
+			t.Fatalf("querySegmentSizes() returned error %+v", err)
+		}
+		expected := map[int]int64{0: 110, 1: 220}
+		if !reflect.DeepEqual(sizes, expected) {
+			t.Errorf("got sizes %v, want %v", sizes, expected)
+		}
+
+		relations, err := countRelations(conn)
+		if err != nil {
+			t.Fatalf("countRelations() returned error %+v", err)
+		}
+		if relations != 42 {
+			t.Errorf("got %d relations, want 42", relations)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		conn, mock := testhelper.CreateAndConnectMockDB(1)
+
+		expected := xerrors.New("connection reset")
+		mock.ExpectQuery("SELECT datname").WillReturnError(expected)
+
+		_, err := queryDatabases(conn)
+		if !xerrors.Is(err, expected) {
+			t.Errorf("got error %#v, want %#v", err, expected)
+		}
+	})
+}
+
+func TestMeasurementsFile(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	_, err = LoadMeasurements(stateDir)
+	if !xerrors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %#v, want ErrNotExist", err)
+	}
+
+	m := &Measurements{
+		Relations:          42,
+		Master:             SegmentMeasurements{Host: "mdw", Content: -1, DirBytes: 1000, Files: 10},
+		Primaries:          []SegmentMeasurements{{Host: "sdw1", Content: 0, DatabaseBytes: 500, DirBytes: 900, Files: 9}},
+		DefaultParallelism: map[string]int{"sdw1": 4},
+	}
+	if err := m.Save(stateDir); err != nil {
+		t.Fatalf("Save() returned error %+v", err)
+	}
+
+	loaded, err := LoadMeasurements(stateDir)
+	if err != nil {
+		t.Fatalf("LoadMeasurements() returned error %+v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("loaded %+v, want %+v", loaded, m)
+	}
+}
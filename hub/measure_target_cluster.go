@@ -0,0 +1,130 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/disk"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+// TargetMeasurementsFileName is the file in the state directory, next to
+// MeasurementsFileName, that records the sizes of the target cluster's data
+// directories for the upgrade report.
+const TargetMeasurementsFileName = "target_measurements.json"
+
+// TargetMeasurementsSchemaVersion is the version of the
+// target_measurements.json format written by this version of gpupgrade.
+//
+// History:
+//   1: the first version.
+const TargetMeasurementsSchemaVersion = 1
+
+var targetMeasurementsSchema = schema.NewRegistry(TargetMeasurementsFileName, TargetMeasurementsSchemaVersion)
+
+// TargetMeasurements is the serialized form of target_measurements.json,
+// written by the first report after the primaries have been upgraded.
+type TargetMeasurements struct {
+	SchemaVersion int
+
+	Master    SegmentMeasurements
+	Primaries []SegmentMeasurements
+}
+
+// LoadTargetMeasurements returns the measurements of the target cluster saved
+// in the state directory. The error wraps os.ErrNotExist if the target has not
+// been measured.
+func LoadTargetMeasurements(stateDir string) (*TargetMeasurements, error) {
+	path := filepath.Join(stateDir, TargetMeasurementsFileName)
+
+	doc, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("reading measurements of the target cluster: %w", err)
+	}
+
+	doc, err = targetMeasurementsSchema.Migrate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &TargetMeasurements{}
+	if err := json.Unmarshal(doc, m); err != nil {
+		return nil, xerrors.Errorf("reading %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Save atomically writes the measurements to the state directory.
+func (m *TargetMeasurements) Save(stateDir string) error {
+	m.SchemaVersion = TargetMeasurementsSchemaVersion
+
+	path := filepath.Join(stateDir, TargetMeasurementsFileName)
+	return utils.AtomicallyWrite(path, 0600, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	})
+}
+
+// targetMeasurements returns the measurements of the target cluster for the
+// report. The target is measured the first time the report is written after
+// its primaries were upgraded at upgraded, and the result is kept in the state
+// directory. Measuring it then, rather than during execute, keeps the scan out
+// of the timed substeps and of the window in which the cluster is down.
+func (s *Server) targetMeasurements(ctx context.Context, upgraded *time.Time) (*TargetMeasurements, error) {
+	info, err := os.Stat(filepath.Join(s.StateDir, TargetMeasurementsFileName))
+	switch {
+	case err == nil && (upgraded == nil || !info.ModTime().Before(*upgraded)):
+		return LoadTargetMeasurements(s.StateDir)
+	case err != nil && !os.IsNotExist(err):
+		return nil, xerrors.Errorf("reading measurements of the target cluster: %w", err)
+	}
+
+	if s.Target == nil {
+		return nil, xerrors.New("the target cluster has not been configured")
+	}
+
+	agents, err := s.AgentConns()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := measureTargetCluster(ctx, s.Target, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.Save(s.StateDir); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func measureTargetCluster(ctx context.Context, target *utils.Cluster, agents []*Connection) (*TargetMeasurements, error) {
+	m := &TargetMeasurements{}
+
+	m.Master = SegmentMeasurements{Host: target.MasterHostname(), Content: -1}
+
+	var err error
+	m.Master.DirBytes, m.Master.Files, err = disk.Scan(target.MasterDataDir())
+	if err != nil {
+		return nil, err
+	}
+
+	m.Primaries, _, err = scanPrimaries(ctx, target, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
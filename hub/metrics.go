@@ -0,0 +1,36 @@
+package hub
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// agentConnCollector reports the state of the hub's connections to its agents.
+type agentConnCollector struct {
+	server *Server
+	desc   *prometheus.Desc
+}
+
+func newAgentConnCollector(s *Server) *agentConnCollector {
+	return &agentConnCollector{
+		server: s,
+		desc: prometheus.NewDesc(
+			"gpupgrade_agent_connection_state",
+			"Connectivity state of the hub's connection to an agent. The current state has a value of 1.",
+			[]string{"host", "state"}, nil,
+		),
+	}
+}
+
+func (c *agentConnCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *agentConnCollector) Collect(ch chan<- prometheus.Metric) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+
+	for _, conn := range c.server.agentConns {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1,
+			conn.Hostname, conn.Conn.GetState().String())
+	}
+}
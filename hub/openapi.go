@@ -0,0 +1,275 @@
+package hub
+
+import (
+	"encoding/json"
+	"strings"
+
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// openAPIDocument generates the OpenAPI 3 document of the gateway from the
+// CliToHub descriptor, so that it always matches the proto. Schemas follow the
+// proto3 JSON mapping used by the gateway: enums are named, 64-bit integers
+// and bytes are strings.
+func openAPIDocument(fd *descpb.FileDescriptorProto, methods map[string]*gatewayMethod) ([]byte, error) {
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type":        "object",
+			"description": "A failed request, or the end of a stream. Codes are the names of gRPC status codes.",
+			"properties": map[string]interface{}{
+				"code":    map[string]interface{}{"type": "string"},
+				"message": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+
+	prefix := fd.GetPackage()
+	for _, msg := range fd.MessageType {
+		addMessageSchemas(schemas, prefix, msg)
+	}
+	for _, enum := range fd.EnumType {
+		schemas[prefix+"."+enum.GetName()] = enumSchema(enum)
+	}
+
+	fields := make(map[string]*descpb.DescriptorProto)
+	collectMessages(fields, prefix, fd.MessageType)
+
+	paths := make(map[string]map[string]interface{})
+	for _, route := range gatewayRoutes {
+		m := methods[route.rpc]
+
+		if paths[route.path] == nil {
+			paths[route.path] = make(map[string]interface{})
+		}
+		paths[route.path][strings.ToLower(route.method)] = openAPIOperation(route, m, fields[m.input])
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "gpupgrade hub",
+			"version": "v1",
+			"description": "The CliToHub service of the gpupgrade hub as a REST/JSON API. " +
+				"Streaming requests are served as Server-Sent Events: a \"message\" event carrying each " +
+				"message as JSON, and then an \"end\" event carrying an Error, whose code is OK on success.",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func openAPIOperation(route gatewayRoute, m *gatewayMethod, input *descpb.DescriptorProto) map[string]interface{} {
+	var parameters []interface{}
+	bound := make(map[string]bool)
+
+	for _, segment := range strings.Split(route.path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		name := strings.Trim(segment, "{}")
+		bound[name] = true
+		parameters = append(parameters, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   fieldSchema(findField(input, name)),
+		})
+	}
+
+	if _, ok := stepMethods[cliToHubPrefix+m.name]; ok {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        detachParam,
+			"in":          "query",
+			"description": "Run the step in the background as a job, and return its ID rather than its messages.",
+			"schema":      map[string]interface{}{"type": "boolean"},
+		})
+	}
+
+	op := map[string]interface{}{
+		"operationId": m.name,
+		"summary":     route.summary,
+	}
+
+	if route.method == "GET" {
+		// The rest of the request's fields are set by query parameters.
+		for _, f := range input.GetField() {
+			if !bound[f.GetName()] {
+				parameters = append(parameters, map[string]interface{}{
+					"name":   f.GetName(),
+					"in":     "query",
+					"schema": fieldSchema(f),
+				})
+			}
+		}
+	} else if len(input.GetField()) > len(bound) {
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef(m.input)},
+			},
+		}
+	}
+
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{
+		"default": map[string]interface{}{
+			"description": "The request failed.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef("Error")},
+			},
+		},
+	}
+
+	if m.serverStreaming {
+		responses["200"] = map[string]interface{}{
+			"description": "A stream of Server-Sent Events, each \"message\" event carrying a " + m.output + ".",
+			"content": map[string]interface{}{
+				"text/event-stream": map[string]interface{}{"schema": schemaRef(m.output)},
+			},
+		}
+	} else {
+		responses["200"] = map[string]interface{}{
+			"description": "The reply.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef(m.output)},
+			},
+		}
+	}
+
+	if _, ok := stepMethods[cliToHubPrefix+m.name]; ok {
+		responses["202"] = map[string]interface{}{
+			"description": "The step was detached and is running as a job.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"job":    map[string]interface{}{"type": "integer"},
+						"events": map[string]interface{}{"type": "string"},
+					},
+				}},
+			},
+		}
+	}
+
+	op["responses"] = responses
+	return op
+}
+
+// addMessageSchemas adds the schemas of msg and of the messages and enums
+// nested within it. Map entries are not added; maps are objects.
+func addMessageSchemas(schemas map[string]interface{}, prefix string, msg *descpb.DescriptorProto) {
+	name := prefix + "." + msg.GetName()
+	if msg.GetOptions().GetMapEntry() {
+		return
+	}
+
+	nested := make(map[string]*descpb.DescriptorProto)
+	collectMessages(nested, name, msg.NestedType)
+
+	properties := make(map[string]interface{})
+	for _, f := range msg.Field {
+		schema := fieldSchema(f)
+		if entry := nested[strings.TrimPrefix(f.GetTypeName(), ".")]; entry != nil && entry.GetOptions().GetMapEntry() {
+			schema = map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": fieldSchema(findField(entry, "value")),
+			}
+		}
+		properties[jsonName(f)] = schema
+	}
+
+	schemas[name] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	for _, n := range msg.NestedType {
+		addMessageSchemas(schemas, name, n)
+	}
+	for _, enum := range msg.EnumType {
+		schemas[name+"."+enum.GetName()] = enumSchema(enum)
+	}
+}
+
+// collectMessages indexes messages, and those nested within them, by their
+// fully qualified names.
+func collectMessages(index map[string]*descpb.DescriptorProto, prefix string, messages []*descpb.DescriptorProto) {
+	for _, msg := range messages {
+		name := prefix + "." + msg.GetName()
+		index[name] = msg
+		collectMessages(index, name, msg.NestedType)
+	}
+}
+
+func findField(msg *descpb.DescriptorProto, name string) *descpb.FieldDescriptorProto {
+	for _, f := range msg.GetField() {
+		if f.GetName() == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func enumSchema(enum *descpb.EnumDescriptorProto) map[string]interface{} {
+	var values []string
+	for _, v := range enum.Value {
+		values = append(values, v.GetName())
+	}
+
+	return map[string]interface{}{"type": "string", "enum": values}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// jsonName returns the name of a field in the proto3 JSON mapping.
+func jsonName(f *descpb.FieldDescriptorProto) string {
+	if f.GetJsonName() != "" {
+		return f.GetJsonName()
+	}
+
+	parts := strings.Split(f.GetName(), "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.Title(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
+func fieldSchema(f *descpb.FieldDescriptorProto) map[string]interface{} {
+	var schema map[string]interface{}
+
+	switch f.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_MESSAGE, descpb.FieldDescriptorProto_TYPE_ENUM:
+		schema = schemaRef(strings.TrimPrefix(f.GetTypeName(), "."))
+	case descpb.FieldDescriptorProto_TYPE_STRING:
+		schema = map[string]interface{}{"type": "string"}
+	case descpb.FieldDescriptorProto_TYPE_BYTES:
+		schema = map[string]interface{}{"type": "string", "format": "byte"}
+	case descpb.FieldDescriptorProto_TYPE_BOOL:
+		schema = map[string]interface{}{"type": "boolean"}
+	case descpb.FieldDescriptorProto_TYPE_INT32, descpb.FieldDescriptorProto_TYPE_SINT32,
+		descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		schema = map[string]interface{}{"type": "integer", "format": "int32"}
+	case descpb.FieldDescriptorProto_TYPE_UINT32, descpb.FieldDescriptorProto_TYPE_FIXED32:
+		schema = map[string]interface{}{"type": "integer", "format": "int64", "minimum": 0}
+	case descpb.FieldDescriptorProto_TYPE_FLOAT:
+		schema = map[string]interface{}{"type": "number", "format": "float"}
+	case descpb.FieldDescriptorProto_TYPE_DOUBLE:
+		schema = map[string]interface{}{"type": "number", "format": "double"}
+	default: // 64-bit integers
+		schema = map[string]interface{}{"type": "string", "format": "int64"}
+	}
+
+	if f.GetLabel() == descpb.FieldDescriptorProto_LABEL_REPEATED {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+
+	return schema
+}
@@ -3,15 +3,16 @@ package hub
 import (
 	"database/sql"
 	"fmt"
-	"os/exec"
+	"path/filepath"
+	"strconv"
 
-	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
 )
 
 var ErrContentMismatch = errors.New("content ids do not match")
@@ -193,19 +194,20 @@ func UpdateCatalogWithPortInformation(source, target *utils.Cluster) error {
 	return nil
 }
 
+// UpdateMasterPostgresqlConf sets the port in the target master's
+// postgresql.conf to the source master's port. The file is replaced
+// atomically.
 func UpdateMasterPostgresqlConf(source, target *utils.Cluster) error {
-	script := fmt.Sprintf(
-		"sed 's/port=%d/port=%d/' %[3]s/postgresql.conf > %[3]s/postgresql.conf.updated && "+
-			"mv %[3]s/postgresql.conf %[3]s/postgresql.conf.bak && "+ // XXX not atomic! failure here means we lost the .conf
-			"mv %[3]s/postgresql.conf.updated %[3]s/postgresql.conf",
-		target.MasterPort(), source.MasterPort(), target.MasterDataDir(),
-	)
-	gplog.Debug("executing command: %+v", script) // TODO: Move this debug log into ExecuteLocalCommand()
-	cmd := exec.Command("bash", "-c", script)
-	_, err := cmd.Output()
+	path := filepath.Join(target.MasterDataDir(), "postgresql.conf")
+
+	err := pgconf.Edit(path, func(conf *pgconf.File) error {
+		conf.Set("port", strconv.Itoa(source.MasterPort()))
+		return nil
+	})
 	if err != nil {
-		return xerrors.Errorf("%s failed to execute sed command: %w",
-			idl.Substep_FINALIZE_UPDATE_POSTGRESQL_CONF, err)
+		return xerrors.Errorf("%s failed to update %s: %w",
+			idl.Substep_FINALIZE_UPDATE_POSTGRESQL_CONF, path, err)
 	}
+
 	return nil
 }
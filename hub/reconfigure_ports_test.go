@@ -2,6 +2,9 @@ package hub_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -302,3 +305,45 @@ func expectPortUpdate(mock sqlmock.Sqlmock, seg utils.SegConfig) *sqlmock.Expect
 		"UPDATE gp_segment_configuration SET port = (.+) WHERE content = (.+) AND role = (.+)",
 	).WithArgs(seg.Port, seg.ContentID, seg.Role)
 }
+
+func TestUpdateMasterPostgresqlConf(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	source, err := utils.NewCluster([]utils.SegConfig{
+		{ContentID: -1, Port: 5432, Role: "p", PreferredRole: "p", DataDir: "/data/qddir/seg-1"},
+	})
+	if err != nil {
+		t.Fatalf("constructing source cluster: %+v", err)
+	}
+
+	target, err := utils.NewCluster([]utils.SegConfig{
+		{ContentID: -1, Port: 6000, Role: "p", PreferredRole: "p", DataDir: dataDir},
+	})
+	if err != nil {
+		t.Fatalf("constructing target cluster: %+v", err)
+	}
+
+	path := filepath.Join(dataDir, "postgresql.conf")
+	contents := "#port = 5432\nport = 6000 # set by gpinitsystem\nmax_connections = 250\n"
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("writing postgresql.conf: %+v", err)
+	}
+
+	if err := UpdateMasterPostgresqlConf(source, target); err != nil {
+		t.Fatalf("UpdateMasterPostgresqlConf() returned error %+v", err)
+	}
+
+	actual, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading postgresql.conf: %+v", err)
+	}
+
+	expected := "#port = 5432\nport = 5432 # set by gpinitsystem\nmax_connections = 250\n"
+	if string(actual) != expected {
+		t.Errorf("got postgresql.conf %q, want %q", actual, expected)
+	}
+}
@@ -0,0 +1,443 @@
+package hub
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// ReportMarkdownName and ReportHTMLName are the files in the state directory
+// to which the upgrade report is written at the end of each step.
+const (
+	ReportMarkdownName = "report.md"
+	ReportHTMLName     = "report.html"
+)
+
+// reportSteps are the steps whose logs are searched for warnings, in the
+// order they run. The pre-checks, such as pg_upgrade --check, are logged by
+// initialize.
+var reportSteps = []string{"initialize", "execute", "finalize"}
+
+// warningLine matches the warnings in step logs, whether from gpupgrade and
+// its hooks, the Greenplum utilities or pg_upgrade.
+var warningLine = regexp.MustCompile(`(?i)\bwarn(ing)?\b`)
+
+// maxReportWarnings bounds the warnings listed for each step.
+const maxReportWarnings = 50
+
+// report is the record of an upgrade kept for the change board: what was
+// upgraded, how long it took, and what needs looking at afterwards. It is
+// rendered as Markdown and HTML.
+type report struct {
+	Generated time.Time
+	Step      string // the step that just ran, or empty if regenerated on request
+	LinkMode  bool
+
+	Source, Target reportCluster
+	Topology       []reportSegment
+	Substeps       []reportSubstep
+	Warnings       []reportWarnings
+
+	Disk     []reportDisk
+	DiskNote string // why disk usage is incomplete, if it is
+
+	PgUpgradeReports []reportFile
+}
+
+type reportCluster struct {
+	Version string
+	BinDir  string
+}
+
+// reportSegment is a segment before and after the upgrade. A zero Target is
+// not created yet.
+type reportSegment struct {
+	Role           string
+	Content        int
+	Source, Target reportLocation
+}
+
+type reportLocation struct {
+	Host    string
+	Port    int
+	DataDir string
+}
+
+type reportSubstep struct {
+	Substep  string
+	Status   string
+	Started  string
+	Duration string
+}
+
+type reportWarnings struct {
+	Step  string
+	Lines []string
+	More  int // the number of warnings left out
+}
+
+// reportDisk is the size of a segment's data directory before and after the
+// upgrade.
+type reportDisk struct {
+	Host          string
+	Content       int
+	Before, After string
+}
+
+type reportFile struct {
+	Path  string
+	Lines int
+}
+
+// reportStep writes the upgrade report at the end of a step. The report is
+// only a record, so failing to write it does not fail the step.
+func (s *Server) reportStep(name string) {
+	if _, err := s.writeReport(context.Background(), name); err != nil {
+		gplog.Warn("writing the upgrade report: %v", err)
+	}
+}
+
+// Report regenerates the upgrade report.
+func (s *Server) Report(ctx context.Context, in *idl.ReportRequest) (*idl.ReportReply, error) {
+	markdown, err := s.writeReport(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &idl.ReportReply{
+		MarkdownPath: filepath.Join(s.StateDir, ReportMarkdownName),
+		HtmlPath:     filepath.Join(s.StateDir, ReportHTMLName),
+		Markdown:     string(markdown),
+	}, nil
+}
+
+// writeReport generates the report and writes both renderings of it to the
+// state directory, returning the Markdown.
+func (s *Server) writeReport(ctx context.Context, stepName string) ([]byte, error) {
+	r, err := s.newReport(ctx, stepName)
+	if err != nil {
+		return nil, err
+	}
+
+	markdown, err := r.markdown()
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := r.html()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.AtomicallyWriteFile(filepath.Join(s.StateDir, ReportMarkdownName), markdown, 0600); err != nil {
+		return nil, err
+	}
+
+	if err := utils.AtomicallyWriteFile(filepath.Join(s.StateDir, ReportHTMLName), html, 0600); err != nil {
+		return nil, err
+	}
+
+	return markdown, nil
+}
+
+// newReport gathers the report from the configuration, status.json, the step
+// logs and the pg_upgrade working directory. The disk usage before the upgrade
+// is that measured by initialize, and after it is that measured once the
+// primaries have been upgraded.
+func (s *Server) newReport(ctx context.Context, stepName string) (*report, error) {
+	r := &report{
+		Generated: utils.System.Now(),
+		Step:      stepName,
+		LinkMode:  s.UseLinkMode,
+		Source:    newReportCluster(s.Source),
+		Target:    newReportCluster(s.Target),
+	}
+
+	path, err := getStatusFile(s.StateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := step.NewFileStore(path).Records()
+	if err != nil {
+		return nil, xerrors.Errorf("reading substep statuses: %w", err)
+	}
+
+	r.Topology = s.reportTopology(records)
+	r.Substeps = reportSubsteps(records)
+
+	for _, name := range reportSteps {
+		warnings, err := readWarnings(filepath.Join(s.StateDir, fmt.Sprintf("%s.log", name)))
+		if err != nil {
+			return nil, err
+		}
+		if warnings != nil {
+			r.Warnings = append(r.Warnings, *warnings)
+		}
+	}
+
+	r.Disk, r.DiskNote = s.reportDisk(ctx, records)
+
+	r.PgUpgradeReports, err = findPgUpgradeReports(s.StateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func newReportCluster(c *utils.Cluster) reportCluster {
+	if c == nil {
+		return reportCluster{}
+	}
+
+	return reportCluster{Version: c.Version.VersionString, BinDir: c.BinDir}
+}
+
+// reportTopology returns the master, standby and primaries of the source
+// cluster, and where each is in the target cluster. Once finalize has given
+// the target the source's ports, those are the target's ports.
+func (s *Server) reportTopology(records map[idl.Substep]step.Record) []reportSegment {
+	if s.Source == nil {
+		return nil
+	}
+
+	sourcePorts := records[idl.Substep_FINALIZE_UPDATE_CATALOG_WITH_PORT].Status == idl.Status_COMPLETE
+
+	var segments []reportSegment
+	for _, content := range sortedContents(s.Source) {
+		seg := reportSegment{
+			Role:    "primary",
+			Content: content,
+			Source:  newReportLocation(s.Source.Primaries[content]),
+		}
+		if content == -1 {
+			seg.Role = "master"
+		}
+
+		if s.Target != nil {
+			if target, ok := s.Target.Primaries[content]; ok {
+				seg.Target = newReportLocation(target)
+				if sourcePorts {
+					seg.Target.Port = seg.Source.Port
+				}
+			}
+		}
+
+		segments = append(segments, seg)
+	}
+
+	if s.Source.HasStandby() {
+		standby := reportSegment{
+			Role:    "standby",
+			Content: -1,
+			Source:  newReportLocation(s.Source.Mirrors[-1]),
+		}
+
+		if records[idl.Substep_FINALIZE_UPGRADE_STANDBY].Status == idl.Status_COMPLETE {
+			standby.Target = reportLocation{
+				Host:    s.Source.StandbyHostname(),
+				Port:    s.TargetPorts.Standby,
+				DataDir: upgradedStandbyDataDir(s.Source),
+			}
+			if sourcePorts {
+				standby.Target.Port = standby.Source.Port
+			}
+		}
+
+		// List the standby after the master.
+		segments = append(segments[:1], append([]reportSegment{standby}, segments[1:]...)...)
+	}
+
+	return segments
+}
+
+func newReportLocation(seg utils.SegConfig) reportLocation {
+	return reportLocation{Host: seg.Hostname, Port: seg.Port, DataDir: seg.DataDir}
+}
+
+// sortedContents returns the content IDs of the cluster's primaries in order,
+// the master's first.
+func sortedContents(c *utils.Cluster) []int {
+	contents := append([]int(nil), c.ContentIDs...)
+	sort.Ints(contents)
+	return contents
+}
+
+// reportSubsteps lists the substeps in the order they last started; those that
+// have not run are left out.
+func reportSubsteps(records map[idl.Substep]step.Record) []reportSubstep {
+	var substeps []idl.Substep
+	for substep, record := range records {
+		if record.Started != nil {
+			substeps = append(substeps, substep)
+		}
+	}
+
+	sort.Slice(substeps, func(i, j int) bool {
+		a, b := records[substeps[i]].Started, records[substeps[j]].Started
+		if !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return substeps[i] < substeps[j]
+	})
+
+	var rows []reportSubstep
+	for _, substep := range substeps {
+		record := records[substep]
+
+		row := reportSubstep{
+			Substep:  substep.String(),
+			Status:   record.Status.String(),
+			Started:  formatReportTime(*record.Started),
+			Duration: "-",
+		}
+		if record.Finished != nil {
+			row.Duration = record.Finished.Sub(*record.Started).Round(time.Second).String()
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func formatReportTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05 MST")
+}
+
+// readWarnings returns the distinct warnings in a step log, or nil if there
+// are none or the step has not run.
+func readWarnings(path string) (*reportWarnings, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("reading warnings: %w", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	warnings := &reportWarnings{Step: name[:len(name)-len(filepath.Ext(name))]}
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024) // pg_upgrade and gpinitsystem write long lines
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !warningLine.MatchString(line) || seen[line] {
+			continue
+		}
+		seen[line] = true
+
+		if len(warnings.Lines) == maxReportWarnings {
+			warnings.More++
+			continue
+		}
+		warnings.Lines = append(warnings.Lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("reading warnings from %s: %w", path, err)
+	}
+
+	if len(warnings.Lines) == 0 {
+		return nil, nil
+	}
+
+	return warnings, nil
+}
+
+// reportDisk returns the size of each segment's data directory before and
+// after the upgrade, and a note of whatever is missing from them.
+func (s *Server) reportDisk(ctx context.Context, records map[idl.Substep]step.Record) ([]reportDisk, string) {
+	m, err := LoadMeasurements(s.StateDir)
+	if err != nil {
+		if xerrors.Is(err, os.ErrNotExist) {
+			return nil, "The source cluster was not measured by initialize."
+		}
+		return nil, fmt.Sprintf("The measurements of the source cluster cannot be read: %v", err)
+	}
+
+	var rows []reportDisk
+	index := make(map[int]int)
+	for _, seg := range append([]SegmentMeasurements{m.Master}, m.Primaries...) {
+		index[seg.Content] = len(rows)
+		rows = append(rows, reportDisk{Host: seg.Host, Content: seg.Content, Before: formatReportBytes(seg.DirBytes), After: "-"})
+	}
+
+	upgraded := records[idl.Substep_UPGRADE_PRIMARIES]
+	if upgraded.Status != idl.Status_COMPLETE {
+		return rows, "The target cluster is measured once its primaries have been upgraded."
+	}
+
+	after, err := s.targetMeasurements(ctx, upgraded.Finished)
+	if err != nil {
+		return rows, fmt.Sprintf("The target cluster cannot be measured: %v", err)
+	}
+
+	for _, seg := range append([]SegmentMeasurements{after.Master}, after.Primaries...) {
+		if i, ok := index[seg.Content]; ok {
+			rows[i].After = formatReportBytes(seg.DirBytes)
+		}
+	}
+
+	var note string
+	if s.UseLinkMode {
+		note = "In link mode the target's data files are hard links to the source's, so most of their size is not additional disk usage."
+	}
+
+	return rows, note
+}
+
+// findPgUpgradeReports lists the report files that pg_upgrade left in its
+// working directories on this host, which are those of the master; the
+// segments' are on their own hosts.
+func findPgUpgradeReports(stateDir string) ([]reportFile, error) {
+	paths, err := filepath.Glob(filepath.Join(stateDir, "pg_upgrade", "*", "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var files []reportFile
+	var errs *multierror.Error
+	for _, path := range paths {
+		contents, err := utils.System.ReadFile(path)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		files = append(files, reportFile{Path: path, Lines: bytes.Count(contents, []byte("\n"))})
+	}
+
+	return files, errs.ErrorOrNil()
+}
+
+// formatReportBytes returns a size in binary units.
+func formatReportBytes(b int64) string {
+	size := float64(b)
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	for _, unit := range units {
+		if size < 1024.0 {
+			return fmt.Sprintf("%.4g %s", size, unit)
+		}
+		size /= 1024.0
+	}
+	return fmt.Sprintf("%.4g %s", size, "EiB")
+}
@@ -0,0 +1,180 @@
+package hub
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"golang.org/x/xerrors"
+)
+
+// reportFuncs are shared by both renderings of the report.
+var reportFuncs = map[string]interface{}{
+	"time":   formatReportTime,
+	"orDash": orDash,
+	"portOrDash": func(port int) interface{} {
+		if port == 0 {
+			return "-"
+		}
+		return port
+	},
+	"mode": func(linkMode bool) string {
+		if linkMode {
+			return "link"
+		}
+		return "copy"
+	},
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// markdownCell keeps a value from breaking out of a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.Replace(orDash(s), "|", `\|`, -1)
+}
+
+var markdownReport = texttemplate.Must(texttemplate.New("report.md").
+	Funcs(reportFuncs).
+	Funcs(texttemplate.FuncMap{"cell": markdownCell}).
+	Parse(`# gpupgrade report
+
+Generated {{time .Generated}}{{if .Step}} at the end of {{.Step}}{{end}}.
+
+## Clusters
+
+| | Source | Target |
+|---|---|---|
+| Version | {{cell .Source.Version}} | {{cell .Target.Version}} |
+| Binaries | {{cell .Source.BinDir}} | {{cell .Target.BinDir}} |
+
+The upgrade runs in {{mode .LinkMode}} mode.
+
+## Topology
+{{if .Topology}}
+| Segment | Content | Source host | Source port | Source data directory | Target host | Target port | Target data directory |
+|---|---|---|---|---|---|---|---|
+{{range .Topology}}| {{.Role}} | {{.Content}} | {{cell .Source.Host}} | {{portOrDash .Source.Port}} | {{cell .Source.DataDir}} | {{cell .Target.Host}} | {{portOrDash .Target.Port}} | {{cell .Target.DataDir}} |
+{{end}}{{else}}
+The source cluster has not been configured.
+{{end}}
+## Substeps
+{{if .Substeps}}
+| Substep | Status | Started | Duration |
+|---|---|---|---|
+{{range .Substeps}}| {{.Substep}} | {{.Status}} | {{.Started}} | {{.Duration}} |
+{{end}}{{else}}
+No substeps have run.
+{{end}}
+## Warnings
+{{range .Warnings}}
+### {{.Step}}
+
+` + "```" + `
+{{range .Lines}}{{.}}
+{{end}}` + "```" + `
+{{if .More}}
+... and {{.More}} more; see {{.Step}}.log.
+{{end}}{{else}}
+No warnings were logged.
+{{end}}
+## Disk usage
+{{if .Disk}}
+| Host | Content | Source data directory | Target data directory |
+|---|---|---|---|
+{{range .Disk}}| {{cell .Host}} | {{.Content}} | {{.Before}} | {{.After}} |
+{{end}}{{end}}{{if .DiskNote}}
+{{.DiskNote}}
+{{end}}
+## pg_upgrade reports
+{{range .PgUpgradeReports}}
+- {{.Path}} ({{.Lines}} lines){{else}}
+pg_upgrade left no reports on the master host.{{end}}
+`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("report.html").
+	Funcs(reportFuncs).
+	Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gpupgrade report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; }
+th { background: #eee; }
+pre { background: #f6f6f6; padding: 0.5em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>gpupgrade report</h1>
+<p>Generated {{time .Generated}}{{if .Step}} at the end of {{.Step}}{{end}}.</p>
+
+<h2>Clusters</h2>
+<table>
+<tr><th></th><th>Source</th><th>Target</th></tr>
+<tr><th>Version</th><td>{{orDash .Source.Version}}</td><td>{{orDash .Target.Version}}</td></tr>
+<tr><th>Binaries</th><td>{{orDash .Source.BinDir}}</td><td>{{orDash .Target.BinDir}}</td></tr>
+</table>
+<p>The upgrade runs in {{mode .LinkMode}} mode.</p>
+
+<h2>Topology</h2>
+{{if .Topology}}<table>
+<tr><th>Segment</th><th>Content</th><th>Source host</th><th>Source port</th><th>Source data directory</th><th>Target host</th><th>Target port</th><th>Target data directory</th></tr>
+{{range .Topology}}<tr><td>{{.Role}}</td><td>{{.Content}}</td><td>{{orDash .Source.Host}}</td><td>{{portOrDash .Source.Port}}</td><td>{{orDash .Source.DataDir}}</td><td>{{orDash .Target.Host}}</td><td>{{portOrDash .Target.Port}}</td><td>{{orDash .Target.DataDir}}</td></tr>
+{{end}}</table>
+{{else}}<p>The source cluster has not been configured.</p>
+{{end}}
+<h2>Substeps</h2>
+{{if .Substeps}}<table>
+<tr><th>Substep</th><th>Status</th><th>Started</th><th>Duration</th></tr>
+{{range .Substeps}}<tr><td>{{.Substep}}</td><td>{{.Status}}</td><td>{{.Started}}</td><td>{{.Duration}}</td></tr>
+{{end}}</table>
+{{else}}<p>No substeps have run.</p>
+{{end}}
+<h2>Warnings</h2>
+{{range .Warnings}}<h3>{{.Step}}</h3>
+<pre>{{range .Lines}}{{.}}
+{{end}}</pre>
+{{if .More}}<p>... and {{.More}} more; see {{.Step}}.log.</p>
+{{end}}{{else}}<p>No warnings were logged.</p>
+{{end}}
+<h2>Disk usage</h2>
+{{if .Disk}}<table>
+<tr><th>Host</th><th>Content</th><th>Source data directory</th><th>Target data directory</th></tr>
+{{range .Disk}}<tr><td>{{orDash .Host}}</td><td>{{.Content}}</td><td>{{.Before}}</td><td>{{.After}}</td></tr>
+{{end}}</table>
+{{end}}{{if .DiskNote}}<p>{{.DiskNote}}</p>
+{{end}}
+<h2>pg_upgrade reports</h2>
+{{if .PgUpgradeReports}}<ul>
+{{range .PgUpgradeReports}}<li>{{.Path}} ({{.Lines}} lines)</li>
+{{end}}</ul>
+{{else}}<p>pg_upgrade left no reports on the master host.</p>
+{{end}}</body>
+</html>
+`))
+
+func (r *report) markdown() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownReport.Execute(&buf, r); err != nil {
+		return nil, xerrors.Errorf("rendering report: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r *report) html() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, r); err != nil {
+		return nil, xerrors.Errorf("rendering report: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,293 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+func TestReport(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	source := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Hostname: "mdw", Port: 15432, DataDir: "/data/qddir/demoDataDir-1", Role: "p", PreferredRole: "p"},
+		{ContentID: -1, DbID: 8, Hostname: "smdw", Port: 16432, DataDir: "/data/standby", Role: "m", PreferredRole: "m"},
+		{ContentID: 0, DbID: 2, Hostname: "sdw1", Port: 25432, DataDir: "/data/dbfast1/demoDataDir0", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Hostname: "sdw2", Port: 25433, DataDir: "/data/dbfast2/demoDataDir1", Role: "p", PreferredRole: "p"},
+	})
+	source.Version = dbconn.GPDBVersion{VersionString: "5.28.0"}
+	source.BinDir = "/usr/local/gpdb5/bin"
+
+	target := MustCreateCluster(t, []utils.SegConfig{
+		{ContentID: -1, DbID: 1, Hostname: "mdw", Port: 50432, DataDir: "/data/qddir_upgrade/demoDataDir-1", Role: "p", PreferredRole: "p"},
+		{ContentID: 0, DbID: 2, Hostname: "sdw1", Port: 50434, DataDir: "/data/dbfast1_upgrade/demoDataDir0", Role: "p", PreferredRole: "p"},
+		{ContentID: 1, DbID: 3, Hostname: "sdw2", Port: 50435, DataDir: "/data/dbfast2_upgrade/demoDataDir1", Role: "p", PreferredRole: "p"},
+	})
+	target.Version = dbconn.GPDBVersion{VersionString: "6.9.0"}
+	target.BinDir = "/usr/local/gpdb6/bin"
+
+	s := New(&Config{
+		Source:      source,
+		Target:      target,
+		TargetPorts: PortAssignments{Master: 50432, Standby: 50433, Primaries: []int{50434, 50435}},
+		UseLinkMode: true,
+	}, nil, stateDir)
+
+	start := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { utils.System = utils.InitializeSystemFunctions() }()
+
+	path, err := getStatusFile(stateDir)
+	if err != nil {
+		t.Fatalf("creating status file: %+v", err)
+	}
+	store := step.NewFileStore(path)
+	for _, w := range []struct {
+		offset  time.Duration
+		substep idl.Substep
+		status  idl.Status
+	}{
+		{0, idl.Substep_CONFIG, idl.Status_RUNNING},
+		{5 * time.Second, idl.Substep_CONFIG, idl.Status_COMPLETE},
+		{time.Minute, idl.Substep_FINALIZE_UPGRADE_STANDBY, idl.Status_RUNNING},
+		{2 * time.Minute, idl.Substep_FINALIZE_UPGRADE_STANDBY, idl.Status_COMPLETE},
+		{3 * time.Minute, idl.Substep_FINALIZE_UPDATE_CATALOG_WITH_PORT, idl.Status_RUNNING},
+		{3*time.Minute + 2*time.Second, idl.Substep_FINALIZE_UPDATE_CATALOG_WITH_PORT, idl.Status_COMPLETE},
+		{4 * time.Minute, idl.Substep_FINALIZE_START_TARGET_CLUSTER, idl.Status_RUNNING},
+	} {
+		now := start.Add(w.offset)
+		utils.System.Now = func() time.Time { return now }
+		if err := store.Write(w.substep, w.status); err != nil {
+			t.Fatalf("writing status: %+v", err)
+		}
+	}
+
+	log := strings.Join([]string{
+		"Starting FINALIZE_UPGRADE_STANDBY...",
+		"warning: after hook failed: exit status 1",
+		"20200401:12:01:00:gpinitstandby:mdw:gpadmin-[WARNING]:-<standby> is slow",
+		"warning: after hook failed: exit status 1",
+		"done",
+	}, "\n")
+	if err := ioutil.WriteFile(filepath.Join(stateDir, "finalize.log"), []byte(log), 0600); err != nil {
+		t.Fatalf("writing log: %+v", err)
+	}
+
+	m := &Measurements{
+		Master: SegmentMeasurements{Host: "mdw", Content: -1, DirBytes: 512 << 20},
+		Primaries: []SegmentMeasurements{
+			{Host: "sdw1", Content: 0, DirBytes: 2 << 30},
+			{Host: "sdw2", Content: 1, DirBytes: 3 << 30},
+		},
+	}
+	if err := m.Save(stateDir); err != nil {
+		t.Fatalf("saving measurements: %+v", err)
+	}
+
+	workDir := filepath.Join(stateDir, "pg_upgrade", "seg-1")
+	if err := os.MkdirAll(workDir, 0700); err != nil {
+		t.Fatalf("creating pg_upgrade directory: %+v", err)
+	}
+	reportPath := filepath.Join(workDir, "tables_with_oids.txt")
+	if err := ioutil.WriteFile(reportPath, []byte("public.a\npublic.b\n"), 0600); err != nil {
+		t.Fatalf("writing pg_upgrade report: %+v", err)
+	}
+
+	utils.System.Now = func() time.Time { return start.Add(5 * time.Minute) }
+	reply, err := s.Report(context.Background(), &idl.ReportRequest{})
+	if err != nil {
+		t.Fatalf("Report() returned error %+v", err)
+	}
+
+	t.Run("writes the Markdown report", func(t *testing.T) {
+		contents, err := ioutil.ReadFile(reply.MarkdownPath)
+		if err != nil {
+			t.Fatalf("reading Markdown report: %+v", err)
+		}
+		if string(contents) != reply.Markdown {
+			t.Errorf("returned Markdown differs from %s", reply.MarkdownPath)
+		}
+
+		expected := []string{
+			"| Version | 5.28.0 | 6.9.0 |",
+			"The upgrade runs in link mode.",
+			// Finalize has given the target the source's ports.
+			"| master | -1 | mdw | 15432 | /data/qddir/demoDataDir-1 | mdw | 15432 | /data/qddir_upgrade/demoDataDir-1 |",
+			"| standby | -1 | smdw | 16432 | /data/standby | smdw | 16432 | /data/standby_upgrade |",
+			"| primary | 1 | sdw2 | 25433 | /data/dbfast2/demoDataDir1 | sdw2 | 25433 | /data/dbfast2_upgrade/demoDataDir1 |",
+			fmt.Sprintf("| CONFIG | COMPLETE | %s | 5s |", formatReportTime(start)),
+			fmt.Sprintf("| FINALIZE_START_TARGET_CLUSTER | RUNNING | %s | - |", formatReportTime(start.Add(4*time.Minute))),
+			"### finalize",
+			"| sdw1 | 0 | 2 GiB | - |",
+			"The target cluster is measured once its primaries have been upgraded.",
+			fmt.Sprintf("- %s (2 lines)", reportPath),
+		}
+		for _, line := range expected {
+			if !strings.Contains(reply.Markdown, line+"\n") {
+				t.Errorf("report has no line %q:\n%s", line, reply.Markdown)
+			}
+		}
+
+		if n := strings.Count(reply.Markdown, "warning: after hook failed"); n != 1 {
+			t.Errorf("report has %d copies of a repeated warning, want 1", n)
+		}
+
+		standby := strings.Index(reply.Markdown, "| standby |")
+		primary := strings.Index(reply.Markdown, "| primary | 0 |")
+		if standby < 0 || primary < standby {
+			t.Errorf("standby is not listed before the primaries")
+		}
+	})
+
+	t.Run("writes the HTML report", func(t *testing.T) {
+		contents, err := ioutil.ReadFile(reply.HtmlPath)
+		if err != nil {
+			t.Fatalf("reading HTML report: %+v", err)
+		}
+
+		for _, s := range []string{"<td>6.9.0</td>", "&lt;standby&gt; is slow", "<td>standby</td>"} {
+			if !strings.Contains(string(contents), s) {
+				t.Errorf("HTML report does not contain %q", s)
+			}
+		}
+	})
+}
+
+func TestReportBeforeConfiguration(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	s := New(&Config{}, nil, stateDir)
+	markdown, err := s.writeReport(context.Background(), "initialize")
+	if err != nil {
+		t.Fatalf("writeReport() returned error %+v", err)
+	}
+
+	for _, line := range []string{
+		"The source cluster has not been configured.",
+		"No substeps have run.",
+		"No warnings were logged.",
+		"The source cluster was not measured by initialize.",
+		"pg_upgrade left no reports on the master host.",
+	} {
+		if !strings.Contains(string(markdown), line) {
+			t.Errorf("report has no line %q:\n%s", line, markdown)
+		}
+	}
+}
+
+func TestReportDisk(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	s := New(&Config{}, nil, stateDir)
+	records := map[idl.Substep]step.Record{idl.Substep_UPGRADE_PRIMARIES: {Status: idl.Status_COMPLETE}}
+
+	m := &Measurements{
+		Master:    SegmentMeasurements{Host: "mdw", Content: -1, DirBytes: 512 << 20},
+		Primaries: []SegmentMeasurements{{Host: "sdw1", Content: 0, DirBytes: 2 << 30}},
+	}
+	if err := m.Save(stateDir); err != nil {
+		t.Fatalf("saving measurements: %+v", err)
+	}
+
+	t.Run("notes that the target cannot be measured", func(t *testing.T) {
+		rows, note := s.reportDisk(context.Background(), records)
+		if len(rows) != 2 || rows[1].After != "-" {
+			t.Errorf("got rows %v, want the target's sizes missing", rows)
+		}
+		if note != "The target cluster cannot be measured: the target cluster has not been configured" {
+			t.Errorf("got note %q", note)
+		}
+	})
+
+	t.Run("reads the measurements of the target", func(t *testing.T) {
+		after := &TargetMeasurements{
+			Master:    SegmentMeasurements{Host: "mdw", Content: -1, DirBytes: 1 << 30},
+			Primaries: []SegmentMeasurements{{Host: "sdw1", Content: 0, DirBytes: 4 << 30}},
+		}
+		if err := after.Save(stateDir); err != nil {
+			t.Fatalf("saving target measurements: %+v", err)
+		}
+
+		rows, note := s.reportDisk(context.Background(), records)
+		expected := []reportDisk{
+			{Host: "mdw", Content: -1, Before: "512 MiB", After: "1 GiB"},
+			{Host: "sdw1", Content: 0, Before: "2 GiB", After: "4 GiB"},
+		}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Errorf("got rows %v, want %v", rows, expected)
+		}
+		if note != "" {
+			t.Errorf("got note %q", note)
+		}
+	})
+
+	t.Run("measures the target again once its primaries are upgraded again", func(t *testing.T) {
+		upgraded := time.Now().Add(time.Hour)
+		records := map[idl.Substep]step.Record{idl.Substep_UPGRADE_PRIMARIES: {
+			Status: idl.Status_COMPLETE,
+			Timing: step.Timing{Finished: &upgraded},
+		}}
+
+		rows, note := s.reportDisk(context.Background(), records)
+		if len(rows) != 2 || rows[1].After != "-" {
+			t.Errorf("got rows %v, want the stale sizes of the target left out", rows)
+		}
+		if !strings.HasPrefix(note, "The target cluster cannot be measured") {
+			t.Errorf("got note %q", note)
+		}
+	})
+}
+
+func TestReadWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var lines []string
+	for i := 0; i < maxReportWarnings+10; i++ {
+		lines = append(lines, fmt.Sprintf("WARNING: number %d", i), "not a problem")
+	}
+
+	path := filepath.Join(dir, "execute.log")
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatalf("writing log: %+v", err)
+	}
+
+	warnings, err := readWarnings(path)
+	if err != nil {
+		t.Fatalf("readWarnings() returned error %+v", err)
+	}
+	if warnings.Step != "execute" || len(warnings.Lines) != maxReportWarnings || warnings.More != 10 {
+		t.Errorf("got %s warnings with %d lines and %d more", warnings.Step, len(warnings.Lines), warnings.More)
+	}
+
+	warnings, err = readWarnings(filepath.Join(dir, "finalize.log"))
+	if err != nil || warnings != nil {
+		t.Errorf("for a step that has not run, got %v and error %+v", warnings, err)
+	}
+}
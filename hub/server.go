@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -20,13 +21,18 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	grpcStatus "google.golang.org/grpc/status"
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
 	"github.com/greenplum-db/gpupgrade/utils/daemon"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
 	"github.com/greenplum-db/gpupgrade/utils/log"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
+	"github.com/greenplum-db/gpupgrade/utils/webhook"
 )
 
 var DialTimeout = 3 * time.Second
@@ -44,9 +50,17 @@ type Server struct {
 	agentConns []*Connection
 	grpcDialer Dialer
 
-	mu     sync.Mutex
-	server *grpc.Server
-	lis    net.Listener
+	mu      sync.Mutex
+	server  *grpc.Server
+	lis     net.Listener
+	metrics *metrics.Server
+	gateway *gatewayServer
+	audit   *audit.Log
+
+	// jobs records the running step and those that ran before it, oldest
+	// first, for Jobs and Watch.
+	jobs      []*stepRecording
+	lastJobID int32
 
 	// This is used both as a channel to communicate from Start() to
 	// Stop() to indicate to Stop() that it can finally terminate
@@ -90,26 +104,123 @@ func (s *Server) Start() error {
 		return errors.Wrap(err, "failed to listen")
 	}
 
-	// Set up an interceptor function to log any panics we get from request
-	// handlers.
-	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		defer log.WritePanics()
-		return handler(ctx, req)
+	auditLog, err := audit.Open(filepath.Join(s.StateDir, AuditLogFileName))
+	if err != nil {
+		lis.Close()
+		return err
+	}
+
+	var metricsServer *metrics.Server
+	if s.MetricsPort != 0 {
+		metricsServer, err = metrics.Listen(s.MetricsPort, newAgentConnCollector(s))
+		if err != nil {
+			lis.Close()
+			return err
+		}
+	}
+
+	var gatewayServer *gatewayServer
+	if s.GatewayPort != 0 {
+		gatewayServer, err = listenGateway(s.GatewayAddress, s.GatewayPort, "localhost:"+strconv.Itoa(s.Port), s.gatewayToken)
+		if err != nil {
+			lis.Close()
+			if metricsServer != nil {
+				metricsServer.Stop()
+			}
+			return err
+		}
 	}
-	server := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+
+	// Set up interceptors to log requests and any panics we get from their
+	// handlers, to record request metrics, and to audit operator requests.
+	unaryMetrics := metrics.GRPCServer.UnaryServerInterceptor()
+	interceptor := log.UnaryServerInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		resp, err = unaryMetrics(ctx, req, info, handler)
+		s.auditCall(ctx, info.FullMethod, req, err)
+		return resp, err
+	})
+	// Detached steps outlive their requests, so they are logged as they run
+	// rather than around the request.
+	streamMetrics := log.StreamServerInterceptor(metrics.GRPCServer.StreamServerInterceptor())
+	streamInterceptor := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requests := s.auditStream(ss, info.FullMethod)
+		run := func(stream grpc.ServerStream) error {
+			err := streamMetrics(srv, stream, info, handler)
+			s.auditCall(ss.Context(), info.FullMethod, requests.request, err)
+			return err
+		}
+
+		// Steps run as jobs, which are recorded so that clients can attach to
+		// them with Watch, and which may run detached from their request.
+		job, err := s.recordStep(info.FullMethod, requests)
+		if err != nil {
+			s.auditCall(ss.Context(), info.FullMethod, nil, err)
+			return err
+		}
+		if job == nil {
+			return run(requests)
+		}
+
+		if detachRequested(ss.Context()) {
+			return runDetached(job, run)
+		}
+
+		if err := ss.SetHeader(jobHeader(job.recording)); err != nil {
+			gplog.Debug("sending job header: %v", err)
+		}
+
+		err = run(job)
+		job.recording.finish(err)
+		return err
+	}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
 
 	s.mu.Lock()
 	if s.stopped == nil {
 		// Stop() has already been called; return without serving.
 		s.mu.Unlock()
+		lis.Close()
+		if metricsServer != nil {
+			metricsServer.Stop()
+		}
+		if gatewayServer != nil {
+			gatewayServer.Stop()
+		}
 		return ErrHubStopped
 	}
 	s.server = server
 	s.lis = lis
+	s.metrics = metricsServer
+	s.gateway = gatewayServer
+	s.audit = auditLog
 	s.mu.Unlock()
 
 	idl.RegisterCliToHubServer(server, s)
+	healthpb.RegisterHealthServer(server, &hubHealth{s})
+	idl.RegisterFileServerServer(server, &dircopy.FileServer{Allow: s.servesDir})
 	reflection.Register(server)
+	metrics.GRPCServer.InitializeMetrics(server)
+
+	if metricsServer != nil {
+		go func() {
+			err := metricsServer.Serve()
+			if err != http.ErrServerClosed {
+				gplog.Error("metrics server stopped: %v", err)
+			}
+		}()
+	}
+
+	if gatewayServer != nil {
+		go func() {
+			err := gatewayServer.Serve()
+			if err != http.ErrServerClosed {
+				gplog.Error("gateway stopped: %v", err)
+			}
+		}()
+	}
 
 	if s.daemon {
 		fmt.Printf("Hub started on port %d (pid %d)\n", s.Port, os.Getpid())
@@ -127,7 +238,23 @@ func (s *Server) Start() error {
 	return err
 }
 
+// servesDir decides which directories the hub's FileServer may serve: only the
+// target master data directory, which agents pull during execute.
+func (s *Server) servesDir(dir string) bool {
+	return s.Target != nil && dir == filepath.Clean(s.Target.MasterDataDir())
+}
+
+// StopServices stops the agents and then the hub. It refuses while a step is
+// running, since stopping would abandon the step part way through.
 func (s *Server) StopServices(ctx context.Context, in *idl.StopServicesRequest) (*idl.StopServicesReply, error) {
+	s.mu.Lock()
+	job := s.runningJob()
+	s.mu.Unlock()
+	if job != nil {
+		return nil, grpcStatus.Errorf(codes.FailedPrecondition,
+			"job %d (%s) is still running; wait for it to finish before stopping the hub", job.id, job.name)
+	}
+
 	err := s.StopAgents()
 	if err != nil {
 		gplog.Debug("failed to stop agents: %#v", err)
@@ -162,10 +289,7 @@ func (s *Server) StopAgents() error {
 				return
 			}
 
-			// XXX: "transport is closing" is not documented but is needed to uniquely interpret codes.Unavailable
-			// https://github.com/grpc/grpc/blob/v1.24.0/doc/statuscodes.md
-			errStatus := grpcStatus.Convert(err)
-			if errStatus.Code() != codes.Unavailable || errStatus.Message() != "transport is closing" {
+			if !AgentStopped(err) {
 				errs <- xerrors.Errorf("failed to stop agent on host %s : %w", conn.Hostname, err)
 			}
 		}()
@@ -182,7 +306,21 @@ func (s *Server) StopAgents() error {
 	return multiErr.ErrorOrNil()
 }
 
+// AgentStopped returns whether the error returned by a StopAgent call indicates
+// that the agent shut down. The agent stops its server while handling the
+// request, so a successful call never returns a reply.
+func AgentStopped(err error) bool {
+	// XXX: "transport is closing" is not documented but is needed to uniquely interpret codes.Unavailable
+	// https://github.com/grpc/grpc/blob/v1.24.0/doc/statuscodes.md
+	errStatus := grpcStatus.Convert(err)
+	return errStatus.Code() == codes.Unavailable && errStatus.Message() == "transport is closing"
+}
+
 func (s *Server) Stop(closeAgentConns bool) {
+	// The metrics server must be shut down before we take the lock below, since
+	// in-flight scrapes need the lock to report agent connection state.
+	s.stopMetrics()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -196,20 +334,68 @@ func (s *Server) Stop(closeAgentConns bool) {
 		<-s.stopped // block until it is OK to stop
 	}
 
+	// The gateway is stopped after the server, which ends the requests that
+	// it is making, so that it can finish its responses. In particular, the
+	// gateway may be making the StopServices request that got us here.
+	if s.gateway != nil {
+		if err := s.gateway.Stop(); err != nil {
+			gplog.Error("stopping gateway: %v", err)
+		}
+		s.gateway = nil
+	}
+
 	// Mark this server stopped so that a concurrent Start() doesn't try to
 	// start things up again.
 	s.stopped = nil
 }
 
+func (s *Server) stopMetrics() {
+	s.mu.Lock()
+	m := s.metrics
+	s.metrics = nil
+	s.mu.Unlock()
+
+	if m == nil {
+		return
+	}
+
+	if err := m.Stop(); err != nil {
+		gplog.Error("stopping metrics server: %v", err)
+	}
+}
+
 func (s *Server) RestartAgents(ctx context.Context, in *idl.RestartAgentsRequest) (*idl.RestartAgentsReply, error) {
-	restartedHosts, err := RestartAgents(ctx, nil, s.Source.GetHostnames(), s.AgentPort, s.StateDir)
+	restartedHosts, err := RestartAgents(ctx, nil, s.Source.GetHostnames(), segmentDataDirs(s.Source, s.Target), s.AgentPort, s.AgentMetricsPort, s.StateDir)
 	return &idl.RestartAgentsReply{AgentHosts: restartedHosts}, err
 }
 
+// segmentDataDirs returns the data directories of the primary segments of the
+// given clusters, which may be nil, by host.
+func segmentDataDirs(clusters ...*utils.Cluster) map[string][]string {
+	dataDirs := make(map[string][]string)
+	for _, c := range clusters {
+		if c == nil {
+			continue
+		}
+
+		for _, content := range c.ContentIDs {
+			if seg := c.Primaries[content]; seg.ContentID != -1 {
+				dataDirs[seg.Hostname] = append(dataDirs[seg.Hostname], seg.DataDir)
+			}
+		}
+	}
+
+	return dataDirs
+}
+
+// RestartAgents starts the agent on each of hostnames that is not running,
+// giving it the data directories of its host from dataDirs.
 func RestartAgents(ctx context.Context,
 	dialer func(context.Context, string) (net.Conn, error),
 	hostnames []string,
+	dataDirs map[string][]string,
 	port int,
+	metricsPort int,
 	stateDir string) ([]string, error) {
 
 	var wg sync.WaitGroup
@@ -249,8 +435,16 @@ func RestartAgents(ctx context.Context,
 				errs <- err
 				return
 			}
+			agentArgs := fmt.Sprintf("--daemonize --state-directory %s", stateDir)
+			if metricsPort != 0 {
+				agentArgs += fmt.Sprintf(" --metrics-port %d", metricsPort)
+			}
+			if dirs := dataDirs[host]; len(dirs) > 0 {
+				agentArgs += fmt.Sprintf(" --data-directories %s", strings.Join(dirs, ","))
+			}
+
 			cmd := execCommand("ssh", host,
-				fmt.Sprintf("bash -c \"%s agent --daemonize --state-directory %s\"", agentPath, stateDir))
+				fmt.Sprintf("bash -c \"%s agent %s\"", agentPath, agentArgs))
 			stdout, err := cmd.Output()
 			if err != nil {
 				errs <- err
@@ -365,6 +559,43 @@ type Config struct {
 	Port        int
 	AgentPort   int
 	UseLinkMode bool
+
+	// MetricsPort and AgentMetricsPort are the ports on which the hub and
+	// agents serve Prometheus metrics. Metrics are disabled when zero.
+	MetricsPort      int `json:",omitempty"`
+	AgentMetricsPort int `json:",omitempty"`
+
+	// GatewayPort is the port on which the hub serves its CliToHub service as
+	// a REST/JSON API; see OpenAPIPath. The gateway is disabled when zero. It
+	// listens on GatewayAddress, or on localhost if that is empty.
+	// GatewayToken is the secret with which clients may name the user they
+	// act for in GatewayUserHeader.
+	GatewayPort    int    `json:",omitempty"`
+	GatewayAddress string `json:",omitempty"`
+	GatewayToken   string `json:",omitempty"`
+
+	// CopyEngine selects how data directories are copied; see
+	// dircopy.ValidateEngine. Empty means rsync.
+	CopyEngine string `json:",omitempty"`
+
+	// CopyFanout is the number of hosts to which the master data directory is
+	// copied at once from each source; see DefaultCopyFanout. CopyBandwidth
+	// limits each copy, in bytes per second. Zero is unlimited.
+	CopyFanout    int   `json:",omitempty"`
+	CopyBandwidth int64 `json:",omitempty"`
+
+	// HostParallelism is the number of segments upgraded at once on each
+	// host; zero uses agent.DefaultParallelism on each host.
+	// ClusterParallelism caps the number upgraded at once across the cluster;
+	// zero is unlimited.
+	HostParallelism    int `json:",omitempty"`
+	ClusterParallelism int `json:",omitempty"`
+
+	// Webhooks are the URLs to which status changes of steps and substeps are
+	// posted, signed with WebhookSecret if it is set; see webhook.Sender.
+	Webhooks      []string       `json:",omitempty"`
+	WebhookSecret string         `json:",omitempty"`
+	WebhookFormat webhook.Format `json:",omitempty"`
 }
 
 type PortAssignments struct {
@@ -373,36 +604,43 @@ type PortAssignments struct {
 	Primaries []int
 }
 
+// Load reads a configuration saved by any version of gpupgrade, migrating it
+// to the current schema version. It refuses configurations written by a newer
+// gpupgrade.
 func (c *Config) Load(r io.Reader) error {
-	dec := json.NewDecoder(r)
-	return dec.Decode(c)
+	var doc json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+
+	doc, err := configSchema.Migrate(doc)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(doc, &versionedConfig{Config: c})
 }
 
+// Save writes the configuration, stamped with the current schema version.
 func (c *Config) Save(w io.Writer) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	return enc.Encode(c)
+	return enc.Encode(versionedConfig{
+		SchemaVersion: ConfigSchemaVersion,
+		Config:        c,
+	})
 }
 
-// SaveConfig persists the hub's configuration to disk.
-func (s *Server) SaveConfig() (err error) {
-	// TODO: Switch to an atomic implementation like renameio. Consider what
-	// happens if Config.Save() panics: we'll have truncated the file
-	// on disk and the hub will be unable to recover. For now, since we normally
-	// only save the configuration during initialize and any configuration
-	// errors could be fixed by reinitializing, the risk seems small.
-	file, err := utils.System.Create(filepath.Join(s.StateDir, ConfigFileName))
+// SaveConfig atomically persists the hub's configuration to disk. The previous
+// configuration is kept as a backup generation; see RestoreConfig.
+func (s *Server) SaveConfig() error {
+	err := rotateConfigBackups(s.StateDir)
 	if err != nil {
-		return err
+		return xerrors.Errorf("backing up hub configuration: %w", err)
 	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil {
-			cerr = xerrors.Errorf("closing hub configuration: %w", cerr)
-			err = multierror.Append(err, cerr).ErrorOrNil()
-		}
-	}()
 
-	err = s.Config.Save(file)
+	path := filepath.Join(s.StateDir, ConfigFileName)
+	err = utils.AtomicallyWrite(path, 0600, s.Config.Save)
 	if err != nil {
 		return xerrors.Errorf("saving hub configuration: %w", err)
 	}
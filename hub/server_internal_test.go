@@ -12,7 +12,7 @@ func TestConfig(t *testing.T) {
 	// "stream" refers to the io.Writer/Reader interfaces.
 	t.Run("saves itself to the provided stream", func(t *testing.T) {
 		source, target := testutils.CreateMultinodeSampleClusterPair("/tmp")
-		original := &Config{source, target, PortAssignments{15432, 15432, []int{25432}}, 12345, 54321, false}
+		original := &Config{source, target, PortAssignments{15432, 15432, []int{25432}}, 12345, 54321, false, 9100, 9101, 8080, "0.0.0.0", "open sesame", "native", 8, 1 << 20, 2, 6, []string{"https://hooks.example.com/gpupgrade"}, "shh", "slack"}
 
 		buf := new(bytes.Buffer)
 		err := original.Save(buf)
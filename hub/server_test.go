@@ -1,14 +1,16 @@
 package hub_test
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
 
-	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
 	"google.golang.org/grpc"
@@ -51,7 +53,7 @@ var _ = Describe("Hub", func() {
 		agentA, mockDialer, hubToAgentPort = mock_agent.NewMockAgentServer()
 		source, target = testutils.CreateMultinodeSampleClusterPair("/tmp")
 		useLinkMode = false
-		conf = &hub.Config{source, target, hub.PortAssignments{50432, 50432, []int{50433}}, cliToHubPort, hubToAgentPort, useLinkMode}
+		conf = &hub.Config{source, target, hub.PortAssignments{50432, 50432, []int{50433}}, cliToHubPort, hubToAgentPort, useLinkMode, 0, 0, 0, "", "", "", 0, 0, 0, 0, nil, "", ""}
 	})
 
 	AfterEach(func() {
@@ -189,86 +191,135 @@ var _ = Describe("Hub", func() {
 func TestHubSaveConfig(t *testing.T) {
 	source, target := testutils.CreateMultinodeSampleClusterPair("/tmp")
 	useLinkMode := false
-	conf := &hub.Config{source, target, hub.PortAssignments{50432, 50432, []int{50433}}, 12345, 54321, useLinkMode}
+	conf := &hub.Config{source, target, hub.PortAssignments{50432, 50432, []int{50433}}, 12345, 54321, useLinkMode, 0, 0, 0, "", "", "", 0, 0, 0, 0, nil, "", ""}
 
-	h := hub.New(conf, nil, "")
+	stateDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	h := hub.New(conf, nil, stateDir)
+	path := filepath.Join(stateDir, hub.ConfigFileName)
 
 	t.Run("saves configuration contents to disk", func(t *testing.T) {
-		// Set up utils.System.Create to return the write side of a pipe. We can
-		// read from the other side to confirm what was saved to "disk".
-		read, write, err := os.Pipe()
-		if err != nil {
-			t.Fatalf("creating pipe: %+v", err)
+		if err := h.SaveConfig(); err != nil {
+			t.Errorf("SaveConfig() returned error %+v", err)
 		}
-		defer func() {
-			read.Close()
-			write.Close()
-		}()
 
-		utils.System.Create = func(path string) (*os.File, error) {
-			return write, nil
+		actual := loadConfig(t, path)
+		if !reflect.DeepEqual(h.Config, actual) {
+			t.Errorf("wrote config %#v, want %#v", actual, h.Config)
 		}
-		defer func() {
-			utils.System = utils.InitializeSystemFunctions()
-		}()
 
-		// Write the hub's configuration to the pipe.
-		if err := h.SaveConfig(); err != nil {
-			t.Errorf("SaveConfig() returned error %+v", err)
+		// Only the configuration (and its backups) should be left behind.
+		entries, err := ioutil.ReadDir(stateDir)
+		if err != nil {
+			t.Fatalf("reading state directory: %+v", err)
+		}
+		for _, e := range entries {
+			if e.Name() != hub.ConfigFileName && e.Name() != hub.ConfigBackupDir {
+				t.Errorf("unexpected file %q in state directory", e.Name())
+			}
 		}
+	})
 
-		// Reload the configuration from the read side of the pipe and ensure the
-		// contents are the same.
-		actual := new(hub.Config)
-		if err := actual.Load(read); err != nil {
-			t.Errorf("loading configuration results: %+v", err)
+	t.Run("keeps backup generations of previous configurations", func(t *testing.T) {
+		for port := 1; port <= hub.ConfigBackups+2; port++ {
+			h.Port = port
+			if err := h.SaveConfig(); err != nil {
+				t.Fatalf("SaveConfig() returned error %+v", err)
+			}
 		}
 
-		if !reflect.DeepEqual(h.Config, actual) {
-			t.Errorf("wrote config %#v, want %#v", actual, h.Config)
+		for gen := 1; gen <= hub.ConfigBackups; gen++ {
+			backup := loadConfig(t, hub.ConfigBackupPath(stateDir, gen))
+
+			expected := hub.ConfigBackups + 2 - gen
+			if backup.Port != expected {
+				t.Errorf("backup generation %d has port %d, want %d", gen, backup.Port, expected)
+			}
+		}
+
+		_, err := os.Stat(hub.ConfigBackupPath(stateDir, hub.ConfigBackups+1))
+		if !os.IsNotExist(err) {
+			t.Errorf("expected only %d backup generations (stat error %v)", hub.ConfigBackups, err)
 		}
 	})
 
-	t.Run("bubbles up file creation errors", func(t *testing.T) {
-		expected := errors.New("can't create")
+	t.Run("restores a backup generation", func(t *testing.T) {
+		h.Port = 1000
+		if err := h.SaveConfig(); err != nil {
+			t.Fatalf("SaveConfig() returned error %+v", err)
+		}
+		h.Port = 2000
+		if err := h.SaveConfig(); err != nil {
+			t.Fatalf("SaveConfig() returned error %+v", err)
+		}
 
-		utils.System.Create = func(path string) (*os.File, error) {
-			return nil, expected
+		if err := hub.RestoreConfig(stateDir, 1); err != nil {
+			t.Fatalf("RestoreConfig() returned error %+v", err)
 		}
-		defer func() {
-			utils.System = utils.InitializeSystemFunctions()
-		}()
 
-		err := h.SaveConfig()
-		if !xerrors.Is(err, expected) {
-			t.Errorf("returned %#v, want %#v", err, expected)
+		if actual := loadConfig(t, path); actual.Port != 1000 {
+			t.Errorf("restored port %d, want %d", actual.Port, 1000)
+		}
+
+		// The replaced configuration is kept, so the restore can be undone.
+		if backup := loadConfig(t, hub.ConfigBackupPath(stateDir, 1)); backup.Port != 2000 {
+			t.Errorf("backed up port %d, want %d", backup.Port, 2000)
 		}
 	})
 
-	t.Run("bubbles up file manipulation errors", func(t *testing.T) {
-		// A nil file will fail to write and close, so we can make sure things
-		// are handled correctly.
-		utils.System.Create = func(path string) (*os.File, error) {
-			return nil, nil
+	t.Run("refuses to restore an unusable backup", func(t *testing.T) {
+		backup := hub.ConfigBackupPath(stateDir, 2)
+		if err := ioutil.WriteFile(backup, []byte("{corrupt"), 0600); err != nil {
+			t.Fatalf("writing backup: %+v", err)
 		}
-		defer func() {
-			utils.System = utils.InitializeSystemFunctions()
-		}()
 
-		err := h.SaveConfig()
+		before, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading configuration: %+v", err)
+		}
 
-		// multierror.Error that contains os.ErrInvalid is not itself an instance
-		// of os.ErrInvalid, so unpack it to check existence of os.ErrInvalid
-		var merr *multierror.Error
-		if !xerrors.As(err, &merr) {
-			t.Fatalf("returned %#v, want error type %T", err, merr)
+		if err := hub.RestoreConfig(stateDir, 2); err == nil {
+			t.Error("RestoreConfig() returned nil error")
 		}
 
-		for _, err := range merr.Errors {
-			// For nil Files, operations return os.ErrInvalid.
-			if !xerrors.Is(err, os.ErrInvalid) {
-				t.Errorf("returned error %#v, want %#v", err, os.ErrInvalid)
-			}
+		after, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading configuration: %+v", err)
+		}
+		if !bytes.Equal(before, after) {
+			t.Errorf("configuration was changed to\n%s", after)
 		}
 	})
+
+	t.Run("bubbles up file creation errors", func(t *testing.T) {
+		h := hub.New(conf, nil, filepath.Join(stateDir, "does-not-exist"))
+
+		err := h.SaveConfig()
+
+		var pathErr *os.PathError
+		if !xerrors.As(err, &pathErr) || !os.IsNotExist(pathErr) {
+			t.Errorf("returned %#v, want a not-exist %T", err, pathErr)
+		}
+	})
+}
+
+func loadConfig(t *testing.T, path string) *hub.Config {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening configuration: %+v", err)
+	}
+	defer file.Close()
+
+	conf := new(hub.Config)
+	if err := conf.Load(file); err != nil {
+		t.Fatalf("loading configuration %s: %+v", path, err)
+	}
+
+	return conf
 }
@@ -117,6 +117,25 @@ func (m *multiplexedStream) Close() error {
 	return nil
 }
 
+// Send sends a message other than output to the client, serialized with the
+// output. As with output, errors are logged and otherwise ignored, and nothing
+// more is sent after the first error.
+func (m *multiplexedStream) Send(msg *idl.Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stream == nil {
+		return nil
+	}
+
+	if err := m.stream.Send(msg); err != nil {
+		gplog.Info("halting client stream: %v", err)
+		m.stream = nil
+	}
+
+	return nil
+}
+
 type streamWriter struct {
 	*multiplexedStream
 	cType idl.Chunk_Type
@@ -1,15 +1,19 @@
 package hub
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/step"
 	"github.com/greenplum-db/gpupgrade/upgrade"
 	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/dircopy"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 )
 
 // Allow exec.Command to be mocked out by exectest.NewCommand.
@@ -21,7 +25,7 @@ const originalMasterBackupName = "master.bak"
 // XXX this makes more sense as a Server method, but it's so difficult to stub a
 // Server that the parameters have been split out for testing. Revisit if/when the
 // Server monolith is broken up.
-func UpgradeMaster(source, target *utils.Cluster, stateDir string, stream step.OutStreams, checkOnly bool, useLinkMode bool) error {
+func UpgradeMaster(source, target *utils.Cluster, stateDir string, stream step.OutStreams, checkOnly bool, useLinkMode bool, copyEngine string) error {
 	wd := upgrade.MasterWorkingDirectory(stateDir)
 	err := utils.System.MkdirAll(wd, 0700)
 	if err != nil {
@@ -29,7 +33,7 @@ func UpgradeMaster(source, target *utils.Cluster, stateDir string, stream step.O
 	}
 
 	sourceDir := filepath.Join(stateDir, originalMasterBackupName)
-	err = RsyncMasterDataDir(stream, sourceDir, target.MasterDataDir())
+	err = CopyDataDir(copyEngine, stream, sourceDir, target.MasterDataDir())
 	if err != nil {
 		return err
 	}
@@ -52,7 +56,14 @@ func UpgradeMaster(source, target *utils.Cluster, stateDir string, stream step.O
 		options = append(options, upgrade.WithLinkMode())
 	}
 
-	return upgrade.Run(pair, options...)
+	err = upgrade.Run(pair, options...)
+
+	var upgradeErr *upgrade.Error
+	if xerrors.As(err, &upgradeErr) {
+		upgradeErr.Host = target.MasterHostname()
+	}
+
+	return err
 }
 
 func masterSegmentFromCluster(cluster *utils.Cluster) *upgrade.Segment {
@@ -64,6 +75,30 @@ func masterSegmentFromCluster(cluster *utils.Cluster) *upgrade.Segment {
 	}
 }
 
+// CopyDataDir replaces the contents of targetDir with those of the local
+// master data directory sourceDir, excluding its logs, using the given copy
+// engine.
+func CopyDataDir(copyEngine string, stream step.OutStreams, sourceDir, targetDir string) error {
+	if copyEngine != dircopy.EngineNative {
+		return RsyncMasterDataDir(stream, sourceDir, targetDir)
+	}
+
+	start := time.Now()
+	stats, err := dircopy.Copy(sourceDir, targetDir, dircopy.Options{
+		Exclude: []string{"pg_log/*"},
+		Delete:  true,
+	})
+	metrics.ObserveCopy("master_data_dir", start, stats.Bytes, err)
+
+	if err != nil {
+		return xerrors.Errorf("copy %q to %q: %w", sourceDir, targetDir, err)
+	}
+
+	_, err = fmt.Fprintf(stream.Stdout(), "copied %d files (%d bytes) to %s, deleted %d\n",
+		stats.Files, stats.Bytes, targetDir, stats.Deleted)
+	return err
+}
+
 func RsyncMasterDataDir(stream step.OutStreams, sourceDir, targetDir string) error {
 	sourceDirRsync := filepath.Clean(sourceDir) + string(os.PathSeparator)
 	cmd := execCommandRsync("rsync", "--archive", "--delete", "--exclude=pg_log/*", sourceDirRsync, targetDir)
@@ -71,7 +106,10 @@ func RsyncMasterDataDir(stream step.OutStreams, sourceDir, targetDir string) err
 	cmd.Stdout = stream.Stdout()
 	cmd.Stderr = stream.Stderr()
 
+	start := time.Now()
 	err := cmd.Run()
+	metrics.ObserveRsync("rsync_master_data_dir", start, nil, err)
+
 	if err != nil {
 		return xerrors.Errorf("rsync %q to %q: %w", sourceDirRsync, targetDir, err)
 	}
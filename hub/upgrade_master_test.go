@@ -148,7 +148,7 @@ func TestUpgradeMaster(t *testing.T) {
 		SetRsyncExecCommand(exectest.NewCommand(Success))
 		defer ResetRsyncExecCommand()
 
-		err := UpgradeMaster(source, target, tempDir, DevNull, false, false)
+		err := UpgradeMaster(source, target, tempDir, DevNull, false, false, "")
 		if err != nil {
 			t.Errorf("returned error %+v", err)
 		}
@@ -168,7 +168,7 @@ func TestUpgradeMaster(t *testing.T) {
 
 		stream := new(bufferedStreams)
 
-		err := UpgradeMaster(source, target, tempDir, stream, false, false)
+		err := UpgradeMaster(source, target, tempDir, stream, false, false, "")
 		if err != nil {
 			t.Errorf("returned error %+v", err)
 		}
@@ -193,7 +193,7 @@ func TestUpgradeMaster(t *testing.T) {
 		defer ResetRsyncExecCommand()
 
 		expectedErr := errors.New("write failed!")
-		err := UpgradeMaster(source, target, tempDir, failingStreams{expectedErr}, false, false)
+		err := UpgradeMaster(source, target, tempDir, failingStreams{expectedErr}, false, false, "")
 		if !xerrors.Is(err, expectedErr) {
 			t.Errorf("returned error %+v, want %+v", err, expectedErr)
 		}
@@ -208,7 +208,7 @@ func TestUpgradeMaster(t *testing.T) {
 
 		stream := new(bufferedStreams)
 
-		err := UpgradeMaster(source, target, tempDir, stream, false, false)
+		err := UpgradeMaster(source, target, tempDir, stream, false, false, "")
 		if err == nil {
 			t.Errorf("expected error, returned nil")
 		}
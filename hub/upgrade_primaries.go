@@ -3,39 +3,96 @@ package hub
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
 	"github.com/greenplum-db/gpupgrade/utils"
 )
 
-func UpgradePrimaries(checkOnly bool, masterBackupDir string, agentConns []*Connection, dataDirPairMap map[string][]*idl.DataDirPair, source *utils.Cluster, target *utils.Cluster, useLinkMode bool) error {
+// UpgradePrimaryArgs are the arguments to UpgradePrimaries.
+type UpgradePrimaryArgs struct {
+	CheckOnly       bool
+	MasterBackupDir string
+	AgentConns      []*Connection
+	DataDirPairMap  map[string][]*idl.DataDirPair
+	Source          *utils.Cluster
+	Target          *utils.Cluster
+	UseLinkMode     bool
+	CopyEngine      string
+
+	// HostParallelism is the number of segments each agent upgrades at once;
+	// zero selects the agent's default. ClusterParallelism caps the number of
+	// segments upgraded at once across the cluster; zero is unlimited.
+	HostParallelism    int
+	ClusterParallelism int
+
+	// Progress, if set, is told as each segment enters a new phase of its
+	// upgrade. It is called by several goroutines at once.
+	Progress func(*idl.SegmentProgress)
+}
+
+func UpgradePrimaries(args UpgradePrimaryArgs) error {
 	wg := sync.WaitGroup{}
-	agentErrs := make(chan error, len(agentConns))
-	for _, agentConn := range agentConns {
-		wg.Add(1)
-
-		go func(conn *Connection) {
-			defer wg.Done()
-
-			_, err := idl.NewAgentClient(conn.Conn).UpgradePrimaries(context.Background(), &idl.UpgradePrimariesRequest{
-				SourceBinDir:    source.BinDir,
-				TargetBinDir:    target.BinDir,
-				TargetVersion:   target.Version.SemVer.String(),
-				DataDirPairs:    dataDirPairMap[conn.Hostname],
-				CheckOnly:       checkOnly,
-				UseLinkMode:     useLinkMode,
-				MasterBackupDir: masterBackupDir,
-			})
-
-			if err != nil {
-				agentErrs <- errors.Wrapf(err, "gpupgrade agent failed to convert primary segment on host %s", conn.Hostname)
-			}
-		}(agentConn)
+
+	// There is at most one error for each agent or segment.
+	agentErrs := make(chan error, len(args.AgentConns)+countPairs(args.DataDirPairMap))
+
+	// With a cluster-wide cap, each segment is sent to its agent separately
+	// so that the hub can schedule them, keeping to each host's parallelism;
+	// otherwise each agent is sent all of its segments at once and schedules
+	// them itself.
+	var cluster chan struct{}
+	if args.ClusterParallelism > 0 {
+		cluster = make(chan struct{}, args.ClusterParallelism)
+	}
+
+	for _, agentConn := range args.AgentConns {
+		pairs := args.DataDirPairMap[agentConn.Hostname]
+
+		if cluster == nil {
+			wg.Add(1)
+			go func(conn *Connection) {
+				defer wg.Done()
+
+				if err := upgradeSegments(args, conn, pairs); err != nil {
+					agentErrs <- err
+				}
+			}(agentConn)
+
+			continue
+		}
+
+		limit, err := hostParallelism(args, agentConn)
+		if err != nil {
+			agentErrs <- err
+			continue
+		}
+		host := make(chan struct{}, limit)
+
+		for _, pair := range pairs {
+			wg.Add(1)
+			go func(conn *Connection, pair *idl.DataDirPair) {
+				defer wg.Done()
+
+				// Always acquire the host's slot before the cluster's, so
+				// that a segment waiting for its host holds no cluster slot.
+				host <- struct{}{}
+				defer func() { <-host }()
+				cluster <- struct{}{}
+				defer func() { <-cluster }()
+
+				if err := upgradeSegments(args, conn, []*idl.DataDirPair{pair}); err != nil {
+					agentErrs <- err
+				}
+			}(agentConn, pair)
+		}
 	}
 
 	wg.Wait()
@@ -50,6 +107,85 @@ func UpgradePrimaries(checkOnly bool, masterBackupDir string, agentConns []*Conn
 	return err
 }
 
+// hostParallelism returns the number of segments that may be upgraded at once
+// on the host of conn: HostParallelism if it is set, or else the agent's
+// default, which it reports from ScanDataDirs.
+func hostParallelism(args UpgradePrimaryArgs, conn *Connection) (int, error) {
+	if args.HostParallelism > 0 {
+		return args.HostParallelism, nil
+	}
+
+	reply, err := idl.NewAgentClient(conn.Conn).ScanDataDirs(context.Background(), &idl.ScanDataDirsRequest{})
+	if err != nil {
+		return 0, errors.Wrapf(err, "gpupgrade agent failed to report its parallelism on host %s", conn.Hostname)
+	}
+
+	if reply.DefaultParallelism < 1 {
+		return 1, nil
+	}
+
+	return int(reply.DefaultParallelism), nil
+}
+
+// upgradeSegments has the agent of conn upgrade the given segments on its host.
+func upgradeSegments(args UpgradePrimaryArgs, conn *Connection, pairs []*idl.DataDirPair) error {
+	parallelism := args.HostParallelism
+	if len(pairs) == 1 {
+		parallelism = 1
+	}
+
+	stream, err := idl.NewAgentClient(conn.Conn).UpgradePrimaries(context.Background(), &idl.UpgradePrimariesRequest{
+		SourceBinDir:    args.Source.BinDir,
+		TargetBinDir:    args.Target.BinDir,
+		TargetVersion:   args.Target.Version.SemVer.String(),
+		DataDirPairs:    pairs,
+		CheckOnly:       args.CheckOnly,
+		UseLinkMode:     args.UseLinkMode,
+		MasterBackupDir: args.MasterBackupDir,
+		CopyEngine:      args.CopyEngine,
+		Parallelism:     int32(parallelism),
+	})
+
+	for err == nil {
+		var reply *idl.UpgradePrimariesReply
+		reply, err = stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if progress := reply.GetProgress(); progress != nil && args.Progress != nil {
+			progress.Host = conn.Hostname
+			progress.Time = time.Now().Unix()
+			args.Progress(progress)
+		}
+	}
+
+	return errors.Wrapf(err, "gpupgrade agent failed to convert primary segment on host %s", conn.Hostname)
+}
+
+// sendProgress returns a Progress function for UpgradePrimaryArgs that sends
+// segment progress to the client of a step, if the step's streams can carry
+// messages as well as output.
+func sendProgress(streams step.OutStreams) func(*idl.SegmentProgress) {
+	sender, ok := streams.(idl.MessageSender)
+	if !ok {
+		return nil
+	}
+
+	return func(progress *idl.SegmentProgress) {
+		sender.Send(&idl.Message{Contents: &idl.Message_Progress{Progress: progress}})
+	}
+}
+
+func countPairs(dataDirPairMap map[string][]*idl.DataDirPair) int {
+	count := 0
+	for _, pairs := range dataDirPairMap {
+		count += len(pairs)
+	}
+
+	return count
+}
+
 func (s *Server) GetDataDirPairs() (map[string][]*idl.DataDirPair, error) {
 	dataDirPairMap := make(map[string][]*idl.DataDirPair)
 
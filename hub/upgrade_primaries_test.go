@@ -3,6 +3,7 @@ package hub_test
 import (
 	"errors"
 	"path/filepath"
+	"sync"
 
 	"github.com/greenplum-db/gpupgrade/hub"
 	"github.com/greenplum-db/gpupgrade/idl"
@@ -61,12 +62,22 @@ var _ = Describe("UpgradePrimaries", func() {
 		agentConns, _ := testHub.AgentConns()
 		dataDirPairMap, _ := testHub.GetDataDirPairs()
 
-		err := hub.UpgradePrimaries(false, "/some/cool/backupdir", agentConns, dataDirPairMap, source, target, useLinkMode)
+		err := hub.UpgradePrimaries(hub.UpgradePrimaryArgs{
+			CheckOnly:       false,
+			MasterBackupDir: "/some/cool/backupdir",
+			AgentConns:      agentConns,
+			DataDirPairMap:  dataDirPairMap,
+			Source:          source,
+			Target:          target,
+			UseLinkMode:     useLinkMode,
+			HostParallelism: 3,
+		})
 		Expect(err).ToNot(HaveOccurred())
 
 		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.SourceBinDir).To(Equal("/source/bindir"))
 		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.TargetBinDir).To(Equal("/target/bindir"))
 		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.MasterBackupDir).To(Equal("/some/cool/backupdir"))
+		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.Parallelism).To(Equal(int32(3)))
 		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.DataDirPairs).To(ConsistOf([]*idl.DataDirPair{
 			{
 				SourceDataDir: filepath.Join(dir, "seg1"),
@@ -93,9 +104,107 @@ var _ = Describe("UpgradePrimaries", func() {
 		agentConns, _ := testHub.AgentConns()
 		dataDirPairMap, _ := testHub.GetDataDirPairs()
 
-		err := hub.UpgradePrimaries(false, "", agentConns, dataDirPairMap, source, target, useLinkMode)
+		err := hub.UpgradePrimaries(hub.UpgradePrimaryArgs{
+			CheckOnly:      false,
+			AgentConns:     agentConns,
+			DataDirPairMap: dataDirPairMap,
+			Source:         source,
+			Target:         target,
+			UseLinkMode:    useLinkMode,
+		})
 		Expect(err).To(HaveOccurred())
 
 		Expect(mockAgent.NumberOfCalls()).To(Equal(2))
 	})
+
+	It("sends each segment separately when the cluster parallelism is capped", func() {
+		// Put both segments on the same host.
+		seg2 := target.Primaries[1]
+		seg2.Hostname = target.Primaries[0].Hostname
+		target.Primaries[1] = seg2
+
+		sourceSeg2 := source.Primaries[1]
+		sourceSeg2.Hostname = seg2.Hostname
+		source.Primaries[1] = sourceSeg2
+
+		agentConns, _ := testHub.AgentConns()
+		dataDirPairMap, _ := testHub.GetDataDirPairs()
+		Expect(dataDirPairMap[seg2.Hostname]).To(HaveLen(2))
+
+		err := hub.UpgradePrimaries(hub.UpgradePrimaryArgs{
+			CheckOnly:          false,
+			AgentConns:         agentConns,
+			DataDirPairMap:     dataDirPairMap,
+			Source:             source,
+			Target:             target,
+			UseLinkMode:        useLinkMode,
+			HostParallelism:    3,
+			ClusterParallelism: 1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(mockAgent.NumberOfCalls()).To(Equal(2))
+		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.DataDirPairs).To(HaveLen(1))
+		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.Parallelism).To(Equal(int32(1)))
+	})
+
+	It("keeps to the agent's default parallelism when only the cluster parallelism is capped", func() {
+		// Put both segments on the same host.
+		seg2 := target.Primaries[1]
+		seg2.Hostname = target.Primaries[0].Hostname
+		target.Primaries[1] = seg2
+
+		sourceSeg2 := source.Primaries[1]
+		sourceSeg2.Hostname = seg2.Hostname
+		source.Primaries[1] = sourceSeg2
+
+		mockAgent.DefaultParallelism = 1
+
+		agentConns, _ := testHub.AgentConns()
+		dataDirPairMap, _ := testHub.GetDataDirPairs()
+
+		err := hub.UpgradePrimaries(hub.UpgradePrimaryArgs{
+			CheckOnly:          false,
+			AgentConns:         agentConns,
+			DataDirPairMap:     dataDirPairMap,
+			Source:             source,
+			Target:             target,
+			UseLinkMode:        useLinkMode,
+			ClusterParallelism: 2,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// The agent is asked for its default before its two segments are sent.
+		Expect(mockAgent.NumberOfCalls()).To(Equal(3))
+		Expect(mockAgent.MaxConcurrentUpgrades).To(Equal(1))
+		Expect(mockAgent.UpgradeConvertPrimarySegmentsRequest.DataDirPairs).To(HaveLen(1))
+	})
+
+	It("reports the progress of each segment with its host", func() {
+		agentConns, _ := testHub.AgentConns()
+		dataDirPairMap, _ := testHub.GetDataDirPairs()
+
+		var mu sync.Mutex
+		var progress []*idl.SegmentProgress
+
+		err := hub.UpgradePrimaries(hub.UpgradePrimaryArgs{
+			AgentConns:     agentConns,
+			DataDirPairMap: dataDirPairMap,
+			Source:         source,
+			Target:         target,
+			Progress: func(p *idl.SegmentProgress) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress = append(progress, p)
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(progress).To(HaveLen(2))
+		for _, p := range progress {
+			Expect(p.Host).To(Equal(target.Primaries[int(p.Content)].Hostname))
+			Expect(p.Phase).To(Equal(idl.SegmentProgress_DONE))
+			Expect(p.Time).ToNot(BeZero())
+		}
+	})
 })
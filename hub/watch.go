@@ -0,0 +1,366 @@
+package hub
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// ReplayBytes bounds the messages of a step that are kept for clients that
+// attach to it with Watch. The oldest output is dropped first, and other
+// messages only once they alone fill the bound.
+const ReplayBytes = 8 << 20
+
+// HistoryBytes bounds the messages kept across the finished jobs. The history
+// of older jobs is dropped, though they are still listed by Jobs.
+const HistoryBytes = 4 * ReplayBytes
+
+// watcherBacklog is the number of messages that may be waiting for a watcher
+// before it is considered too slow and disconnected.
+const watcherBacklog = 1024
+
+// StepHeader is the metadata key in which Watch names the step it replays.
+const StepHeader = "gpupgrade-step"
+
+// stepMethods maps the CliToHub requests that run steps to the step names.
+var stepMethods = map[string]string{
+	cliToHubPrefix + "Initialize":              "initialize",
+	cliToHubPrefix + "InitializeCreateCluster": "initialize",
+	cliToHubPrefix + "Execute":                 "execute",
+	cliToHubPrefix + "Finalize":                "finalize",
+}
+
+// stepRecording is the job that runs a step. It keeps the messages sent by
+// the step, bounded by ReplayBytes, and relays new ones to any watchers.
+type stepRecording struct {
+	id      int32
+	name    string
+	started time.Time
+
+	mu            sync.Mutex
+	messages      []*idl.Message
+	size          int
+	omitted       int // bytes of output dropped from the front of messages
+	omittedOther  int // other messages dropped from the front of messages
+	watchers      map[*watcher]bool
+	substep       idl.Substep // the substep that last reported its status
+	substepStatus idl.Status
+	detachedJob   bool // started with DetachKey
+	done          bool
+	finished      time.Time
+	err           error // the step's result, once done
+}
+
+type watcher struct {
+	messages chan *idl.Message
+	lagged   bool
+}
+
+func newStepRecording(id int32, name string) *stepRecording {
+	return &stepRecording{
+		id:       id,
+		name:     name,
+		started:  time.Now(),
+		watchers: make(map[*watcher]bool),
+	}
+}
+
+// record keeps msg for replay and relays it to the watchers. Output is copied,
+// since its buffer belongs to the writer that produced it and may be reused.
+func (r *stepRecording) record(msg *idl.Message) {
+	if chunk := msg.GetChunk(); chunk != nil {
+		msg = &idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{
+			Buffer: append([]byte(nil), chunk.Buffer...),
+			Type:   chunk.Type,
+		}}}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, msg)
+	if status := msg.GetStatus(); status != nil {
+		r.substep = status.Step
+		r.substepStatus = status.Status
+	}
+	r.size += proto.Size(msg)
+	if r.size > ReplayBytes {
+		r.trim()
+	}
+
+	for w := range r.watchers {
+		select {
+		case w.messages <- msg:
+		default:
+			w.lagged = true
+			r.drop(w)
+		}
+	}
+}
+
+// trim drops the oldest output, and then if need be the oldest of the other
+// messages, until the recording fills three quarters of ReplayBytes, leaving
+// room so that it is not trimmed for every message. r.mu must be held.
+func (r *stepRecording) trim() {
+	r.dropOldest(ReplayBytes*3/4, true)
+	r.dropOldest(ReplayBytes*3/4, false)
+}
+
+// dropOldest drops the oldest messages, or with onlyOutput the oldest output,
+// until the recording fills at most limit bytes. r.mu must be held.
+func (r *stepRecording) dropOldest(limit int, onlyOutput bool) {
+	kept := r.messages[:0]
+	for _, msg := range r.messages {
+		chunk := msg.GetChunk()
+		if r.size > limit && (chunk != nil || !onlyOutput) {
+			r.size -= proto.Size(msg)
+			if chunk != nil {
+				r.omitted += len(chunk.Buffer)
+			} else {
+				r.omittedOther++
+			}
+			continue
+		}
+		kept = append(kept, msg)
+	}
+
+	// Clear the tail of the array so the dropped messages can be collected.
+	for i := len(kept); i < len(r.messages); i++ {
+		r.messages[i] = nil
+	}
+	r.messages = kept
+}
+
+// historySize returns the bytes of messages kept for replay.
+func (r *stepRecording) historySize() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.size
+}
+
+// forget drops every message kept for replay.
+func (r *stepRecording) forget() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropOldest(0, false)
+}
+
+// watch returns the messages recorded so far and, unless the step is done, a
+// watcher that receives the rest.
+func (r *stepRecording) watch() ([]*idl.Message, *watcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := make([]*idl.Message, 0, len(r.messages)+1)
+	if r.omitted > 0 || r.omittedOther > 0 {
+		notice := fmt.Sprintf("[%d bytes of earlier output omitted]\n", r.omitted)
+		if r.omittedOther > 0 {
+			notice = fmt.Sprintf("[%d bytes of earlier output and %d other messages omitted]\n", r.omitted, r.omittedOther)
+		}
+		history = append(history, &idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{
+			Buffer: []byte(notice),
+			Type:   idl.Chunk_STDOUT,
+		}}})
+	}
+	history = append(history, r.messages...)
+
+	if r.done {
+		return history, nil
+	}
+
+	w := &watcher{messages: make(chan *idl.Message, watcherBacklog)}
+	r.watchers[w] = true
+	return history, w
+}
+
+func (r *stepRecording) unwatch(w *watcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.drop(w)
+}
+
+// drop disconnects a watcher. r.mu must be held.
+func (r *stepRecording) drop(w *watcher) {
+	if r.watchers[w] {
+		delete(r.watchers, w)
+		close(w.messages)
+	}
+}
+
+// finish records the step's result and disconnects the watchers.
+func (r *stepRecording) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done = true
+	r.finished = time.Now()
+	r.err = err
+	for w := range r.watchers {
+		r.drop(w)
+	}
+}
+
+func (r *stepRecording) running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !r.done
+}
+
+func (r *stepRecording) result() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// recordingStream records the messages that a step sends to its client. Once
+// the client has gone, messages are only recorded, so that the step carries on
+// and can be watched again with Watch.
+type recordingStream struct {
+	grpc.ServerStream
+	recording *stepRecording
+	detached  bool
+}
+
+func (r *recordingStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(*idl.Message); ok {
+		r.recording.record(msg)
+	}
+
+	if r.detached {
+		return nil
+	}
+
+	if err := r.ServerStream.SendMsg(m); err != nil {
+		gplog.Info("client detached from %s; continuing without it: %v", r.recording.name, err)
+		r.detached = true
+	}
+
+	return nil
+}
+
+// recordStep starts a job for a step request, dropping the oldest job beyond
+// MaxJobs and the oldest history beyond HistoryBytes. It returns nil for requests that are not steps, and refuses to
+// start a step while another is running.
+func (s *Server) recordStep(method string, stream grpc.ServerStream) (*recordingStream, error) {
+	name, ok := stepMethods[method]
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	if job := s.runningJob(); job != nil {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"job %d (%s) is still running; wait for it to finish, or follow it with \"gpupgrade attach --job %d\"",
+			job.id, job.name, job.id)
+	}
+
+	s.lastJobID++
+	recording := newStepRecording(s.lastJobID, name)
+	s.jobs = append(s.jobs, recording)
+	if len(s.jobs) > MaxJobs {
+		s.jobs = append([]*stepRecording(nil), s.jobs[len(s.jobs)-MaxJobs:]...)
+	}
+	s.forgetHistory()
+	s.mu.Unlock()
+
+	return &recordingStream{ServerStream: stream, recording: recording}, nil
+}
+
+// runningJob returns the job whose step is running, or nil if there is none.
+// s.mu must be held.
+func (s *Server) runningJob() *stepRecording {
+	for _, job := range s.jobs {
+		if job.running() {
+			return job
+		}
+	}
+
+	return nil
+}
+
+// forgetHistory drops the messages of the oldest finished jobs, keeping those
+// of the most recent ones within HistoryBytes. s.mu must be held.
+func (s *Server) forgetHistory() {
+	total := 0
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		job := s.jobs[i]
+		if job.running() {
+			continue
+		}
+
+		size := job.historySize()
+		if total+size > HistoryBytes {
+			job.forget()
+			continue
+		}
+		total += size
+	}
+}
+
+// Watch replays the messages of the requested job, or of the most recent one,
+// and then relays new ones until its step finishes, returning the step's
+// result.
+func (s *Server) Watch(request *idl.WatchRequest, stream idl.CliToHub_WatchServer) error {
+	recording := s.findJob(request.Job)
+	if recording == nil {
+		if request.Job != 0 {
+			return status.Errorf(codes.NotFound, "no job %d since the hub started", request.Job)
+		}
+		return status.Error(codes.NotFound, "no step has run since the hub started")
+	}
+
+	header := metadata.Pairs(StepHeader, recording.name, JobHeader, strconv.Itoa(int(recording.id)))
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+
+	history, w := recording.watch()
+	if w != nil {
+		defer recording.unwatch(w)
+	}
+
+	for _, msg := range history {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	if w == nil {
+		return recording.result()
+	}
+
+	for {
+		select {
+		case msg, ok := <-w.messages:
+			if !ok {
+				if w.lagged {
+					return status.Error(codes.ResourceExhausted, "fell too far behind the step's output; attach again")
+				}
+				return recording.result()
+			}
+
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
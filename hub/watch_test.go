@@ -0,0 +1,242 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+)
+
+// clientStream is the server side of a step's stream to its client, which
+// fails every send once the client has gone.
+type clientStream struct {
+	grpc.ServerStream
+	sent []interface{}
+	gone bool
+}
+
+func (c *clientStream) SendMsg(m interface{}) error {
+	if c.gone {
+		return errors.New("transport is closing")
+	}
+
+	c.sent = append(c.sent, m)
+	return nil
+}
+
+func statusMessage(substep idl.Substep, status idl.Status) *idl.Message {
+	return &idl.Message{Contents: &idl.Message_Status{&idl.SubstepStatus{Step: substep, Status: status}}}
+}
+
+func chunkMessage(data string) *idl.Message {
+	return &idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{Buffer: []byte(data), Type: idl.Chunk_STDOUT}}}
+}
+
+// watchStream returns a mock Watch stream that passes on each message it is
+// sent.
+func watchStream(ctrl *gomock.Controller, ctx context.Context, sent chan<- *idl.Message) *mock_idl.MockCliToHub_WatchServer {
+	stream := mock_idl.NewMockCliToHub_WatchServer(ctrl)
+	stream.EXPECT().SendHeader(metadata.Pairs(StepHeader, "execute", JobHeader, "1")).Return(nil)
+	stream.EXPECT().Context().Return(ctx).AnyTimes()
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg *idl.Message) error {
+		sent <- msg
+		return nil
+	}).AnyTimes()
+
+	return stream
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("returns NotFound before any step has run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := &Server{}
+		err := s.Watch(&idl.WatchRequest{}, mock_idl.NewMockCliToHub_WatchServer(ctrl))
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("returned error %#v, want code %v", err, codes.NotFound)
+		}
+	})
+
+	t.Run("only records steps", func(t *testing.T) {
+		s := &Server{}
+		if stream := recordStep(t, s, cliToHubPrefix+"CollectLogs", &clientStream{}); stream != nil {
+			t.Errorf("recorded CollectLogs")
+		}
+	})
+
+	t.Run("replays the step and follows it after the client detaches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := &Server{}
+		client := &clientStream{}
+		stream := recordStep(t, s, cliToHubPrefix+"Execute", client)
+
+		first := statusMessage(idl.Substep_UPGRADE_MASTER, idl.Status_RUNNING)
+		if err := stream.SendMsg(first); err != nil {
+			t.Fatalf("SendMsg() returned error %+v", err)
+		}
+
+		// The step carries on after its client has gone.
+		client.gone = true
+		second := chunkMessage("upgrading master\n")
+		if err := stream.SendMsg(second); err != nil {
+			t.Errorf("SendMsg() to a detached client returned error %+v", err)
+		}
+
+		sent := make(chan *idl.Message, 10)
+		result := make(chan error)
+		go func() {
+			result <- s.Watch(&idl.WatchRequest{}, watchStream(ctrl, context.Background(), sent))
+		}()
+
+		for _, expected := range []*idl.Message{first, second} {
+			if msg := <-sent; !proto.Equal(msg, expected) {
+				t.Errorf("replayed %v, want %v", msg, expected)
+			}
+		}
+
+		third := statusMessage(idl.Substep_UPGRADE_MASTER, idl.Status_FAILED)
+		stream.SendMsg(third)
+		if msg := <-sent; msg != third {
+			t.Errorf("relayed %v, want %v", msg, third)
+		}
+
+		expected := errors.New("pg_upgrade failed")
+		stream.recording.finish(expected)
+		if err := <-result; err != expected {
+			t.Errorf("Watch() returned %#v, want the step's result %#v", err, expected)
+		}
+
+		if !reflect.DeepEqual(client.sent, []interface{}{first}) {
+			t.Errorf("client was sent %v", client.sent)
+		}
+	})
+
+	t.Run("replays a finished step", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := &Server{}
+		stream := recordStep(t, s, cliToHubPrefix+"Execute", &clientStream{})
+
+		msg := statusMessage(idl.Substep_START_TARGET_CLUSTER, idl.Status_COMPLETE)
+		stream.SendMsg(msg)
+		stream.recording.finish(nil)
+
+		sent := make(chan *idl.Message, 10)
+		if err := s.Watch(&idl.WatchRequest{}, watchStream(ctrl, context.Background(), sent)); err != nil {
+			t.Errorf("Watch() returned error %+v", err)
+		}
+
+		if len(sent) != 1 || <-sent != msg {
+			t.Errorf("replayed %d messages, want only %v", len(sent), msg)
+		}
+	})
+}
+
+func TestStepRecording(t *testing.T) {
+	t.Run("keeps statuses but omits the oldest output beyond ReplayBytes", func(t *testing.T) {
+		r := newStepRecording(1, "execute")
+
+		running := statusMessage(idl.Substep_UPGRADE_PRIMARIES, idl.Status_RUNNING)
+		r.record(running)
+
+		output := string(bytes.Repeat([]byte("x"), 1<<20))
+		for i := 0; i < 2*ReplayBytes>>20; i++ {
+			r.record(chunkMessage(output))
+		}
+
+		complete := statusMessage(idl.Substep_UPGRADE_PRIMARIES, idl.Status_COMPLETE)
+		r.record(complete)
+
+		history, w := r.watch()
+		if w == nil {
+			t.Fatal("expected a watcher for a running step")
+		}
+		defer r.unwatch(w)
+
+		if r.size > ReplayBytes {
+			t.Errorf("kept %d bytes of output, want at most %d", r.size, ReplayBytes)
+		}
+
+		notice := history[0].GetChunk()
+		if notice == nil || !bytes.Contains(notice.Buffer, []byte("bytes of earlier output omitted")) {
+			t.Errorf("history starts with %v, want a notice of omitted output", history[0])
+		}
+
+		if history[1] != running || history[len(history)-1] != complete {
+			t.Errorf("statuses were not kept in order")
+		}
+	})
+
+	t.Run("bounds the other messages once they alone fill ReplayBytes", func(t *testing.T) {
+		r := newStepRecording(1, "execute")
+
+		running := statusMessage(idl.Substep_UPGRADE_PRIMARIES, idl.Status_RUNNING)
+		for i := 0; i < ReplayBytes/proto.Size(running)+1; i++ {
+			r.record(running)
+		}
+
+		if r.size > ReplayBytes || r.omittedOther == 0 {
+			t.Errorf("kept %d bytes, omitting %d messages; want at most %d bytes", r.size, r.omittedOther, ReplayBytes)
+		}
+
+		history, w := r.watch()
+		defer r.unwatch(w)
+
+		notice := history[0].GetChunk()
+		if notice == nil || !bytes.Contains(notice.Buffer, []byte("other messages omitted")) {
+			t.Errorf("history starts with %v, want a notice of omitted messages", history[0])
+		}
+	})
+
+	t.Run("records a copy of output whose buffer is reused", func(t *testing.T) {
+		r := newStepRecording(1, "execute")
+
+		buf := []byte("first\n")
+		r.record(&idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{Buffer: buf, Type: idl.Chunk_STDOUT}}})
+		copy(buf, "other\n")
+		r.record(&idl.Message{Contents: &idl.Message_Chunk{&idl.Chunk{Buffer: buf, Type: idl.Chunk_STDOUT}}})
+
+		history, w := r.watch()
+		defer r.unwatch(w)
+
+		var replayed []string
+		for _, msg := range history {
+			replayed = append(replayed, string(msg.GetChunk().Buffer))
+		}
+
+		expected := []string{"first\n", "other\n"}
+		if !reflect.DeepEqual(replayed, expected) {
+			t.Errorf("replayed %q, want %q", replayed, expected)
+		}
+	})
+
+	t.Run("disconnects watchers that fall behind", func(t *testing.T) {
+		r := newStepRecording(1, "execute")
+
+		_, w := r.watch()
+		for i := 0; i <= watcherBacklog; i++ {
+			r.record(chunkMessage("output\n"))
+		}
+
+		for range w.messages {
+		}
+		if !w.lagged {
+			t.Errorf("watcher was not marked as lagging")
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+// WavesFileName is the file in the state directory that records the waves in
+// which execute upgrades the primary segments, and how far it has got. It is
+// removed once every wave has been upgraded.
+const WavesFileName = "waves.json"
+
+// WavesSchemaVersion is the version of the waves.json format written by this
+// version of gpupgrade.
+//
+// History:
+//   1: the first version.
+const WavesSchemaVersion = 1
+
+var wavesSchema = schema.NewRegistry(WavesFileName, WavesSchemaVersion)
+
+// WaveOptions choose how the primary segments are split into waves. The
+// segments with the given Contents or on the given Hosts are upgraded first,
+// as a canary wave. The remaining segments follow in waves of BatchSize, or
+// all together if BatchSize is zero. With Pause, execute stops after each
+// wave but the last, and continues with the next wave when it is run again.
+// The target cluster is started only after the last wave, so a paused upgrade
+// can be checked only through pg_upgrade's logs and reports.
+type WaveOptions struct {
+	Contents  []int    `json:",omitempty"`
+	Hosts     []string `json:",omitempty"`
+	BatchSize int      `json:",omitempty"`
+	Pause     bool     `json:",omitempty"`
+}
+
+func WaveOptionsFromRequest(request *idl.ExecuteRequest) WaveOptions {
+	var opts WaveOptions
+
+	for _, c := range request.GetContents() {
+		opts.Contents = append(opts.Contents, int(c))
+	}
+	opts.Hosts = request.GetHosts()
+	opts.BatchSize = int(request.GetBatchSize())
+	opts.Pause = request.GetPause()
+
+	return opts
+}
+
+// IsZero returns true if no options were given, in which case every segment is
+// upgraded in a single wave.
+func (o WaveOptions) IsZero() bool {
+	return len(o.Contents) == 0 && len(o.Hosts) == 0 && o.BatchSize == 0 && !o.Pause
+}
+
+func (o WaveOptions) String() string {
+	if o.IsZero() {
+		return "no options"
+	}
+
+	s := fmt.Sprintf("contents %v, hosts %v, batch size %d", o.Contents, o.Hosts, o.BatchSize)
+	if o.Pause {
+		s += ", pausing between waves"
+	}
+
+	return s
+}
+
+// Wave is a set of primary segments, identified by content ID, that are
+// upgraded together.
+type Wave struct {
+	Contents []int
+	Status   step.PrettyStatus
+}
+
+// WavePlan is the serialized form of waves.json. The plan is made on the first
+// run of execute and is kept until every wave has been upgraded.
+type WavePlan struct {
+	SchemaVersion int
+	Options       WaveOptions
+	Waves         []Wave
+}
+
+// PlanWaves splits the primary segments into waves as described by opts.
+func PlanWaves(dataDirPairMap map[string][]*idl.DataDirPair, opts WaveOptions) ([]Wave, error) {
+	if opts.BatchSize < 0 {
+		return nil, xerrors.Errorf("batch size %d must not be negative", opts.BatchSize)
+	}
+
+	hosts := make(map[int]string)
+	for host, pairs := range dataDirPairMap {
+		for _, pair := range pairs {
+			hosts[int(pair.Content)] = host
+		}
+	}
+
+	canary := make(map[int]bool)
+	for _, content := range opts.Contents {
+		if _, ok := hosts[content]; !ok {
+			return nil, xerrors.Errorf("content %d is not a primary segment", content)
+		}
+		canary[content] = true
+	}
+
+	for _, host := range opts.Hosts {
+		pairs, ok := dataDirPairMap[host]
+		if !ok {
+			return nil, xerrors.Errorf("host %q has no primary segments", host)
+		}
+		for _, pair := range pairs {
+			canary[int(pair.Content)] = true
+		}
+	}
+
+	var first, rest []int
+	for content := range hosts {
+		if canary[content] {
+			first = append(first, content)
+		} else {
+			rest = append(rest, content)
+		}
+	}
+	sort.Ints(first)
+	sort.Ints(rest)
+
+	var waves []Wave
+	if len(first) > 0 {
+		waves = append(waves, Wave{Contents: first})
+	}
+
+	size := opts.BatchSize
+	if size == 0 {
+		size = len(rest)
+	}
+	for len(rest) > 0 {
+		end := size
+		if end > len(rest) {
+			end = len(rest)
+		}
+
+		waves = append(waves, Wave{Contents: rest[:end]})
+		rest = rest[end:]
+	}
+
+	return waves, nil
+}
+
+// LoadWavePlan returns the plan saved in the state directory, or makes and
+// saves a new one if there is none. A saved plan cannot be changed: opts must
+// either match the options it was made with or be empty, and its waves must
+// hold the primary segments in dataDirPairMap.
+func LoadWavePlan(stateDir string, dataDirPairMap map[string][]*idl.DataDirPair, opts WaveOptions) (*WavePlan, error) {
+	plan, err := readWavePlan(stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan == nil {
+		waves, err := PlanWaves(dataDirPairMap, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		plan := &WavePlan{Options: opts, Waves: waves}
+		return plan, plan.Save(stateDir)
+	}
+
+	if !opts.IsZero() && !reflect.DeepEqual(opts, plan.Options) {
+		return nil, xerrors.Errorf("the segments were already split into waves with %s; run execute with those options or none to continue", plan.Options)
+	}
+
+	if err := plan.check(dataDirPairMap); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// check returns an error unless the plan's waves hold exactly the primary
+// segments in dataDirPairMap, so that a stale plan does not leave segments out.
+func (p *WavePlan) check(dataDirPairMap map[string][]*idl.DataDirPair) error {
+	var planned, primaries []int
+	for _, wave := range p.Waves {
+		planned = append(planned, wave.Contents...)
+	}
+	for _, pairs := range dataDirPairMap {
+		for _, pair := range pairs {
+			primaries = append(primaries, int(pair.Content))
+		}
+	}
+	sort.Ints(planned)
+	sort.Ints(primaries)
+
+	if !reflect.DeepEqual(planned, primaries) {
+		return xerrors.Errorf("the saved waves hold contents %v, but the primary segments are contents %v; remove %s from the state directory to plan the waves again",
+			planned, primaries, WavesFileName)
+	}
+
+	return nil
+}
+
+// readWavePlan returns the plan saved in the state directory, or nil if there
+// is none.
+func readWavePlan(stateDir string) (*WavePlan, error) {
+	path := filepath.Join(stateDir, WavesFileName)
+
+	doc, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = wavesSchema.Migrate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &WavePlan{}
+	if err := json.Unmarshal(doc, plan); err != nil {
+		return nil, xerrors.Errorf("reading %s: %w", path, err)
+	}
+
+	return plan, nil
+}
+
+// Save atomically writes the plan to the state directory.
+func (p *WavePlan) Save(stateDir string) error {
+	p.SchemaVersion = WavesSchemaVersion
+
+	path := filepath.Join(stateDir, WavesFileName)
+	return utils.AtomicallyWrite(path, 0600, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	})
+}
+
+// upgradeWaves upgrades the primary segments one wave at a time, recording the
+// status of each wave in the plan. Waves that completed on an earlier run are
+// skipped. If the plan pauses between waves, step.ErrPaused is returned after
+// each wave but the last. The plan is removed once every wave is complete.
+func (s *Server) upgradeWaves(streams step.OutStreams, args UpgradePrimaryArgs, opts WaveOptions) error {
+	plan, err := LoadWavePlan(s.StateDir, args.DataDirPairMap, opts)
+	if err != nil {
+		return xerrors.Errorf("planning segment upgrade waves: %w", err)
+	}
+
+	all := args.DataDirPairMap
+	for i := range plan.Waves {
+		wave := &plan.Waves[i]
+		if wave.Status.Status == idl.Status_COMPLETE {
+			continue
+		}
+
+		_, err := fmt.Fprintf(streams.Stdout(), "upgrading wave %d of %d: contents %v\n", i+1, len(plan.Waves), wave.Contents)
+		if err != nil {
+			return err
+		}
+
+		if err := s.setWaveStatus(plan, wave, idl.Status_RUNNING); err != nil {
+			return err
+		}
+
+		args.DataDirPairMap = filterPairs(all, wave.Contents)
+		if err := UpgradePrimaries(args); err != nil {
+			if serr := s.setWaveStatus(plan, wave, idl.Status_FAILED); serr != nil {
+				gplog.Error("recording status of wave %d: %v", i+1, serr)
+			}
+			return xerrors.Errorf("upgrading wave %d of %d: %w", i+1, len(plan.Waves), err)
+		}
+
+		if err := s.setWaveStatus(plan, wave, idl.Status_COMPLETE); err != nil {
+			return err
+		}
+
+		if plan.Options.Pause && i < len(plan.Waves)-1 {
+			return xerrors.Errorf("after wave %d of %d: %w", i+1, len(plan.Waves), step.ErrPaused)
+		}
+	}
+
+	// Every wave is complete, so the plan is done with.
+	err = os.Remove(filepath.Join(s.StateDir, WavesFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("removing segment upgrade waves: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) setWaveStatus(plan *WavePlan, wave *Wave, status idl.Status) error {
+	wave.Status = step.PrettyStatus{Status: status}
+
+	if err := plan.Save(s.StateDir); err != nil {
+		return xerrors.Errorf("saving segment upgrade waves: %w", err)
+	}
+
+	return nil
+}
+
+// filterPairs returns the data directory pairs for the given content IDs.
+func filterPairs(dataDirPairMap map[string][]*idl.DataDirPair, contents []int) map[string][]*idl.DataDirPair {
+	want := make(map[int32]bool, len(contents))
+	for _, c := range contents {
+		want[int32(c)] = true
+	}
+
+	filtered := make(map[string][]*idl.DataDirPair)
+	for host, pairs := range dataDirPairMap {
+		for _, pair := range pairs {
+			if want[pair.Content] {
+				filtered[host] = append(filtered[host], pair)
+			}
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,162 @@
+package hub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/step"
+)
+
+func TestPlanWaves(t *testing.T) {
+	pairs := map[string][]*idl.DataDirPair{
+		"sdw1": {{Content: 0}, {Content: 1}},
+		"sdw2": {{Content: 2}, {Content: 3}},
+		"sdw3": {{Content: 4}},
+	}
+
+	cases := []struct {
+		name     string
+		opts     WaveOptions
+		expected [][]int
+	}{
+		{"upgrades every segment at once by default", WaveOptions{}, [][]int{{0, 1, 2, 3, 4}}},
+		{"upgrades the chosen contents first", WaveOptions{Contents: []int{3, 0}}, [][]int{{0, 3}, {1, 2, 4}}},
+		{"upgrades the chosen hosts first", WaveOptions{Hosts: []string{"sdw2"}}, [][]int{{2, 3}, {0, 1, 4}}},
+		{"combines contents and hosts", WaveOptions{Contents: []int{4}, Hosts: []string{"sdw1"}}, [][]int{{0, 1, 4}, {2, 3}}},
+		{"splits the rest into batches", WaveOptions{Contents: []int{1}, BatchSize: 3}, [][]int{{1}, {0, 2, 3}, {4}}},
+		{"splits every segment into batches", WaveOptions{BatchSize: 2}, [][]int{{0, 1}, {2, 3}, {4}}},
+		{"needs no second wave when every segment is chosen", WaveOptions{Hosts: []string{"sdw1", "sdw2", "sdw3"}}, [][]int{{0, 1, 2, 3, 4}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			waves, err := PlanWaves(pairs, c.opts)
+			if err != nil {
+				t.Fatalf("PlanWaves() returned error %+v", err)
+			}
+
+			var actual [][]int
+			for _, w := range waves {
+				actual = append(actual, w.Contents)
+			}
+
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("got waves %v, want %v", actual, c.expected)
+			}
+		})
+	}
+
+	errCases := []struct {
+		name     string
+		opts     WaveOptions
+		expected string
+	}{
+		{"rejects unknown contents", WaveOptions{Contents: []int{-1}}, "content -1 is not a primary segment"},
+		{"rejects unknown hosts", WaveOptions{Hosts: []string{"mdw"}}, `host "mdw" has no primary segments`},
+		{"rejects negative batch sizes", WaveOptions{BatchSize: -1}, "batch size -1 must not be negative"},
+	}
+
+	for _, c := range errCases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := PlanWaves(pairs, c.opts)
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("returned error %v, want %q", err, c.expected)
+			}
+		})
+	}
+}
+
+func TestUpgradeWaves(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	s := &Server{StateDir: stateDir}
+
+	// Without agent connections no segment is actually upgraded.
+	args := UpgradePrimaryArgs{
+		DataDirPairMap: map[string][]*idl.DataDirPair{
+			"sdw1": {{Content: 0}, {Content: 1}},
+			"sdw2": {{Content: 2}},
+		},
+	}
+	opts := WaveOptions{Contents: []int{1}, BatchSize: 1, Pause: true}
+
+	expectStatuses := func(t *testing.T, expected ...idl.Status) {
+		t.Helper()
+
+		plan, err := LoadWavePlan(stateDir, args.DataDirPairMap, WaveOptions{})
+		if err != nil {
+			t.Fatalf("LoadWavePlan() returned error %+v", err)
+		}
+
+		var actual []idl.Status
+		for _, w := range plan.Waves {
+			actual = append(actual, w.Status.Status)
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got wave statuses %v, want %v", actual, expected)
+		}
+	}
+
+	t.Run("pauses after the first wave", func(t *testing.T) {
+		err := s.upgradeWaves(DevNull, args, opts)
+		if !xerrors.Is(err, step.ErrPaused) {
+			t.Errorf("returned error %#v, want %#v", err, step.ErrPaused)
+		}
+
+		expectStatuses(t, idl.Status_COMPLETE, idl.Status_UNKNOWN_STATUS, idl.Status_UNKNOWN_STATUS)
+	})
+
+	t.Run("refuses to change the waves once planned", func(t *testing.T) {
+		err := s.upgradeWaves(DevNull, args, WaveOptions{BatchSize: 2})
+		if err == nil || !strings.Contains(err.Error(), "already split into waves") {
+			t.Errorf("returned error %v", err)
+		}
+	})
+
+	t.Run("refuses a plan for other segments", func(t *testing.T) {
+		other := map[string][]*idl.DataDirPair{"sdw1": {{Content: 0}, {Content: 1}}}
+
+		_, err := LoadWavePlan(stateDir, other, WaveOptions{})
+		if err == nil || !strings.Contains(err.Error(), "the saved waves hold contents [0 1 2]") {
+			t.Errorf("returned error %v", err)
+		}
+	})
+
+	t.Run("continues with the next wave when run again", func(t *testing.T) {
+		err := s.upgradeWaves(DevNull, args, WaveOptions{})
+		if !xerrors.Is(err, step.ErrPaused) {
+			t.Errorf("returned error %#v, want %#v", err, step.ErrPaused)
+		}
+
+		expectStatuses(t, idl.Status_COMPLETE, idl.Status_COMPLETE, idl.Status_UNKNOWN_STATUS)
+	})
+
+	t.Run("saves the plan in the state directory", func(t *testing.T) {
+		if _, err := os.Stat(filepath.Join(stateDir, WavesFileName)); err != nil {
+			t.Errorf("stat returned error %+v", err)
+		}
+	})
+
+	t.Run("does not pause after the last wave, and then removes the plan", func(t *testing.T) {
+		err := s.upgradeWaves(DevNull, args, opts)
+		if err != nil {
+			t.Errorf("returned error %+v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(stateDir, WavesFileName)); !os.IsNotExist(err) {
+			t.Errorf("stat returned error %+v, want the plan to be removed", err)
+		}
+	})
+}
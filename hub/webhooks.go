@@ -0,0 +1,53 @@
+package hub
+
+import (
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+	"github.com/greenplum-db/gpupgrade/utils/webhook"
+)
+
+// webhookNotifier implements step.Notifier by posting each status change to
+// the configured webhooks.
+type webhookNotifier struct {
+	sender *webhook.Sender
+	audit  *audit.Log // the head of which is sent with each event, if set
+}
+
+// webhookNotifier returns a notifier for the configured webhooks, or nil if
+// there are none.
+func (s *Server) webhookNotifier() *webhookNotifier {
+	if len(s.Webhooks) == 0 {
+		return nil
+	}
+
+	return &webhookNotifier{
+		sender: &webhook.Sender{
+			URLs:   s.Webhooks,
+			Secret: s.WebhookSecret,
+			Format: s.WebhookFormat,
+			Log:    gplog.Warn,
+		},
+		audit: s.audit,
+	}
+}
+
+func (n *webhookNotifier) Notify(step string, substep idl.Substep, status idl.Status) {
+	e := webhook.Event{
+		Step:   step,
+		Status: status.String(),
+		Time:   time.Now().UTC(),
+	}
+	if substep != idl.Substep_UNKNOWN_STEP {
+		e.Substep = substep.String()
+	}
+	if n.audit != nil {
+		head := n.audit.Head()
+		e.AuditSeq, e.AuditHash = head.Seq, head.Hash
+	}
+
+	n.sender.Send(e)
+}
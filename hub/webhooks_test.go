@@ -0,0 +1,85 @@
+package hub
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/audit"
+	"github.com/greenplum-db/gpupgrade/utils/webhook"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var events []webhook.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		var e webhook.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("decoding %q: %+v", body, err)
+		}
+		events = append(events, e)
+	}))
+	defer server.Close()
+
+	t.Run("is not created without webhooks", func(t *testing.T) {
+		s := &Server{Config: &Config{}}
+		if n := s.webhookNotifier(); n != nil {
+			t.Errorf("got notifier %#v", n)
+		}
+	})
+
+	t.Run("posts substep and step status changes", func(t *testing.T) {
+		s := &Server{Config: &Config{Webhooks: []string{server.URL}}}
+
+		n := s.webhookNotifier()
+		n.Notify("execute", idl.Substep_UPGRADE_MASTER, idl.Status_FAILED)
+		n.Notify("execute", idl.Substep_UNKNOWN_STEP, idl.Status_FAILED)
+		n.sender.Wait()
+
+		if len(events) != 2 {
+			t.Fatalf("got events %+v, want 2", events)
+		}
+
+		if e := events[0]; e.Step != "execute" || e.Substep != "UPGRADE_MASTER" || e.Status != "FAILED" || e.Time.IsZero() {
+			t.Errorf("got substep event %+v", e)
+		}
+
+		if e := events[1]; e.Step != "execute" || e.Substep != "" || e.Status != "FAILED" {
+			t.Errorf("got step event %+v", e)
+		}
+	})
+
+	t.Run("sends the head of the audit log", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "gpupgrade")
+		if err != nil {
+			t.Fatalf("creating temporary directory: %+v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		log, err := audit.Open(filepath.Join(dir, AuditLogFileName))
+		if err != nil {
+			t.Fatalf("Open() returned error %+v", err)
+		}
+		if err := log.Append(audit.Entry{Method: "Execute"}); err != nil {
+			t.Fatalf("Append() returned error %+v", err)
+		}
+
+		events = nil
+		s := &Server{Config: &Config{Webhooks: []string{server.URL}}, audit: log}
+
+		n := s.webhookNotifier()
+		n.Notify("execute", idl.Substep_UPGRADE_MASTER, idl.Status_RUNNING)
+		n.sender.Wait()
+
+		head := log.Head()
+		if len(events) != 1 || events[0].AuditSeq != 1 || events[0].AuditHash != head.Hash {
+			t.Errorf("got events %+v, want the audit log head %v", events, head)
+		}
+	})
+}
@@ -46,6 +46,9 @@ const (
 	Substep_FINALIZE_UPDATE_POSTGRESQL_CONF   Substep = 17
 	Substep_FINALIZE_START_TARGET_CLUSTER     Substep = 18
 	Substep_FINALIZE_UPGRADE_STANDBY          Substep = 19
+	Substep_CARRY_OVER_CONFIGURATION          Substep = 20
+	Substep_VERIFY_MASTER_COPY                Substep = 21
+	Substep_MEASURE_SOURCE_CLUSTER            Substep = 22
 )
 
 var Substep_name = map[int32]string{
@@ -69,6 +72,9 @@ var Substep_name = map[int32]string{
 	17: "FINALIZE_UPDATE_POSTGRESQL_CONF",
 	18: "FINALIZE_START_TARGET_CLUSTER",
 	19: "FINALIZE_UPGRADE_STANDBY",
+	20: "CARRY_OVER_CONFIGURATION",
+	21: "VERIFY_MASTER_COPY",
+	22: "MEASURE_SOURCE_CLUSTER",
 }
 var Substep_value = map[string]int32{
 	"UNKNOWN_STEP":                      0,
@@ -91,13 +97,16 @@ var Substep_value = map[string]int32{
 	"FINALIZE_UPDATE_POSTGRESQL_CONF":   17,
 	"FINALIZE_START_TARGET_CLUSTER":     18,
 	"FINALIZE_UPGRADE_STANDBY":          19,
+	"CARRY_OVER_CONFIGURATION":          20,
+	"VERIFY_MASTER_COPY":                21,
+	"MEASURE_SOURCE_CLUSTER":            22,
 }
 
 func (x Substep) String() string {
 	return proto.EnumName(Substep_name, int32(x))
 }
 func (Substep) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{0}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{0}
 }
 
 type Status int32
@@ -107,6 +116,7 @@ const (
 	Status_RUNNING        Status = 1
 	Status_COMPLETE       Status = 2
 	Status_FAILED         Status = 3
+	Status_PAUSED         Status = 4
 )
 
 var Status_name = map[int32]string{
@@ -114,19 +124,21 @@ var Status_name = map[int32]string{
 	1: "RUNNING",
 	2: "COMPLETE",
 	3: "FAILED",
+	4: "PAUSED",
 }
 var Status_value = map[string]int32{
 	"UNKNOWN_STATUS": 0,
 	"RUNNING":        1,
 	"COMPLETE":       2,
 	"FAILED":         3,
+	"PAUSED":         4,
 }
 
 func (x Status) String() string {
 	return proto.EnumName(Status_name, int32(x))
 }
 func (Status) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{1}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{1}
 }
 
 type Chunk_Type int32
@@ -152,7 +164,42 @@ func (x Chunk_Type) String() string {
 	return proto.EnumName(Chunk_Type_name, int32(x))
 }
 func (Chunk_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{15, 0}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{19, 0}
+}
+
+type SegmentProgress_Phase int32
+
+const (
+	SegmentProgress_UNKNOWN_PHASE  SegmentProgress_Phase = 0
+	SegmentProgress_RESTORE_BACKUP SegmentProgress_Phase = 1
+	SegmentProgress_CHECK          SegmentProgress_Phase = 2
+	SegmentProgress_UPGRADE        SegmentProgress_Phase = 3
+	SegmentProgress_DONE           SegmentProgress_Phase = 4
+	SegmentProgress_FAILED         SegmentProgress_Phase = 5
+)
+
+var SegmentProgress_Phase_name = map[int32]string{
+	0: "UNKNOWN_PHASE",
+	1: "RESTORE_BACKUP",
+	2: "CHECK",
+	3: "UPGRADE",
+	4: "DONE",
+	5: "FAILED",
+}
+var SegmentProgress_Phase_value = map[string]int32{
+	"UNKNOWN_PHASE":  0,
+	"RESTORE_BACKUP": 1,
+	"CHECK":          2,
+	"UPGRADE":        3,
+	"DONE":           4,
+	"FAILED":         5,
+}
+
+func (x SegmentProgress_Phase) String() string {
+	return proto.EnumName(SegmentProgress_Phase_name, int32(x))
+}
+func (SegmentProgress_Phase) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{20, 0}
 }
 
 type InitializeRequest struct {
@@ -161,6 +208,7 @@ type InitializeRequest struct {
 	SourcePort           int32    `protobuf:"varint,3,opt,name=sourcePort" json:"sourcePort,omitempty"`
 	UseLinkMode          bool     `protobuf:"varint,4,opt,name=useLinkMode" json:"useLinkMode,omitempty"`
 	Ports                []uint32 `protobuf:"varint,5,rep,packed,name=ports" json:"ports,omitempty"`
+	CopyEngine           string   `protobuf:"bytes,6,opt,name=copyEngine" json:"copyEngine,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -170,7 +218,7 @@ func (m *InitializeRequest) Reset()         { *m = InitializeRequest{} }
 func (m *InitializeRequest) String() string { return proto.CompactTextString(m) }
 func (*InitializeRequest) ProtoMessage()    {}
 func (*InitializeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{0}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{0}
 }
 func (m *InitializeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InitializeRequest.Unmarshal(m, b)
@@ -225,6 +273,13 @@ func (m *InitializeRequest) GetPorts() []uint32 {
 	return nil
 }
 
+func (m *InitializeRequest) GetCopyEngine() string {
+	if m != nil {
+		return m.CopyEngine
+	}
+	return ""
+}
+
 type InitializeCreateClusterRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -235,7 +290,7 @@ func (m *InitializeCreateClusterRequest) Reset()         { *m = InitializeCreate
 func (m *InitializeCreateClusterRequest) String() string { return proto.CompactTextString(m) }
 func (*InitializeCreateClusterRequest) ProtoMessage()    {}
 func (*InitializeCreateClusterRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{1}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{1}
 }
 func (m *InitializeCreateClusterRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InitializeCreateClusterRequest.Unmarshal(m, b)
@@ -255,7 +310,15 @@ func (m *InitializeCreateClusterRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_InitializeCreateClusterRequest proto.InternalMessageInfo
 
+// ExecuteRequest splits the primary segment upgrade into waves. The segments
+// named by Contents and Hosts are upgraded first, as a canary wave; the rest
+// follow in waves of BatchSize segments, or all at once if BatchSize is zero.
+// With Pause set, execute stops after each wave until it is run again.
 type ExecuteRequest struct {
+	Contents             []int32  `protobuf:"varint,1,rep,packed,name=Contents" json:"Contents,omitempty"`
+	Hosts                []string `protobuf:"bytes,2,rep,name=Hosts" json:"Hosts,omitempty"`
+	BatchSize            int32    `protobuf:"varint,3,opt,name=BatchSize" json:"BatchSize,omitempty"`
+	Pause                bool     `protobuf:"varint,4,opt,name=Pause" json:"Pause,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -265,7 +328,7 @@ func (m *ExecuteRequest) Reset()         { *m = ExecuteRequest{} }
 func (m *ExecuteRequest) String() string { return proto.CompactTextString(m) }
 func (*ExecuteRequest) ProtoMessage()    {}
 func (*ExecuteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{2}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{2}
 }
 func (m *ExecuteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecuteRequest.Unmarshal(m, b)
@@ -285,6 +348,34 @@ func (m *ExecuteRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ExecuteRequest proto.InternalMessageInfo
 
+func (m *ExecuteRequest) GetContents() []int32 {
+	if m != nil {
+		return m.Contents
+	}
+	return nil
+}
+
+func (m *ExecuteRequest) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
+}
+
+func (m *ExecuteRequest) GetBatchSize() int32 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func (m *ExecuteRequest) GetPause() bool {
+	if m != nil {
+		return m.Pause
+	}
+	return false
+}
+
 type FinalizeRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -295,7 +386,7 @@ func (m *FinalizeRequest) Reset()         { *m = FinalizeRequest{} }
 func (m *FinalizeRequest) String() string { return proto.CompactTextString(m) }
 func (*FinalizeRequest) ProtoMessage()    {}
 func (*FinalizeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{3}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{3}
 }
 func (m *FinalizeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_FinalizeRequest.Unmarshal(m, b)
@@ -315,6 +406,217 @@ func (m *FinalizeRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_FinalizeRequest proto.InternalMessageInfo
 
+// WatchRequest selects the job to watch; zero selects the most recent.
+type WatchRequest struct {
+	Job                  int32    `protobuf:"varint,1,opt,name=Job" json:"Job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{4}
+}
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (dst *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(dst, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetJob() int32 {
+	if m != nil {
+		return m.Job
+	}
+	return 0
+}
+
+type JobsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobsRequest) Reset()         { *m = JobsRequest{} }
+func (m *JobsRequest) String() string { return proto.CompactTextString(m) }
+func (*JobsRequest) ProtoMessage()    {}
+func (*JobsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{5}
+}
+func (m *JobsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobsRequest.Unmarshal(m, b)
+}
+func (m *JobsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobsRequest.Marshal(b, m, deterministic)
+}
+func (dst *JobsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobsRequest.Merge(dst, src)
+}
+func (m *JobsRequest) XXX_Size() int {
+	return xxx_messageInfo_JobsRequest.Size(m)
+}
+func (m *JobsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobsRequest proto.InternalMessageInfo
+
+type JobsReply struct {
+	Jobs                 []*Job   `protobuf:"bytes,1,rep,name=Jobs" json:"Jobs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobsReply) Reset()         { *m = JobsReply{} }
+func (m *JobsReply) String() string { return proto.CompactTextString(m) }
+func (*JobsReply) ProtoMessage()    {}
+func (*JobsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{6}
+}
+func (m *JobsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobsReply.Unmarshal(m, b)
+}
+func (m *JobsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobsReply.Marshal(b, m, deterministic)
+}
+func (dst *JobsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobsReply.Merge(dst, src)
+}
+func (m *JobsReply) XXX_Size() int {
+	return xxx_messageInfo_JobsReply.Size(m)
+}
+func (m *JobsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobsReply proto.InternalMessageInfo
+
+func (m *JobsReply) GetJobs() []*Job {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
+// Job is a run of a step. Its Status is RUNNING until the step finishes, and
+// then COMPLETE, FAILED or PAUSED. Times are in seconds since the Unix epoch.
+type Job struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID" json:"ID,omitempty"`
+	Step                 string   `protobuf:"bytes,2,opt,name=Step" json:"Step,omitempty"`
+	Status               Status   `protobuf:"varint,3,opt,name=Status,enum=idl.Status" json:"Status,omitempty"`
+	Substep              Substep  `protobuf:"varint,4,opt,name=Substep,enum=idl.Substep" json:"Substep,omitempty"`
+	SubstepStatus        Status   `protobuf:"varint,5,opt,name=SubstepStatus,enum=idl.Status" json:"SubstepStatus,omitempty"`
+	StartTime            int64    `protobuf:"varint,6,opt,name=StartTime" json:"StartTime,omitempty"`
+	EndTime              int64    `protobuf:"varint,7,opt,name=EndTime" json:"EndTime,omitempty"`
+	Error                string   `protobuf:"bytes,8,opt,name=Error" json:"Error,omitempty"`
+	Detached             bool     `protobuf:"varint,9,opt,name=Detached" json:"Detached,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Job) Reset()         { *m = Job{} }
+func (m *Job) String() string { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()    {}
+func (*Job) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{7}
+}
+func (m *Job) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Job.Unmarshal(m, b)
+}
+func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+}
+func (dst *Job) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Job.Merge(dst, src)
+}
+func (m *Job) XXX_Size() int {
+	return xxx_messageInfo_Job.Size(m)
+}
+func (m *Job) XXX_DiscardUnknown() {
+	xxx_messageInfo_Job.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Job proto.InternalMessageInfo
+
+func (m *Job) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *Job) GetStep() string {
+	if m != nil {
+		return m.Step
+	}
+	return ""
+}
+
+func (m *Job) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_UNKNOWN_STATUS
+}
+
+func (m *Job) GetSubstep() Substep {
+	if m != nil {
+		return m.Substep
+	}
+	return Substep_UNKNOWN_STEP
+}
+
+func (m *Job) GetSubstepStatus() Status {
+	if m != nil {
+		return m.SubstepStatus
+	}
+	return Status_UNKNOWN_STATUS
+}
+
+func (m *Job) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *Job) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *Job) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Job) GetDetached() bool {
+	if m != nil {
+		return m.Detached
+	}
+	return false
+}
+
 type RestartAgentsRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -325,7 +627,7 @@ func (m *RestartAgentsRequest) Reset()         { *m = RestartAgentsRequest{} }
 func (m *RestartAgentsRequest) String() string { return proto.CompactTextString(m) }
 func (*RestartAgentsRequest) ProtoMessage()    {}
 func (*RestartAgentsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{4}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{8}
 }
 func (m *RestartAgentsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RestartAgentsRequest.Unmarshal(m, b)
@@ -356,7 +658,7 @@ func (m *RestartAgentsReply) Reset()         { *m = RestartAgentsReply{} }
 func (m *RestartAgentsReply) String() string { return proto.CompactTextString(m) }
 func (*RestartAgentsReply) ProtoMessage()    {}
 func (*RestartAgentsReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{5}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{9}
 }
 func (m *RestartAgentsReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RestartAgentsReply.Unmarshal(m, b)
@@ -393,7 +695,7 @@ func (m *StopServicesRequest) Reset()         { *m = StopServicesRequest{} }
 func (m *StopServicesRequest) String() string { return proto.CompactTextString(m) }
 func (*StopServicesRequest) ProtoMessage()    {}
 func (*StopServicesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{6}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{10}
 }
 func (m *StopServicesRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopServicesRequest.Unmarshal(m, b)
@@ -423,7 +725,7 @@ func (m *StopServicesReply) Reset()         { *m = StopServicesReply{} }
 func (m *StopServicesReply) String() string { return proto.CompactTextString(m) }
 func (*StopServicesReply) ProtoMessage()    {}
 func (*StopServicesReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{7}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{11}
 }
 func (m *StopServicesReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopServicesReply.Unmarshal(m, b)
@@ -455,7 +757,7 @@ func (m *SubstepStatus) Reset()         { *m = SubstepStatus{} }
 func (m *SubstepStatus) String() string { return proto.CompactTextString(m) }
 func (*SubstepStatus) ProtoMessage()    {}
 func (*SubstepStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{8}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{12}
 }
 func (m *SubstepStatus) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SubstepStatus.Unmarshal(m, b)
@@ -499,7 +801,7 @@ func (m *CheckVersionRequest) Reset()         { *m = CheckVersionRequest{} }
 func (m *CheckVersionRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckVersionRequest) ProtoMessage()    {}
 func (*CheckVersionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{9}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{13}
 }
 func (m *CheckVersionRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckVersionRequest.Unmarshal(m, b)
@@ -530,7 +832,7 @@ func (m *CheckVersionReply) Reset()         { *m = CheckVersionReply{} }
 func (m *CheckVersionReply) String() string { return proto.CompactTextString(m) }
 func (*CheckVersionReply) ProtoMessage()    {}
 func (*CheckVersionReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{10}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{14}
 }
 func (m *CheckVersionReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckVersionReply.Unmarshal(m, b)
@@ -568,7 +870,7 @@ func (m *CheckDiskSpaceRequest) Reset()         { *m = CheckDiskSpaceRequest{} }
 func (m *CheckDiskSpaceRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckDiskSpaceRequest) ProtoMessage()    {}
 func (*CheckDiskSpaceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{11}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{15}
 }
 func (m *CheckDiskSpaceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckDiskSpaceRequest.Unmarshal(m, b)
@@ -606,7 +908,7 @@ func (m *CheckDiskSpaceReply) Reset()         { *m = CheckDiskSpaceReply{} }
 func (m *CheckDiskSpaceReply) String() string { return proto.CompactTextString(m) }
 func (*CheckDiskSpaceReply) ProtoMessage()    {}
 func (*CheckDiskSpaceReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{12}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{16}
 }
 func (m *CheckDiskSpaceReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckDiskSpaceReply.Unmarshal(m, b)
@@ -645,7 +947,7 @@ func (m *CheckDiskSpaceReply_DiskUsage) Reset()         { *m = CheckDiskSpaceRep
 func (m *CheckDiskSpaceReply_DiskUsage) String() string { return proto.CompactTextString(m) }
 func (*CheckDiskSpaceReply_DiskUsage) ProtoMessage()    {}
 func (*CheckDiskSpaceReply_DiskUsage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{12, 0}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{16, 0}
 }
 func (m *CheckDiskSpaceReply_DiskUsage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckDiskSpaceReply_DiskUsage.Unmarshal(m, b)
@@ -689,7 +991,7 @@ func (m *PrepareInitClusterRequest) Reset()         { *m = PrepareInitClusterReq
 func (m *PrepareInitClusterRequest) String() string { return proto.CompactTextString(m) }
 func (*PrepareInitClusterRequest) ProtoMessage()    {}
 func (*PrepareInitClusterRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{13}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{17}
 }
 func (m *PrepareInitClusterRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PrepareInitClusterRequest.Unmarshal(m, b)
@@ -719,7 +1021,7 @@ func (m *PrepareInitClusterReply) Reset()         { *m = PrepareInitClusterReply
 func (m *PrepareInitClusterReply) String() string { return proto.CompactTextString(m) }
 func (*PrepareInitClusterReply) ProtoMessage()    {}
 func (*PrepareInitClusterReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{14}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{18}
 }
 func (m *PrepareInitClusterReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PrepareInitClusterReply.Unmarshal(m, b)
@@ -751,7 +1053,7 @@ func (m *Chunk) Reset()         { *m = Chunk{} }
 func (m *Chunk) String() string { return proto.CompactTextString(m) }
 func (*Chunk) ProtoMessage()    {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{15}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{19}
 }
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Chunk.Unmarshal(m, b)
@@ -785,10 +1087,337 @@ func (m *Chunk) GetType() Chunk_Type {
 	return Chunk_UNKNOWN
 }
 
+// SegmentProgress reports that the upgrade of a primary segment has entered a
+// new phase. Agents report the Content and Phase; the hub adds the Host and the
+// Time, in seconds since the Unix epoch, at which it heard of the change.
+type SegmentProgress struct {
+	Host                 string                `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Content              int32                 `protobuf:"varint,2,opt,name=content" json:"content,omitempty"`
+	Phase                SegmentProgress_Phase `protobuf:"varint,3,opt,name=phase,enum=idl.SegmentProgress_Phase" json:"phase,omitempty"`
+	Time                 int64                 `protobuf:"varint,4,opt,name=time" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *SegmentProgress) Reset()         { *m = SegmentProgress{} }
+func (m *SegmentProgress) String() string { return proto.CompactTextString(m) }
+func (*SegmentProgress) ProtoMessage()    {}
+func (*SegmentProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{20}
+}
+func (m *SegmentProgress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SegmentProgress.Unmarshal(m, b)
+}
+func (m *SegmentProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SegmentProgress.Marshal(b, m, deterministic)
+}
+func (dst *SegmentProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SegmentProgress.Merge(dst, src)
+}
+func (m *SegmentProgress) XXX_Size() int {
+	return xxx_messageInfo_SegmentProgress.Size(m)
+}
+func (m *SegmentProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_SegmentProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SegmentProgress proto.InternalMessageInfo
+
+func (m *SegmentProgress) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *SegmentProgress) GetContent() int32 {
+	if m != nil {
+		return m.Content
+	}
+	return 0
+}
+
+func (m *SegmentProgress) GetPhase() SegmentProgress_Phase {
+	if m != nil {
+		return m.Phase
+	}
+	return SegmentProgress_UNKNOWN_PHASE
+}
+
+func (m *SegmentProgress) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+// Estimate predicts how long the substeps of execute that take longest will
+// run. Each substep has the Status it had when the estimate was made, and
+// Seconds is its actual duration if it is COMPLETE, or else its predicted
+// duration. Remaining is the predicted number of seconds until they have all
+// finished.
+type Estimate struct {
+	Substeps             []*Estimate_Substep `protobuf:"bytes,1,rep,name=substeps" json:"substeps,omitempty"`
+	Remaining            int64               `protobuf:"varint,2,opt,name=remaining" json:"remaining,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Estimate) Reset()         { *m = Estimate{} }
+func (m *Estimate) String() string { return proto.CompactTextString(m) }
+func (*Estimate) ProtoMessage()    {}
+func (*Estimate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{21}
+}
+func (m *Estimate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Estimate.Unmarshal(m, b)
+}
+func (m *Estimate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Estimate.Marshal(b, m, deterministic)
+}
+func (dst *Estimate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Estimate.Merge(dst, src)
+}
+func (m *Estimate) XXX_Size() int {
+	return xxx_messageInfo_Estimate.Size(m)
+}
+func (m *Estimate) XXX_DiscardUnknown() {
+	xxx_messageInfo_Estimate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Estimate proto.InternalMessageInfo
+
+func (m *Estimate) GetSubsteps() []*Estimate_Substep {
+	if m != nil {
+		return m.Substeps
+	}
+	return nil
+}
+
+func (m *Estimate) GetRemaining() int64 {
+	if m != nil {
+		return m.Remaining
+	}
+	return 0
+}
+
+type Estimate_Substep struct {
+	Step                 Substep  `protobuf:"varint,1,opt,name=step,enum=idl.Substep" json:"step,omitempty"`
+	Status               Status   `protobuf:"varint,2,opt,name=status,enum=idl.Status" json:"status,omitempty"`
+	Seconds              int64    `protobuf:"varint,3,opt,name=seconds" json:"seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Estimate_Substep) Reset()         { *m = Estimate_Substep{} }
+func (m *Estimate_Substep) String() string { return proto.CompactTextString(m) }
+func (*Estimate_Substep) ProtoMessage()    {}
+func (*Estimate_Substep) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{21, 0}
+}
+func (m *Estimate_Substep) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Estimate_Substep.Unmarshal(m, b)
+}
+func (m *Estimate_Substep) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Estimate_Substep.Marshal(b, m, deterministic)
+}
+func (dst *Estimate_Substep) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Estimate_Substep.Merge(dst, src)
+}
+func (m *Estimate_Substep) XXX_Size() int {
+	return xxx_messageInfo_Estimate_Substep.Size(m)
+}
+func (m *Estimate_Substep) XXX_DiscardUnknown() {
+	xxx_messageInfo_Estimate_Substep.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Estimate_Substep proto.InternalMessageInfo
+
+func (m *Estimate_Substep) GetStep() Substep {
+	if m != nil {
+		return m.Step
+	}
+	return Substep_UNKNOWN_STEP
+}
+
+func (m *Estimate_Substep) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_UNKNOWN_STATUS
+}
+
+func (m *Estimate_Substep) GetSeconds() int64 {
+	if m != nil {
+		return m.Seconds
+	}
+	return 0
+}
+
+type EstimateRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EstimateRequest) Reset()         { *m = EstimateRequest{} }
+func (m *EstimateRequest) String() string { return proto.CompactTextString(m) }
+func (*EstimateRequest) ProtoMessage()    {}
+func (*EstimateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{22}
+}
+func (m *EstimateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EstimateRequest.Unmarshal(m, b)
+}
+func (m *EstimateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EstimateRequest.Marshal(b, m, deterministic)
+}
+func (dst *EstimateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EstimateRequest.Merge(dst, src)
+}
+func (m *EstimateRequest) XXX_Size() int {
+	return xxx_messageInfo_EstimateRequest.Size(m)
+}
+func (m *EstimateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EstimateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EstimateRequest proto.InternalMessageInfo
+
+type EstimateReply struct {
+	Estimate             *Estimate `protobuf:"bytes,1,opt,name=estimate" json:"estimate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *EstimateReply) Reset()         { *m = EstimateReply{} }
+func (m *EstimateReply) String() string { return proto.CompactTextString(m) }
+func (*EstimateReply) ProtoMessage()    {}
+func (*EstimateReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{23}
+}
+func (m *EstimateReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EstimateReply.Unmarshal(m, b)
+}
+func (m *EstimateReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EstimateReply.Marshal(b, m, deterministic)
+}
+func (dst *EstimateReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EstimateReply.Merge(dst, src)
+}
+func (m *EstimateReply) XXX_Size() int {
+	return xxx_messageInfo_EstimateReply.Size(m)
+}
+func (m *EstimateReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_EstimateReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EstimateReply proto.InternalMessageInfo
+
+func (m *EstimateReply) GetEstimate() *Estimate {
+	if m != nil {
+		return m.Estimate
+	}
+	return nil
+}
+
+// ReportRequest regenerates the upgrade report in the hub's state directory.
+// ReportReply has the paths of its Markdown and HTML renderings, and the
+// Markdown itself.
+type ReportRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReportRequest) Reset()         { *m = ReportRequest{} }
+func (m *ReportRequest) String() string { return proto.CompactTextString(m) }
+func (*ReportRequest) ProtoMessage()    {}
+func (*ReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{24}
+}
+func (m *ReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportRequest.Unmarshal(m, b)
+}
+func (m *ReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportRequest.Marshal(b, m, deterministic)
+}
+func (dst *ReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportRequest.Merge(dst, src)
+}
+func (m *ReportRequest) XXX_Size() int {
+	return xxx_messageInfo_ReportRequest.Size(m)
+}
+func (m *ReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportRequest proto.InternalMessageInfo
+
+type ReportReply struct {
+	MarkdownPath         string   `protobuf:"bytes,1,opt,name=markdownPath" json:"markdownPath,omitempty"`
+	HtmlPath             string   `protobuf:"bytes,2,opt,name=htmlPath" json:"htmlPath,omitempty"`
+	Markdown             string   `protobuf:"bytes,3,opt,name=markdown" json:"markdown,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReportReply) Reset()         { *m = ReportReply{} }
+func (m *ReportReply) String() string { return proto.CompactTextString(m) }
+func (*ReportReply) ProtoMessage()    {}
+func (*ReportReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{25}
+}
+func (m *ReportReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportReply.Unmarshal(m, b)
+}
+func (m *ReportReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportReply.Marshal(b, m, deterministic)
+}
+func (dst *ReportReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportReply.Merge(dst, src)
+}
+func (m *ReportReply) XXX_Size() int {
+	return xxx_messageInfo_ReportReply.Size(m)
+}
+func (m *ReportReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportReply proto.InternalMessageInfo
+
+func (m *ReportReply) GetMarkdownPath() string {
+	if m != nil {
+		return m.MarkdownPath
+	}
+	return ""
+}
+
+func (m *ReportReply) GetHtmlPath() string {
+	if m != nil {
+		return m.HtmlPath
+	}
+	return ""
+}
+
+func (m *ReportReply) GetMarkdown() string {
+	if m != nil {
+		return m.Markdown
+	}
+	return ""
+}
+
 type Message struct {
 	// Types that are valid to be assigned to Contents:
 	//	*Message_Chunk
 	//	*Message_Status
+	//	*Message_Progress
+	//	*Message_Estimate
 	Contents             isMessage_Contents `protobuf_oneof:"contents"`
 	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
 	XXX_unrecognized     []byte             `json:"-"`
@@ -799,7 +1428,7 @@ func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{16}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{26}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Message.Unmarshal(m, b)
@@ -829,9 +1458,17 @@ type Message_Chunk struct {
 type Message_Status struct {
 	Status *SubstepStatus `protobuf:"bytes,2,opt,name=status,oneof"`
 }
+type Message_Progress struct {
+	Progress *SegmentProgress `protobuf:"bytes,3,opt,name=progress,oneof"`
+}
+type Message_Estimate struct {
+	Estimate *Estimate `protobuf:"bytes,4,opt,name=estimate,oneof"`
+}
 
-func (*Message_Chunk) isMessage_Contents()  {}
-func (*Message_Status) isMessage_Contents() {}
+func (*Message_Chunk) isMessage_Contents()    {}
+func (*Message_Status) isMessage_Contents()   {}
+func (*Message_Progress) isMessage_Contents() {}
+func (*Message_Estimate) isMessage_Contents() {}
 
 func (m *Message) GetContents() isMessage_Contents {
 	if m != nil {
@@ -854,11 +1491,27 @@ func (m *Message) GetStatus() *SubstepStatus {
 	return nil
 }
 
+func (m *Message) GetProgress() *SegmentProgress {
+	if x, ok := m.GetContents().(*Message_Progress); ok {
+		return x.Progress
+	}
+	return nil
+}
+
+func (m *Message) GetEstimate() *Estimate {
+	if x, ok := m.GetContents().(*Message_Estimate); ok {
+		return x.Estimate
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
 		(*Message_Chunk)(nil),
 		(*Message_Status)(nil),
+		(*Message_Progress)(nil),
+		(*Message_Estimate)(nil),
 	}
 }
 
@@ -876,6 +1529,16 @@ func _Message_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Status); err != nil {
 			return err
 		}
+	case *Message_Progress:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Progress); err != nil {
+			return err
+		}
+	case *Message_Estimate:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Estimate); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Message.Contents has unexpected type %T", x)
@@ -902,6 +1565,22 @@ func _Message_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer
 		err := b.DecodeMessage(msg)
 		m.Contents = &Message_Status{msg}
 		return true, err
+	case 3: // contents.progress
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SegmentProgress)
+		err := b.DecodeMessage(msg)
+		m.Contents = &Message_Progress{msg}
+		return true, err
+	case 4: // contents.estimate
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Estimate)
+		err := b.DecodeMessage(msg)
+		m.Contents = &Message_Estimate{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -921,6 +1600,16 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Message_Progress:
+		s := proto.Size(x.Progress)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Estimate:
+		s := proto.Size(x.Estimate)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -940,7 +1629,7 @@ func (m *SetConfigRequest) Reset()         { *m = SetConfigRequest{} }
 func (m *SetConfigRequest) String() string { return proto.CompactTextString(m) }
 func (*SetConfigRequest) ProtoMessage()    {}
 func (*SetConfigRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{17}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{27}
 }
 func (m *SetConfigRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetConfigRequest.Unmarshal(m, b)
@@ -984,7 +1673,7 @@ func (m *SetConfigReply) Reset()         { *m = SetConfigReply{} }
 func (m *SetConfigReply) String() string { return proto.CompactTextString(m) }
 func (*SetConfigReply) ProtoMessage()    {}
 func (*SetConfigReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{18}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{28}
 }
 func (m *SetConfigReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SetConfigReply.Unmarshal(m, b)
@@ -1015,7 +1704,7 @@ func (m *GetConfigRequest) Reset()         { *m = GetConfigRequest{} }
 func (m *GetConfigRequest) String() string { return proto.CompactTextString(m) }
 func (*GetConfigRequest) ProtoMessage()    {}
 func (*GetConfigRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{19}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{29}
 }
 func (m *GetConfigRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetConfigRequest.Unmarshal(m, b)
@@ -1053,7 +1742,7 @@ func (m *GetConfigReply) Reset()         { *m = GetConfigReply{} }
 func (m *GetConfigReply) String() string { return proto.CompactTextString(m) }
 func (*GetConfigReply) ProtoMessage()    {}
 func (*GetConfigReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cli_to_hub_13d72a794479be7c, []int{20}
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{30}
 }
 func (m *GetConfigReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetConfigReply.Unmarshal(m, b)
@@ -1080,11 +1769,228 @@ func (m *GetConfigReply) GetValue() string {
 	return ""
 }
 
+type CollectLogsRequest struct {
+	// The maximum number of uncompressed bytes to collect from each host. Zero
+	// means no limit.
+	MaxBytesPerHost      uint64   `protobuf:"varint,1,opt,name=maxBytesPerHost" json:"maxBytesPerHost,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CollectLogsRequest) Reset()         { *m = CollectLogsRequest{} }
+func (m *CollectLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*CollectLogsRequest) ProtoMessage()    {}
+func (*CollectLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{31}
+}
+func (m *CollectLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CollectLogsRequest.Unmarshal(m, b)
+}
+func (m *CollectLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CollectLogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *CollectLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CollectLogsRequest.Merge(dst, src)
+}
+func (m *CollectLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_CollectLogsRequest.Size(m)
+}
+func (m *CollectLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CollectLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CollectLogsRequest proto.InternalMessageInfo
+
+func (m *CollectLogsRequest) GetMaxBytesPerHost() uint64 {
+	if m != nil {
+		return m.MaxBytesPerHost
+	}
+	return 0
+}
+
+type CollectLogsReply struct {
+	// A piece of a gzipped tar archive.
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CollectLogsReply) Reset()         { *m = CollectLogsReply{} }
+func (m *CollectLogsReply) String() string { return proto.CompactTextString(m) }
+func (*CollectLogsReply) ProtoMessage()    {}
+func (*CollectLogsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{32}
+}
+func (m *CollectLogsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CollectLogsReply.Unmarshal(m, b)
+}
+func (m *CollectLogsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CollectLogsReply.Marshal(b, m, deterministic)
+}
+func (dst *CollectLogsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CollectLogsReply.Merge(dst, src)
+}
+func (m *CollectLogsReply) XXX_Size() int {
+	return xxx_messageInfo_CollectLogsReply.Size(m)
+}
+func (m *CollectLogsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CollectLogsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CollectLogsReply proto.InternalMessageInfo
+
+func (m *CollectLogsReply) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// PgUpgradeFailure describes why pg_upgrade failed. It is attached to gRPC
+// error statuses so that the hub and CLI can present failures from any host.
+type PgUpgradeFailure struct {
+	Host                 string             `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	WorkDir              string             `protobuf:"bytes,2,opt,name=workDir" json:"workDir,omitempty"`
+	Check                string             `protobuf:"bytes,3,opt,name=check" json:"check,omitempty"`
+	Message              string             `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+	Reports              []*PgUpgradeReport `protobuf:"bytes,5,rep,name=reports" json:"reports,omitempty"`
+	Error                string             `protobuf:"bytes,6,opt,name=error" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *PgUpgradeFailure) Reset()         { *m = PgUpgradeFailure{} }
+func (m *PgUpgradeFailure) String() string { return proto.CompactTextString(m) }
+func (*PgUpgradeFailure) ProtoMessage()    {}
+func (*PgUpgradeFailure) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{33}
+}
+func (m *PgUpgradeFailure) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PgUpgradeFailure.Unmarshal(m, b)
+}
+func (m *PgUpgradeFailure) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PgUpgradeFailure.Marshal(b, m, deterministic)
+}
+func (dst *PgUpgradeFailure) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PgUpgradeFailure.Merge(dst, src)
+}
+func (m *PgUpgradeFailure) XXX_Size() int {
+	return xxx_messageInfo_PgUpgradeFailure.Size(m)
+}
+func (m *PgUpgradeFailure) XXX_DiscardUnknown() {
+	xxx_messageInfo_PgUpgradeFailure.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PgUpgradeFailure proto.InternalMessageInfo
+
+func (m *PgUpgradeFailure) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *PgUpgradeFailure) GetWorkDir() string {
+	if m != nil {
+		return m.WorkDir
+	}
+	return ""
+}
+
+func (m *PgUpgradeFailure) GetCheck() string {
+	if m != nil {
+		return m.Check
+	}
+	return ""
+}
+
+func (m *PgUpgradeFailure) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *PgUpgradeFailure) GetReports() []*PgUpgradeReport {
+	if m != nil {
+		return m.Reports
+	}
+	return nil
+}
+
+func (m *PgUpgradeFailure) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type PgUpgradeReport struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Lines                []string `protobuf:"bytes,2,rep,name=lines" json:"lines,omitempty"`
+	TotalLines           int32    `protobuf:"varint,3,opt,name=totalLines" json:"totalLines,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PgUpgradeReport) Reset()         { *m = PgUpgradeReport{} }
+func (m *PgUpgradeReport) String() string { return proto.CompactTextString(m) }
+func (*PgUpgradeReport) ProtoMessage()    {}
+func (*PgUpgradeReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cli_to_hub_f2075c5d823d3859, []int{34}
+}
+func (m *PgUpgradeReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PgUpgradeReport.Unmarshal(m, b)
+}
+func (m *PgUpgradeReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PgUpgradeReport.Marshal(b, m, deterministic)
+}
+func (dst *PgUpgradeReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PgUpgradeReport.Merge(dst, src)
+}
+func (m *PgUpgradeReport) XXX_Size() int {
+	return xxx_messageInfo_PgUpgradeReport.Size(m)
+}
+func (m *PgUpgradeReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_PgUpgradeReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PgUpgradeReport proto.InternalMessageInfo
+
+func (m *PgUpgradeReport) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *PgUpgradeReport) GetLines() []string {
+	if m != nil {
+		return m.Lines
+	}
+	return nil
+}
+
+func (m *PgUpgradeReport) GetTotalLines() int32 {
+	if m != nil {
+		return m.TotalLines
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*InitializeRequest)(nil), "idl.InitializeRequest")
 	proto.RegisterType((*InitializeCreateClusterRequest)(nil), "idl.InitializeCreateClusterRequest")
 	proto.RegisterType((*ExecuteRequest)(nil), "idl.ExecuteRequest")
 	proto.RegisterType((*FinalizeRequest)(nil), "idl.FinalizeRequest")
+	proto.RegisterType((*WatchRequest)(nil), "idl.WatchRequest")
+	proto.RegisterType((*JobsRequest)(nil), "idl.JobsRequest")
+	proto.RegisterType((*JobsReply)(nil), "idl.JobsReply")
+	proto.RegisterType((*Job)(nil), "idl.Job")
 	proto.RegisterType((*RestartAgentsRequest)(nil), "idl.RestartAgentsRequest")
 	proto.RegisterType((*RestartAgentsReply)(nil), "idl.RestartAgentsReply")
 	proto.RegisterType((*StopServicesRequest)(nil), "idl.StopServicesRequest")
@@ -1099,14 +2005,26 @@ func init() {
 	proto.RegisterType((*PrepareInitClusterRequest)(nil), "idl.PrepareInitClusterRequest")
 	proto.RegisterType((*PrepareInitClusterReply)(nil), "idl.PrepareInitClusterReply")
 	proto.RegisterType((*Chunk)(nil), "idl.Chunk")
+	proto.RegisterType((*SegmentProgress)(nil), "idl.SegmentProgress")
+	proto.RegisterType((*Estimate)(nil), "idl.Estimate")
+	proto.RegisterType((*Estimate_Substep)(nil), "idl.Estimate.Substep")
+	proto.RegisterType((*EstimateRequest)(nil), "idl.EstimateRequest")
+	proto.RegisterType((*EstimateReply)(nil), "idl.EstimateReply")
+	proto.RegisterType((*ReportRequest)(nil), "idl.ReportRequest")
+	proto.RegisterType((*ReportReply)(nil), "idl.ReportReply")
 	proto.RegisterType((*Message)(nil), "idl.Message")
 	proto.RegisterType((*SetConfigRequest)(nil), "idl.SetConfigRequest")
 	proto.RegisterType((*SetConfigReply)(nil), "idl.SetConfigReply")
 	proto.RegisterType((*GetConfigRequest)(nil), "idl.GetConfigRequest")
 	proto.RegisterType((*GetConfigReply)(nil), "idl.GetConfigReply")
+	proto.RegisterType((*CollectLogsRequest)(nil), "idl.CollectLogsRequest")
+	proto.RegisterType((*CollectLogsReply)(nil), "idl.CollectLogsReply")
+	proto.RegisterType((*PgUpgradeFailure)(nil), "idl.PgUpgradeFailure")
+	proto.RegisterType((*PgUpgradeReport)(nil), "idl.PgUpgradeReport")
 	proto.RegisterEnum("idl.Substep", Substep_name, Substep_value)
 	proto.RegisterEnum("idl.Status", Status_name, Status_value)
 	proto.RegisterEnum("idl.Chunk_Type", Chunk_Type_name, Chunk_Type_value)
+	proto.RegisterEnum("idl.SegmentProgress_Phase", SegmentProgress_Phase_name, SegmentProgress_Phase_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1130,6 +2048,11 @@ type CliToHubClient interface {
 	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigReply, error)
 	RestartAgents(ctx context.Context, in *RestartAgentsRequest, opts ...grpc.CallOption) (*RestartAgentsReply, error)
 	StopServices(ctx context.Context, in *StopServicesRequest, opts ...grpc.CallOption) (*StopServicesReply, error)
+	CollectLogs(ctx context.Context, in *CollectLogsRequest, opts ...grpc.CallOption) (CliToHub_CollectLogsClient, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (CliToHub_WatchClient, error)
+	Jobs(ctx context.Context, in *JobsRequest, opts ...grpc.CallOption) (*JobsReply, error)
+	Estimate(ctx context.Context, in *EstimateRequest, opts ...grpc.CallOption) (*EstimateReply, error)
+	Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportReply, error)
 }
 
 type cliToHubClient struct {
@@ -1322,6 +2245,97 @@ func (c *cliToHubClient) StopServices(ctx context.Context, in *StopServicesReque
 	return out, nil
 }
 
+func (c *cliToHubClient) CollectLogs(ctx context.Context, in *CollectLogsRequest, opts ...grpc.CallOption) (CliToHub_CollectLogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CliToHub_serviceDesc.Streams[4], c.cc, "/idl.CliToHub/CollectLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cliToHubCollectLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CliToHub_CollectLogsClient interface {
+	Recv() (*CollectLogsReply, error)
+	grpc.ClientStream
+}
+
+type cliToHubCollectLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *cliToHubCollectLogsClient) Recv() (*CollectLogsReply, error) {
+	m := new(CollectLogsReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cliToHubClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (CliToHub_WatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CliToHub_serviceDesc.Streams[5], c.cc, "/idl.CliToHub/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cliToHubWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CliToHub_WatchClient interface {
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type cliToHubWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *cliToHubWatchClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cliToHubClient) Jobs(ctx context.Context, in *JobsRequest, opts ...grpc.CallOption) (*JobsReply, error) {
+	out := new(JobsReply)
+	err := grpc.Invoke(ctx, "/idl.CliToHub/Jobs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliToHubClient) Estimate(ctx context.Context, in *EstimateRequest, opts ...grpc.CallOption) (*EstimateReply, error) {
+	out := new(EstimateReply)
+	err := grpc.Invoke(ctx, "/idl.CliToHub/Estimate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliToHubClient) Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportReply, error) {
+	out := new(ReportReply)
+	err := grpc.Invoke(ctx, "/idl.CliToHub/Report", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for CliToHub service
 
 type CliToHubServer interface {
@@ -1335,6 +2349,11 @@ type CliToHubServer interface {
 	GetConfig(context.Context, *GetConfigRequest) (*GetConfigReply, error)
 	RestartAgents(context.Context, *RestartAgentsRequest) (*RestartAgentsReply, error)
 	StopServices(context.Context, *StopServicesRequest) (*StopServicesReply, error)
+	CollectLogs(*CollectLogsRequest, CliToHub_CollectLogsServer) error
+	Watch(*WatchRequest, CliToHub_WatchServer) error
+	Jobs(context.Context, *JobsRequest) (*JobsReply, error)
+	Estimate(context.Context, *EstimateRequest) (*EstimateReply, error)
+	Report(context.Context, *ReportRequest) (*ReportReply, error)
 }
 
 func RegisterCliToHubServer(s *grpc.Server, srv CliToHubServer) {
@@ -1533,6 +2552,102 @@ func _CliToHub_StopServices_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CliToHub_CollectLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CollectLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CliToHubServer).CollectLogs(m, &cliToHubCollectLogsServer{stream})
+}
+
+type CliToHub_CollectLogsServer interface {
+	Send(*CollectLogsReply) error
+	grpc.ServerStream
+}
+
+type cliToHubCollectLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *cliToHubCollectLogsServer) Send(m *CollectLogsReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CliToHub_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CliToHubServer).Watch(m, &cliToHubWatchServer{stream})
+}
+
+type CliToHub_WatchServer interface {
+	Send(*Message) error
+	grpc.ServerStream
+}
+
+type cliToHubWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *cliToHubWatchServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CliToHub_Jobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliToHubServer).Jobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.CliToHub/Jobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliToHubServer).Jobs(ctx, req.(*JobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliToHub_Estimate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliToHubServer).Estimate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.CliToHub/Estimate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliToHubServer).Estimate(ctx, req.(*EstimateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliToHub_Report_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliToHubServer).Report(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.CliToHub/Report",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliToHubServer).Report(ctx, req.(*ReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CliToHub_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "idl.CliToHub",
 	HandlerType: (*CliToHubServer)(nil),
@@ -1561,6 +2676,18 @@ var _CliToHub_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopServices",
 			Handler:    _CliToHub_StopServices_Handler,
 		},
+		{
+			MethodName: "Jobs",
+			Handler:    _CliToHub_Jobs_Handler,
+		},
+		{
+			MethodName: "Estimate",
+			Handler:    _CliToHub_Estimate_Handler,
+		},
+		{
+			MethodName: "Report",
+			Handler:    _CliToHub_Report_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1583,86 +2710,144 @@ var _CliToHub_serviceDesc = grpc.ServiceDesc{
 			Handler:       _CliToHub_Finalize_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "CollectLogs",
+			Handler:       _CliToHub_CollectLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _CliToHub_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "cli_to_hub.proto",
 }
 
-func init() { proto.RegisterFile("cli_to_hub.proto", fileDescriptor_cli_to_hub_13d72a794479be7c) }
-
-var fileDescriptor_cli_to_hub_13d72a794479be7c = []byte{
-	// 1174 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xdb, 0x6e, 0xdb, 0x46,
-	0x13, 0x16, 0xad, 0x83, 0xa5, 0x91, 0x2c, 0x53, 0x2b, 0x3b, 0x56, 0x94, 0xfc, 0xf9, 0x15, 0x3a,
-	0x0d, 0x8c, 0xb4, 0x35, 0x02, 0xb5, 0x28, 0x92, 0x22, 0x37, 0x34, 0x45, 0x4b, 0x44, 0x64, 0x89,
-	0x5d, 0x52, 0x09, 0x52, 0xa0, 0x10, 0x68, 0x79, 0xed, 0x10, 0x66, 0x48, 0x85, 0x5c, 0x06, 0x75,
-	0xdf, 0xa0, 0xcf, 0xd1, 0x47, 0xe8, 0xd3, 0xf5, 0xae, 0xd8, 0xe5, 0x52, 0xa2, 0x14, 0x1a, 0xe8,
-	0x1d, 0x77, 0xe6, 0x9b, 0x23, 0xbf, 0x9d, 0x59, 0x90, 0x17, 0x9e, 0x3b, 0xa7, 0xc1, 0xfc, 0x63,
-	0x7c, 0x79, 0xba, 0x0c, 0x03, 0x1a, 0xa0, 0xa2, 0x7b, 0xe5, 0x29, 0x7f, 0x4b, 0xd0, 0x32, 0x7c,
-	0x97, 0xba, 0x8e, 0xe7, 0xfe, 0x41, 0x30, 0xf9, 0x1c, 0x93, 0x88, 0x22, 0x05, 0x1a, 0x51, 0x10,
-	0x87, 0x0b, 0x72, 0xe6, 0xfa, 0x03, 0x37, 0xec, 0x48, 0x3d, 0xe9, 0xa4, 0x86, 0x37, 0x64, 0x0c,
-	0x43, 0x9d, 0xf0, 0x86, 0x50, 0x81, 0xd9, 0x49, 0x30, 0x59, 0x19, 0x7a, 0x02, 0x90, 0xd8, 0x98,
-	0x41, 0x48, 0x3b, 0xc5, 0x9e, 0x74, 0x52, 0xc6, 0x19, 0x09, 0xea, 0x41, 0x3d, 0x8e, 0xc8, 0xd8,
-	0xf5, 0x6f, 0x2f, 0x82, 0x2b, 0xd2, 0x29, 0xf5, 0xa4, 0x93, 0x2a, 0xce, 0x8a, 0xd0, 0x01, 0x94,
-	0x97, 0x41, 0x48, 0xa3, 0x4e, 0xb9, 0x57, 0x3c, 0xd9, 0xc3, 0xc9, 0x41, 0xe9, 0xc1, 0x93, 0x75,
-	0xd2, 0x5a, 0x48, 0x1c, 0x4a, 0x34, 0x2f, 0x8e, 0x28, 0x09, 0x45, 0x05, 0x8a, 0x0c, 0x4d, 0xfd,
-	0x77, 0xb2, 0x88, 0x69, 0x5a, 0x93, 0xd2, 0x82, 0xfd, 0x73, 0xd7, 0xcf, 0x96, 0xa9, 0x3c, 0x80,
-	0x03, 0x4c, 0x22, 0xea, 0x84, 0x54, 0xbd, 0x21, 0x3e, 0x8d, 0x52, 0xf9, 0x8f, 0x80, 0xb6, 0xe4,
-	0x4b, 0xef, 0x8e, 0x15, 0xe3, 0xb0, 0xe3, 0x28, 0x88, 0x68, 0xd4, 0x91, 0x7a, 0xc5, 0x93, 0x1a,
-	0xce, 0x48, 0x94, 0x43, 0x68, 0x5b, 0x34, 0x58, 0x5a, 0x24, 0xfc, 0xe2, 0x2e, 0xc8, 0xca, 0x59,
-	0x1b, 0x5a, 0x9b, 0xe2, 0xa5, 0x77, 0xa7, 0xbc, 0x83, 0x3d, 0x2b, 0xbe, 0x8c, 0x28, 0x59, 0x5a,
-	0xd4, 0xa1, 0x71, 0x84, 0x7a, 0x50, 0x62, 0x27, 0xde, 0xe9, 0x66, 0xbf, 0x71, 0xea, 0x5e, 0x79,
-	0xa7, 0x02, 0x81, 0xb9, 0x06, 0x1d, 0x43, 0x25, 0xe2, 0x58, 0xde, 0xe9, 0x66, 0xbf, 0x9e, 0x60,
-	0xb8, 0x08, 0x0b, 0x15, 0xcb, 0x41, 0xfb, 0x48, 0x16, 0xb7, 0xef, 0x48, 0x18, 0xb9, 0x81, 0x9f,
-	0xe6, 0xa0, 0x43, 0x6b, 0x53, 0xcc, 0xea, 0x79, 0x09, 0x6d, 0x23, 0x12, 0x12, 0x2d, 0xf8, 0xb4,
-	0x74, 0xa8, 0x7b, 0xe9, 0x11, 0x9e, 0x41, 0x15, 0xe7, 0xa9, 0x94, 0xef, 0xe1, 0x90, 0xbb, 0x19,
-	0xb8, 0xd1, 0xad, 0xb5, 0x74, 0x16, 0x2b, 0xbe, 0x1c, 0x40, 0x39, 0x74, 0xa8, 0x1b, 0x70, 0x63,
-	0x09, 0x27, 0x07, 0xe5, 0x1f, 0x49, 0x64, 0x93, 0xc1, 0xb3, 0xc0, 0x6f, 0xa0, 0x72, 0xed, 0xb8,
-	0x1e, 0xb9, 0xe2, 0x4d, 0xac, 0xf7, 0x9f, 0xf1, 0x4a, 0x72, 0x90, 0xa7, 0xe7, 0x1c, 0xa6, 0xfb,
-	0x34, 0xbc, 0xc3, 0xc2, 0xa6, 0xab, 0x43, 0x8d, 0xa1, 0x66, 0x91, 0x73, 0x43, 0xd0, 0x63, 0xa8,
-	0x39, 0x5f, 0x1c, 0xd7, 0x73, 0xd2, 0xcc, 0x4b, 0x78, 0x2d, 0x40, 0x5d, 0xa8, 0x86, 0xe4, 0x73,
-	0xec, 0x86, 0xe4, 0x8a, 0x37, 0xad, 0x84, 0x57, 0xe7, 0xee, 0x6f, 0x50, 0xcf, 0x78, 0x47, 0x32,
-	0x14, 0x6f, 0xc9, 0x9d, 0x20, 0x3a, 0xfb, 0x44, 0xaf, 0xa0, 0xfc, 0xc5, 0xf1, 0x62, 0xc2, 0x2d,
-	0xeb, 0x7d, 0xe5, 0xde, 0x24, 0x57, 0xd9, 0xe0, 0xc4, 0xe0, 0xe7, 0x9d, 0x57, 0x92, 0xf2, 0x08,
-	0x1e, 0x9a, 0x21, 0x59, 0x3a, 0x21, 0x61, 0x44, 0xdd, 0x22, 0xe7, 0x43, 0x38, 0xca, 0x53, 0x32,
-	0x62, 0x7c, 0x86, 0xb2, 0xf6, 0x31, 0xf6, 0x6f, 0xd1, 0x03, 0xa8, 0x5c, 0xc6, 0xd7, 0xd7, 0x24,
-	0xb9, 0x7c, 0x0d, 0x2c, 0x4e, 0xe8, 0x18, 0x4a, 0xf4, 0x6e, 0x49, 0x04, 0x09, 0xf6, 0x45, 0x56,
-	0xb1, 0x7f, 0x7b, 0x6a, 0xdf, 0x2d, 0x09, 0xe6, 0x4a, 0xe5, 0x5b, 0x28, 0xb1, 0x13, 0xaa, 0xc3,
-	0xee, 0x6c, 0xf2, 0x76, 0x32, 0x7d, 0x3f, 0x91, 0x0b, 0x08, 0xa0, 0x62, 0xd9, 0x83, 0xe9, 0xcc,
-	0x96, 0x25, 0xf1, 0xad, 0x63, 0x2c, 0xef, 0x28, 0x37, 0xb0, 0x7b, 0x41, 0x22, 0xde, 0x4e, 0x05,
-	0xca, 0x0b, 0xe6, 0x8b, 0xc7, 0xac, 0xf7, 0x61, 0xed, 0x7d, 0x54, 0xc0, 0x89, 0x0a, 0x7d, 0xb7,
-	0xc1, 0xc3, 0x7a, 0x1f, 0x65, 0xb9, 0x9a, 0xd0, 0x71, 0x54, 0x48, 0x09, 0x79, 0x06, 0x50, 0x5d,
-	0x04, 0x3e, 0x65, 0xb7, 0x48, 0x79, 0x03, 0xb2, 0x45, 0xa8, 0x16, 0xf8, 0xd7, 0xee, 0x4d, 0xca,
-	0x1c, 0x04, 0x25, 0xdf, 0xf9, 0x44, 0x44, 0xe3, 0xf9, 0x37, 0x63, 0xd3, 0xba, 0xf3, 0x35, 0xd1,
-	0x55, 0x76, 0xa3, 0x33, 0xd6, 0xac, 0x57, 0xcf, 0x41, 0x1e, 0xfe, 0x07, 0x7f, 0xca, 0x73, 0x68,
-	0x0e, 0x37, 0x2c, 0xd7, 0x11, 0xa4, 0x4c, 0x84, 0x17, 0x7f, 0x95, 0x60, 0x57, 0xd4, 0x81, 0x64,
-	0x68, 0x88, 0xce, 0xcd, 0x2d, 0x5b, 0x37, 0x93, 0xf6, 0x69, 0xd3, 0xc9, 0xb9, 0x31, 0x94, 0x25,
-	0xa6, 0xb5, 0x6c, 0x15, 0xdb, 0x73, 0x75, 0xa8, 0x4f, 0x6c, 0x4b, 0xde, 0x41, 0x1d, 0x38, 0xd0,
-	0xb0, 0xae, 0xda, 0xfa, 0xdc, 0x56, 0xf1, 0x50, 0xb7, 0xe7, 0x02, 0x5b, 0x44, 0x8f, 0xe0, 0xc8,
-	0x1a, 0xcd, 0xec, 0x01, 0x77, 0x35, 0x9d, 0x61, 0x4d, 0x9f, 0x6b, 0xe3, 0x99, 0x65, 0xeb, 0x58,
-	0x2e, 0xa1, 0x23, 0x68, 0x1b, 0x13, 0xc3, 0x5e, 0x19, 0x09, 0x45, 0x79, 0xc3, 0x6a, 0x4b, 0x59,
-	0x61, 0xc1, 0xce, 0x54, 0xed, 0xed, 0xcc, 0x4c, 0x55, 0x17, 0x2a, 0xd7, 0xec, 0xa2, 0x16, 0xec,
-	0x69, 0x23, 0x5d, 0x7b, 0x3b, 0x9f, 0x99, 0x43, 0xac, 0x0e, 0x74, 0xb9, 0x8a, 0x10, 0x34, 0xc5,
-	0x21, 0x85, 0xd5, 0xd0, 0x3e, 0xd4, 0xb5, 0xa9, 0xf9, 0x21, 0x15, 0x00, 0x3a, 0x84, 0x56, 0x0a,
-	0x32, 0xb1, 0x71, 0xa1, 0x62, 0x43, 0xb7, 0xe4, 0x3a, 0x0b, 0x94, 0xd4, 0xb9, 0x95, 0x42, 0x03,
-	0x3d, 0x83, 0xde, 0xb9, 0x31, 0x51, 0xc7, 0xc6, 0xaf, 0xfa, 0xfc, 0xbe, 0x44, 0xf7, 0x50, 0x0f,
-	0x1e, 0xaf, 0x51, 0x59, 0x47, 0x22, 0x70, 0x13, 0x7d, 0x03, 0x4f, 0x57, 0x88, 0x99, 0x39, 0x60,
-	0x0d, 0xd4, 0x54, 0x5b, 0x1d, 0x4f, 0x87, 0xf3, 0xf7, 0x86, 0x3d, 0x9a, 0x9b, 0x53, 0x6c, 0xcb,
-	0xfb, 0xe8, 0x18, 0xfe, 0x7f, 0x6f, 0x38, 0xe1, 0x4b, 0xde, 0x00, 0x09, 0x5f, 0xe6, 0xd4, 0xb2,
-	0x87, 0x58, 0xb7, 0x7e, 0x19, 0xf3, 0x1f, 0x22, 0xb7, 0xd0, 0x53, 0xf8, 0x5f, 0x7e, 0x4a, 0x69,
-	0xd6, 0x08, 0x3d, 0x86, 0x4e, 0xc6, 0x4f, 0xd2, 0x15, 0xcb, 0x56, 0x27, 0x83, 0xb3, 0x0f, 0x72,
-	0xfb, 0x85, 0x06, 0x15, 0x31, 0xb3, 0x59, 0x67, 0x57, 0x1c, 0x51, 0xed, 0x99, 0x25, 0x17, 0xd8,
-	0x8d, 0xc3, 0xb3, 0xc9, 0xc4, 0x98, 0x30, 0x9a, 0x34, 0xa0, 0xaa, 0x4d, 0x2f, 0xcc, 0xb1, 0x6e,
-	0xeb, 0xf2, 0x0e, 0x23, 0xd0, 0xb9, 0x6a, 0x8c, 0xf5, 0x81, 0x5c, 0xec, 0xff, 0x59, 0x86, 0xaa,
-	0xe6, 0xb9, 0x76, 0x30, 0x8a, 0x2f, 0xd1, 0x19, 0x34, 0xb2, 0xd3, 0x19, 0x75, 0xd6, 0xa3, 0x66,
-	0x73, 0x8e, 0x77, 0x1f, 0xe4, 0x68, 0xd8, 0x4d, 0x28, 0xa0, 0x11, 0x34, 0x37, 0x67, 0x13, 0xea,
-	0xe6, 0x0e, 0xac, 0xc4, 0x4f, 0xe7, 0xbe, 0x61, 0xa6, 0x14, 0xd0, 0x4f, 0x00, 0xeb, 0xdd, 0x8a,
-	0x92, 0x88, 0x5f, 0xbd, 0x10, 0xba, 0xc9, 0x86, 0x12, 0x73, 0x43, 0x29, 0xbc, 0x94, 0x90, 0x09,
-	0x47, 0xf7, 0xec, 0x64, 0x74, 0xbc, 0xe5, 0x24, 0x6f, 0x63, 0xe7, 0x78, 0x7c, 0x09, 0xbb, 0x62,
-	0x87, 0xa3, 0x36, 0x57, 0x6e, 0x6e, 0xf4, 0x1c, 0x8b, 0x3e, 0x54, 0xd3, 0x1d, 0x8f, 0x0e, 0xb8,
-	0x76, 0x6b, 0xe5, 0xe7, 0xd8, 0xbc, 0x86, 0xda, 0x6a, 0xae, 0xa0, 0xc3, 0x64, 0x98, 0x6d, 0x4d,
-	0x95, 0x6e, 0x7b, 0x5b, 0x9c, 0xb4, 0xea, 0x35, 0xd4, 0x86, 0x5b, 0xa6, 0xc3, 0x7c, 0xd3, 0xe1,
-	0xb6, 0xa9, 0x0e, 0x7b, 0x1b, 0x4f, 0x0c, 0xf4, 0x90, 0xe3, 0xf2, 0x9e, 0x23, 0xdd, 0xa3, 0x3c,
-	0x55, 0xe2, 0xe6, 0x0c, 0x1a, 0xd9, 0xc7, 0x85, 0xa0, 0x4e, 0xce, 0x33, 0x44, 0x50, 0xe7, 0xeb,
-	0x97, 0x48, 0xe1, 0xb2, 0xc2, 0x9f, 0x83, 0x3f, 0xfc, 0x1b, 0x00, 0x00, 0xff, 0xff, 0xfc, 0xa5,
-	0x69, 0x40, 0x22, 0x0a, 0x00, 0x00,
+func init() { proto.RegisterFile("cli_to_hub.proto", fileDescriptor_cli_to_hub_f2075c5d823d3859) }
+
+var fileDescriptor_cli_to_hub_f2075c5d823d3859 = []byte{
+	// 1943 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x18, 0xdb, 0x6e, 0xe3, 0xc6,
+	0xd5, 0xd4, 0xc5, 0x96, 0x8e, 0x7c, 0xa1, 0xc7, 0x37, 0x45, 0xd9, 0x6e, 0x15, 0x6e, 0xba, 0x70,
+	0x36, 0xa9, 0xe1, 0xb8, 0x45, 0x91, 0x04, 0x41, 0x01, 0x9a, 0xa2, 0x25, 0xed, 0xda, 0x12, 0x3b,
+	0xa4, 0x76, 0xb1, 0xbd, 0x40, 0xa0, 0xa5, 0x59, 0x99, 0xb5, 0x44, 0x6a, 0xc9, 0xd1, 0x26, 0xce,
+	0x57, 0x14, 0xe8, 0xbf, 0xf4, 0x17, 0xda, 0xf7, 0x7e, 0x42, 0xbf, 0xa1, 0x0f, 0x7d, 0x2b, 0xe6,
+	0x46, 0x51, 0x5a, 0x1a, 0xe8, 0x43, 0xdf, 0x78, 0xae, 0x73, 0xce, 0x99, 0x33, 0xe7, 0x42, 0xd0,
+	0x47, 0xd3, 0x60, 0x48, 0xa3, 0xe1, 0xdd, 0xe2, 0xf6, 0x6c, 0x1e, 0x47, 0x34, 0x42, 0xc5, 0x60,
+	0x3c, 0x35, 0xfe, 0xa9, 0xc1, 0x7e, 0x37, 0x0c, 0x68, 0xe0, 0x4f, 0x83, 0x9f, 0x08, 0x26, 0xef,
+	0x17, 0x24, 0xa1, 0xc8, 0x80, 0xed, 0x24, 0x5a, 0xc4, 0x23, 0x72, 0x19, 0x84, 0xad, 0x20, 0xae,
+	0x6b, 0x4d, 0xed, 0xb4, 0x8a, 0x57, 0x70, 0x8c, 0x87, 0xfa, 0xf1, 0x84, 0x50, 0xc9, 0x53, 0x10,
+	0x3c, 0x59, 0x1c, 0x7a, 0x0a, 0x20, 0x64, 0x9c, 0x28, 0xa6, 0xf5, 0x62, 0x53, 0x3b, 0x2d, 0xe3,
+	0x0c, 0x06, 0x35, 0xa1, 0xb6, 0x48, 0xc8, 0x75, 0x10, 0xde, 0xdf, 0x44, 0x63, 0x52, 0x2f, 0x35,
+	0xb5, 0xd3, 0x0a, 0xce, 0xa2, 0xd0, 0x21, 0x94, 0xe7, 0x51, 0x4c, 0x93, 0x7a, 0xb9, 0x59, 0x3c,
+	0xdd, 0xc1, 0x02, 0x60, 0x7a, 0x47, 0xd1, 0xfc, 0xc1, 0x0e, 0x27, 0x41, 0x48, 0xea, 0x9b, 0xfc,
+	0xe4, 0x0c, 0xc6, 0x68, 0xc2, 0xd3, 0xa5, 0x53, 0x56, 0x4c, 0x7c, 0x4a, 0xac, 0xe9, 0x22, 0xa1,
+	0x24, 0x96, 0x1e, 0x1a, 0x1f, 0x60, 0xd7, 0xfe, 0x91, 0x8c, 0x16, 0x34, 0xf5, 0xb9, 0x01, 0x15,
+	0x2b, 0x0a, 0x29, 0x09, 0x69, 0x52, 0xd7, 0x9a, 0xc5, 0xd3, 0x32, 0x4e, 0x61, 0x66, 0x45, 0x27,
+	0x4a, 0x68, 0x52, 0x2f, 0x34, 0x8b, 0xa7, 0x55, 0x2c, 0x00, 0xf4, 0x04, 0xaa, 0x97, 0x3e, 0x1d,
+	0xdd, 0xb9, 0xc1, 0x4f, 0x44, 0x3a, 0xb7, 0x44, 0x30, 0x19, 0xc7, 0x5f, 0x24, 0xca, 0x2b, 0x01,
+	0x18, 0xfb, 0xb0, 0x77, 0x15, 0x84, 0xd9, 0x60, 0x1b, 0x4d, 0xd8, 0x7e, 0xc3, 0xa4, 0x94, 0x21,
+	0x3a, 0x14, 0x5f, 0x46, 0xb7, 0x3c, 0xe6, 0x65, 0xcc, 0x3e, 0x8d, 0x1d, 0xa8, 0xbd, 0x8c, 0x6e,
+	0x13, 0x25, 0xf0, 0x05, 0x54, 0x05, 0x38, 0x9f, 0x3e, 0xa0, 0x27, 0x50, 0x62, 0x00, 0x37, 0xb9,
+	0x76, 0x51, 0x39, 0x0b, 0xc6, 0xd3, 0xb3, 0x97, 0xd1, 0x2d, 0xe6, 0x58, 0xe3, 0xaf, 0x05, 0xae,
+	0x0c, 0xed, 0x42, 0xa1, 0xdb, 0x92, 0x2a, 0x0b, 0xdd, 0x16, 0x42, 0x50, 0x72, 0x29, 0x99, 0xcb,
+	0x4b, 0xe3, 0xdf, 0xe8, 0x19, 0x6c, 0xba, 0xd4, 0xa7, 0x8b, 0x84, 0xfb, 0xb2, 0x7b, 0x51, 0xe3,
+	0xba, 0x04, 0x0a, 0x4b, 0x12, 0x7a, 0x0e, 0x5b, 0xee, 0xe2, 0x36, 0x61, 0xb2, 0x25, 0xce, 0xb5,
+	0x2d, 0xb8, 0x04, 0x0e, 0x2b, 0x22, 0xfa, 0x1a, 0x76, 0xe4, 0xa7, 0xd4, 0x59, 0xfe, 0x58, 0xe7,
+	0x2a, 0x07, 0x0b, 0xa7, 0x4b, 0xfd, 0x98, 0x7a, 0xc1, 0x4c, 0xdc, 0x69, 0x11, 0x2f, 0x11, 0xa8,
+	0x0e, 0x5b, 0x76, 0x38, 0xe6, 0xb4, 0x2d, 0x4e, 0x53, 0x20, 0x0b, 0xb4, 0x1d, 0xc7, 0x51, 0x5c,
+	0xaf, 0x70, 0x67, 0x04, 0xc0, 0xae, 0xb3, 0x45, 0xa8, 0x3f, 0xba, 0x23, 0xe3, 0x7a, 0x95, 0xdf,
+	0x40, 0x0a, 0x1b, 0xc7, 0x70, 0x88, 0x49, 0xc2, 0x54, 0x9b, 0x13, 0x76, 0xbf, 0x2a, 0xb0, 0xbf,
+	0x06, 0xb4, 0x86, 0x67, 0x11, 0x7e, 0x0a, 0xe0, 0x33, 0x50, 0x64, 0x80, 0xc6, 0x33, 0x20, 0x83,
+	0x31, 0x8e, 0xe0, 0xc0, 0xa5, 0xd1, 0xdc, 0x25, 0xf1, 0x87, 0x60, 0x44, 0x52, 0x65, 0x07, 0xb0,
+	0xbf, 0x8a, 0x9e, 0x4f, 0x1f, 0x8c, 0xd7, 0x6b, 0x61, 0x41, 0x4d, 0x28, 0xf1, 0x60, 0x6a, 0x39,
+	0xc1, 0x2c, 0x25, 0xf2, 0x5a, 0x12, 0x11, 0xc2, 0x42, 0xce, 0xb5, 0x08, 0x12, 0xb3, 0xc1, 0xba,
+	0x23, 0xa3, 0xfb, 0xd7, 0x24, 0x4e, 0x82, 0x28, 0x54, 0x36, 0xd8, 0xb0, 0xbf, 0x8a, 0x66, 0xfe,
+	0x9c, 0xc3, 0x41, 0x37, 0x91, 0x18, 0x2b, 0x9a, 0xcd, 0x7d, 0x1a, 0xdc, 0x4e, 0x09, 0xb7, 0xa0,
+	0x82, 0xf3, 0x48, 0xc6, 0x2f, 0xe1, 0x88, 0xab, 0x69, 0x05, 0xc9, 0xbd, 0x3b, 0xf7, 0x47, 0xe9,
+	0x9b, 0x39, 0x84, 0x72, 0xec, 0xd3, 0x20, 0xe2, 0xc2, 0x1a, 0x16, 0x80, 0xf1, 0x1f, 0x0d, 0x0e,
+	0xd6, 0xf9, 0xd9, 0xc1, 0xdf, 0xc3, 0xe6, 0x3b, 0x3f, 0x98, 0x92, 0xb1, 0x4c, 0xd6, 0xcf, 0xb9,
+	0x27, 0x39, 0x9c, 0x67, 0x57, 0x9c, 0xcd, 0x0e, 0x69, 0xfc, 0x80, 0xa5, 0x4c, 0xc3, 0x86, 0x2a,
+	0xe3, 0x1a, 0x24, 0xfe, 0x84, 0xb0, 0x5c, 0xf1, 0x3f, 0xf8, 0xc1, 0xd4, 0x57, 0x96, 0x97, 0xf0,
+	0x12, 0xc1, 0xee, 0x3e, 0x26, 0xef, 0x17, 0x41, 0x4c, 0xc6, 0x3c, 0x68, 0x25, 0x9c, 0xc2, 0x8d,
+	0x3f, 0x41, 0x2d, 0xa3, 0x9d, 0x3d, 0xb6, 0x7b, 0xf2, 0x20, 0x0b, 0x1c, 0xfb, 0x44, 0xdf, 0x40,
+	0xf9, 0x83, 0x3f, 0x5d, 0x10, 0x2e, 0x59, 0xbb, 0x30, 0x1e, 0x35, 0x32, 0xb5, 0x06, 0x0b, 0x81,
+	0xef, 0x0a, 0xdf, 0x68, 0xc6, 0xa7, 0xf0, 0x89, 0x13, 0x93, 0xb9, 0x1f, 0x13, 0x56, 0x80, 0xd6,
+	0x8a, 0xce, 0x27, 0x70, 0x92, 0x47, 0x64, 0x89, 0xf1, 0x1e, 0xca, 0xd6, 0xdd, 0x22, 0xbc, 0x47,
+	0xc7, 0xb0, 0x79, 0xbb, 0x78, 0xf7, 0x8e, 0x88, 0xa2, 0xbb, 0x8d, 0x25, 0x84, 0x9e, 0x41, 0x89,
+	0x3e, 0xcc, 0x89, 0x4c, 0x82, 0x3d, 0x69, 0xd5, 0x22, 0xbc, 0x3f, 0xf3, 0x1e, 0xe6, 0x04, 0x73,
+	0xa2, 0xf1, 0x25, 0x94, 0x18, 0x84, 0x6a, 0xb0, 0x35, 0xe8, 0xbd, 0xea, 0xf5, 0xdf, 0xf4, 0xf4,
+	0x0d, 0x04, 0xb0, 0xe9, 0x7a, 0xad, 0xfe, 0xc0, 0xd3, 0x35, 0xf9, 0x6d, 0x63, 0xac, 0x17, 0x8c,
+	0x7f, 0x69, 0xb0, 0xe7, 0x92, 0xc9, 0x8c, 0x84, 0xd4, 0x89, 0xa3, 0x49, 0x4c, 0x92, 0x84, 0xd5,
+	0x85, 0xbb, 0x28, 0xa1, 0x32, 0x1e, 0xfc, 0x9b, 0xbd, 0xbc, 0x91, 0x28, 0x84, 0xfc, 0xf0, 0x32,
+	0x56, 0x20, 0x3a, 0x87, 0xf2, 0xfc, 0xce, 0x4f, 0x88, 0x2c, 0x18, 0x0d, 0x91, 0x99, 0xab, 0x2a,
+	0xcf, 0x1c, 0xc6, 0x81, 0x05, 0x23, 0xd3, 0x4f, 0xd9, 0x13, 0x2e, 0xf1, 0x27, 0xcc, 0xbf, 0x8d,
+	0x3f, 0x42, 0x99, 0xf3, 0xa0, 0x7d, 0xd8, 0x91, 0x56, 0x0f, 0x9d, 0x8e, 0xe9, 0xda, 0xfa, 0x06,
+	0x42, 0xb0, 0x8b, 0x6d, 0xd7, 0xeb, 0x63, 0x7b, 0x78, 0x69, 0x5a, 0xaf, 0x06, 0x8e, 0xae, 0xa1,
+	0x2a, 0x94, 0xad, 0x8e, 0x6d, 0xbd, 0xd2, 0x0b, 0xdc, 0x4f, 0xa7, 0x8d, 0xcd, 0x96, 0xad, 0x17,
+	0x51, 0x05, 0x4a, 0xad, 0x7e, 0xcf, 0xd6, 0x4b, 0xcc, 0xcb, 0x2b, 0xb3, 0x7b, 0x6d, 0xb7, 0xf4,
+	0xb2, 0xf1, 0x77, 0x0d, 0x2a, 0x76, 0x42, 0x83, 0x99, 0x4f, 0x09, 0xfa, 0x1a, 0x2a, 0x89, 0x78,
+	0x5c, 0xaa, 0x60, 0x1e, 0x71, 0x9b, 0x15, 0x43, 0xfa, 0xf4, 0x52, 0x36, 0x96, 0x69, 0x31, 0x99,
+	0xf9, 0x41, 0x18, 0x84, 0x13, 0xee, 0x7f, 0x11, 0x2f, 0x11, 0x8d, 0x3f, 0xa7, 0xe5, 0xf0, 0xff,
+	0xf4, 0x92, 0x59, 0xb4, 0x13, 0x32, 0x8a, 0xc2, 0xb1, 0x28, 0xc3, 0x45, 0xac, 0x40, 0xd6, 0x3a,
+	0x94, 0x9d, 0x2a, 0xa1, 0xbe, 0x83, 0x9d, 0x25, 0x8a, 0x3d, 0xb1, 0x2f, 0xa0, 0x42, 0x24, 0x82,
+	0x1b, 0x52, 0xbb, 0xd8, 0x59, 0x71, 0x10, 0xa7, 0x64, 0x63, 0x0f, 0x76, 0x30, 0x61, 0xed, 0x54,
+	0x29, 0x0b, 0xa0, 0xa6, 0x10, 0x4c, 0x95, 0x01, 0xdb, 0x33, 0x3f, 0xbe, 0x1f, 0x47, 0x3f, 0x84,
+	0x8e, 0x4f, 0xef, 0xd4, 0x0c, 0x90, 0xc5, 0xb1, 0x87, 0x76, 0x47, 0x67, 0x53, 0x4e, 0x17, 0xad,
+	0x24, 0x85, 0x19, 0x4d, 0xf1, 0x72, 0x4f, 0xaa, 0x38, 0x85, 0x8d, 0x7f, 0x68, 0xb0, 0x75, 0x43,
+	0x12, 0xfe, 0x94, 0x0d, 0x28, 0x8f, 0x58, 0x1e, 0x4b, 0x7b, 0x61, 0x99, 0xd9, 0x9d, 0x0d, 0x2c,
+	0x48, 0xe8, 0xab, 0x95, 0xc8, 0xd5, 0x2e, 0x50, 0x36, 0xba, 0x22, 0x80, 0x9d, 0x8d, 0x34, 0x84,
+	0x17, 0x50, 0x99, 0xcb, 0xec, 0xe3, 0x27, 0xd7, 0x2e, 0x0e, 0xf3, 0x32, 0xb3, 0xb3, 0x81, 0x53,
+	0x3e, 0xf4, 0x65, 0x26, 0x70, 0xa5, 0x9c, 0xc0, 0x31, 0x66, 0xc5, 0x70, 0x09, 0x50, 0x91, 0x4f,
+	0x20, 0x31, 0xbe, 0x07, 0xdd, 0x25, 0xd4, 0x8a, 0xc2, 0x77, 0xc1, 0x44, 0x95, 0x45, 0x04, 0xa5,
+	0xd0, 0x9f, 0x11, 0xf5, 0x8a, 0xd8, 0x37, 0x2b, 0x95, 0xcb, 0xb2, 0x52, 0x95, 0x25, 0xc3, 0xd0,
+	0x61, 0x37, 0x23, 0xcd, 0x0a, 0xc1, 0x73, 0xd0, 0xdb, 0xff, 0x83, 0x3e, 0xe3, 0x39, 0xec, 0xb6,
+	0x57, 0x24, 0x97, 0x27, 0x68, 0xd9, 0x13, 0x7e, 0x0b, 0xc8, 0x8a, 0xa6, 0x53, 0x32, 0xa2, 0xd7,
+	0xd1, 0x44, 0x35, 0x27, 0x74, 0x0a, 0x7b, 0x33, 0xff, 0xc7, 0xcb, 0x07, 0x4a, 0x12, 0x87, 0xc4,
+	0x1d, 0xf5, 0xe4, 0x4b, 0x78, 0x1d, 0xcd, 0xec, 0x59, 0x91, 0x67, 0x27, 0x21, 0x28, 0x8d, 0x7d,
+	0xea, 0xcb, 0x0a, 0xc5, 0xbf, 0x8d, 0xbf, 0x69, 0xa0, 0x3b, 0x93, 0xc1, 0x7c, 0x12, 0xfb, 0x63,
+	0xc2, 0x2a, 0xec, 0x22, 0x26, 0x8f, 0x95, 0x93, 0x1f, 0xa2, 0xf8, 0x7e, 0x39, 0x32, 0x2a, 0x90,
+	0x39, 0x30, 0x62, 0x75, 0x56, 0xa6, 0x8b, 0x00, 0x18, 0xff, 0x4c, 0xa4, 0x0a, 0xbf, 0x98, 0x2a,
+	0x56, 0x20, 0x3a, 0x83, 0xad, 0x98, 0x2c, 0xa7, 0x43, 0x75, 0xcd, 0xa9, 0x15, 0x32, 0x9b, 0x15,
+	0x13, 0xd3, 0x4f, 0xf8, 0xa0, 0x20, 0x06, 0x46, 0x01, 0x18, 0x7f, 0x80, 0xbd, 0x35, 0x09, 0x66,
+	0xf6, 0x7c, 0x99, 0xf2, 0xfc, 0x9b, 0x09, 0x4f, 0x83, 0x90, 0xa4, 0x23, 0x20, 0x07, 0xd8, 0x6c,
+	0x40, 0x23, 0xea, 0x4f, 0xaf, 0x39, 0x49, 0x0e, 0xb8, 0x4b, 0xcc, 0x8b, 0x7f, 0x97, 0x96, 0x05,
+	0x42, 0x87, 0x6d, 0x55, 0xde, 0x5c, 0xcf, 0x76, 0x44, 0x65, 0xb6, 0xfa, 0xbd, 0xab, 0x6e, 0x5b,
+	0xd7, 0x18, 0xd5, 0xf5, 0x4c, 0xec, 0x0d, 0xcd, 0xb6, 0xdd, 0xf3, 0x5c, 0xbd, 0x80, 0xea, 0x70,
+	0x68, 0x61, 0xdb, 0xf4, 0xec, 0xa1, 0x67, 0xe2, 0xb6, 0xed, 0x0d, 0x25, 0x6f, 0x11, 0x7d, 0x0a,
+	0x27, 0x6e, 0x67, 0xe0, 0xb5, 0xb8, 0xaa, 0xfe, 0x00, 0x5b, 0xf6, 0xd0, 0xba, 0x1e, 0xb8, 0x9e,
+	0x8d, 0xf5, 0x12, 0x3a, 0x81, 0x83, 0x6e, 0xaf, 0xeb, 0xa5, 0x42, 0x92, 0x50, 0x5e, 0x91, 0x5a,
+	0x23, 0x6e, 0xb2, 0xc3, 0x44, 0x81, 0x55, 0xa4, 0x1b, 0x93, 0x53, 0xb6, 0x58, 0x55, 0xe6, 0xe5,
+	0x76, 0xa8, 0x2a, 0x6d, 0x85, 0x55, 0x65, 0x09, 0x28, 0xb6, 0x2a, 0xda, 0x83, 0x9a, 0xd5, 0x77,
+	0xde, 0x2a, 0x04, 0xa0, 0x23, 0xd8, 0x57, 0x4c, 0x0e, 0xee, 0xde, 0x98, 0xb8, 0x6b, 0xbb, 0x7a,
+	0x8d, 0x1d, 0x24, 0xfc, 0x5c, 0x33, 0x61, 0x1b, 0x7d, 0x0e, 0xcd, 0xab, 0x6e, 0xcf, 0xbc, 0xee,
+	0xfe, 0xde, 0x1e, 0x3e, 0x66, 0xe8, 0x0e, 0x6a, 0xc2, 0x93, 0x25, 0x57, 0x56, 0x91, 0x3c, 0x78,
+	0x17, 0xfd, 0x02, 0x3e, 0x4b, 0x39, 0x06, 0x4e, 0x8b, 0x05, 0xd0, 0x32, 0x3d, 0xf3, 0xba, 0xdf,
+	0x1e, 0xbe, 0xe9, 0x7a, 0x9d, 0xa1, 0xd3, 0xc7, 0x9e, 0xbe, 0x87, 0x9e, 0xc1, 0xcf, 0x1f, 0x3d,
+	0x4e, 0xea, 0xd2, 0x57, 0x98, 0xa4, 0x2e, 0xa7, 0xef, 0x7a, 0x6d, 0x6c, 0xbb, 0xbf, 0xbb, 0xe6,
+	0x17, 0xa2, 0xef, 0xa3, 0xcf, 0xe0, 0x67, 0xf9, 0x26, 0x29, 0xab, 0x11, 0x7a, 0x02, 0xf5, 0x8c,
+	0x1e, 0x11, 0x15, 0xd7, 0x33, 0x7b, 0xad, 0xcb, 0xb7, 0xfa, 0x01, 0xa3, 0x5a, 0x26, 0xc6, 0x6f,
+	0x87, 0xfd, 0xd7, 0x36, 0x96, 0xd7, 0x3c, 0xc0, 0xa6, 0xd7, 0xed, 0xf7, 0xf4, 0x43, 0x74, 0x0c,
+	0xe8, 0xb5, 0x8d, 0xbb, 0x57, 0x2a, 0xb6, 0x43, 0x16, 0x67, 0xfd, 0x08, 0x35, 0xe0, 0xf8, 0xc6,
+	0x36, 0xdd, 0x01, 0xb6, 0xd7, 0x93, 0xe0, 0xf8, 0x45, 0x5f, 0xcd, 0xf2, 0xfc, 0xae, 0xd2, 0xac,
+	0x33, 0xbd, 0x81, 0xab, 0x6f, 0xb0, 0xb6, 0x89, 0x07, 0xbd, 0x5e, 0xb7, 0xc7, 0x12, 0x6f, 0x1b,
+	0x2a, 0x56, 0xff, 0xc6, 0xb9, 0xb6, 0x3d, 0x5b, 0x2f, 0x64, 0x5a, 0x67, 0x91, 0x7d, 0x3b, 0xe6,
+	0xc0, 0xb5, 0x5b, 0x7a, 0xe9, 0xe2, 0x2f, 0x5b, 0x50, 0xb1, 0xa6, 0x81, 0x17, 0x75, 0x16, 0xb7,
+	0xe8, 0x12, 0xb6, 0xb3, 0x63, 0x25, 0xaa, 0x2f, 0x67, 0xa4, 0xd5, 0x01, 0xb4, 0x71, 0x9c, 0x43,
+	0x61, 0x55, 0x6e, 0x03, 0x75, 0x60, 0x77, 0x75, 0xa8, 0x42, 0x8d, 0xdc, 0x49, 0x4b, 0xe8, 0xa9,
+	0x3f, 0x36, 0x85, 0x19, 0x1b, 0xe8, 0x37, 0x00, 0xcb, 0x65, 0x0f, 0x89, 0x13, 0x3f, 0x5a, 0x69,
+	0x1b, 0xa2, 0x21, 0xcb, 0xa6, 0x63, 0x6c, 0x9c, 0x6b, 0xc8, 0x81, 0x93, 0x47, 0x96, 0x44, 0xf4,
+	0x6c, 0x4d, 0x49, 0xde, 0x0a, 0x99, 0xa3, 0xf1, 0x1c, 0xb6, 0xe4, 0x52, 0x89, 0x0e, 0x38, 0x71,
+	0x75, 0xc5, 0xcc, 0x91, 0xb8, 0x80, 0x8a, 0x5a, 0x07, 0x91, 0xa8, 0x5e, 0x6b, 0xdb, 0x61, 0x8e,
+	0xcc, 0xb7, 0x50, 0x4d, 0x7b, 0x06, 0x3a, 0x92, 0x9d, 0x6d, 0xb5, 0x63, 0x34, 0x0e, 0xd6, 0xd1,
+	0x22, 0x54, 0xdf, 0x42, 0xb5, 0xbd, 0x26, 0xda, 0xce, 0x17, 0x6d, 0xaf, 0x8b, 0xda, 0xb0, 0xb3,
+	0xb2, 0x1b, 0xa1, 0x4f, 0x38, 0x5f, 0xde, 0x1e, 0xd5, 0x38, 0xc9, 0x23, 0x09, 0x35, 0x97, 0xb0,
+	0x9d, 0xdd, 0x8a, 0x64, 0xea, 0xe4, 0xec, 0x4f, 0x8d, 0xe3, 0x1c, 0x8a, 0xd0, 0x61, 0x42, 0x2d,
+	0xd3, 0x92, 0x90, 0x38, 0xed, 0xe3, 0x26, 0xd7, 0x38, 0xfa, 0x98, 0xc0, 0x15, 0x9c, 0x6b, 0xe8,
+	0x2b, 0x28, 0xf3, 0x9d, 0x1b, 0xed, 0x73, 0x9e, 0xec, 0xfe, 0x9d, 0x13, 0xf1, 0x17, 0x62, 0xc7,
+	0x46, 0xba, 0xda, 0xae, 0xd3, 0x23, 0x76, 0x33, 0x18, 0x95, 0x8d, 0xcb, 0x71, 0xf3, 0x70, 0x75,
+	0xf6, 0x92, 0x32, 0x68, 0x0d, 0x2b, 0xe4, 0xce, 0x61, 0x53, 0x75, 0x1f, 0x19, 0xbd, 0xcc, 0x6c,
+	0xd6, 0xd0, 0x57, 0x70, 0x5c, 0xe2, 0x76, 0x93, 0xff, 0xc6, 0xf9, 0xd5, 0x7f, 0x07, 0x00, 0x56,
+	0x1d, 0x0c, 0x50, 0xda, 0x11, 0x00, 0x00,
 }
@@ -23,6 +23,64 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type GUCChange_ChangeClass int32
+
+const (
+	GUCChange_PORTABLE   GUCChange_ChangeClass = 0
+	GUCChange_RENAMED    GUCChange_ChangeClass = 1
+	GUCChange_REMOVED    GUCChange_ChangeClass = 2
+	GUCChange_UNPORTABLE GUCChange_ChangeClass = 3
+)
+
+var GUCChange_ChangeClass_name = map[int32]string{
+	0: "PORTABLE",
+	1: "RENAMED",
+	2: "REMOVED",
+	3: "UNPORTABLE",
+}
+var GUCChange_ChangeClass_value = map[string]int32{
+	"PORTABLE":   0,
+	"RENAMED":    1,
+	"REMOVED":    2,
+	"UNPORTABLE": 3,
+}
+
+func (x GUCChange_ChangeClass) String() string {
+	return proto.EnumName(GUCChange_ChangeClass_name, int32(x))
+}
+func (GUCChange_ChangeClass) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{14, 0}
+}
+
+type FileEntry_Type int32
+
+const (
+	FileEntry_FILE      FileEntry_Type = 0
+	FileEntry_DIRECTORY FileEntry_Type = 1
+	FileEntry_SYMLINK   FileEntry_Type = 2
+	FileEntry_HARDLINK  FileEntry_Type = 3
+)
+
+var FileEntry_Type_name = map[int32]string{
+	0: "FILE",
+	1: "DIRECTORY",
+	2: "SYMLINK",
+	3: "HARDLINK",
+}
+var FileEntry_Type_value = map[string]int32{
+	"FILE":      0,
+	"DIRECTORY": 1,
+	"SYMLINK":   2,
+	"HARDLINK":  3,
+}
+
+func (x FileEntry_Type) String() string {
+	return proto.EnumName(FileEntry_Type_name, int32(x))
+}
+func (FileEntry_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{15, 0}
+}
+
 type UpgradePrimariesRequest struct {
 	SourceBinDir         string         `protobuf:"bytes,1,opt,name=SourceBinDir" json:"SourceBinDir,omitempty"`
 	TargetBinDir         string         `protobuf:"bytes,2,opt,name=TargetBinDir" json:"TargetBinDir,omitempty"`
@@ -31,6 +89,8 @@ type UpgradePrimariesRequest struct {
 	CheckOnly            bool           `protobuf:"varint,5,opt,name=CheckOnly" json:"CheckOnly,omitempty"`
 	UseLinkMode          bool           `protobuf:"varint,6,opt,name=UseLinkMode" json:"UseLinkMode,omitempty"`
 	MasterBackupDir      string         `protobuf:"bytes,7,opt,name=MasterBackupDir" json:"MasterBackupDir,omitempty"`
+	CopyEngine           string         `protobuf:"bytes,8,opt,name=CopyEngine" json:"CopyEngine,omitempty"`
+	Parallelism          int32          `protobuf:"varint,9,opt,name=Parallelism" json:"Parallelism,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
 	XXX_unrecognized     []byte         `json:"-"`
 	XXX_sizecache        int32          `json:"-"`
@@ -40,7 +100,7 @@ func (m *UpgradePrimariesRequest) Reset()         { *m = UpgradePrimariesRequest
 func (m *UpgradePrimariesRequest) String() string { return proto.CompactTextString(m) }
 func (*UpgradePrimariesRequest) ProtoMessage()    {}
 func (*UpgradePrimariesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{0}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{0}
 }
 func (m *UpgradePrimariesRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpgradePrimariesRequest.Unmarshal(m, b)
@@ -109,6 +169,20 @@ func (m *UpgradePrimariesRequest) GetMasterBackupDir() string {
 	return ""
 }
 
+func (m *UpgradePrimariesRequest) GetCopyEngine() string {
+	if m != nil {
+		return m.CopyEngine
+	}
+	return ""
+}
+
+func (m *UpgradePrimariesRequest) GetParallelism() int32 {
+	if m != nil {
+		return m.Parallelism
+	}
+	return 0
+}
+
 type DataDirPair struct {
 	SourceDataDir        string   `protobuf:"bytes,1,opt,name=SourceDataDir" json:"SourceDataDir,omitempty"`
 	TargetDataDir        string   `protobuf:"bytes,2,opt,name=TargetDataDir" json:"TargetDataDir,omitempty"`
@@ -125,7 +199,7 @@ func (m *DataDirPair) Reset()         { *m = DataDirPair{} }
 func (m *DataDirPair) String() string { return proto.CompactTextString(m) }
 func (*DataDirPair) ProtoMessage()    {}
 func (*DataDirPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{1}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{1}
 }
 func (m *DataDirPair) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DataDirPair.Unmarshal(m, b)
@@ -187,17 +261,20 @@ func (m *DataDirPair) GetDBID() int32 {
 	return 0
 }
 
+// UpgradePrimariesReply is sent as each segment enters a new phase of its
+// upgrade.
 type UpgradePrimariesReply struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Progress             *SegmentProgress `protobuf:"bytes,1,opt,name=Progress" json:"Progress,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *UpgradePrimariesReply) Reset()         { *m = UpgradePrimariesReply{} }
 func (m *UpgradePrimariesReply) String() string { return proto.CompactTextString(m) }
 func (*UpgradePrimariesReply) ProtoMessage()    {}
 func (*UpgradePrimariesReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{2}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{2}
 }
 func (m *UpgradePrimariesReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpgradePrimariesReply.Unmarshal(m, b)
@@ -217,6 +294,155 @@ func (m *UpgradePrimariesReply) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_UpgradePrimariesReply proto.InternalMessageInfo
 
+func (m *UpgradePrimariesReply) GetProgress() *SegmentProgress {
+	if m != nil {
+		return m.Progress
+	}
+	return nil
+}
+
+type ScanDataDirsRequest struct {
+	DataDirs             []string `protobuf:"bytes,1,rep,name=DataDirs" json:"DataDirs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScanDataDirsRequest) Reset()         { *m = ScanDataDirsRequest{} }
+func (m *ScanDataDirsRequest) String() string { return proto.CompactTextString(m) }
+func (*ScanDataDirsRequest) ProtoMessage()    {}
+func (*ScanDataDirsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{3}
+}
+func (m *ScanDataDirsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanDataDirsRequest.Unmarshal(m, b)
+}
+func (m *ScanDataDirsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanDataDirsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ScanDataDirsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanDataDirsRequest.Merge(dst, src)
+}
+func (m *ScanDataDirsRequest) XXX_Size() int {
+	return xxx_messageInfo_ScanDataDirsRequest.Size(m)
+}
+func (m *ScanDataDirsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanDataDirsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanDataDirsRequest proto.InternalMessageInfo
+
+func (m *ScanDataDirsRequest) GetDataDirs() []string {
+	if m != nil {
+		return m.DataDirs
+	}
+	return nil
+}
+
+// DataDirScan is the total size of the files in a data directory, and how
+// many there are.
+type DataDirScan struct {
+	DataDir              string   `protobuf:"bytes,1,opt,name=DataDir" json:"DataDir,omitempty"`
+	Bytes                int64    `protobuf:"varint,2,opt,name=Bytes" json:"Bytes,omitempty"`
+	Files                int64    `protobuf:"varint,3,opt,name=Files" json:"Files,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DataDirScan) Reset()         { *m = DataDirScan{} }
+func (m *DataDirScan) String() string { return proto.CompactTextString(m) }
+func (*DataDirScan) ProtoMessage()    {}
+func (*DataDirScan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{4}
+}
+func (m *DataDirScan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DataDirScan.Unmarshal(m, b)
+}
+func (m *DataDirScan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DataDirScan.Marshal(b, m, deterministic)
+}
+func (dst *DataDirScan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DataDirScan.Merge(dst, src)
+}
+func (m *DataDirScan) XXX_Size() int {
+	return xxx_messageInfo_DataDirScan.Size(m)
+}
+func (m *DataDirScan) XXX_DiscardUnknown() {
+	xxx_messageInfo_DataDirScan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DataDirScan proto.InternalMessageInfo
+
+func (m *DataDirScan) GetDataDir() string {
+	if m != nil {
+		return m.DataDir
+	}
+	return ""
+}
+
+func (m *DataDirScan) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *DataDirScan) GetFiles() int64 {
+	if m != nil {
+		return m.Files
+	}
+	return 0
+}
+
+// ScanDataDirsReply also reports the number of segments that the agent
+// upgrades at once by default.
+type ScanDataDirsReply struct {
+	Scans                []*DataDirScan `protobuf:"bytes,1,rep,name=Scans" json:"Scans,omitempty"`
+	DefaultParallelism   int32          `protobuf:"varint,2,opt,name=DefaultParallelism" json:"DefaultParallelism,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ScanDataDirsReply) Reset()         { *m = ScanDataDirsReply{} }
+func (m *ScanDataDirsReply) String() string { return proto.CompactTextString(m) }
+func (*ScanDataDirsReply) ProtoMessage()    {}
+func (*ScanDataDirsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{5}
+}
+func (m *ScanDataDirsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanDataDirsReply.Unmarshal(m, b)
+}
+func (m *ScanDataDirsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanDataDirsReply.Marshal(b, m, deterministic)
+}
+func (dst *ScanDataDirsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanDataDirsReply.Merge(dst, src)
+}
+func (m *ScanDataDirsReply) XXX_Size() int {
+	return xxx_messageInfo_ScanDataDirsReply.Size(m)
+}
+func (m *ScanDataDirsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanDataDirsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanDataDirsReply proto.InternalMessageInfo
+
+func (m *ScanDataDirsReply) GetScans() []*DataDirScan {
+	if m != nil {
+		return m.Scans
+	}
+	return nil
+}
+
+func (m *ScanDataDirsReply) GetDefaultParallelism() int32 {
+	if m != nil {
+		return m.DefaultParallelism
+	}
+	return 0
+}
+
 type CreateSegmentDataDirRequest struct {
 	Datadirs             []string `protobuf:"bytes,1,rep,name=datadirs" json:"datadirs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -228,7 +454,7 @@ func (m *CreateSegmentDataDirRequest) Reset()         { *m = CreateSegmentDataDi
 func (m *CreateSegmentDataDirRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateSegmentDataDirRequest) ProtoMessage()    {}
 func (*CreateSegmentDataDirRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{3}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{6}
 }
 func (m *CreateSegmentDataDirRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateSegmentDataDirRequest.Unmarshal(m, b)
@@ -265,7 +491,7 @@ func (m *CreateSegmentDataDirReply) Reset()         { *m = CreateSegmentDataDirR
 func (m *CreateSegmentDataDirReply) String() string { return proto.CompactTextString(m) }
 func (*CreateSegmentDataDirReply) ProtoMessage()    {}
 func (*CreateSegmentDataDirReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{4}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{7}
 }
 func (m *CreateSegmentDataDirReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateSegmentDataDirReply.Unmarshal(m, b)
@@ -295,7 +521,7 @@ func (m *StopAgentRequest) Reset()         { *m = StopAgentRequest{} }
 func (m *StopAgentRequest) String() string { return proto.CompactTextString(m) }
 func (*StopAgentRequest) ProtoMessage()    {}
 func (*StopAgentRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{5}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{8}
 }
 func (m *StopAgentRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopAgentRequest.Unmarshal(m, b)
@@ -325,7 +551,7 @@ func (m *StopAgentReply) Reset()         { *m = StopAgentReply{} }
 func (m *StopAgentReply) String() string { return proto.CompactTextString(m) }
 func (*StopAgentReply) ProtoMessage()    {}
 func (*StopAgentReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{6}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{9}
 }
 func (m *StopAgentReply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StopAgentReply.Unmarshal(m, b)
@@ -357,7 +583,7 @@ func (m *CheckSegmentDiskSpaceRequest) Reset()         { *m = CheckSegmentDiskSp
 func (m *CheckSegmentDiskSpaceRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckSegmentDiskSpaceRequest) ProtoMessage()    {}
 func (*CheckSegmentDiskSpaceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_hub_to_agent_db6442cf90a0c433, []int{7}
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{10}
 }
 func (m *CheckSegmentDiskSpaceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckSegmentDiskSpaceRequest.Unmarshal(m, b)
@@ -391,138 +617,1063 @@ func (m *CheckSegmentDiskSpaceRequest) GetDatadirs() []string {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*UpgradePrimariesRequest)(nil), "idl.UpgradePrimariesRequest")
-	proto.RegisterType((*DataDirPair)(nil), "idl.DataDirPair")
-	proto.RegisterType((*UpgradePrimariesReply)(nil), "idl.UpgradePrimariesReply")
-	proto.RegisterType((*CreateSegmentDataDirRequest)(nil), "idl.CreateSegmentDataDirRequest")
-	proto.RegisterType((*CreateSegmentDataDirReply)(nil), "idl.CreateSegmentDataDirReply")
-	proto.RegisterType((*StopAgentRequest)(nil), "idl.StopAgentRequest")
-	proto.RegisterType((*StopAgentReply)(nil), "idl.StopAgentReply")
-	proto.RegisterType((*CheckSegmentDiskSpaceRequest)(nil), "idl.CheckSegmentDiskSpaceRequest")
+type CarryOverConfigurationRequest struct {
+	DataDirPairs         []*DataDirPair `protobuf:"bytes,1,rep,name=DataDirPairs" json:"DataDirPairs,omitempty"`
+	SourceMajorVersion   uint64         `protobuf:"varint,2,opt,name=SourceMajorVersion" json:"SourceMajorVersion,omitempty"`
+	TargetMajorVersion   uint64         `protobuf:"varint,3,opt,name=TargetMajorVersion" json:"TargetMajorVersion,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *CarryOverConfigurationRequest) Reset()         { *m = CarryOverConfigurationRequest{} }
+func (m *CarryOverConfigurationRequest) String() string { return proto.CompactTextString(m) }
+func (*CarryOverConfigurationRequest) ProtoMessage()    {}
+func (*CarryOverConfigurationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{11}
+}
+func (m *CarryOverConfigurationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CarryOverConfigurationRequest.Unmarshal(m, b)
+}
+func (m *CarryOverConfigurationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CarryOverConfigurationRequest.Marshal(b, m, deterministic)
+}
+func (dst *CarryOverConfigurationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CarryOverConfigurationRequest.Merge(dst, src)
+}
+func (m *CarryOverConfigurationRequest) XXX_Size() int {
+	return xxx_messageInfo_CarryOverConfigurationRequest.Size(m)
+}
+func (m *CarryOverConfigurationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CarryOverConfigurationRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_CarryOverConfigurationRequest proto.InternalMessageInfo
 
-// Client API for Agent service
+func (m *CarryOverConfigurationRequest) GetDataDirPairs() []*DataDirPair {
+	if m != nil {
+		return m.DataDirPairs
+	}
+	return nil
+}
 
-type AgentClient interface {
-	CheckDiskSpace(ctx context.Context, in *CheckSegmentDiskSpaceRequest, opts ...grpc.CallOption) (*CheckDiskSpaceReply, error)
-	UpgradePrimaries(ctx context.Context, in *UpgradePrimariesRequest, opts ...grpc.CallOption) (*UpgradePrimariesReply, error)
-	CreateSegmentDataDirectories(ctx context.Context, in *CreateSegmentDataDirRequest, opts ...grpc.CallOption) (*CreateSegmentDataDirReply, error)
-	StopAgent(ctx context.Context, in *StopAgentRequest, opts ...grpc.CallOption) (*StopAgentReply, error)
+func (m *CarryOverConfigurationRequest) GetSourceMajorVersion() uint64 {
+	if m != nil {
+		return m.SourceMajorVersion
+	}
+	return 0
 }
 
-type agentClient struct {
-	cc *grpc.ClientConn
+func (m *CarryOverConfigurationRequest) GetTargetMajorVersion() uint64 {
+	if m != nil {
+		return m.TargetMajorVersion
+	}
+	return 0
 }
 
-func NewAgentClient(cc *grpc.ClientConn) AgentClient {
-	return &agentClient{cc}
+type CarryOverConfigurationReply struct {
+	CarryOvers           []*ConfigurationCarryOver `protobuf:"bytes,1,rep,name=CarryOvers" json:"CarryOvers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
 }
 
-func (c *agentClient) CheckDiskSpace(ctx context.Context, in *CheckSegmentDiskSpaceRequest, opts ...grpc.CallOption) (*CheckDiskSpaceReply, error) {
-	out := new(CheckDiskSpaceReply)
-	err := grpc.Invoke(ctx, "/idl.Agent/CheckDiskSpace", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *CarryOverConfigurationReply) Reset()         { *m = CarryOverConfigurationReply{} }
+func (m *CarryOverConfigurationReply) String() string { return proto.CompactTextString(m) }
+func (*CarryOverConfigurationReply) ProtoMessage()    {}
+func (*CarryOverConfigurationReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{12}
+}
+func (m *CarryOverConfigurationReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CarryOverConfigurationReply.Unmarshal(m, b)
+}
+func (m *CarryOverConfigurationReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CarryOverConfigurationReply.Marshal(b, m, deterministic)
+}
+func (dst *CarryOverConfigurationReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CarryOverConfigurationReply.Merge(dst, src)
+}
+func (m *CarryOverConfigurationReply) XXX_Size() int {
+	return xxx_messageInfo_CarryOverConfigurationReply.Size(m)
+}
+func (m *CarryOverConfigurationReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CarryOverConfigurationReply.DiscardUnknown(m)
 }
 
-func (c *agentClient) UpgradePrimaries(ctx context.Context, in *UpgradePrimariesRequest, opts ...grpc.CallOption) (*UpgradePrimariesReply, error) {
-	out := new(UpgradePrimariesReply)
-	err := grpc.Invoke(ctx, "/idl.Agent/UpgradePrimaries", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_CarryOverConfigurationReply proto.InternalMessageInfo
+
+func (m *CarryOverConfigurationReply) GetCarryOvers() []*ConfigurationCarryOver {
+	if m != nil {
+		return m.CarryOvers
 	}
-	return out, nil
+	return nil
 }
 
-func (c *agentClient) CreateSegmentDataDirectories(ctx context.Context, in *CreateSegmentDataDirRequest, opts ...grpc.CallOption) (*CreateSegmentDataDirReply, error) {
-	out := new(CreateSegmentDataDirReply)
-	err := grpc.Invoke(ctx, "/idl.Agent/CreateSegmentDataDirectories", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+// ConfigurationCarryOver describes the postgresql.conf and pg_hba.conf changes
+// made to a single target segment.
+type ConfigurationCarryOver struct {
+	Content              int32        `protobuf:"varint,1,opt,name=Content" json:"Content,omitempty"`
+	Changes              []*GUCChange `protobuf:"bytes,2,rep,name=Changes" json:"Changes,omitempty"`
+	HBAEntries           int32        `protobuf:"varint,3,opt,name=HBAEntries" json:"HBAEntries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ConfigurationCarryOver) Reset()         { *m = ConfigurationCarryOver{} }
+func (m *ConfigurationCarryOver) String() string { return proto.CompactTextString(m) }
+func (*ConfigurationCarryOver) ProtoMessage()    {}
+func (*ConfigurationCarryOver) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{13}
+}
+func (m *ConfigurationCarryOver) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigurationCarryOver.Unmarshal(m, b)
+}
+func (m *ConfigurationCarryOver) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigurationCarryOver.Marshal(b, m, deterministic)
+}
+func (dst *ConfigurationCarryOver) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigurationCarryOver.Merge(dst, src)
+}
+func (m *ConfigurationCarryOver) XXX_Size() int {
+	return xxx_messageInfo_ConfigurationCarryOver.Size(m)
+}
+func (m *ConfigurationCarryOver) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigurationCarryOver.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigurationCarryOver proto.InternalMessageInfo
+
+func (m *ConfigurationCarryOver) GetContent() int32 {
+	if m != nil {
+		return m.Content
 	}
-	return out, nil
+	return 0
 }
 
-func (c *agentClient) StopAgent(ctx context.Context, in *StopAgentRequest, opts ...grpc.CallOption) (*StopAgentReply, error) {
-	out := new(StopAgentReply)
-	err := grpc.Invoke(ctx, "/idl.Agent/StopAgent", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ConfigurationCarryOver) GetChanges() []*GUCChange {
+	if m != nil {
+		return m.Changes
 	}
-	return out, nil
+	return nil
 }
 
-// Server API for Agent service
+func (m *ConfigurationCarryOver) GetHBAEntries() int32 {
+	if m != nil {
+		return m.HBAEntries
+	}
+	return 0
+}
 
-type AgentServer interface {
-	CheckDiskSpace(context.Context, *CheckSegmentDiskSpaceRequest) (*CheckDiskSpaceReply, error)
-	UpgradePrimaries(context.Context, *UpgradePrimariesRequest) (*UpgradePrimariesReply, error)
-	CreateSegmentDataDirectories(context.Context, *CreateSegmentDataDirRequest) (*CreateSegmentDataDirReply, error)
-	StopAgent(context.Context, *StopAgentRequest) (*StopAgentReply, error)
+type GUCChange struct {
+	Name                 string                `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	SourceValue          string                `protobuf:"bytes,2,opt,name=SourceValue" json:"SourceValue,omitempty"`
+	TargetValue          string                `protobuf:"bytes,3,opt,name=TargetValue" json:"TargetValue,omitempty"`
+	Class                GUCChange_ChangeClass `protobuf:"varint,4,opt,name=Class,enum=idl.GUCChange_ChangeClass" json:"Class,omitempty"`
+	NewName              string                `protobuf:"bytes,5,opt,name=NewName" json:"NewName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
-func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
-	s.RegisterService(&_Agent_serviceDesc, srv)
+func (m *GUCChange) Reset()         { *m = GUCChange{} }
+func (m *GUCChange) String() string { return proto.CompactTextString(m) }
+func (*GUCChange) ProtoMessage()    {}
+func (*GUCChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{14}
+}
+func (m *GUCChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GUCChange.Unmarshal(m, b)
+}
+func (m *GUCChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GUCChange.Marshal(b, m, deterministic)
+}
+func (dst *GUCChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GUCChange.Merge(dst, src)
+}
+func (m *GUCChange) XXX_Size() int {
+	return xxx_messageInfo_GUCChange.Size(m)
+}
+func (m *GUCChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_GUCChange.DiscardUnknown(m)
 }
 
-func _Agent_CheckDiskSpace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CheckSegmentDiskSpaceRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(AgentServer).CheckDiskSpace(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/idl.Agent/CheckDiskSpace",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServer).CheckDiskSpace(ctx, req.(*CheckSegmentDiskSpaceRequest))
+var xxx_messageInfo_GUCChange proto.InternalMessageInfo
+
+func (m *GUCChange) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _Agent_UpgradePrimaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpgradePrimariesRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(AgentServer).UpgradePrimaries(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/idl.Agent/UpgradePrimaries",
+func (m *GUCChange) GetSourceValue() string {
+	if m != nil {
+		return m.SourceValue
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServer).UpgradePrimaries(ctx, req.(*UpgradePrimariesRequest))
+	return ""
+}
+
+func (m *GUCChange) GetTargetValue() string {
+	if m != nil {
+		return m.TargetValue
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _Agent_CreateSegmentDataDirectories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateSegmentDataDirRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *GUCChange) GetClass() GUCChange_ChangeClass {
+	if m != nil {
+		return m.Class
 	}
-	if interceptor == nil {
-		return srv.(AgentServer).CreateSegmentDataDirectories(ctx, in)
+	return GUCChange_PORTABLE
+}
+
+func (m *GUCChange) GetNewName() string {
+	if m != nil {
+		return m.NewName
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/idl.Agent/CreateSegmentDataDirectories",
+	return ""
+}
+
+// FileEntry describes a file in a directory being copied by the hub. Paths are
+// relative to the directory and slash-separated.
+type FileEntry struct {
+	Path                 string         `protobuf:"bytes,1,opt,name=Path" json:"Path,omitempty"`
+	Type                 FileEntry_Type `protobuf:"varint,2,opt,name=type,enum=idl.FileEntry_Type" json:"type,omitempty"`
+	Mode                 uint32         `protobuf:"varint,3,opt,name=Mode" json:"Mode,omitempty"`
+	Uid                  uint32         `protobuf:"varint,4,opt,name=Uid" json:"Uid,omitempty"`
+	Gid                  uint32         `protobuf:"varint,5,opt,name=Gid" json:"Gid,omitempty"`
+	ModTime              int64          `protobuf:"varint,6,opt,name=ModTime" json:"ModTime,omitempty"`
+	Size                 int64          `protobuf:"varint,7,opt,name=Size" json:"Size,omitempty"`
+	Target               string         `protobuf:"bytes,8,opt,name=Target" json:"Target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *FileEntry) Reset()         { *m = FileEntry{} }
+func (m *FileEntry) String() string { return proto.CompactTextString(m) }
+func (*FileEntry) ProtoMessage()    {}
+func (*FileEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{15}
+}
+func (m *FileEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileEntry.Unmarshal(m, b)
+}
+func (m *FileEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileEntry.Marshal(b, m, deterministic)
+}
+func (dst *FileEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileEntry.Merge(dst, src)
+}
+func (m *FileEntry) XXX_Size() int {
+	return xxx_messageInfo_FileEntry.Size(m)
+}
+func (m *FileEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileEntry proto.InternalMessageInfo
+
+func (m *FileEntry) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FileEntry) GetType() FileEntry_Type {
+	if m != nil {
+		return m.Type
+	}
+	return FileEntry_FILE
+}
+
+func (m *FileEntry) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *FileEntry) GetUid() uint32 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *FileEntry) GetGid() uint32 {
+	if m != nil {
+		return m.Gid
+	}
+	return 0
+}
+
+func (m *FileEntry) GetModTime() int64 {
+	if m != nil {
+		return m.ModTime
+	}
+	return 0
+}
+
+func (m *FileEntry) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *FileEntry) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+// FileChunk is sent on a ServeFiles stream. A chunk with an Entry begins a
+// new file, and the Data of it and any following chunks without an Entry make
+// up the file's contents.
+type FileChunk struct {
+	Entry                *FileEntry `protobuf:"bytes,1,opt,name=Entry" json:"Entry,omitempty"`
+	Data                 []byte     `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *FileChunk) Reset()         { *m = FileChunk{} }
+func (m *FileChunk) String() string { return proto.CompactTextString(m) }
+func (*FileChunk) ProtoMessage()    {}
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{16}
+}
+func (m *FileChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileChunk.Unmarshal(m, b)
+}
+func (m *FileChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileChunk.Marshal(b, m, deterministic)
+}
+func (dst *FileChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileChunk.Merge(dst, src)
+}
+func (m *FileChunk) XXX_Size() int {
+	return xxx_messageInfo_FileChunk.Size(m)
+}
+func (m *FileChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileChunk proto.InternalMessageInfo
+
+func (m *FileChunk) GetEntry() *FileEntry {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+func (m *FileChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// ManifestEntry records the size and SHA-256 checksum of a regular file in a
+// directory. Path is relative to the directory and slash-separated.
+type ManifestEntry struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path" json:"Path,omitempty"`
+	Size                 int64    `protobuf:"varint,2,opt,name=Size" json:"Size,omitempty"`
+	SHA256               string   `protobuf:"bytes,3,opt,name=SHA256" json:"SHA256,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ManifestEntry) Reset()         { *m = ManifestEntry{} }
+func (m *ManifestEntry) String() string { return proto.CompactTextString(m) }
+func (*ManifestEntry) ProtoMessage()    {}
+func (*ManifestEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{17}
+}
+func (m *ManifestEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ManifestEntry.Unmarshal(m, b)
+}
+func (m *ManifestEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ManifestEntry.Marshal(b, m, deterministic)
+}
+func (dst *ManifestEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ManifestEntry.Merge(dst, src)
+}
+func (m *ManifestEntry) XXX_Size() int {
+	return xxx_messageInfo_ManifestEntry.Size(m)
+}
+func (m *ManifestEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_ManifestEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ManifestEntry proto.InternalMessageInfo
+
+func (m *ManifestEntry) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ManifestEntry) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ManifestEntry) GetSHA256() string {
+	if m != nil {
+		return m.SHA256
+	}
+	return ""
+}
+
+// VerifyManifestRequest is sent on a VerifyManifest stream. Together the
+// Entries of all messages make up the manifest; Dir is set on the first
+// message only.
+type VerifyManifestRequest struct {
+	Dir                  string           `protobuf:"bytes,1,opt,name=Dir" json:"Dir,omitempty"`
+	Entries              []*ManifestEntry `protobuf:"bytes,2,rep,name=Entries" json:"Entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *VerifyManifestRequest) Reset()         { *m = VerifyManifestRequest{} }
+func (m *VerifyManifestRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyManifestRequest) ProtoMessage()    {}
+func (*VerifyManifestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{18}
+}
+func (m *VerifyManifestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyManifestRequest.Unmarshal(m, b)
+}
+func (m *VerifyManifestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyManifestRequest.Marshal(b, m, deterministic)
+}
+func (dst *VerifyManifestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyManifestRequest.Merge(dst, src)
+}
+func (m *VerifyManifestRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyManifestRequest.Size(m)
+}
+func (m *VerifyManifestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyManifestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyManifestRequest proto.InternalMessageInfo
+
+func (m *VerifyManifestRequest) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+func (m *VerifyManifestRequest) GetEntries() []*ManifestEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type ManifestMismatch struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path" json:"Path,omitempty"`
+	Reason               string   `protobuf:"bytes,2,opt,name=Reason" json:"Reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ManifestMismatch) Reset()         { *m = ManifestMismatch{} }
+func (m *ManifestMismatch) String() string { return proto.CompactTextString(m) }
+func (*ManifestMismatch) ProtoMessage()    {}
+func (*ManifestMismatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{19}
+}
+func (m *ManifestMismatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ManifestMismatch.Unmarshal(m, b)
+}
+func (m *ManifestMismatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ManifestMismatch.Marshal(b, m, deterministic)
+}
+func (dst *ManifestMismatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ManifestMismatch.Merge(dst, src)
+}
+func (m *ManifestMismatch) XXX_Size() int {
+	return xxx_messageInfo_ManifestMismatch.Size(m)
+}
+func (m *ManifestMismatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_ManifestMismatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ManifestMismatch proto.InternalMessageInfo
+
+func (m *ManifestMismatch) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ManifestMismatch) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type VerifyManifestReply struct {
+	Mismatches           []*ManifestMismatch `protobuf:"bytes,1,rep,name=Mismatches" json:"Mismatches,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *VerifyManifestReply) Reset()         { *m = VerifyManifestReply{} }
+func (m *VerifyManifestReply) String() string { return proto.CompactTextString(m) }
+func (*VerifyManifestReply) ProtoMessage()    {}
+func (*VerifyManifestReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{20}
+}
+func (m *VerifyManifestReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyManifestReply.Unmarshal(m, b)
+}
+func (m *VerifyManifestReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyManifestReply.Marshal(b, m, deterministic)
+}
+func (dst *VerifyManifestReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyManifestReply.Merge(dst, src)
+}
+func (m *VerifyManifestReply) XXX_Size() int {
+	return xxx_messageInfo_VerifyManifestReply.Size(m)
+}
+func (m *VerifyManifestReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyManifestReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyManifestReply proto.InternalMessageInfo
+
+func (m *VerifyManifestReply) GetMismatches() []*ManifestMismatch {
+	if m != nil {
+		return m.Mismatches
+	}
+	return nil
+}
+
+// ServeFilesRequest asks a FileServer for the contents of Dir. Every entry of
+// the directory is sent as a FileChunk, parents before children, and the
+// contents of each regular file follow its entry.
+type ServeFilesRequest struct {
+	Dir                  string   `protobuf:"bytes,1,opt,name=Dir" json:"Dir,omitempty"`
+	Excludes             []string `protobuf:"bytes,2,rep,name=Excludes" json:"Excludes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServeFilesRequest) Reset()         { *m = ServeFilesRequest{} }
+func (m *ServeFilesRequest) String() string { return proto.CompactTextString(m) }
+func (*ServeFilesRequest) ProtoMessage()    {}
+func (*ServeFilesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{21}
+}
+func (m *ServeFilesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServeFilesRequest.Unmarshal(m, b)
+}
+func (m *ServeFilesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServeFilesRequest.Marshal(b, m, deterministic)
+}
+func (dst *ServeFilesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServeFilesRequest.Merge(dst, src)
+}
+func (m *ServeFilesRequest) XXX_Size() int {
+	return xxx_messageInfo_ServeFilesRequest.Size(m)
+}
+func (m *ServeFilesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServeFilesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServeFilesRequest proto.InternalMessageInfo
+
+func (m *ServeFilesRequest) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+func (m *ServeFilesRequest) GetExcludes() []string {
+	if m != nil {
+		return m.Excludes
+	}
+	return nil
+}
+
+// PullDirRequest asks an agent to copy the directory Dir from the FileServer at
+// Source, a host:port address, into TargetDir on the agent's host.
+type PullDirRequest struct {
+	Source               string   `protobuf:"bytes,1,opt,name=Source" json:"Source,omitempty"`
+	Dir                  string   `protobuf:"bytes,2,opt,name=Dir" json:"Dir,omitempty"`
+	TargetDir            string   `protobuf:"bytes,3,opt,name=TargetDir" json:"TargetDir,omitempty"`
+	Excludes             []string `protobuf:"bytes,4,rep,name=Excludes" json:"Excludes,omitempty"`
+	Delete               bool     `protobuf:"varint,5,opt,name=Delete" json:"Delete,omitempty"`
+	BandwidthLimit       int64    `protobuf:"varint,6,opt,name=BandwidthLimit" json:"BandwidthLimit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PullDirRequest) Reset()         { *m = PullDirRequest{} }
+func (m *PullDirRequest) String() string { return proto.CompactTextString(m) }
+func (*PullDirRequest) ProtoMessage()    {}
+func (*PullDirRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{22}
+}
+func (m *PullDirRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PullDirRequest.Unmarshal(m, b)
+}
+func (m *PullDirRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PullDirRequest.Marshal(b, m, deterministic)
+}
+func (dst *PullDirRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PullDirRequest.Merge(dst, src)
+}
+func (m *PullDirRequest) XXX_Size() int {
+	return xxx_messageInfo_PullDirRequest.Size(m)
+}
+func (m *PullDirRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PullDirRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PullDirRequest proto.InternalMessageInfo
+
+func (m *PullDirRequest) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *PullDirRequest) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+func (m *PullDirRequest) GetTargetDir() string {
+	if m != nil {
+		return m.TargetDir
+	}
+	return ""
+}
+
+func (m *PullDirRequest) GetExcludes() []string {
+	if m != nil {
+		return m.Excludes
+	}
+	return nil
+}
+
+func (m *PullDirRequest) GetDelete() bool {
+	if m != nil {
+		return m.Delete
+	}
+	return false
+}
+
+func (m *PullDirRequest) GetBandwidthLimit() int64 {
+	if m != nil {
+		return m.BandwidthLimit
+	}
+	return 0
+}
+
+// PullDirReply reports the progress of a PullDir. The last reply of the stream
+// describes the completed copy.
+type PullDirReply struct {
+	Files                int64    `protobuf:"varint,1,opt,name=Files" json:"Files,omitempty"`
+	Bytes                int64    `protobuf:"varint,2,opt,name=Bytes" json:"Bytes,omitempty"`
+	Deleted              int64    `protobuf:"varint,3,opt,name=Deleted" json:"Deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PullDirReply) Reset()         { *m = PullDirReply{} }
+func (m *PullDirReply) String() string { return proto.CompactTextString(m) }
+func (*PullDirReply) ProtoMessage()    {}
+func (*PullDirReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hub_to_agent_c645fa1a8b94d837, []int{23}
+}
+func (m *PullDirReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PullDirReply.Unmarshal(m, b)
+}
+func (m *PullDirReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PullDirReply.Marshal(b, m, deterministic)
+}
+func (dst *PullDirReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PullDirReply.Merge(dst, src)
+}
+func (m *PullDirReply) XXX_Size() int {
+	return xxx_messageInfo_PullDirReply.Size(m)
+}
+func (m *PullDirReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PullDirReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PullDirReply proto.InternalMessageInfo
+
+func (m *PullDirReply) GetFiles() int64 {
+	if m != nil {
+		return m.Files
+	}
+	return 0
+}
+
+func (m *PullDirReply) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *PullDirReply) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*UpgradePrimariesRequest)(nil), "idl.UpgradePrimariesRequest")
+	proto.RegisterType((*DataDirPair)(nil), "idl.DataDirPair")
+	proto.RegisterType((*UpgradePrimariesReply)(nil), "idl.UpgradePrimariesReply")
+	proto.RegisterType((*ScanDataDirsRequest)(nil), "idl.ScanDataDirsRequest")
+	proto.RegisterType((*DataDirScan)(nil), "idl.DataDirScan")
+	proto.RegisterType((*ScanDataDirsReply)(nil), "idl.ScanDataDirsReply")
+	proto.RegisterType((*CreateSegmentDataDirRequest)(nil), "idl.CreateSegmentDataDirRequest")
+	proto.RegisterType((*CreateSegmentDataDirReply)(nil), "idl.CreateSegmentDataDirReply")
+	proto.RegisterType((*StopAgentRequest)(nil), "idl.StopAgentRequest")
+	proto.RegisterType((*StopAgentReply)(nil), "idl.StopAgentReply")
+	proto.RegisterType((*CheckSegmentDiskSpaceRequest)(nil), "idl.CheckSegmentDiskSpaceRequest")
+	proto.RegisterType((*CarryOverConfigurationRequest)(nil), "idl.CarryOverConfigurationRequest")
+	proto.RegisterType((*CarryOverConfigurationReply)(nil), "idl.CarryOverConfigurationReply")
+	proto.RegisterType((*ConfigurationCarryOver)(nil), "idl.ConfigurationCarryOver")
+	proto.RegisterType((*GUCChange)(nil), "idl.GUCChange")
+	proto.RegisterType((*FileEntry)(nil), "idl.FileEntry")
+	proto.RegisterType((*FileChunk)(nil), "idl.FileChunk")
+	proto.RegisterType((*ManifestEntry)(nil), "idl.ManifestEntry")
+	proto.RegisterType((*VerifyManifestRequest)(nil), "idl.VerifyManifestRequest")
+	proto.RegisterType((*ManifestMismatch)(nil), "idl.ManifestMismatch")
+	proto.RegisterType((*VerifyManifestReply)(nil), "idl.VerifyManifestReply")
+	proto.RegisterType((*ServeFilesRequest)(nil), "idl.ServeFilesRequest")
+	proto.RegisterType((*PullDirRequest)(nil), "idl.PullDirRequest")
+	proto.RegisterType((*PullDirReply)(nil), "idl.PullDirReply")
+	proto.RegisterEnum("idl.GUCChange_ChangeClass", GUCChange_ChangeClass_name, GUCChange_ChangeClass_value)
+	proto.RegisterEnum("idl.FileEntry_Type", FileEntry_Type_name, FileEntry_Type_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Agent service
+
+type AgentClient interface {
+	CheckDiskSpace(ctx context.Context, in *CheckSegmentDiskSpaceRequest, opts ...grpc.CallOption) (*CheckDiskSpaceReply, error)
+	UpgradePrimaries(ctx context.Context, in *UpgradePrimariesRequest, opts ...grpc.CallOption) (Agent_UpgradePrimariesClient, error)
+	ScanDataDirs(ctx context.Context, in *ScanDataDirsRequest, opts ...grpc.CallOption) (*ScanDataDirsReply, error)
+	CreateSegmentDataDirectories(ctx context.Context, in *CreateSegmentDataDirRequest, opts ...grpc.CallOption) (*CreateSegmentDataDirReply, error)
+	StopAgent(ctx context.Context, in *StopAgentRequest, opts ...grpc.CallOption) (*StopAgentReply, error)
+	CollectLogs(ctx context.Context, in *CollectLogsRequest, opts ...grpc.CallOption) (Agent_CollectLogsClient, error)
+	CarryOverConfiguration(ctx context.Context, in *CarryOverConfigurationRequest, opts ...grpc.CallOption) (*CarryOverConfigurationReply, error)
+	VerifyManifest(ctx context.Context, opts ...grpc.CallOption) (Agent_VerifyManifestClient, error)
+	PullDir(ctx context.Context, in *PullDirRequest, opts ...grpc.CallOption) (Agent_PullDirClient, error)
+}
+
+type agentClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAgentClient(cc *grpc.ClientConn) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) CheckDiskSpace(ctx context.Context, in *CheckSegmentDiskSpaceRequest, opts ...grpc.CallOption) (*CheckDiskSpaceReply, error) {
+	out := new(CheckDiskSpaceReply)
+	err := grpc.Invoke(ctx, "/idl.Agent/CheckDiskSpace", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) UpgradePrimaries(ctx context.Context, in *UpgradePrimariesRequest, opts ...grpc.CallOption) (Agent_UpgradePrimariesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Agent_serviceDesc.Streams[0], c.cc, "/idl.Agent/UpgradePrimaries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentUpgradePrimariesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_UpgradePrimariesClient interface {
+	Recv() (*UpgradePrimariesReply, error)
+	grpc.ClientStream
+}
+
+type agentUpgradePrimariesClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentUpgradePrimariesClient) Recv() (*UpgradePrimariesReply, error) {
+	m := new(UpgradePrimariesReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentClient) ScanDataDirs(ctx context.Context, in *ScanDataDirsRequest, opts ...grpc.CallOption) (*ScanDataDirsReply, error) {
+	out := new(ScanDataDirsReply)
+	err := grpc.Invoke(ctx, "/idl.Agent/ScanDataDirs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) CreateSegmentDataDirectories(ctx context.Context, in *CreateSegmentDataDirRequest, opts ...grpc.CallOption) (*CreateSegmentDataDirReply, error) {
+	out := new(CreateSegmentDataDirReply)
+	err := grpc.Invoke(ctx, "/idl.Agent/CreateSegmentDataDirectories", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) StopAgent(ctx context.Context, in *StopAgentRequest, opts ...grpc.CallOption) (*StopAgentReply, error) {
+	out := new(StopAgentReply)
+	err := grpc.Invoke(ctx, "/idl.Agent/StopAgent", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) CollectLogs(ctx context.Context, in *CollectLogsRequest, opts ...grpc.CallOption) (Agent_CollectLogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Agent_serviceDesc.Streams[1], c.cc, "/idl.Agent/CollectLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentCollectLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_CollectLogsClient interface {
+	Recv() (*CollectLogsReply, error)
+	grpc.ClientStream
+}
+
+type agentCollectLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentCollectLogsClient) Recv() (*CollectLogsReply, error) {
+	m := new(CollectLogsReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentClient) CarryOverConfiguration(ctx context.Context, in *CarryOverConfigurationRequest, opts ...grpc.CallOption) (*CarryOverConfigurationReply, error) {
+	out := new(CarryOverConfigurationReply)
+	err := grpc.Invoke(ctx, "/idl.Agent/CarryOverConfiguration", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) VerifyManifest(ctx context.Context, opts ...grpc.CallOption) (Agent_VerifyManifestClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Agent_serviceDesc.Streams[2], c.cc, "/idl.Agent/VerifyManifest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentVerifyManifestClient{stream}
+	return x, nil
+}
+
+type Agent_VerifyManifestClient interface {
+	Send(*VerifyManifestRequest) error
+	CloseAndRecv() (*VerifyManifestReply, error)
+	grpc.ClientStream
+}
+
+type agentVerifyManifestClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentVerifyManifestClient) Send(m *VerifyManifestRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *agentVerifyManifestClient) CloseAndRecv() (*VerifyManifestReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(VerifyManifestReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentClient) PullDir(ctx context.Context, in *PullDirRequest, opts ...grpc.CallOption) (Agent_PullDirClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Agent_serviceDesc.Streams[3], c.cc, "/idl.Agent/PullDir", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentPullDirClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_PullDirClient interface {
+	Recv() (*PullDirReply, error)
+	grpc.ClientStream
+}
+
+type agentPullDirClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentPullDirClient) Recv() (*PullDirReply, error) {
+	m := new(PullDirReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Agent service
+
+type AgentServer interface {
+	CheckDiskSpace(context.Context, *CheckSegmentDiskSpaceRequest) (*CheckDiskSpaceReply, error)
+	UpgradePrimaries(*UpgradePrimariesRequest, Agent_UpgradePrimariesServer) error
+	ScanDataDirs(context.Context, *ScanDataDirsRequest) (*ScanDataDirsReply, error)
+	CreateSegmentDataDirectories(context.Context, *CreateSegmentDataDirRequest) (*CreateSegmentDataDirReply, error)
+	StopAgent(context.Context, *StopAgentRequest) (*StopAgentReply, error)
+	CollectLogs(*CollectLogsRequest, Agent_CollectLogsServer) error
+	CarryOverConfiguration(context.Context, *CarryOverConfigurationRequest) (*CarryOverConfigurationReply, error)
+	VerifyManifest(Agent_VerifyManifestServer) error
+	PullDir(*PullDirRequest, Agent_PullDirServer) error
+}
+
+func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
+	s.RegisterService(&_Agent_serviceDesc, srv)
+}
+
+func _Agent_CheckDiskSpace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckSegmentDiskSpaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).CheckDiskSpace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Agent/CheckDiskSpace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).CheckDiskSpace(ctx, req.(*CheckSegmentDiskSpaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_UpgradePrimaries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UpgradePrimariesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).UpgradePrimaries(m, &agentUpgradePrimariesServer{stream})
+}
+
+type Agent_UpgradePrimariesServer interface {
+	Send(*UpgradePrimariesReply) error
+	grpc.ServerStream
+}
+
+type agentUpgradePrimariesServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentUpgradePrimariesServer) Send(m *UpgradePrimariesReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Agent_ScanDataDirs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanDataDirsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).ScanDataDirs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Agent/ScanDataDirs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).ScanDataDirs(ctx, req.(*ScanDataDirsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_CreateSegmentDataDirectories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSegmentDataDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).CreateSegmentDataDirectories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Agent/CreateSegmentDataDirectories",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(AgentServer).CreateSegmentDataDirectories(ctx, req.(*CreateSegmentDataDirRequest))
@@ -548,6 +1699,92 @@ func _Agent_StopAgent_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Agent_CollectLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CollectLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).CollectLogs(m, &agentCollectLogsServer{stream})
+}
+
+type Agent_CollectLogsServer interface {
+	Send(*CollectLogsReply) error
+	grpc.ServerStream
+}
+
+type agentCollectLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentCollectLogsServer) Send(m *CollectLogsReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Agent_CarryOverConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CarryOverConfigurationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).CarryOverConfiguration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Agent/CarryOverConfiguration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).CarryOverConfiguration(ctx, req.(*CarryOverConfigurationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_VerifyManifest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServer).VerifyManifest(&agentVerifyManifestServer{stream})
+}
+
+type Agent_VerifyManifestServer interface {
+	SendAndClose(*VerifyManifestReply) error
+	Recv() (*VerifyManifestRequest, error)
+	grpc.ServerStream
+}
+
+type agentVerifyManifestServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentVerifyManifestServer) SendAndClose(m *VerifyManifestReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *agentVerifyManifestServer) Recv() (*VerifyManifestRequest, error) {
+	m := new(VerifyManifestRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Agent_PullDir_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullDirRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).PullDir(m, &agentPullDirServer{stream})
+}
+
+type Agent_PullDirServer interface {
+	Send(*PullDirReply) error
+	grpc.ServerStream
+}
+
+type agentPullDirServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentPullDirServer) Send(m *PullDirReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Agent_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "idl.Agent",
 	HandlerType: (*AgentServer)(nil),
@@ -557,8 +1794,8 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Agent_CheckDiskSpace_Handler,
 		},
 		{
-			MethodName: "UpgradePrimaries",
-			Handler:    _Agent_UpgradePrimaries_Handler,
+			MethodName: "ScanDataDirs",
+			Handler:    _Agent_ScanDataDirs_Handler,
 		},
 		{
 			MethodName: "CreateSegmentDataDirectories",
@@ -568,45 +1805,217 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopAgent",
 			Handler:    _Agent_StopAgent_Handler,
 		},
+		{
+			MethodName: "CarryOverConfiguration",
+			Handler:    _Agent_CarryOverConfiguration_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UpgradePrimaries",
+			Handler:       _Agent_UpgradePrimaries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CollectLogs",
+			Handler:       _Agent_CollectLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "VerifyManifest",
+			Handler:       _Agent_VerifyManifest_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "PullDir",
+			Handler:       _Agent_PullDir_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "hub_to_agent.proto",
+}
+
+// Client API for FileServer service
+
+type FileServerClient interface {
+	ServeFiles(ctx context.Context, in *ServeFilesRequest, opts ...grpc.CallOption) (FileServer_ServeFilesClient, error)
+}
+
+type fileServerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileServerClient(cc *grpc.ClientConn) FileServerClient {
+	return &fileServerClient{cc}
+}
+
+func (c *fileServerClient) ServeFiles(ctx context.Context, in *ServeFilesRequest, opts ...grpc.CallOption) (FileServer_ServeFilesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_FileServer_serviceDesc.Streams[0], c.cc, "/idl.FileServer/ServeFiles", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServerServeFilesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileServer_ServeFilesClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type fileServerServeFilesClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServerServeFilesClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for FileServer service
+
+type FileServerServer interface {
+	ServeFiles(*ServeFilesRequest, FileServer_ServeFilesServer) error
+}
+
+func RegisterFileServerServer(s *grpc.Server, srv FileServerServer) {
+	s.RegisterService(&_FileServer_serviceDesc, srv)
+}
+
+func _FileServer_ServeFiles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServeFilesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServerServer).ServeFiles(m, &fileServerServeFilesServer{stream})
+}
+
+type FileServer_ServeFilesServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
+}
+
+type fileServerServeFilesServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServerServeFilesServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _FileServer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "idl.FileServer",
+	HandlerType: (*FileServerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ServeFiles",
+			Handler:       _FileServer_ServeFiles_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "hub_to_agent.proto",
 }
 
-func init() { proto.RegisterFile("hub_to_agent.proto", fileDescriptor_hub_to_agent_db6442cf90a0c433) }
-
-var fileDescriptor_hub_to_agent_db6442cf90a0c433 = []byte{
-	// 503 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0x4d, 0x6f, 0x9b, 0x40,
-	0x10, 0xad, 0x3f, 0x88, 0xe3, 0x71, 0x9a, 0xa2, 0xad, 0xa2, 0x50, 0x62, 0x45, 0x14, 0xf5, 0xc0,
-	0xc9, 0x07, 0x37, 0x97, 0x1c, 0x6b, 0x73, 0xa9, 0xd4, 0x34, 0x16, 0x6e, 0x7a, 0x8d, 0xd6, 0x30,
-	0xb2, 0x57, 0x26, 0x2c, 0x5d, 0x96, 0x43, 0x7e, 0x51, 0x7f, 0x4a, 0xfe, 0x56, 0xb5, 0xbb, 0x26,
-	0x06, 0x6a, 0xe7, 0xb6, 0xfb, 0xe6, 0xcd, 0xcc, 0x9b, 0x37, 0x0b, 0x40, 0x36, 0xe5, 0xea, 0x51,
-	0xf2, 0x47, 0xba, 0xc6, 0x4c, 0x4e, 0x72, 0xc1, 0x25, 0x27, 0x3d, 0x96, 0xa4, 0xae, 0x1d, 0xa7,
-	0x4c, 0x05, 0x36, 0xe5, 0xca, 0xc0, 0xfe, 0xdf, 0x2e, 0x5c, 0x3e, 0xe4, 0x6b, 0x41, 0x13, 0x5c,
-	0x08, 0xf6, 0x44, 0x05, 0xc3, 0x22, 0xc2, 0x3f, 0x25, 0x16, 0x92, 0xf8, 0x70, 0xb6, 0xe4, 0xa5,
-	0x88, 0x71, 0xc6, 0xb2, 0x90, 0x09, 0xa7, 0xe3, 0x75, 0x82, 0x61, 0xd4, 0xc0, 0x14, 0xe7, 0x17,
-	0x15, 0x6b, 0x94, 0x3b, 0x4e, 0xd7, 0x70, 0xea, 0x18, 0xf9, 0x02, 0xef, 0xcd, 0xfd, 0x37, 0x8a,
-	0x82, 0xf1, 0xcc, 0xe9, 0x69, 0x52, 0x13, 0x24, 0x37, 0x70, 0x16, 0x52, 0x49, 0x43, 0x26, 0x16,
-	0x94, 0x89, 0xc2, 0xe9, 0x7b, 0xbd, 0x60, 0x34, 0xb5, 0x27, 0x2c, 0x49, 0x27, 0xb5, 0x40, 0xd4,
-	0x60, 0x91, 0x31, 0x0c, 0xe7, 0x1b, 0x8c, 0xb7, 0xf7, 0x59, 0xfa, 0xec, 0x58, 0x5e, 0x27, 0x38,
-	0x8d, 0xf6, 0x00, 0xf1, 0x60, 0xf4, 0x50, 0xe0, 0x0f, 0x96, 0x6d, 0xef, 0x78, 0x82, 0xce, 0x89,
-	0x8e, 0xd7, 0x21, 0x12, 0xc0, 0x87, 0x3b, 0x5a, 0x48, 0x14, 0x33, 0x1a, 0x6f, 0xcb, 0x5c, 0x8d,
-	0x30, 0xd0, 0xea, 0xda, 0xb0, 0xff, 0xd2, 0x81, 0x51, 0xad, 0xb5, 0x9a, 0xca, 0x38, 0xb1, 0x03,
-	0x77, 0xf6, 0x34, 0xc1, 0xfd, 0xec, 0x15, 0xab, 0x5b, 0x9f, 0xbd, 0x62, 0x5d, 0x03, 0x98, 0xb4,
-	0x05, 0x17, 0x52, 0xdb, 0x63, 0x45, 0x35, 0x44, 0xc5, 0x4d, 0x82, 0x8e, 0xf7, 0x4d, 0x7c, 0x8f,
-	0x10, 0x07, 0x06, 0x73, 0x9e, 0x49, 0xcc, 0xa4, 0xf6, 0xc0, 0x8a, 0xaa, 0x2b, 0x21, 0xd0, 0x0f,
-	0x67, 0xdf, 0x43, 0x3d, 0xba, 0x15, 0xe9, 0xb3, 0x7f, 0x09, 0x17, 0xff, 0xaf, 0x3c, 0x4f, 0x9f,
-	0xfd, 0x5b, 0xb8, 0x9a, 0x0b, 0xa4, 0x12, 0x97, 0xb8, 0x7e, 0xc2, 0xac, 0x92, 0x57, 0xbd, 0x07,
-	0x17, 0x4e, 0x13, 0x2a, 0x69, 0xa2, 0xb6, 0xd3, 0xf1, 0x7a, 0xc1, 0x30, 0x7a, 0xbd, 0xfb, 0x57,
-	0xf0, 0xe9, 0x70, 0xaa, 0xaa, 0x4b, 0xc0, 0x5e, 0x4a, 0x9e, 0x7f, 0x53, 0xcf, 0x71, 0x57, 0xcc,
-	0xb7, 0xe1, 0xbc, 0x86, 0x29, 0x56, 0x0e, 0x63, 0xbd, 0xb9, 0xaa, 0x02, 0x2b, 0xb6, 0xcb, 0x9c,
-	0xc6, 0x58, 0xb5, 0xbf, 0x81, 0x81, 0x30, 0x47, 0x6d, 0xf5, 0x68, 0xea, 0xea, 0xb7, 0xa1, 0x73,
-	0xda, 0xe4, 0xa8, 0xa2, 0x36, 0x44, 0x77, 0x9b, 0xa2, 0xa7, 0x2f, 0x5d, 0xb0, 0xb4, 0x00, 0x72,
-	0x0f, 0xe7, 0xcd, 0x3a, 0xe4, 0xf3, 0xbe, 0xf8, 0x11, 0x41, 0xae, 0x73, 0xb0, 0xbf, 0x1a, 0xe5,
-	0x1d, 0xf9, 0x09, 0x76, 0xdb, 0x63, 0x32, 0xd6, 0xfc, 0x23, 0x5f, 0x9b, 0xeb, 0x1e, 0x89, 0x9a,
-	0x7a, 0x2b, 0x18, 0x1f, 0xf2, 0x17, 0x63, 0xc9, 0x75, 0x6d, 0xcf, 0x68, 0x39, 0xbe, 0x3d, 0xf7,
-	0xfa, 0x0d, 0x86, 0xe9, 0x71, 0x0b, 0xc3, 0xd7, 0x95, 0x90, 0x0b, 0x4d, 0x6f, 0xaf, 0xcd, 0xfd,
-	0xd8, 0x86, 0x75, 0xea, 0xea, 0x44, 0xff, 0x4d, 0xbe, 0xfe, 0x0b, 0x00, 0x00, 0xff, 0xff, 0xcb,
-	0xe9, 0x3a, 0xd6, 0x7a, 0x04, 0x00, 0x00,
+func init() { proto.RegisterFile("hub_to_agent.proto", fileDescriptor_hub_to_agent_c645fa1a8b94d837) }
+
+var fileDescriptor_hub_to_agent_c645fa1a8b94d837 = []byte{
+	// 1404 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x57, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0xee, 0xfa, 0x27, 0x8e, 0x8f, 0x13, 0xe3, 0x4e, 0x9a, 0xd4, 0x6c, 0x42, 0x65, 0x56, 0x55,
+	0xf1, 0x05, 0x8a, 0x4a, 0x68, 0x11, 0x05, 0x09, 0xc9, 0x7f, 0x6d, 0x03, 0x71, 0x6c, 0x8d, 0x93,
+	0xa0, 0x72, 0x53, 0x26, 0xf6, 0xc4, 0x1e, 0xb2, 0xde, 0x35, 0xb3, 0xe3, 0x16, 0x23, 0xae, 0x78,
+	0x26, 0xfa, 0x0c, 0x3c, 0x0b, 0x2f, 0xc0, 0x35, 0x9a, 0xbf, 0xf5, 0xae, 0x6b, 0x57, 0x5c, 0x79,
+	0xce, 0x39, 0xdf, 0xcc, 0x9c, 0xf3, 0xed, 0xf9, 0x19, 0x03, 0x9a, 0xcc, 0xaf, 0x5f, 0x8b, 0xf0,
+	0x35, 0x19, 0xd3, 0x40, 0x1c, 0xcf, 0x78, 0x28, 0x42, 0x94, 0x65, 0x23, 0xdf, 0xad, 0x0c, 0x7d,
+	0x26, 0x0d, 0x93, 0xf9, 0xb5, 0x56, 0x7b, 0xff, 0x64, 0xe0, 0xfe, 0xe5, 0x6c, 0xcc, 0xc9, 0x88,
+	0xf6, 0x39, 0x9b, 0x12, 0xce, 0x68, 0x84, 0xe9, 0xaf, 0x73, 0x1a, 0x09, 0xe4, 0xc1, 0xce, 0x20,
+	0x9c, 0xf3, 0x21, 0x6d, 0xb2, 0xa0, 0xcd, 0x78, 0xd5, 0xa9, 0x39, 0xf5, 0x22, 0x4e, 0xe9, 0x24,
+	0xe6, 0x82, 0xf0, 0x31, 0x15, 0x06, 0x93, 0xd1, 0x98, 0xa4, 0x0e, 0x3d, 0x84, 0x5d, 0x2d, 0x5f,
+	0x51, 0x1e, 0xb1, 0x30, 0xa8, 0x66, 0x15, 0x28, 0xad, 0x44, 0x4f, 0x60, 0xa7, 0x4d, 0x04, 0x69,
+	0x33, 0xde, 0x27, 0x8c, 0x47, 0xd5, 0x5c, 0x2d, 0x5b, 0x2f, 0x9d, 0x54, 0x8e, 0xd9, 0xc8, 0x3f,
+	0x4e, 0x18, 0x70, 0x0a, 0x85, 0x8e, 0xa0, 0xd8, 0x9a, 0xd0, 0xe1, 0x6d, 0x2f, 0xf0, 0x17, 0xd5,
+	0x7c, 0xcd, 0xa9, 0x6f, 0xe3, 0xa5, 0x02, 0xd5, 0xa0, 0x74, 0x19, 0xd1, 0x33, 0x16, 0xdc, 0x76,
+	0xc3, 0x11, 0xad, 0x6e, 0x29, 0x7b, 0x52, 0x85, 0xea, 0xf0, 0x51, 0x97, 0x44, 0x82, 0xf2, 0x26,
+	0x19, 0xde, 0xce, 0x67, 0x32, 0x84, 0x82, 0xf2, 0x6e, 0x55, 0x8d, 0x1e, 0x00, 0xb4, 0xc2, 0xd9,
+	0xa2, 0x13, 0x8c, 0x59, 0x40, 0xab, 0xdb, 0x0a, 0x94, 0xd0, 0xc8, 0xbb, 0xfa, 0x84, 0x13, 0xdf,
+	0xa7, 0x3e, 0x8b, 0xa6, 0xd5, 0x62, 0xcd, 0xa9, 0xe7, 0x71, 0x52, 0xe5, 0xfd, 0xed, 0x40, 0x29,
+	0xe1, 0xbc, 0xe4, 0x45, 0x73, 0x69, 0x94, 0x86, 0xe0, 0xb4, 0x72, 0xc9, 0x9e, 0x45, 0x65, 0x92,
+	0xec, 0x59, 0xd4, 0x03, 0x00, 0xbd, 0xad, 0x1f, 0x72, 0xa1, 0x08, 0xce, 0xe3, 0x84, 0x46, 0xda,
+	0xf5, 0x06, 0x65, 0xcf, 0x69, 0xfb, 0x52, 0x83, 0xaa, 0x50, 0x68, 0x85, 0x81, 0xa0, 0x81, 0x50,
+	0x2c, 0xe6, 0xb1, 0x15, 0x11, 0x82, 0x5c, 0xbb, 0x79, 0xda, 0x56, 0xe4, 0xe5, 0xb1, 0x5a, 0x7b,
+	0xa7, 0xb0, 0xff, 0x7e, 0xd2, 0xcc, 0xfc, 0x05, 0x7a, 0x0c, 0xdb, 0x7d, 0x1e, 0x8e, 0x39, 0x8d,
+	0x22, 0x15, 0x4d, 0xe9, 0xe4, 0x9e, 0xfa, 0x80, 0x03, 0x3a, 0x9e, 0xd2, 0x40, 0x58, 0x1b, 0x8e,
+	0x51, 0xde, 0x17, 0xb0, 0x37, 0x18, 0x92, 0xc0, 0xc4, 0x11, 0xe7, 0x9e, 0x0b, 0xdb, 0x56, 0x55,
+	0x75, 0x6a, 0xd9, 0x7a, 0x11, 0xc7, 0xb2, 0x37, 0x88, 0x69, 0x94, 0x3b, 0xa5, 0xeb, 0x69, 0x02,
+	0xad, 0x88, 0xee, 0x41, 0xbe, 0xb9, 0x10, 0x34, 0x52, 0x94, 0x65, 0xb1, 0x16, 0xa4, 0xf6, 0x39,
+	0xf3, 0x69, 0xa4, 0x58, 0xca, 0x62, 0x2d, 0x78, 0xb7, 0x70, 0x37, 0xed, 0x87, 0x0c, 0xe7, 0x11,
+	0xe4, 0xa5, 0x52, 0xbb, 0xb0, 0x92, 0x8c, 0xd2, 0x80, 0xb5, 0x19, 0x1d, 0x03, 0x6a, 0xd3, 0x1b,
+	0x32, 0xf7, 0x45, 0x32, 0x05, 0x32, 0x8a, 0xb1, 0x35, 0x16, 0xef, 0x19, 0x1c, 0xb6, 0x38, 0x25,
+	0x82, 0x1a, 0x5e, 0xcc, 0x91, 0x89, 0xe0, 0x47, 0x44, 0x90, 0x51, 0x22, 0x78, 0x2b, 0x7b, 0x87,
+	0xf0, 0xf1, 0xfa, 0xad, 0x33, 0x7f, 0xe1, 0x21, 0xa8, 0x0c, 0x44, 0x38, 0x6b, 0xc8, 0xba, 0x37,
+	0x87, 0x79, 0x15, 0x28, 0x27, 0x74, 0x12, 0x35, 0x83, 0x23, 0x55, 0x22, 0xf6, 0x04, 0x16, 0xdd,
+	0x0e, 0x66, 0x64, 0x48, 0xed, 0xf5, 0x4f, 0xa0, 0xc0, 0xf5, 0xd2, 0x7c, 0x43, 0x57, 0xc5, 0xad,
+	0xf6, 0xac, 0x82, 0x71, 0x81, 0xaf, 0x71, 0x3a, 0xb3, 0xe2, 0xf4, 0x3b, 0x07, 0x3e, 0x69, 0x11,
+	0xce, 0x17, 0xbd, 0x37, 0x94, 0xb7, 0xc2, 0xe0, 0x86, 0x8d, 0xe7, 0x9c, 0x08, 0x16, 0x06, 0xcb,
+	0x3b, 0xd3, 0xd5, 0xef, 0xfc, 0xaf, 0xea, 0x3f, 0x06, 0xa4, 0x73, 0xbc, 0x4b, 0x7e, 0x09, 0xb9,
+	0x6d, 0x2f, 0x92, 0xf7, 0x1c, 0x5e, 0x63, 0x91, 0x78, 0x9d, 0xf3, 0x29, 0x7c, 0x56, 0xe3, 0xdf,
+	0xb7, 0x78, 0x3f, 0xc1, 0xe1, 0x26, 0xb7, 0x65, 0x7a, 0x7c, 0x0b, 0x10, 0x9b, 0xad, 0xcb, 0x87,
+	0x9a, 0xab, 0x24, 0x38, 0xc6, 0xe0, 0x04, 0xdc, 0xfb, 0x03, 0x0e, 0xd6, 0xa3, 0x92, 0xb5, 0xe8,
+	0xa4, 0x6b, 0xb1, 0x0e, 0x85, 0xd6, 0x84, 0x04, 0x63, 0xaa, 0x29, 0x2e, 0x9d, 0x94, 0xd5, 0x6d,
+	0x2f, 0x2e, 0x5b, 0x5a, 0x8d, 0xad, 0x59, 0xd6, 0xfb, 0xcb, 0x66, 0xa3, 0x13, 0x08, 0x59, 0x9b,
+	0xb6, 0x1f, 0x2c, 0x35, 0xde, 0xbf, 0x0e, 0x14, 0xe3, 0x6d, 0xb2, 0xc6, 0xcf, 0xc9, 0x94, 0x9a,
+	0xfa, 0x51, 0x6b, 0xd9, 0xcf, 0x34, 0x83, 0x57, 0xc4, 0x9f, 0x53, 0xd3, 0x75, 0x92, 0x2a, 0x89,
+	0x30, 0x2d, 0x5c, 0x21, 0x74, 0x57, 0x4f, 0xaa, 0xd0, 0x63, 0xc8, 0xb7, 0x7c, 0x12, 0x45, 0xaa,
+	0xe1, 0x94, 0x4d, 0x1e, 0xc5, 0xd7, 0x1e, 0xeb, 0x1f, 0x85, 0xc0, 0x1a, 0x28, 0x63, 0x3f, 0xa7,
+	0x6f, 0x95, 0x33, 0x79, 0x5d, 0xcc, 0x46, 0xf4, 0x3a, 0x50, 0x4a, 0xe0, 0xd1, 0x0e, 0x6c, 0xf7,
+	0x7b, 0xf8, 0xa2, 0xd1, 0x3c, 0xeb, 0x54, 0xee, 0xa0, 0x12, 0x14, 0x70, 0xe7, 0xbc, 0xd1, 0xed,
+	0xb4, 0x2b, 0x8e, 0x16, 0xba, 0xbd, 0xab, 0x4e, 0xbb, 0x92, 0x41, 0x65, 0x80, 0xcb, 0xf3, 0x18,
+	0x99, 0xf5, 0xfe, 0xcc, 0x40, 0x51, 0x56, 0xbc, 0x24, 0x62, 0x21, 0x03, 0xef, 0x13, 0x31, 0xb1,
+	0x81, 0xcb, 0x35, 0xfa, 0x0c, 0x72, 0x62, 0x31, 0xd3, 0x11, 0x97, 0x4f, 0xf6, 0x94, 0xcf, 0xf1,
+	0x8e, 0xe3, 0x8b, 0xc5, 0x8c, 0x62, 0x05, 0x90, 0x9b, 0xd5, 0x58, 0x91, 0x81, 0xef, 0x62, 0xb5,
+	0x46, 0x15, 0xc8, 0x5e, 0xb2, 0x91, 0x8a, 0x77, 0x17, 0xcb, 0xa5, 0xd4, 0xbc, 0x60, 0x23, 0x15,
+	0xcd, 0x2e, 0x96, 0x4b, 0x19, 0x63, 0x37, 0x1c, 0x5d, 0xb0, 0xa9, 0x9e, 0x48, 0x59, 0x6c, 0x45,
+	0x79, 0xe2, 0x80, 0xfd, 0x4e, 0xd5, 0x08, 0xca, 0x62, 0xb5, 0x46, 0x07, 0xb0, 0xa5, 0x29, 0x35,
+	0x33, 0xc7, 0x48, 0xde, 0x37, 0x90, 0x93, 0xbe, 0xa0, 0x6d, 0xc8, 0x3d, 0x3f, 0x55, 0x24, 0xec,
+	0x42, 0xb1, 0x7d, 0x8a, 0x3b, 0xad, 0x8b, 0x1e, 0x7e, 0xa5, 0x69, 0x18, 0xbc, 0xea, 0x9e, 0x9d,
+	0x9e, 0xff, 0x50, 0xc9, 0x48, 0xba, 0x5e, 0x36, 0x70, 0x5b, 0x49, 0x59, 0xaf, 0xa3, 0x39, 0x68,
+	0x4d, 0xe6, 0xc1, 0x2d, 0x7a, 0x08, 0x79, 0x15, 0x9a, 0x29, 0xf6, 0x72, 0x3a, 0x60, 0x9c, 0x8f,
+	0x99, 0x92, 0xa5, 0xa7, 0x58, 0xd9, 0xc1, 0x6a, 0xed, 0xf5, 0x60, 0xb7, 0x4b, 0x02, 0x76, 0x43,
+	0x23, 0xb1, 0x99, 0x4e, 0x1b, 0x53, 0x26, 0x1d, 0xd3, 0xe0, 0x65, 0xe3, 0xe4, 0xe9, 0x57, 0x26,
+	0x69, 0x8c, 0xe4, 0xfd, 0x08, 0xfb, 0x57, 0x94, 0xb3, 0x9b, 0x85, 0x3d, 0xd6, 0xb6, 0x87, 0x0a,
+	0x64, 0x97, 0xfd, 0x5d, 0x2e, 0xd1, 0xe7, 0x50, 0xb0, 0xd9, 0xad, 0x4b, 0x01, 0x29, 0xbf, 0x53,
+	0xfe, 0x60, 0x0b, 0xf1, 0xbe, 0x83, 0x8a, 0xb5, 0x74, 0x59, 0x34, 0x25, 0x62, 0x38, 0x59, 0xeb,
+	0xec, 0x01, 0x6c, 0x61, 0x4a, 0x22, 0xd3, 0x44, 0x8a, 0xd8, 0x48, 0xde, 0x19, 0xec, 0xad, 0x3a,
+	0x26, 0x1b, 0xc0, 0x53, 0x00, 0x7b, 0x1c, 0xb5, 0x0d, 0x60, 0x3f, 0xe5, 0x87, 0x35, 0xe3, 0x04,
+	0xd0, 0x6b, 0xc0, 0xdd, 0x01, 0xe5, 0x6f, 0xa8, 0x9a, 0x3c, 0x9b, 0x43, 0x74, 0x61, 0xbb, 0xf3,
+	0xdb, 0xd0, 0x9f, 0x8f, 0x68, 0xdc, 0x51, 0xad, 0xec, 0xfd, 0xe5, 0x40, 0xb9, 0x3f, 0xf7, 0xfd,
+	0xc4, 0xd4, 0x90, 0xa4, 0xaa, 0xea, 0x34, 0x67, 0x18, 0xc9, 0x1e, 0x9c, 0x59, 0x1e, 0x7c, 0x04,
+	0x45, 0xf3, 0x7a, 0x60, 0xdc, 0x7c, 0x81, 0xa5, 0x22, 0x75, 0x6d, 0x2e, 0x7d, 0xad, 0xbc, 0xa3,
+	0x4d, 0x7d, 0x2a, 0xa8, 0x79, 0x6b, 0x19, 0x09, 0x3d, 0x82, 0x72, 0x93, 0x04, 0xa3, 0xb7, 0x6c,
+	0x24, 0x26, 0x67, 0x6c, 0xca, 0x84, 0xc9, 0xec, 0x15, 0xad, 0x77, 0x01, 0x3b, 0xb1, 0xd7, 0x92,
+	0xc0, 0x78, 0x16, 0x3b, 0x89, 0x59, 0xbc, 0x61, 0x6e, 0xcb, 0x39, 0xaf, 0x6e, 0x1b, 0x99, 0xc9,
+	0x6d, 0xc5, 0x93, 0x77, 0x79, 0xc8, 0xab, 0xf9, 0x86, 0x7a, 0x50, 0x4e, 0x8f, 0x29, 0xf4, 0xe9,
+	0x72, 0x76, 0x6d, 0x98, 0x77, 0x6e, 0x75, 0xed, 0x78, 0x93, 0x93, 0xf2, 0x0e, 0xea, 0x43, 0x65,
+	0xf5, 0xa5, 0x83, 0x8e, 0x14, 0x7e, 0xc3, 0xab, 0xd9, 0x75, 0x37, 0x58, 0xd5, 0x79, 0x8f, 0x1d,
+	0xd4, 0x84, 0x9d, 0xe4, 0x43, 0x03, 0xe9, 0xdb, 0xd7, 0xbc, 0x81, 0xdc, 0x83, 0x35, 0x16, 0xed,
+	0xd5, 0x35, 0x1c, 0xad, 0x7b, 0x04, 0xd0, 0xa1, 0x08, 0x95, 0x87, 0x35, 0x1d, 0xd1, 0xe6, 0x27,
+	0x86, 0xfb, 0xe0, 0x03, 0x08, 0x7d, 0xc7, 0x33, 0x28, 0xc6, 0xef, 0x06, 0xa4, 0x93, 0x7a, 0xf5,
+	0x6d, 0xe1, 0xee, 0xad, 0xaa, 0xf5, 0xd6, 0x06, 0x94, 0x5a, 0xa1, 0xef, 0xd3, 0xa1, 0x38, 0x0b,
+	0xc7, 0x11, 0xba, 0x6f, 0x46, 0x62, 0xac, 0xb1, 0xdb, 0xf7, 0xdf, 0x37, 0x58, 0x96, 0x7e, 0x86,
+	0x83, 0xf5, 0x93, 0x17, 0x79, 0x7a, 0xd3, 0x87, 0x5e, 0x13, 0x6e, 0xed, 0x83, 0x18, 0xed, 0xe4,
+	0xf7, 0x50, 0x4e, 0x97, 0x34, 0xd2, 0x5f, 0x6e, 0x6d, 0x03, 0x72, 0xab, 0x6b, 0x6d, 0xea, 0xa4,
+	0xba, 0x83, 0x9e, 0x42, 0xc1, 0xa4, 0x35, 0xd2, 0x94, 0xa4, 0x4b, 0xd3, 0xbd, 0x9b, 0x56, 0x9a,
+	0x20, 0x4f, 0x9e, 0x03, 0xc8, 0x84, 0x57, 0xbd, 0x80, 0xa3, 0xaf, 0x01, 0x96, 0x5d, 0x01, 0x99,
+	0x8f, 0xbf, 0xda, 0x26, 0xdc, 0x65, 0x7b, 0x56, 0xdd, 0x5b, 0x9e, 0x73, 0xbd, 0xa5, 0xfe, 0xcb,
+	0x7d, 0xf9, 0xdf, 0x00, 0x3b, 0xb1, 0xcf, 0x9d, 0xf8, 0x0d, 0x00, 0x00,
 }
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/greenplum-db/gpupgrade/idl (interfaces: CliToHubClient,CliToHubServer,CliToHub_ExecuteServer,CliToHub_ExecuteClient)
+// Source: github.com/greenplum-db/gpupgrade/idl (interfaces: CliToHubClient,CliToHubServer,CliToHub_ExecuteServer,CliToHub_ExecuteClient,CliToHub_WatchServer)
 
 // Package mock_idl is a generated GoMock package.
 package mock_idl
@@ -76,6 +76,46 @@ func (mr *MockCliToHubClientMockRecorder) CheckVersion(arg0, arg1 interface{}, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckVersion", reflect.TypeOf((*MockCliToHubClient)(nil).CheckVersion), varargs...)
 }
 
+// CollectLogs mocks base method
+func (m *MockCliToHubClient) CollectLogs(arg0 context.Context, arg1 *idl.CollectLogsRequest, arg2 ...grpc.CallOption) (idl.CliToHub_CollectLogsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CollectLogs", varargs...)
+	ret0, _ := ret[0].(idl.CliToHub_CollectLogsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectLogs indicates an expected call of CollectLogs
+func (mr *MockCliToHubClientMockRecorder) CollectLogs(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectLogs", reflect.TypeOf((*MockCliToHubClient)(nil).CollectLogs), varargs...)
+}
+
+// Estimate mocks base method
+func (m *MockCliToHubClient) Estimate(arg0 context.Context, arg1 *idl.EstimateRequest, arg2 ...grpc.CallOption) (*idl.EstimateReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Estimate", varargs...)
+	ret0, _ := ret[0].(*idl.EstimateReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Estimate indicates an expected call of Estimate
+func (mr *MockCliToHubClientMockRecorder) Estimate(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Estimate", reflect.TypeOf((*MockCliToHubClient)(nil).Estimate), varargs...)
+}
+
 // Execute mocks base method
 func (m *MockCliToHubClient) Execute(arg0 context.Context, arg1 *idl.ExecuteRequest, arg2 ...grpc.CallOption) (idl.CliToHub_ExecuteClient, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +216,46 @@ func (mr *MockCliToHubClientMockRecorder) InitializeCreateCluster(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitializeCreateCluster", reflect.TypeOf((*MockCliToHubClient)(nil).InitializeCreateCluster), varargs...)
 }
 
+// Jobs mocks base method
+func (m *MockCliToHubClient) Jobs(arg0 context.Context, arg1 *idl.JobsRequest, arg2 ...grpc.CallOption) (*idl.JobsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Jobs", varargs...)
+	ret0, _ := ret[0].(*idl.JobsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Jobs indicates an expected call of Jobs
+func (mr *MockCliToHubClientMockRecorder) Jobs(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Jobs", reflect.TypeOf((*MockCliToHubClient)(nil).Jobs), varargs...)
+}
+
+// Report mocks base method
+func (m *MockCliToHubClient) Report(arg0 context.Context, arg1 *idl.ReportRequest, arg2 ...grpc.CallOption) (*idl.ReportReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Report", varargs...)
+	ret0, _ := ret[0].(*idl.ReportReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report
+func (mr *MockCliToHubClientMockRecorder) Report(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockCliToHubClient)(nil).Report), varargs...)
+}
+
 // RestartAgents mocks base method
 func (m *MockCliToHubClient) RestartAgents(arg0 context.Context, arg1 *idl.RestartAgentsRequest, arg2 ...grpc.CallOption) (*idl.RestartAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -236,6 +316,26 @@ func (mr *MockCliToHubClientMockRecorder) StopServices(arg0, arg1 interface{}, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopServices", reflect.TypeOf((*MockCliToHubClient)(nil).StopServices), varargs...)
 }
 
+// Watch mocks base method
+func (m *MockCliToHubClient) Watch(arg0 context.Context, arg1 *idl.WatchRequest, arg2 ...grpc.CallOption) (idl.CliToHub_WatchClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Watch", varargs...)
+	ret0, _ := ret[0].(idl.CliToHub_WatchClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockCliToHubClientMockRecorder) Watch(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockCliToHubClient)(nil).Watch), varargs...)
+}
+
 // MockCliToHubServer is a mock of CliToHubServer interface
 type MockCliToHubServer struct {
 	ctrl     *gomock.Controller
@@ -289,6 +389,35 @@ func (mr *MockCliToHubServerMockRecorder) CheckVersion(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckVersion", reflect.TypeOf((*MockCliToHubServer)(nil).CheckVersion), arg0, arg1)
 }
 
+// CollectLogs mocks base method
+func (m *MockCliToHubServer) CollectLogs(arg0 *idl.CollectLogsRequest, arg1 idl.CliToHub_CollectLogsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectLogs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CollectLogs indicates an expected call of CollectLogs
+func (mr *MockCliToHubServerMockRecorder) CollectLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectLogs", reflect.TypeOf((*MockCliToHubServer)(nil).CollectLogs), arg0, arg1)
+}
+
+// Estimate mocks base method
+func (m *MockCliToHubServer) Estimate(arg0 context.Context, arg1 *idl.EstimateRequest) (*idl.EstimateReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Estimate", arg0, arg1)
+	ret0, _ := ret[0].(*idl.EstimateReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Estimate indicates an expected call of Estimate
+func (mr *MockCliToHubServerMockRecorder) Estimate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Estimate", reflect.TypeOf((*MockCliToHubServer)(nil).Estimate), arg0, arg1)
+}
+
 // Execute mocks base method
 func (m *MockCliToHubServer) Execute(arg0 *idl.ExecuteRequest, arg1 idl.CliToHub_ExecuteServer) error {
 	m.ctrl.T.Helper()
@@ -360,6 +489,36 @@ func (mr *MockCliToHubServerMockRecorder) InitializeCreateCluster(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitializeCreateCluster", reflect.TypeOf((*MockCliToHubServer)(nil).InitializeCreateCluster), arg0, arg1)
 }
 
+// Jobs mocks base method
+func (m *MockCliToHubServer) Jobs(arg0 context.Context, arg1 *idl.JobsRequest) (*idl.JobsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Jobs", arg0, arg1)
+	ret0, _ := ret[0].(*idl.JobsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Jobs indicates an expected call of Jobs
+func (mr *MockCliToHubServerMockRecorder) Jobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Jobs", reflect.TypeOf((*MockCliToHubServer)(nil).Jobs), arg0, arg1)
+}
+
+// Report mocks base method
+func (m *MockCliToHubServer) Report(arg0 context.Context, arg1 *idl.ReportRequest) (*idl.ReportReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ReportReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report
+func (mr *MockCliToHubServerMockRecorder) Report(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockCliToHubServer)(nil).Report), arg0, arg1)
+}
+
 // RestartAgents mocks base method
 func (m *MockCliToHubServer) RestartAgents(arg0 context.Context, arg1 *idl.RestartAgentsRequest) (*idl.RestartAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -405,6 +564,20 @@ func (mr *MockCliToHubServerMockRecorder) StopServices(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopServices", reflect.TypeOf((*MockCliToHubServer)(nil).StopServices), arg0, arg1)
 }
 
+// Watch mocks base method
+func (m *MockCliToHubServer) Watch(arg0 *idl.WatchRequest, arg1 idl.CliToHub_WatchServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockCliToHubServerMockRecorder) Watch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockCliToHubServer)(nil).Watch), arg0, arg1)
+}
+
 // MockCliToHub_ExecuteServer is a mock of CliToHub_ExecuteServer interface
 type MockCliToHub_ExecuteServer struct {
 	ctrl     *gomock.Controller
@@ -646,3 +819,122 @@ func (mr *MockCliToHub_ExecuteClientMockRecorder) Trailer() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockCliToHub_ExecuteClient)(nil).Trailer))
 }
+
+// MockCliToHub_WatchServer is a mock of CliToHub_WatchServer interface
+type MockCliToHub_WatchServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCliToHub_WatchServerMockRecorder
+}
+
+// MockCliToHub_WatchServerMockRecorder is the mock recorder for MockCliToHub_WatchServer
+type MockCliToHub_WatchServerMockRecorder struct {
+	mock *MockCliToHub_WatchServer
+}
+
+// NewMockCliToHub_WatchServer creates a new mock instance
+func NewMockCliToHub_WatchServer(ctrl *gomock.Controller) *MockCliToHub_WatchServer {
+	mock := &MockCliToHub_WatchServer{ctrl: ctrl}
+	mock.recorder = &MockCliToHub_WatchServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCliToHub_WatchServer) EXPECT() *MockCliToHub_WatchServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method
+func (m *MockCliToHub_WatchServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockCliToHub_WatchServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).Context))
+}
+
+// RecvMsg mocks base method
+func (m *MockCliToHub_WatchServer) RecvMsg(arg0 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecvMsg", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockCliToHub_WatchServerMockRecorder) RecvMsg(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).RecvMsg), arg0)
+}
+
+// Send mocks base method
+func (m *MockCliToHub_WatchServer) Send(arg0 *idl.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockCliToHub_WatchServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockCliToHub_WatchServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockCliToHub_WatchServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method
+func (m *MockCliToHub_WatchServer) SendMsg(arg0 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMsg", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockCliToHub_WatchServerMockRecorder) SendMsg(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).SendMsg), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockCliToHub_WatchServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockCliToHub_WatchServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockCliToHub_WatchServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockCliToHub_WatchServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockCliToHub_WatchServer)(nil).SetTrailer), arg0)
+}
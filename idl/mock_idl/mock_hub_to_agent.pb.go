@@ -9,6 +9,7 @@ import (
 	idl "github.com/greenplum-db/gpupgrade/idl"
 	context "golang.org/x/net/context"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
 	reflect "reflect"
 )
 
@@ -56,14 +57,14 @@ func (mr *MockAgentClientMockRecorder) CheckDiskSpace(ctx, in interface{}, opts
 }
 
 // UpgradePrimaries mocks base method
-func (m *MockAgentClient) UpgradePrimaries(ctx context.Context, in *idl.UpgradePrimariesRequest, opts ...grpc.CallOption) (*idl.UpgradePrimariesReply, error) {
+func (m *MockAgentClient) UpgradePrimaries(ctx context.Context, in *idl.UpgradePrimariesRequest, opts ...grpc.CallOption) (idl.Agent_UpgradePrimariesClient, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "UpgradePrimaries", varargs...)
-	ret0, _ := ret[0].(*idl.UpgradePrimariesReply)
+	ret0, _ := ret[0].(idl.Agent_UpgradePrimariesClient)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -75,6 +76,26 @@ func (mr *MockAgentClientMockRecorder) UpgradePrimaries(ctx, in interface{}, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradePrimaries", reflect.TypeOf((*MockAgentClient)(nil).UpgradePrimaries), varargs...)
 }
 
+// ScanDataDirs mocks base method
+func (m *MockAgentClient) ScanDataDirs(ctx context.Context, in *idl.ScanDataDirsRequest, opts ...grpc.CallOption) (*idl.ScanDataDirsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ScanDataDirs", varargs...)
+	ret0, _ := ret[0].(*idl.ScanDataDirsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanDataDirs indicates an expected call of ScanDataDirs
+func (mr *MockAgentClientMockRecorder) ScanDataDirs(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanDataDirs", reflect.TypeOf((*MockAgentClient)(nil).ScanDataDirs), varargs...)
+}
+
 // CreateSegmentDataDirectories mocks base method
 func (m *MockAgentClient) CreateSegmentDataDirectories(ctx context.Context, in *idl.CreateSegmentDataDirRequest, opts ...grpc.CallOption) (*idl.CreateSegmentDataDirReply, error) {
 	m.ctrl.T.Helper()
@@ -115,85 +136,1555 @@ func (mr *MockAgentClientMockRecorder) StopAgent(ctx, in interface{}, opts ...in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAgent", reflect.TypeOf((*MockAgentClient)(nil).StopAgent), varargs...)
 }
 
-// MockAgentServer is a mock of AgentServer interface
-type MockAgentServer struct {
+// CollectLogs mocks base method
+func (m *MockAgentClient) CollectLogs(ctx context.Context, in *idl.CollectLogsRequest, opts ...grpc.CallOption) (idl.Agent_CollectLogsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CollectLogs", varargs...)
+	ret0, _ := ret[0].(idl.Agent_CollectLogsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectLogs indicates an expected call of CollectLogs
+func (mr *MockAgentClientMockRecorder) CollectLogs(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectLogs", reflect.TypeOf((*MockAgentClient)(nil).CollectLogs), varargs...)
+}
+
+// CarryOverConfiguration mocks base method
+func (m *MockAgentClient) CarryOverConfiguration(ctx context.Context, in *idl.CarryOverConfigurationRequest, opts ...grpc.CallOption) (*idl.CarryOverConfigurationReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CarryOverConfiguration", varargs...)
+	ret0, _ := ret[0].(*idl.CarryOverConfigurationReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CarryOverConfiguration indicates an expected call of CarryOverConfiguration
+func (mr *MockAgentClientMockRecorder) CarryOverConfiguration(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CarryOverConfiguration", reflect.TypeOf((*MockAgentClient)(nil).CarryOverConfiguration), varargs...)
+}
+
+// VerifyManifest mocks base method
+func (m *MockAgentClient) VerifyManifest(ctx context.Context, opts ...grpc.CallOption) (idl.Agent_VerifyManifestClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyManifest", varargs...)
+	ret0, _ := ret[0].(idl.Agent_VerifyManifestClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyManifest indicates an expected call of VerifyManifest
+func (mr *MockAgentClientMockRecorder) VerifyManifest(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyManifest", reflect.TypeOf((*MockAgentClient)(nil).VerifyManifest), varargs...)
+}
+
+// PullDir mocks base method
+func (m *MockAgentClient) PullDir(ctx context.Context, in *idl.PullDirRequest, opts ...grpc.CallOption) (idl.Agent_PullDirClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PullDir", varargs...)
+	ret0, _ := ret[0].(idl.Agent_PullDirClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PullDir indicates an expected call of PullDir
+func (mr *MockAgentClientMockRecorder) PullDir(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullDir", reflect.TypeOf((*MockAgentClient)(nil).PullDir), varargs...)
+}
+
+// MockAgent_UpgradePrimariesClient is a mock of Agent_UpgradePrimariesClient interface
+type MockAgent_UpgradePrimariesClient struct {
 	ctrl     *gomock.Controller
-	recorder *MockAgentServerMockRecorder
+	recorder *MockAgent_UpgradePrimariesClientMockRecorder
 }
 
-// MockAgentServerMockRecorder is the mock recorder for MockAgentServer
-type MockAgentServerMockRecorder struct {
-	mock *MockAgentServer
+// MockAgent_UpgradePrimariesClientMockRecorder is the mock recorder for MockAgent_UpgradePrimariesClient
+type MockAgent_UpgradePrimariesClientMockRecorder struct {
+	mock *MockAgent_UpgradePrimariesClient
 }
 
-// NewMockAgentServer creates a new mock instance
-func NewMockAgentServer(ctrl *gomock.Controller) *MockAgentServer {
-	mock := &MockAgentServer{ctrl: ctrl}
-	mock.recorder = &MockAgentServerMockRecorder{mock}
+// NewMockAgent_UpgradePrimariesClient creates a new mock instance
+func NewMockAgent_UpgradePrimariesClient(ctrl *gomock.Controller) *MockAgent_UpgradePrimariesClient {
+	mock := &MockAgent_UpgradePrimariesClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_UpgradePrimariesClientMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockAgentServer) EXPECT() *MockAgentServerMockRecorder {
+func (m *MockAgent_UpgradePrimariesClient) EXPECT() *MockAgent_UpgradePrimariesClientMockRecorder {
 	return m.recorder
 }
 
-// CheckDiskSpace mocks base method
-func (m *MockAgentServer) CheckDiskSpace(arg0 context.Context, arg1 *idl.CheckSegmentDiskSpaceRequest) (*idl.CheckDiskSpaceReply, error) {
+// Recv mocks base method
+func (m *MockAgent_UpgradePrimariesClient) Recv() (*idl.UpgradePrimariesReply, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckDiskSpace", arg0, arg1)
-	ret0, _ := ret[0].(*idl.CheckDiskSpaceReply)
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.UpgradePrimariesReply)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CheckDiskSpace indicates an expected call of CheckDiskSpace
-func (mr *MockAgentServerMockRecorder) CheckDiskSpace(arg0, arg1 interface{}) *gomock.Call {
+// Recv indicates an expected call of Recv
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) Recv() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDiskSpace", reflect.TypeOf((*MockAgentServer)(nil).CheckDiskSpace), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).Recv))
 }
 
-// UpgradePrimaries mocks base method
-func (m *MockAgentServer) UpgradePrimaries(arg0 context.Context, arg1 *idl.UpgradePrimariesRequest) (*idl.UpgradePrimariesReply, error) {
+// Header mocks base method
+func (m *MockAgent_UpgradePrimariesClient) Header() (metadata.MD, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpgradePrimaries", arg0, arg1)
-	ret0, _ := ret[0].(*idl.UpgradePrimariesReply)
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpgradePrimaries indicates an expected call of UpgradePrimaries
-func (mr *MockAgentServerMockRecorder) UpgradePrimaries(arg0, arg1 interface{}) *gomock.Call {
+// Header indicates an expected call of Header
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) Header() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradePrimaries", reflect.TypeOf((*MockAgentServer)(nil).UpgradePrimaries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).Header))
 }
 
-// CreateSegmentDataDirectories mocks base method
-func (m *MockAgentServer) CreateSegmentDataDirectories(arg0 context.Context, arg1 *idl.CreateSegmentDataDirRequest) (*idl.CreateSegmentDataDirReply, error) {
+// Trailer mocks base method
+func (m *MockAgent_UpgradePrimariesClient) Trailer() metadata.MD {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSegmentDataDirectories", arg0, arg1)
-	ret0, _ := ret[0].(*idl.CreateSegmentDataDirReply)
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockAgent_UpgradePrimariesClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockAgent_UpgradePrimariesClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_UpgradePrimariesClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_UpgradePrimariesClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_UpgradePrimariesClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_UpgradePrimariesClient)(nil).RecvMsg), m)
+}
+
+// MockAgent_CollectLogsClient is a mock of Agent_CollectLogsClient interface
+type MockAgent_CollectLogsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_CollectLogsClientMockRecorder
+}
+
+// MockAgent_CollectLogsClientMockRecorder is the mock recorder for MockAgent_CollectLogsClient
+type MockAgent_CollectLogsClientMockRecorder struct {
+	mock *MockAgent_CollectLogsClient
+}
+
+// NewMockAgent_CollectLogsClient creates a new mock instance
+func NewMockAgent_CollectLogsClient(ctrl *gomock.Controller) *MockAgent_CollectLogsClient {
+	mock := &MockAgent_CollectLogsClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_CollectLogsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_CollectLogsClient) EXPECT() *MockAgent_CollectLogsClientMockRecorder {
+	return m.recorder
+}
+
+// Recv mocks base method
+func (m *MockAgent_CollectLogsClient) Recv() (*idl.CollectLogsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.CollectLogsReply)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSegmentDataDirectories indicates an expected call of CreateSegmentDataDirectories
-func (mr *MockAgentServerMockRecorder) CreateSegmentDataDirectories(arg0, arg1 interface{}) *gomock.Call {
+// Recv indicates an expected call of Recv
+func (mr *MockAgent_CollectLogsClientMockRecorder) Recv() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSegmentDataDirectories", reflect.TypeOf((*MockAgentServer)(nil).CreateSegmentDataDirectories), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).Recv))
 }
 
-// StopAgent mocks base method
-func (m *MockAgentServer) StopAgent(arg0 context.Context, arg1 *idl.StopAgentRequest) (*idl.StopAgentReply, error) {
+// Header mocks base method
+func (m *MockAgent_CollectLogsClient) Header() (metadata.MD, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StopAgent", arg0, arg1)
-	ret0, _ := ret[0].(*idl.StopAgentReply)
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// StopAgent indicates an expected call of StopAgent
-func (mr *MockAgentServerMockRecorder) StopAgent(arg0, arg1 interface{}) *gomock.Call {
+// Header indicates an expected call of Header
+func (mr *MockAgent_CollectLogsClientMockRecorder) Header() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAgent", reflect.TypeOf((*MockAgentServer)(nil).StopAgent), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockAgent_CollectLogsClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockAgent_CollectLogsClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockAgent_CollectLogsClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockAgent_CollectLogsClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockAgent_CollectLogsClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_CollectLogsClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_CollectLogsClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_CollectLogsClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_CollectLogsClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_CollectLogsClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_CollectLogsClient)(nil).RecvMsg), m)
+}
+
+// MockAgent_VerifyManifestClient is a mock of Agent_VerifyManifestClient interface
+type MockAgent_VerifyManifestClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_VerifyManifestClientMockRecorder
+}
+
+// MockAgent_VerifyManifestClientMockRecorder is the mock recorder for MockAgent_VerifyManifestClient
+type MockAgent_VerifyManifestClientMockRecorder struct {
+	mock *MockAgent_VerifyManifestClient
+}
+
+// NewMockAgent_VerifyManifestClient creates a new mock instance
+func NewMockAgent_VerifyManifestClient(ctrl *gomock.Controller) *MockAgent_VerifyManifestClient {
+	mock := &MockAgent_VerifyManifestClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_VerifyManifestClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_VerifyManifestClient) EXPECT() *MockAgent_VerifyManifestClientMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockAgent_VerifyManifestClient) Send(arg0 *idl.VerifyManifestRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockAgent_VerifyManifestClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).Send), arg0)
+}
+
+// CloseAndRecv mocks base method
+func (m *MockAgent_VerifyManifestClient) CloseAndRecv() (*idl.VerifyManifestReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*idl.VerifyManifestReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv
+func (mr *MockAgent_VerifyManifestClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).CloseAndRecv))
+}
+
+// Header mocks base method
+func (m *MockAgent_VerifyManifestClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header
+func (mr *MockAgent_VerifyManifestClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockAgent_VerifyManifestClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockAgent_VerifyManifestClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockAgent_VerifyManifestClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockAgent_VerifyManifestClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockAgent_VerifyManifestClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_VerifyManifestClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_VerifyManifestClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_VerifyManifestClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_VerifyManifestClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_VerifyManifestClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_VerifyManifestClient)(nil).RecvMsg), m)
+}
+
+// MockAgent_PullDirClient is a mock of Agent_PullDirClient interface
+type MockAgent_PullDirClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_PullDirClientMockRecorder
+}
+
+// MockAgent_PullDirClientMockRecorder is the mock recorder for MockAgent_PullDirClient
+type MockAgent_PullDirClientMockRecorder struct {
+	mock *MockAgent_PullDirClient
+}
+
+// NewMockAgent_PullDirClient creates a new mock instance
+func NewMockAgent_PullDirClient(ctrl *gomock.Controller) *MockAgent_PullDirClient {
+	mock := &MockAgent_PullDirClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_PullDirClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_PullDirClient) EXPECT() *MockAgent_PullDirClientMockRecorder {
+	return m.recorder
+}
+
+// Recv mocks base method
+func (m *MockAgent_PullDirClient) Recv() (*idl.PullDirReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.PullDirReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv
+func (mr *MockAgent_PullDirClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_PullDirClient)(nil).Recv))
+}
+
+// Header mocks base method
+func (m *MockAgent_PullDirClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header
+func (mr *MockAgent_PullDirClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_PullDirClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockAgent_PullDirClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockAgent_PullDirClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_PullDirClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockAgent_PullDirClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockAgent_PullDirClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_PullDirClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockAgent_PullDirClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_PullDirClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_PullDirClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_PullDirClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_PullDirClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_PullDirClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_PullDirClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_PullDirClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_PullDirClient)(nil).RecvMsg), m)
+}
+
+// MockAgentServer is a mock of AgentServer interface
+type MockAgentServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentServerMockRecorder
+}
+
+// MockAgentServerMockRecorder is the mock recorder for MockAgentServer
+type MockAgentServerMockRecorder struct {
+	mock *MockAgentServer
+}
+
+// NewMockAgentServer creates a new mock instance
+func NewMockAgentServer(ctrl *gomock.Controller) *MockAgentServer {
+	mock := &MockAgentServer{ctrl: ctrl}
+	mock.recorder = &MockAgentServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgentServer) EXPECT() *MockAgentServerMockRecorder {
+	return m.recorder
+}
+
+// CheckDiskSpace mocks base method
+func (m *MockAgentServer) CheckDiskSpace(arg0 context.Context, arg1 *idl.CheckSegmentDiskSpaceRequest) (*idl.CheckDiskSpaceReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckDiskSpace", arg0, arg1)
+	ret0, _ := ret[0].(*idl.CheckDiskSpaceReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckDiskSpace indicates an expected call of CheckDiskSpace
+func (mr *MockAgentServerMockRecorder) CheckDiskSpace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDiskSpace", reflect.TypeOf((*MockAgentServer)(nil).CheckDiskSpace), arg0, arg1)
+}
+
+// UpgradePrimaries mocks base method
+func (m *MockAgentServer) UpgradePrimaries(arg0 *idl.UpgradePrimariesRequest, arg1 idl.Agent_UpgradePrimariesServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradePrimaries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradePrimaries indicates an expected call of UpgradePrimaries
+func (mr *MockAgentServerMockRecorder) UpgradePrimaries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradePrimaries", reflect.TypeOf((*MockAgentServer)(nil).UpgradePrimaries), arg0, arg1)
+}
+
+// ScanDataDirs mocks base method
+func (m *MockAgentServer) ScanDataDirs(arg0 context.Context, arg1 *idl.ScanDataDirsRequest) (*idl.ScanDataDirsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanDataDirs", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ScanDataDirsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanDataDirs indicates an expected call of ScanDataDirs
+func (mr *MockAgentServerMockRecorder) ScanDataDirs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanDataDirs", reflect.TypeOf((*MockAgentServer)(nil).ScanDataDirs), arg0, arg1)
+}
+
+// CreateSegmentDataDirectories mocks base method
+func (m *MockAgentServer) CreateSegmentDataDirectories(arg0 context.Context, arg1 *idl.CreateSegmentDataDirRequest) (*idl.CreateSegmentDataDirReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSegmentDataDirectories", arg0, arg1)
+	ret0, _ := ret[0].(*idl.CreateSegmentDataDirReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSegmentDataDirectories indicates an expected call of CreateSegmentDataDirectories
+func (mr *MockAgentServerMockRecorder) CreateSegmentDataDirectories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSegmentDataDirectories", reflect.TypeOf((*MockAgentServer)(nil).CreateSegmentDataDirectories), arg0, arg1)
+}
+
+// StopAgent mocks base method
+func (m *MockAgentServer) StopAgent(arg0 context.Context, arg1 *idl.StopAgentRequest) (*idl.StopAgentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopAgent", arg0, arg1)
+	ret0, _ := ret[0].(*idl.StopAgentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopAgent indicates an expected call of StopAgent
+func (mr *MockAgentServerMockRecorder) StopAgent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAgent", reflect.TypeOf((*MockAgentServer)(nil).StopAgent), arg0, arg1)
+}
+
+// CollectLogs mocks base method
+func (m *MockAgentServer) CollectLogs(arg0 *idl.CollectLogsRequest, arg1 idl.Agent_CollectLogsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectLogs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CollectLogs indicates an expected call of CollectLogs
+func (mr *MockAgentServerMockRecorder) CollectLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectLogs", reflect.TypeOf((*MockAgentServer)(nil).CollectLogs), arg0, arg1)
+}
+
+// CarryOverConfiguration mocks base method
+func (m *MockAgentServer) CarryOverConfiguration(arg0 context.Context, arg1 *idl.CarryOverConfigurationRequest) (*idl.CarryOverConfigurationReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CarryOverConfiguration", arg0, arg1)
+	ret0, _ := ret[0].(*idl.CarryOverConfigurationReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CarryOverConfiguration indicates an expected call of CarryOverConfiguration
+func (mr *MockAgentServerMockRecorder) CarryOverConfiguration(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CarryOverConfiguration", reflect.TypeOf((*MockAgentServer)(nil).CarryOverConfiguration), arg0, arg1)
+}
+
+// VerifyManifest mocks base method
+func (m *MockAgentServer) VerifyManifest(arg0 idl.Agent_VerifyManifestServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyManifest", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyManifest indicates an expected call of VerifyManifest
+func (mr *MockAgentServerMockRecorder) VerifyManifest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyManifest", reflect.TypeOf((*MockAgentServer)(nil).VerifyManifest), arg0)
+}
+
+// PullDir mocks base method
+func (m *MockAgentServer) PullDir(arg0 *idl.PullDirRequest, arg1 idl.Agent_PullDirServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PullDir", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PullDir indicates an expected call of PullDir
+func (mr *MockAgentServerMockRecorder) PullDir(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullDir", reflect.TypeOf((*MockAgentServer)(nil).PullDir), arg0, arg1)
+}
+
+// MockAgent_UpgradePrimariesServer is a mock of Agent_UpgradePrimariesServer interface
+type MockAgent_UpgradePrimariesServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_UpgradePrimariesServerMockRecorder
+}
+
+// MockAgent_UpgradePrimariesServerMockRecorder is the mock recorder for MockAgent_UpgradePrimariesServer
+type MockAgent_UpgradePrimariesServerMockRecorder struct {
+	mock *MockAgent_UpgradePrimariesServer
+}
+
+// NewMockAgent_UpgradePrimariesServer creates a new mock instance
+func NewMockAgent_UpgradePrimariesServer(ctrl *gomock.Controller) *MockAgent_UpgradePrimariesServer {
+	mock := &MockAgent_UpgradePrimariesServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_UpgradePrimariesServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_UpgradePrimariesServer) EXPECT() *MockAgent_UpgradePrimariesServerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockAgent_UpgradePrimariesServer) Send(arg0 *idl.UpgradePrimariesReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).Send), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockAgent_UpgradePrimariesServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockAgent_UpgradePrimariesServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockAgent_UpgradePrimariesServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockAgent_UpgradePrimariesServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_UpgradePrimariesServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_UpgradePrimariesServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_UpgradePrimariesServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_UpgradePrimariesServer)(nil).RecvMsg), m)
+}
+
+// MockAgent_CollectLogsServer is a mock of Agent_CollectLogsServer interface
+type MockAgent_CollectLogsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_CollectLogsServerMockRecorder
+}
+
+// MockAgent_CollectLogsServerMockRecorder is the mock recorder for MockAgent_CollectLogsServer
+type MockAgent_CollectLogsServerMockRecorder struct {
+	mock *MockAgent_CollectLogsServer
+}
+
+// NewMockAgent_CollectLogsServer creates a new mock instance
+func NewMockAgent_CollectLogsServer(ctrl *gomock.Controller) *MockAgent_CollectLogsServer {
+	mock := &MockAgent_CollectLogsServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_CollectLogsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_CollectLogsServer) EXPECT() *MockAgent_CollectLogsServerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockAgent_CollectLogsServer) Send(arg0 *idl.CollectLogsReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockAgent_CollectLogsServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).Send), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockAgent_CollectLogsServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockAgent_CollectLogsServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockAgent_CollectLogsServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockAgent_CollectLogsServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockAgent_CollectLogsServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockAgent_CollectLogsServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockAgent_CollectLogsServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_CollectLogsServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_CollectLogsServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_CollectLogsServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_CollectLogsServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_CollectLogsServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_CollectLogsServer)(nil).RecvMsg), m)
+}
+
+// MockAgent_VerifyManifestServer is a mock of Agent_VerifyManifestServer interface
+type MockAgent_VerifyManifestServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_VerifyManifestServerMockRecorder
+}
+
+// MockAgent_VerifyManifestServerMockRecorder is the mock recorder for MockAgent_VerifyManifestServer
+type MockAgent_VerifyManifestServerMockRecorder struct {
+	mock *MockAgent_VerifyManifestServer
+}
+
+// NewMockAgent_VerifyManifestServer creates a new mock instance
+func NewMockAgent_VerifyManifestServer(ctrl *gomock.Controller) *MockAgent_VerifyManifestServer {
+	mock := &MockAgent_VerifyManifestServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_VerifyManifestServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_VerifyManifestServer) EXPECT() *MockAgent_VerifyManifestServerMockRecorder {
+	return m.recorder
+}
+
+// SendAndClose mocks base method
+func (m *MockAgent_VerifyManifestServer) SendAndClose(arg0 *idl.VerifyManifestReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose
+func (mr *MockAgent_VerifyManifestServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).SendAndClose), arg0)
+}
+
+// Recv mocks base method
+func (m *MockAgent_VerifyManifestServer) Recv() (*idl.VerifyManifestRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.VerifyManifestRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv
+func (mr *MockAgent_VerifyManifestServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).Recv))
+}
+
+// SetHeader mocks base method
+func (m *MockAgent_VerifyManifestServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockAgent_VerifyManifestServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockAgent_VerifyManifestServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockAgent_VerifyManifestServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockAgent_VerifyManifestServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockAgent_VerifyManifestServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockAgent_VerifyManifestServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_VerifyManifestServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_VerifyManifestServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_VerifyManifestServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_VerifyManifestServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_VerifyManifestServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_VerifyManifestServer)(nil).RecvMsg), m)
+}
+
+// MockAgent_PullDirServer is a mock of Agent_PullDirServer interface
+type MockAgent_PullDirServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_PullDirServerMockRecorder
+}
+
+// MockAgent_PullDirServerMockRecorder is the mock recorder for MockAgent_PullDirServer
+type MockAgent_PullDirServerMockRecorder struct {
+	mock *MockAgent_PullDirServer
+}
+
+// NewMockAgent_PullDirServer creates a new mock instance
+func NewMockAgent_PullDirServer(ctrl *gomock.Controller) *MockAgent_PullDirServer {
+	mock := &MockAgent_PullDirServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_PullDirServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAgent_PullDirServer) EXPECT() *MockAgent_PullDirServerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockAgent_PullDirServer) Send(arg0 *idl.PullDirReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockAgent_PullDirServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_PullDirServer)(nil).Send), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockAgent_PullDirServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockAgent_PullDirServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_PullDirServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockAgent_PullDirServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockAgent_PullDirServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_PullDirServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockAgent_PullDirServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockAgent_PullDirServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_PullDirServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockAgent_PullDirServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockAgent_PullDirServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_PullDirServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockAgent_PullDirServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockAgent_PullDirServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_PullDirServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockAgent_PullDirServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockAgent_PullDirServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_PullDirServer)(nil).RecvMsg), m)
+}
+
+// MockFileServerClient is a mock of FileServerClient interface
+type MockFileServerClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileServerClientMockRecorder
+}
+
+// MockFileServerClientMockRecorder is the mock recorder for MockFileServerClient
+type MockFileServerClientMockRecorder struct {
+	mock *MockFileServerClient
+}
+
+// NewMockFileServerClient creates a new mock instance
+func NewMockFileServerClient(ctrl *gomock.Controller) *MockFileServerClient {
+	mock := &MockFileServerClient{ctrl: ctrl}
+	mock.recorder = &MockFileServerClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFileServerClient) EXPECT() *MockFileServerClientMockRecorder {
+	return m.recorder
+}
+
+// ServeFiles mocks base method
+func (m *MockFileServerClient) ServeFiles(ctx context.Context, in *idl.ServeFilesRequest, opts ...grpc.CallOption) (idl.FileServer_ServeFilesClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ServeFiles", varargs...)
+	ret0, _ := ret[0].(idl.FileServer_ServeFilesClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServeFiles indicates an expected call of ServeFiles
+func (mr *MockFileServerClientMockRecorder) ServeFiles(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeFiles", reflect.TypeOf((*MockFileServerClient)(nil).ServeFiles), varargs...)
+}
+
+// MockFileServer_ServeFilesClient is a mock of FileServer_ServeFilesClient interface
+type MockFileServer_ServeFilesClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileServer_ServeFilesClientMockRecorder
+}
+
+// MockFileServer_ServeFilesClientMockRecorder is the mock recorder for MockFileServer_ServeFilesClient
+type MockFileServer_ServeFilesClientMockRecorder struct {
+	mock *MockFileServer_ServeFilesClient
+}
+
+// NewMockFileServer_ServeFilesClient creates a new mock instance
+func NewMockFileServer_ServeFilesClient(ctrl *gomock.Controller) *MockFileServer_ServeFilesClient {
+	mock := &MockFileServer_ServeFilesClient{ctrl: ctrl}
+	mock.recorder = &MockFileServer_ServeFilesClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFileServer_ServeFilesClient) EXPECT() *MockFileServer_ServeFilesClientMockRecorder {
+	return m.recorder
+}
+
+// Recv mocks base method
+func (m *MockFileServer_ServeFilesClient) Recv() (*idl.FileChunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.FileChunk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv
+func (mr *MockFileServer_ServeFilesClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).Recv))
+}
+
+// Header mocks base method
+func (m *MockFileServer_ServeFilesClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header
+func (mr *MockFileServer_ServeFilesClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockFileServer_ServeFilesClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockFileServer_ServeFilesClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockFileServer_ServeFilesClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockFileServer_ServeFilesClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockFileServer_ServeFilesClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockFileServer_ServeFilesClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockFileServer_ServeFilesClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockFileServer_ServeFilesClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockFileServer_ServeFilesClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockFileServer_ServeFilesClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockFileServer_ServeFilesClient)(nil).RecvMsg), m)
+}
+
+// MockFileServerServer is a mock of FileServerServer interface
+type MockFileServerServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileServerServerMockRecorder
+}
+
+// MockFileServerServerMockRecorder is the mock recorder for MockFileServerServer
+type MockFileServerServerMockRecorder struct {
+	mock *MockFileServerServer
+}
+
+// NewMockFileServerServer creates a new mock instance
+func NewMockFileServerServer(ctrl *gomock.Controller) *MockFileServerServer {
+	mock := &MockFileServerServer{ctrl: ctrl}
+	mock.recorder = &MockFileServerServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFileServerServer) EXPECT() *MockFileServerServerMockRecorder {
+	return m.recorder
+}
+
+// ServeFiles mocks base method
+func (m *MockFileServerServer) ServeFiles(arg0 *idl.ServeFilesRequest, arg1 idl.FileServer_ServeFilesServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServeFiles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ServeFiles indicates an expected call of ServeFiles
+func (mr *MockFileServerServerMockRecorder) ServeFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeFiles", reflect.TypeOf((*MockFileServerServer)(nil).ServeFiles), arg0, arg1)
+}
+
+// MockFileServer_ServeFilesServer is a mock of FileServer_ServeFilesServer interface
+type MockFileServer_ServeFilesServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileServer_ServeFilesServerMockRecorder
+}
+
+// MockFileServer_ServeFilesServerMockRecorder is the mock recorder for MockFileServer_ServeFilesServer
+type MockFileServer_ServeFilesServerMockRecorder struct {
+	mock *MockFileServer_ServeFilesServer
+}
+
+// NewMockFileServer_ServeFilesServer creates a new mock instance
+func NewMockFileServer_ServeFilesServer(ctrl *gomock.Controller) *MockFileServer_ServeFilesServer {
+	mock := &MockFileServer_ServeFilesServer{ctrl: ctrl}
+	mock.recorder = &MockFileServer_ServeFilesServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFileServer_ServeFilesServer) EXPECT() *MockFileServer_ServeFilesServerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockFileServer_ServeFilesServer) Send(arg0 *idl.FileChunk) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockFileServer_ServeFilesServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).Send), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockFileServer_ServeFilesServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockFileServer_ServeFilesServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockFileServer_ServeFilesServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockFileServer_ServeFilesServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockFileServer_ServeFilesServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockFileServer_ServeFilesServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockFileServer_ServeFilesServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockFileServer_ServeFilesServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockFileServer_ServeFilesServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockFileServer_ServeFilesServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockFileServer_ServeFilesServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockFileServer_ServeFilesServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockFileServer_ServeFilesServer)(nil).RecvMsg), m)
 }
@@ -5,3 +5,35 @@ package idl
 type MessageSender interface {
 	Send(*Message) error // matches gRPC streaming Send()
 }
+
+// CollectLogsSender is implemented by the CollectLogs streaming servers of both
+// the hub and the agents.
+type CollectLogsSender interface {
+	Send(*CollectLogsReply) error // matches gRPC streaming Send()
+}
+
+// CollectLogsWriter is an io.Writer that sends everything written to it over a
+// CollectLogs stream. Callers should buffer their writes to avoid sending many
+// tiny messages.
+type CollectLogsWriter struct {
+	Sender CollectLogsSender
+}
+
+func (w CollectLogsWriter) Write(p []byte) (int, error) {
+	if err := w.Sender.Send(&CollectLogsReply{Data: p}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// FileChunkSender is implemented by the server side of a ServeFiles stream.
+type FileChunkSender interface {
+	Send(*FileChunk) error // matches gRPC streaming Send()
+}
+
+// VerifyManifestReceiver is implemented by the server side of a VerifyManifest
+// stream.
+type VerifyManifestReceiver interface {
+	Recv() (*VerifyManifestRequest, error) // matches gRPC streaming Recv()
+}
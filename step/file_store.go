@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-
-	"github.com/google/renameio"
-	"github.com/hashicorp/go-multierror"
+	"time"
 
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
 )
 
 // FileStore implements step.Store by providing persistent storage on disk.
@@ -42,74 +42,143 @@ func (p *PrettyStatus) UnmarshalText(buf []byte) error {
 	return nil
 }
 
-func (f *FileStore) load() (map[string]idl.Status, error) {
+// StatusSchemaVersion is the version of the status file format written by
+// this version of gpupgrade.
+//
+// History:
+//   0: unversioned. A flat object mapping substep names to statuses.
+//   1: statuses moved under Substeps, alongside SchemaVersion.
+//   2: Timings added.
+const StatusSchemaVersion = 2
+
+var statusSchema = schema.NewRegistry("status.json", StatusSchemaVersion)
+
+func init() {
+	statusSchema.Register(0, func(doc []byte) ([]byte, error) {
+		var substeps map[string]json.RawMessage
+		if err := json.Unmarshal(doc, &substeps); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(map[string]interface{}{"Substeps": substeps})
+	})
+
+	// Timings are optional, so a version 1 document needs no changes.
+	statusSchema.Register(1, func(doc []byte) ([]byte, error) {
+		return doc, nil
+	})
+}
+
+// Timing records when a substep last started and finished running. Finished
+// is nil while the substep is running.
+type Timing struct {
+	Started  *time.Time `json:",omitempty"`
+	Finished *time.Time `json:",omitempty"`
+}
+
+// Record is the status of a substep and the time it last ran.
+type Record struct {
+	Status idl.Status
+	Timing
+}
+
+// statusFile is the serialized form of the status file.
+type statusFile struct {
+	SchemaVersion int
+	Substeps      map[string]PrettyStatus
+	Timings       map[string]Timing `json:",omitempty"`
+}
+
+func (f *FileStore) load() (*statusFile, error) {
 	data, err := ioutil.ReadFile(f.path)
 	if err != nil {
 		return nil, err
 	}
 
-	var prettySubsteps map[string]PrettyStatus
-	err = json.Unmarshal(data, &prettySubsteps)
+	data, err = statusSchema.Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var file statusFile
+	err = json.Unmarshal(data, &file)
 	if err != nil {
 		return nil, err
 	}
 
-	substeps := make(map[string]idl.Status)
-	for k, v := range prettySubsteps {
-		substeps[k] = v.Status
+	if file.Substeps == nil {
+		file.Substeps = make(map[string]PrettyStatus)
 	}
-	return substeps, nil
+	if file.Timings == nil {
+		file.Timings = make(map[string]Timing)
+	}
+
+	return &file, nil
 }
 
 func (f *FileStore) Read(substep idl.Substep) (idl.Status, error) {
-	steps, err := f.load()
+	file, err := f.load()
 	if err != nil {
 		return idl.Status_UNKNOWN_STATUS, err
 	}
 
-	status, ok := steps[substep.String()]
+	status, ok := file.Substeps[substep.String()]
 	if !ok {
 		return idl.Status_UNKNOWN_STATUS, nil
 	}
 
-	return status, nil
+	return status.Status, nil
 }
 
-// Write atomically updates the status file.
-// Load the latest values from the filesystem, rather than storing
-// in-memory on a struct to avoid having two sources of truth.
-func (f *FileStore) Write(substep idl.Substep, status idl.Status) (err error) {
-	steps, err := f.load()
+// Records returns the status and timing of every substep that has been
+// written. Substeps that this version of gpupgrade does not know are left out.
+func (f *FileStore) Records() (map[idl.Substep]Record, error) {
+	file, err := f.load()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	prettySteps := make(map[string]PrettyStatus)
-	for k, v := range steps {
-		prettySteps[k] = PrettyStatus{v}
+	records := make(map[idl.Substep]Record)
+	for name, status := range file.Substeps {
+		val, ok := idl.Substep_value[name]
+		if !ok {
+			continue
+		}
+
+		records[idl.Substep(val)] = Record{Status: status.Status, Timing: file.Timings[name]}
 	}
-	prettySteps[substep.String()] = PrettyStatus{status}
 
-	data, err := json.MarshalIndent(prettySteps, "", "  ") // pretty print JSON
+	return records, nil
+}
+
+// Write atomically updates the status file, and records the time at which the
+// substep started running or stopped.
+// Load the latest values from the filesystem, rather than storing
+// in-memory on a struct to avoid having two sources of truth.
+func (f *FileStore) Write(substep idl.Substep, status idl.Status) error {
+	file, err := f.load()
 	if err != nil {
 		return err
 	}
 
-	// Use renameio to ensure atomicity when writing the status file.
-	t, err := renameio.TempFile("", f.path)
-	if err != nil {
-		return err
+	name := substep.String()
+	file.SchemaVersion = StatusSchemaVersion
+	file.Substeps[name] = PrettyStatus{status}
+
+	now := utils.System.Now().UTC()
+	switch status {
+	case idl.Status_RUNNING:
+		file.Timings[name] = Timing{Started: &now}
+	case idl.Status_COMPLETE, idl.Status_FAILED, idl.Status_PAUSED:
+		timing := file.Timings[name]
+		timing.Finished = &now
+		file.Timings[name] = timing
 	}
-	defer func() {
-		if cErr := t.Cleanup(); cErr != nil {
-			err = multierror.Append(err, cErr).ErrorOrNil()
-		}
-	}()
 
-	_, err = t.Write(data)
+	data, err := json.MarshalIndent(file, "", "  ") // pretty print JSON
 	if err != nil {
 		return err
 	}
 
-	return t.CloseAtomicallyReplace()
+	return utils.AtomicallyWriteFile(f.path, data, 0600)
 }
@@ -5,10 +5,16 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/idl"
 	"github.com/greenplum-db/gpupgrade/step"
+	"github.com/greenplum-db/gpupgrade/utils"
+	"github.com/greenplum-db/gpupgrade/utils/schema"
 )
 
 func TestFileStore(t *testing.T) {
@@ -73,6 +79,41 @@ func TestFileStore(t *testing.T) {
 		}
 	})
 
+	t.Run("records when substeps start and finish", func(t *testing.T) {
+		start := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+		finish := start.Add(time.Minute)
+		defer func() { utils.System = utils.InitializeSystemFunctions() }()
+
+		substep := idl.Substep_UPGRADE_MASTER
+		utils.System.Now = func() time.Time { return start }
+		if err := fs.Write(substep, idl.Status_RUNNING); err != nil {
+			t.Fatalf("Write(): %+v", err)
+		}
+
+		records, err := fs.Records()
+		if err != nil {
+			t.Fatalf("Records() returned error %+v", err)
+		}
+		record := records[substep]
+		if record.Status != idl.Status_RUNNING || !record.Started.Equal(start) || record.Finished != nil {
+			t.Errorf("while running, got record %+v", record)
+		}
+
+		utils.System.Now = func() time.Time { return finish }
+		if err := fs.Write(substep, idl.Status_COMPLETE); err != nil {
+			t.Fatalf("Write(): %+v", err)
+		}
+
+		records, err = fs.Records()
+		if err != nil {
+			t.Fatalf("Records() returned error %+v", err)
+		}
+		record = records[substep]
+		if record.Status != idl.Status_COMPLETE || !record.Started.Equal(start) || !record.Finished.Equal(finish) {
+			t.Errorf("when complete, got record %+v", record)
+		}
+	})
+
 	t.Run("uses human-readable serialization", func(t *testing.T) {
 		substep := idl.Substep_INIT_TARGET_CLUSTER
 		status := idl.Status_FAILED
@@ -87,14 +128,116 @@ func TestFileStore(t *testing.T) {
 		defer f.Close()
 
 		dec := json.NewDecoder(f)
-		raw := make(map[string]string)
+		var raw struct {
+			SchemaVersion int
+			Substeps      map[string]string
+		}
 		if err := dec.Decode(&raw); err != nil {
 			t.Fatalf("decoding statuses: %+v", err)
 		}
 
+		if raw.SchemaVersion != step.StatusSchemaVersion {
+			t.Errorf("SchemaVersion = %d, want %d", raw.SchemaVersion, step.StatusSchemaVersion)
+		}
+
 		key := substep.String()
-		if raw[key] != status.String() {
-			t.Errorf("status[%q] = %q, want %q", key, raw[key], status.String())
+		if raw.Substeps[key] != status.String() {
+			t.Errorf("status[%q] = %q, want %q", key, raw.Substeps[key], status.String())
 		}
 	})
 }
+
+func TestFileStoreSchema(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			t.Errorf("removing temp directory: %v", err)
+		}
+	}()
+
+	expected := map[idl.Substep]idl.Status{
+		idl.Substep_CHECK_UPGRADE:           idl.Status_COMPLETE,
+		idl.Substep_INIT_TARGET_CLUSTER:     idl.Status_FAILED,
+		idl.Substep_SHUTDOWN_SOURCE_CLUSTER: idl.Status_RUNNING,
+	}
+
+	for _, golden := range []string{"v0.json", "v1.json", "v2.json"} {
+		t.Run("reads "+golden, func(t *testing.T) {
+			fs := step.NewFileStore(copyGolden(t, tmpDir, golden))
+
+			for substep, status := range expected {
+				actual, err := fs.Read(substep)
+				if err != nil {
+					t.Fatalf("Read(%s) returned error %+v", substep, err)
+				}
+				if actual != status {
+					t.Errorf("Read(%s) = %s, want %s", substep, actual, status)
+				}
+			}
+		})
+	}
+
+	t.Run("upgrades an unversioned file to the current format on write", func(t *testing.T) {
+		path := copyGolden(t, tmpDir, "v0.json")
+		fs := step.NewFileStore(path)
+
+		utils.System.Now = func() time.Time { return time.Date(2020, 4, 1, 12, 30, 0, 0, time.UTC) }
+		defer func() { utils.System = utils.InitializeSystemFunctions() }()
+
+		if err := fs.Write(idl.Substep_CHECK_UPGRADE, idl.Status_COMPLETE); err != nil {
+			t.Fatalf("Write(): %+v", err)
+		}
+
+		actual, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading status file: %+v", err)
+		}
+
+		current, err := ioutil.ReadFile(filepath.Join("testdata", "status", "v2.json"))
+		if err != nil {
+			t.Fatalf("reading golden file: %+v", err)
+		}
+
+		// If this fails, the status file format has changed. Bump
+		// StatusSchemaVersion, register a migration from the previous
+		// version, and add a golden file for the new version.
+		if strings.TrimSpace(string(actual)) != strings.TrimSpace(string(current)) {
+			t.Errorf("wrote\n%s\nwant\n%s", actual, current)
+		}
+	})
+
+	t.Run("refuses a file written by a newer gpupgrade", func(t *testing.T) {
+		path := copyGolden(t, tmpDir, "v0.json")
+		if err := ioutil.WriteFile(path, []byte(`{"SchemaVersion": 999, "Substeps": {}}`), 0600); err != nil {
+			t.Fatalf("writing status file: %+v", err)
+		}
+
+		_, err := step.NewFileStore(path).Read(idl.Substep_CHECK_UPGRADE)
+
+		var versionErr *schema.NewerVersionError
+		if !xerrors.As(err, &versionErr) {
+			t.Errorf("returned error %#v, want type %T", err, versionErr)
+		}
+	})
+}
+
+// copyGolden copies a golden status file into dir, so that it may be modified,
+// and returns the path to the copy.
+func copyGolden(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	contents, err := ioutil.ReadFile(filepath.Join("testdata", "status", name))
+	if err != nil {
+		t.Fatalf("reading golden file: %+v", err)
+	}
+
+	path := filepath.Join(dir, "status.json")
+	if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+		t.Fatalf("writing status file: %+v", err)
+	}
+
+	return path
+}
@@ -0,0 +1,72 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// Hook names the point in a substep at which a hook is run.
+type Hook string
+
+const (
+	Before Hook = "before"
+	After  Hook = "after"
+)
+
+// Hooks runs operator-provided executables around substeps. The hook for a
+// substep is the executable named after it in the before or after
+// subdirectory of Dir, for example
+//
+//    <Dir>/before/SHUTDOWN_SOURCE_CLUSTER
+//
+// Substeps without an executable have no hook. Hooks inherit the environment
+// of the caller, plus the variables returned by Env and GPUPGRADE_STEP,
+// GPUPGRADE_SUBSTEP and GPUPGRADE_HOOK.
+type Hooks struct {
+	Dir string
+	Env func() []string
+}
+
+// Run runs the hook for substep, if there is one, writing its output to
+// streams. It is safe to call on nil Hooks.
+func (h *Hooks) Run(streams OutStreams, step string, hook Hook, substep idl.Substep) error {
+	if h == nil || h.Dir == "" {
+		return nil
+	}
+
+	path := filepath.Join(h.Dir, string(hook), substep.String())
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(streams.Stdout(), "Running %s hook %s...\n", hook, path)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(path)
+	cmd.Stdout = streams.Stdout()
+	cmd.Stderr = streams.Stderr()
+
+	cmd.Env = os.Environ()
+	if h.Env != nil {
+		cmd.Env = append(cmd.Env, h.Env()...)
+	}
+	cmd.Env = append(cmd.Env,
+		"GPUPGRADE_STEP="+step,
+		"GPUPGRADE_SUBSTEP="+substep.String(),
+		"GPUPGRADE_HOOK="+string(hook),
+	)
+
+	if err := cmd.Run(); err != nil {
+		return xerrors.Errorf("%s hook %s: %w", hook, path, err)
+	}
+
+	return nil
+}
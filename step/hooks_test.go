@@ -0,0 +1,183 @@
+package step_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/idl/mock_idl"
+	"github.com/greenplum-db/gpupgrade/step"
+)
+
+func TestHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "hooks.log")
+	writeHook(t, dir, step.Before, idl.Substep_UPGRADE_MASTER,
+		`echo "before $GPUPGRADE_STEP $GPUPGRADE_SUBSTEP $GPUPGRADE_HOOK $GPUPGRADE_SOURCE_MASTER_PORT" >> `+log)
+	writeHook(t, dir, step.After, idl.Substep_UPGRADE_MASTER,
+		`echo "after $GPUPGRADE_HOOK" >> `+log)
+	writeHook(t, dir, step.Before, idl.Substep_SHUTDOWN_SOURCE_CLUSTER,
+		`echo "replication is busy" >&2; exit 1`)
+	writeHook(t, dir, step.After, idl.Substep_START_TARGET_CLUSTER,
+		`exit 2`)
+
+	hooks := &step.Hooks{
+		Dir: dir,
+		Env: func() []string { return []string{"GPUPGRADE_SOURCE_MASTER_PORT=15432"} },
+	}
+
+	newStep := func(ctrl *gomock.Controller, streams step.OutStreamsCloser) *step.Step {
+		server := mock_idl.NewMockCliToHub_ExecuteServer(ctrl)
+		server.EXPECT().Send(gomock.Any()).AnyTimes()
+
+		s := step.New("execute", server, &TestStore{}, streams)
+		s.SetHooks(hooks)
+		return s
+	}
+
+	t.Run("runs hooks around the substep with the cluster environment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := newStep(ctrl, DevNull)
+		s.Run(idl.Substep_UPGRADE_MASTER, func(streams step.OutStreams) error {
+			f, err := os.OpenFile(log, os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			_, err = f.WriteString("substep\n")
+			return err
+		})
+
+		if s.Err() != nil {
+			t.Errorf("returned error %+v", s.Err())
+		}
+
+		contents, err := ioutil.ReadFile(log)
+		if err != nil {
+			t.Fatalf("reading hook log: %+v", err)
+		}
+
+		expected := "before execute UPGRADE_MASTER before 15432\nsubstep\nafter after\n"
+		if string(contents) != expected {
+			t.Errorf("hooks wrote %q, want %q", contents, expected)
+		}
+	})
+
+	t.Run("a failing before hook fails the substep", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		streams := &bufferStreams{}
+		s := newStep(ctrl, streams)
+
+		var called bool
+		s.Run(idl.Substep_SHUTDOWN_SOURCE_CLUSTER, func(streams step.OutStreams) error {
+			called = true
+			return nil
+		})
+
+		if called {
+			t.Error("expected substep to not be called")
+		}
+
+		if s.Err() == nil || !strings.Contains(s.Err().Error(), "before hook") {
+			t.Errorf("returned error %v", s.Err())
+		}
+
+		if !strings.Contains(streams.stderr.String(), "replication is busy") {
+			t.Errorf("hook stderr %q was not written to the stream", streams.stderr.String())
+		}
+	})
+
+	t.Run("a failing after hook is only reported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		streams := &bufferStreams{}
+		store := &TestStore{}
+
+		server := mock_idl.NewMockCliToHub_ExecuteServer(ctrl)
+		server.EXPECT().Send(gomock.Any()).AnyTimes()
+
+		s := step.New("execute", server, store, streams)
+		s.SetHooks(hooks)
+		s.Run(idl.Substep_START_TARGET_CLUSTER, func(streams step.OutStreams) error {
+			return nil
+		})
+
+		if s.Err() != nil {
+			t.Errorf("returned error %+v", s.Err())
+		}
+
+		if store.Status != idl.Status_COMPLETE {
+			t.Errorf("stored status %v, want %v", store.Status, idl.Status_COMPLETE)
+		}
+
+		if !strings.Contains(streams.stderr.String(), "warning: after hook") {
+			t.Errorf("stderr %q does not report the failed hook", streams.stderr.String())
+		}
+	})
+
+	t.Run("substeps without hooks run as usual", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := newStep(ctrl, DevNull)
+
+		expected := errors.New("oops")
+		s.Run(idl.Substep_COPY_MASTER, func(streams step.OutStreams) error {
+			return expected
+		})
+
+		if !xerrors.Is(s.Err(), expected) {
+			t.Errorf("returned error %#v, want %#v", s.Err(), expected)
+		}
+	})
+}
+
+func writeHook(t *testing.T, dir string, hook step.Hook, substep idl.Substep, script string) {
+	t.Helper()
+
+	path := filepath.Join(dir, string(hook), substep.String())
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("creating hook directory: %+v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700); err != nil {
+		t.Fatalf("writing hook: %+v", err)
+	}
+}
+
+// bufferStreams implements step.OutStreamsCloser by buffering all writes.
+type bufferStreams struct {
+	stdout, stderr bytes.Buffer
+}
+
+func (b *bufferStreams) Stdout() io.Writer {
+	return &b.stdout
+}
+
+func (b *bufferStreams) Stderr() io.Writer {
+	return &b.stderr
+}
+
+func (b *bufferStreams) Close() error {
+	return nil
+}
@@ -3,11 +3,13 @@ package step
 import (
 	"fmt"
 	"io"
+	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
 	"golang.org/x/xerrors"
 
 	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
 )
 
 type Step struct {
@@ -15,14 +17,29 @@ type Step struct {
 	sender  idl.MessageSender // sends substep status messages
 	store   Store             // persistent substep status storage
 	streams OutStreamsCloser  // writes substep stdout/err
+	hooks   *Hooks            // runs hook executables around substeps
+	notify  Notifier          // told of status changes; may be nil
 	err     error
+	paused  bool
 }
 
+// ErrPaused is returned by a substep that has stopped partway, to be resumed
+// when the step is next run. The substep is marked PAUSED and the rest of the
+// step is skipped without an error.
+var ErrPaused = xerrors.New("paused")
+
 type Store interface {
 	Read(idl.Substep) (idl.Status, error)
 	Write(idl.Substep, idl.Status) error
 }
 
+// Notifier is told of every status change that a Step records for its
+// substeps, and of the status of the whole step when it finishes, which is
+// reported with the substep UNKNOWN_STEP. Notify must not block.
+type Notifier interface {
+	Notify(step string, substep idl.Substep, status idl.Status)
+}
+
 type OutStreams interface {
 	Stdout() io.Writer
 	Stderr() io.Writer
@@ -43,6 +60,18 @@ func New(name string, sender idl.MessageSender, store Store, streams OutStreamsC
 }
 
 func (s *Step) Finish() error {
+	if s.notify != nil {
+		status := idl.Status_COMPLETE
+		switch {
+		case s.err != nil:
+			status = idl.Status_FAILED
+		case s.paused:
+			status = idl.Status_PAUSED
+		}
+
+		s.notify.Notify(s.name, idl.Substep_UNKNOWN_STEP, status)
+	}
+
 	if err := s.streams.Close(); err != nil {
 		return xerrors.Errorf(`step "%s": %w`, s.name, err)
 	}
@@ -54,6 +83,21 @@ func (s *Step) Err() error {
 	return s.err
 }
 
+// SetHooks sets the hooks to run before and after each substep that is run.
+func (s *Step) SetHooks(hooks *Hooks) {
+	s.hooks = hooks
+}
+
+// SetNotifier sets the Notifier that is told of the step's status changes.
+func (s *Step) SetNotifier(n Notifier) {
+	s.notify = n
+}
+
+// Paused returns true if a substep returned ErrPaused.
+func (s *Step) Paused() bool {
+	return s.paused
+}
+
 func (s *Step) AlwaysRun(substep idl.Substep, f func(OutStreams) error) {
 	s.run(substep, f, true)
 }
@@ -70,7 +114,7 @@ func (s *Step) run(substep idl.Substep, f func(OutStreams) error, alwaysRun bool
 		}
 	}()
 
-	if s.err != nil {
+	if s.err != nil || s.paused {
 		return
 	}
 
@@ -103,7 +147,20 @@ func (s *Step) run(substep idl.Substep, f func(OutStreams) error, alwaysRun bool
 		return
 	}
 
-	err = f(s.streams)
+	start := time.Now()
+	err = s.hooks.Run(s.streams, s.name, Before, substep)
+	if err == nil {
+		err = f(s.streams)
+	}
+	metrics.SubstepDuration.WithLabelValues(s.name, substep.String(), metrics.Status(err)).
+		Observe(time.Since(start).Seconds())
+
+	if xerrors.Is(err, ErrPaused) {
+		s.paused = true
+		err = s.write(substep, idl.Status_PAUSED)
+		return
+	}
+
 	if err != nil {
 		if werr := s.write(substep, idl.Status_FAILED); werr != nil {
 			err = multierror.Append(err, werr).ErrorOrNil()
@@ -111,6 +168,12 @@ func (s *Step) run(substep idl.Substep, f func(OutStreams) error, alwaysRun bool
 		return
 	}
 
+	// The substep has already succeeded, so a failing after hook is only
+	// reported, on the substep's stderr.
+	if herr := s.hooks.Run(s.streams, s.name, After, substep); herr != nil {
+		fmt.Fprintf(s.streams.Stderr(), "warning: %v\n", herr)
+	}
+
 	err = s.write(substep, idl.Status_COMPLETE)
 }
 
@@ -121,6 +184,10 @@ func (s *Step) write(substep idl.Substep, status idl.Status) error {
 	}
 
 	s.sendStatus(substep, status)
+	if s.notify != nil {
+		s.notify.Notify(s.name, substep, status)
+	}
+
 	return nil
 }
 
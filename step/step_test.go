@@ -2,8 +2,10 @@ package step_test
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -218,6 +220,77 @@ func TestStepRun(t *testing.T) {
 		}
 	})
 
+	t.Run("marks a paused substep as paused and skips subsequent substeps", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := mock_idl.NewMockCliToHub_ExecuteServer(ctrl)
+		server.EXPECT().
+			Send(&idl.Message{Contents: &idl.Message_Status{&idl.SubstepStatus{
+				Step:   idl.Substep_UPGRADE_PRIMARIES,
+				Status: idl.Status_RUNNING,
+			}}})
+		server.EXPECT().
+			Send(&idl.Message{Contents: &idl.Message_Status{&idl.SubstepStatus{
+				Step:   idl.Substep_UPGRADE_PRIMARIES,
+				Status: idl.Status_PAUSED,
+			}}})
+
+		store := &TestStore{}
+		s := step.New("Execute", server, store, DevNull)
+
+		s.Run(idl.Substep_UPGRADE_PRIMARIES, func(streams step.OutStreams) error {
+			return xerrors.Errorf("after wave 1: %w", step.ErrPaused)
+		})
+
+		var called bool
+		s.Run(idl.Substep_START_TARGET_CLUSTER, func(streams step.OutStreams) error {
+			called = true
+			return nil
+		})
+
+		if called {
+			t.Error("expected substep to be skipped")
+		}
+
+		if !s.Paused() {
+			t.Error("expected step to be paused")
+		}
+
+		if s.Err() != nil {
+			t.Errorf("returned error %#v", s.Err())
+		}
+
+		if store.Status != idl.Status_PAUSED {
+			t.Errorf("stored status %v, want %v", store.Status, idl.Status_PAUSED)
+		}
+	})
+
+	t.Run("re-runs a paused substep", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := mock_idl.NewMockCliToHub_ExecuteServer(ctrl)
+		server.EXPECT().Send(gomock.Any()).AnyTimes()
+
+		store := &TestStore{Status: idl.Status_PAUSED}
+		s := step.New("Execute", server, store, DevNull)
+
+		var called bool
+		s.Run(idl.Substep_UPGRADE_PRIMARIES, func(streams step.OutStreams) error {
+			called = true
+			return nil
+		})
+
+		if !called {
+			t.Error("expected substep to be called")
+		}
+
+		if store.Status != idl.Status_COMPLETE {
+			t.Errorf("stored status %v, want %v", store.Status, idl.Status_COMPLETE)
+		}
+	})
+
 	t.Run("for a substep that was running mark it as failed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -271,6 +344,61 @@ func TestStepFinish(t *testing.T) {
 	})
 }
 
+func TestStepNotify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := mock_idl.NewMockCliToHub_ExecuteServer(ctrl)
+	server.EXPECT().Send(gomock.Any()).AnyTimes()
+
+	notifier := &TestNotifier{}
+	s := step.New("Execute", server, substepStore{}, &devNull{})
+	s.SetNotifier(notifier)
+
+	s.Run(idl.Substep_UPGRADE_MASTER, func(streams step.OutStreams) error {
+		return nil
+	})
+	s.Run(idl.Substep_COPY_MASTER, func(streams step.OutStreams) error {
+		return errors.New("oops")
+	})
+
+	if err := s.Finish(); err != nil {
+		t.Fatalf("Finish() returned error %+v", err)
+	}
+
+	expected := []string{
+		"Execute UPGRADE_MASTER RUNNING",
+		"Execute UPGRADE_MASTER COMPLETE",
+		"Execute COPY_MASTER RUNNING",
+		"Execute COPY_MASTER FAILED",
+		"Execute UNKNOWN_STEP FAILED",
+	}
+	if !reflect.DeepEqual(notifier.Events, expected) {
+		t.Errorf("got events %q, want %q", notifier.Events, expected)
+	}
+}
+
+// TestNotifier implements step.Notifier by recording each status change.
+type TestNotifier struct {
+	Events []string
+}
+
+func (n *TestNotifier) Notify(step string, substep idl.Substep, status idl.Status) {
+	n.Events = append(n.Events, fmt.Sprintf("%s %s %s", step, substep, status))
+}
+
+// substepStore implements step.Store by keeping each substep's status.
+type substepStore map[idl.Substep]idl.Status
+
+func (s substepStore) Read(substep idl.Substep) (idl.Status, error) {
+	return s[substep], nil
+}
+
+func (s substepStore) Write(substep idl.Substep, status idl.Status) error {
+	s[substep] = status
+	return nil
+}
+
 type TestStore struct {
 	Status   idl.Status
 	WriteErr error
@@ -79,6 +79,21 @@ release_held_port() {
     [ $procname = "gpupgrade" ] || fail "actual process name: $procname"
 }
 
+@test "a second hub cannot use the same state directory" {
+    gpupgrade kill-services
+
+    run gpupgrade hub --daemonize 3>&-
+    [ "$status" -eq 0 ] || fail "$output"
+
+    regex='pid ([[:digit:]]+)'
+    [[ $output =~ $regex ]] || fail "actual output: $output"
+    pid="${BASH_REMATCH[1]}"
+
+    run gpupgrade hub --daemonize 3>&-
+    [ "$status" -eq 1 ] || fail "$output"
+    [[ "$output" = *"another gpupgrade hub (pid $pid"* ]] || fail "actual output: $output"
+}
+
 @test "hub fails if the configuration hasn't been initialized" {
     gpupgrade kill-services
 
@@ -21,6 +21,15 @@ type MockAgentServer struct {
 	UpgradeConvertPrimarySegmentsRequest *idl.UpgradePrimariesRequest
 	CreateSegmentDataDirRequest          *idl.CreateSegmentDataDirRequest
 
+	// DefaultParallelism is reported by ScanDataDirs; zero reports 1.
+	DefaultParallelism int32
+
+	// MaxConcurrentUpgrades is the largest number of UpgradePrimaries calls
+	// that have been in progress at once.
+	MaxConcurrentUpgrades int
+	upgrades              int
+	upgradesMu            sync.Mutex // separate from mu, which serializes the calls
+
 	Err chan error
 }
 
@@ -65,8 +74,10 @@ func (m *MockAgentServer) CheckDiskSpace(context.Context, *idl.CheckSegmentDiskS
 	return &idl.CheckDiskSpaceReply{}, nil
 }
 
-func (m *MockAgentServer) UpgradePrimaries(ctx context.Context, in *idl.UpgradePrimariesRequest) (*idl.UpgradePrimariesReply, error) {
+func (m *MockAgentServer) UpgradePrimaries(in *idl.UpgradePrimariesRequest, stream idl.Agent_UpgradePrimariesServer) error {
 	m.increaseCalls()
+	m.startUpgrade()
+	defer m.finishUpgrade()
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -77,7 +88,38 @@ func (m *MockAgentServer) UpgradePrimaries(ctx context.Context, in *idl.UpgradeP
 		err = <-m.Err
 	}
 
-	return &idl.UpgradePrimariesReply{}, err
+	// Report each segment as done, or the first as failed.
+	for i, pair := range in.DataDirPairs {
+		phase := idl.SegmentProgress_DONE
+		if err != nil && i == 0 {
+			phase = idl.SegmentProgress_FAILED
+		}
+
+		stream.Send(&idl.UpgradePrimariesReply{
+			Progress: &idl.SegmentProgress{Content: pair.Content, Phase: phase},
+		})
+	}
+
+	return err
+}
+
+func (m *MockAgentServer) ScanDataDirs(ctx context.Context, in *idl.ScanDataDirsRequest) (*idl.ScanDataDirsReply, error) {
+	m.increaseCalls()
+
+	m.mu.Lock()
+	parallelism := m.DefaultParallelism
+	m.mu.Unlock()
+
+	if parallelism == 0 {
+		parallelism = 1
+	}
+
+	reply := &idl.ScanDataDirsReply{DefaultParallelism: parallelism}
+	for _, dir := range in.DataDirs {
+		reply.Scans = append(reply.Scans, &idl.DataDirScan{DataDir: dir})
+	}
+
+	return reply, nil
 }
 
 func (m *MockAgentServer) CreateSegmentDataDirectories(ctx context.Context, in *idl.CreateSegmentDataDirRequest) (*idl.CreateSegmentDataDirReply, error) {
@@ -99,6 +141,50 @@ func (m *MockAgentServer) StopAgent(ctx context.Context, in *idl.StopAgentReques
 	return &idl.StopAgentReply{}, nil
 }
 
+func (m *MockAgentServer) CollectLogs(in *idl.CollectLogsRequest, stream idl.Agent_CollectLogsServer) error {
+	m.increaseCalls()
+
+	var err error
+	if len(m.Err) != 0 {
+		err = <-m.Err
+	}
+
+	return err
+}
+
+func (m *MockAgentServer) CarryOverConfiguration(ctx context.Context, in *idl.CarryOverConfigurationRequest) (*idl.CarryOverConfigurationReply, error) {
+	m.increaseCalls()
+
+	var err error
+	if len(m.Err) != 0 {
+		err = <-m.Err
+	}
+
+	return &idl.CarryOverConfigurationReply{}, err
+}
+
+func (m *MockAgentServer) VerifyManifest(stream idl.Agent_VerifyManifestServer) error {
+	m.increaseCalls()
+
+	var err error
+	if len(m.Err) != 0 {
+		err = <-m.Err
+	}
+
+	return err
+}
+
+func (m *MockAgentServer) PullDir(in *idl.PullDirRequest, stream idl.Agent_PullDirServer) error {
+	m.increaseCalls()
+
+	var err error
+	if len(m.Err) != 0 {
+		err = <-m.Err
+	}
+
+	return err
+}
+
 func (m *MockAgentServer) Stop() {
 	m.grpcServer.Stop()
 }
@@ -110,6 +196,23 @@ func (m *MockAgentServer) increaseCalls() {
 	m.numCalls++
 }
 
+func (m *MockAgentServer) startUpgrade() {
+	m.upgradesMu.Lock()
+	defer m.upgradesMu.Unlock()
+
+	m.upgrades++
+	if m.upgrades > m.MaxConcurrentUpgrades {
+		m.MaxConcurrentUpgrades = m.upgrades
+	}
+}
+
+func (m *MockAgentServer) finishUpgrade() {
+	m.upgradesMu.Lock()
+	defer m.upgradesMu.Unlock()
+
+	m.upgrades--
+}
+
 func (m *MockAgentServer) NumberOfCalls() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,228 @@
+package upgrade
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ReportLines is the maximum number of lines that are extracted from each file
+// referenced by a pg_upgrade failure.
+const ReportLines = 10
+
+// internalLog is the file in the pg_upgrade working directory that holds a copy
+// of everything pg_upgrade prints.
+const internalLog = "pg_upgrade_internal.log"
+
+var (
+	// fatalCheck matches the line printed when a pg_upgrade check fails, for
+	// example
+	//
+	//     Checking for tables using composite types                  fatal
+	fatalCheck = regexp.MustCompile(`^(\S.*?)\s+fatal\s*$`)
+
+	// reportFile matches the names of the report and log files that pg_upgrade
+	// refers the user to when it fails.
+	reportFile = regexp.MustCompile(`[\w.-]+\.(txt|log)`)
+)
+
+// Error is returned by Run when pg_upgrade fails and the reason for the
+// failure could be found in its working directory.
+type Error struct {
+	// Host is the host on which pg_upgrade ran. It is not set by Run; callers
+	// that report failures from other hosts fill it in.
+	Host string
+
+	WorkDir string
+	Check   string   // the pg_upgrade step that failed
+	Message string   // pg_upgrade's description of the failure
+	Reports []Report // files that pg_upgrade referred to in Message
+
+	Err error // the original error from pg_upgrade, usually an *exec.ExitError
+}
+
+// Report contains an excerpt of a file that pg_upgrade referred to in its
+// failure message. For report (.txt) files this is the first ReportLines lines;
+// for log files, which tend to have the cause of the failure at the end, it is
+// the last ReportLines lines.
+type Report struct {
+	Path       string
+	Lines      []string
+	TotalLines int
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("pg_upgrade failed: %s", e.Check)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	for _, r := range e.Reports {
+		msg += fmt.Sprintf(" (see %s)", r.Path)
+	}
+
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Details returns a multi-line description of the failure, including excerpts
+// of any referenced reports, that is suitable for display to an operator.
+func (e *Error) Details() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "pg_upgrade failed")
+	if e.Host != "" {
+		fmt.Fprintf(&b, " on host %s", e.Host)
+	}
+	fmt.Fprintf(&b, " (working directory %s)\n", e.WorkDir)
+
+	fmt.Fprintf(&b, "  %s\n", e.Check)
+	if e.Message != "" {
+		fmt.Fprintf(&b, "  %s\n", e.Message)
+	}
+
+	for _, r := range e.Reports {
+		fmt.Fprintf(&b, "\n  %s (%d lines):\n", r.Path, r.TotalLines)
+		for _, line := range r.Lines {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+		if len(r.Lines) < r.TotalLines {
+			fmt.Fprintf(&b, "    ...\n")
+		}
+	}
+
+	return b.String()
+}
+
+// scanWorkDir looks for the reason for a pg_upgrade failure in the given
+// working directory. If one can be found, an *Error wrapping err is returned;
+// otherwise err is returned unchanged.
+func scanWorkDir(workDir string, err error) error {
+	if workDir == "" {
+		workDir = "."
+	}
+
+	lines, rerr := readLines(filepath.Join(workDir, internalLog))
+	if rerr != nil {
+		return err
+	}
+
+	check, message, ok := findFailure(lines)
+	if !ok {
+		return err
+	}
+
+	upgradeErr := &Error{
+		WorkDir: workDir,
+		Check:   check,
+		Message: message,
+		Err:     err,
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range reportFile.FindAllString(message, -1) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		report, rerr := readReport(filepath.Join(workDir, name))
+		if rerr != nil {
+			continue // pg_upgrade may refer to files that were not created
+		}
+
+		upgradeErr.Reports = append(upgradeErr.Reports, report)
+	}
+
+	return upgradeErr
+}
+
+// findFailure returns the name of the failed step and pg_upgrade's explanation
+// of the failure from the lines of the internal log. There are two forms of
+// failure output: failed checks are marked "fatal" and followed by an
+// explanation, and other failures print "*failure*" after the step.
+func findFailure(lines []string) (check string, message string, ok bool) {
+	start := -1
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+
+		if m := fatalCheck.FindStringSubmatch(line); m != nil {
+			check = m[1]
+			start = i + 1
+			break
+		}
+
+		if line == "*failure*" {
+			// The step name is the last non-empty line before the marker.
+			for j := i - 1; j >= 0; j-- {
+				if prev := strings.TrimSpace(lines[j]); prev != "" {
+					check = prev
+					break
+				}
+			}
+			start = i + 1
+			break
+		}
+	}
+
+	if start < 0 {
+		return "", "", false
+	}
+
+	var words []string
+	for _, line := range lines[start:] {
+		line = strings.TrimSpace(line)
+		if line == "Failure, exiting" {
+			break
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+
+	return check, strings.Join(words, " "), true
+}
+
+func readReport(path string) (Report, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Path:       path,
+		TotalLines: len(lines),
+		Lines:      lines,
+	}
+
+	if len(lines) > ReportLines {
+		if filepath.Ext(path) == ".log" {
+			report.Lines = lines[len(lines)-ReportLines:]
+		} else {
+			report.Lines = lines[:ReportLines]
+		}
+	}
+
+	return report, nil
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
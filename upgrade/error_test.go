@@ -0,0 +1,242 @@
+package upgrade_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/testutils/exectest"
+	"github.com/greenplum-db/gpupgrade/upgrade"
+)
+
+const checkFailureLog = `Performing Consistency Checks
+-----------------------------
+Checking cluster versions                                   ok
+Checking database user is a superuser                       ok
+Checking for tables using composite types                   fatal
+
+Your installation contains user-defined composite types in user tables.
+These types are not supported in the new cluster.
+A list of the problem columns is in the file:
+    tables_using_composite.txt
+
+Failure, exiting
+`
+
+const restoreFailureLog = `Performing Upgrade
+------------------
+Analyzing all rows in the new cluster                       ok
+Restoring database schemas in the new cluster
+*failure*
+
+Consult the last few lines of "pg_upgrade_dump_16384.log" for
+the probable cause of the failure.
+Failure, exiting
+`
+
+func TestRunFailure(t *testing.T) {
+	pair := upgrade.SegmentPair{
+		Source: &upgrade.Segment{BinDir: "/old/bin", DataDir: "/old/data", DBID: 1, Port: 15432},
+		Target: &upgrade.Segment{BinDir: "/new/bin", DataDir: "/new/data", DBID: 1, Port: 15433},
+	}
+
+	upgrade.SetExecCommand(exectest.NewCommand(Failure))
+	defer upgrade.ResetExecCommand()
+
+	t.Run("describes a failed check", func(t *testing.T) {
+		wd := tempWorkDir(t, map[string]string{
+			"pg_upgrade_internal.log":    checkFailureLog,
+			"tables_using_composite.txt": "public.t1.c1\npublic.t2.c1\npublic.t3.c1\n",
+		})
+		defer os.RemoveAll(wd)
+
+		err := upgrade.Run(pair, upgrade.WithWorkDir(wd))
+
+		var upgradeErr *upgrade.Error
+		if !xerrors.As(err, &upgradeErr) {
+			t.Fatalf("returned error %#v, want type %T", err, upgradeErr)
+		}
+
+		expected := &upgrade.Error{
+			WorkDir: wd,
+			Check:   "Checking for tables using composite types",
+			Message: "Your installation contains user-defined composite types in user tables. " +
+				"These types are not supported in the new cluster. " +
+				"A list of the problem columns is in the file: tables_using_composite.txt",
+			Reports: []upgrade.Report{{
+				Path:       filepath.Join(wd, "tables_using_composite.txt"),
+				Lines:      []string{"public.t1.c1", "public.t2.c1", "public.t3.c1"},
+				TotalLines: 3,
+			}},
+			Err: upgradeErr.Err,
+		}
+		if !reflect.DeepEqual(upgradeErr, expected) {
+			t.Errorf("returned %#v, want %#v", upgradeErr, expected)
+		}
+
+		var exitErr *exec.ExitError
+		if !xerrors.As(err, &exitErr) {
+			t.Errorf("returned error %#v does not wrap an %T", err, exitErr)
+		}
+	})
+
+	t.Run("describes a failed step with the tail of the referenced log", func(t *testing.T) {
+		var dumpLog []string
+		for i := 1; i <= 15; i++ {
+			dumpLog = append(dumpLog, fmt.Sprintf("line %d", i))
+		}
+
+		wd := tempWorkDir(t, map[string]string{
+			"pg_upgrade_internal.log":   restoreFailureLog,
+			"pg_upgrade_dump_16384.log": strings.Join(dumpLog, "\n") + "\n",
+		})
+		defer os.RemoveAll(wd)
+
+		err := upgrade.Run(pair, upgrade.WithWorkDir(wd))
+
+		var upgradeErr *upgrade.Error
+		if !xerrors.As(err, &upgradeErr) {
+			t.Fatalf("returned error %#v, want type %T", err, upgradeErr)
+		}
+
+		if upgradeErr.Check != "Restoring database schemas in the new cluster" {
+			t.Errorf("got check %q", upgradeErr.Check)
+		}
+
+		if len(upgradeErr.Reports) != 1 {
+			t.Fatalf("got %d reports, want 1", len(upgradeErr.Reports))
+		}
+
+		report := upgradeErr.Reports[0]
+		if report.TotalLines != 15 {
+			t.Errorf("got %d total lines, want 15", report.TotalLines)
+		}
+		if !reflect.DeepEqual(report.Lines, dumpLog[15-upgrade.ReportLines:]) {
+			t.Errorf("got lines %q, want the last %d lines of the log", report.Lines, upgrade.ReportLines)
+		}
+	})
+
+	t.Run("returns the original error when no failure is logged", func(t *testing.T) {
+		wd := tempWorkDir(t, map[string]string{
+			"pg_upgrade_internal.log": "Performing Consistency Checks\n",
+		})
+		defer os.RemoveAll(wd)
+
+		err := upgrade.Run(pair, upgrade.WithWorkDir(wd))
+
+		if _, ok := err.(*exec.ExitError); !ok {
+			t.Errorf("returned error %#v, want type *exec.ExitError", err)
+		}
+	})
+}
+
+func TestErrors(t *testing.T) {
+	failure := &upgrade.Error{
+		Host:    "sdw1",
+		WorkDir: "/home/gpadmin/.gpupgrade/pg_upgrade/seg7",
+		Check:   "Checking for tables using composite types",
+		Message: "Your installation contains user-defined composite types in user tables.",
+		Reports: []upgrade.Report{{
+			Path:       "/home/gpadmin/.gpupgrade/pg_upgrade/seg7/tables_using_composite.txt",
+			Lines:      []string{"public.t1.c1"},
+			TotalLines: 1,
+		}},
+		Err: xerrors.New("exit status 1"),
+	}
+
+	t.Run("finds failures in wrapped errors and multierrors", func(t *testing.T) {
+		var err error = multierror.Append(
+			xerrors.New("some other error"),
+			errors.Wrap(failure, "failed to upgrade primary"),
+		)
+		err = xerrors.Errorf("upgrading primaries: %w", err)
+
+		errs := upgrade.Errors(err)
+		if len(errs) != 1 || errs[0] != failure {
+			t.Errorf("got %v, want %v", errs, []*upgrade.Error{failure})
+		}
+	})
+
+	t.Run("round-trips failures through gRPC statuses", func(t *testing.T) {
+		err := upgrade.StatusError(xerrors.Errorf("upgrading primaries: %w", failure))
+
+		// Wrap it again, as the hub does.
+		err = errors.Wrap(err, "agent failed")
+
+		errs := upgrade.Errors(err)
+		if len(errs) != 1 {
+			t.Fatalf("got %d failures, want 1", len(errs))
+		}
+
+		actual := errs[0]
+		if actual.Err.Error() != failure.Err.Error() {
+			t.Errorf("got underlying error %q, want %q", actual.Err, failure.Err)
+		}
+
+		actual.Err = failure.Err
+		if !reflect.DeepEqual(actual, failure) {
+			t.Errorf("got %#v, want %#v", actual, failure)
+		}
+	})
+
+	t.Run("StatusError leaves other errors alone", func(t *testing.T) {
+		expected := xerrors.New("not a pg_upgrade failure")
+
+		err := upgrade.StatusError(expected)
+		if err != expected {
+			t.Errorf("returned %#v, want %#v", err, expected)
+		}
+	})
+}
+
+func TestErrorDetails(t *testing.T) {
+	failure := &upgrade.Error{
+		Host:    "sdw1",
+		WorkDir: "/state/pg_upgrade/seg7",
+		Check:   "Checking for tables using composite types",
+		Message: "A list of the problem columns is in the file: tables_using_composite.txt",
+		Reports: []upgrade.Report{{
+			Path:       "/state/pg_upgrade/seg7/tables_using_composite.txt",
+			Lines:      []string{"public.t1.c1"},
+			TotalLines: 12,
+		}},
+	}
+
+	expected := `pg_upgrade failed on host sdw1 (working directory /state/pg_upgrade/seg7)
+  Checking for tables using composite types
+  A list of the problem columns is in the file: tables_using_composite.txt
+
+  /state/pg_upgrade/seg7/tables_using_composite.txt (12 lines):
+    public.t1.c1
+    ...
+`
+	if actual := failure.Details(); actual != expected {
+		t.Errorf("got details\n%s\nwant\n%s", actual, expected)
+	}
+}
+
+func tempWorkDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	wd, err := ioutil.TempDir("", "pg_upgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(wd, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("writing %s: %+v", name, err)
+		}
+	}
+
+	return wd
+}
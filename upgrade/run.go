@@ -29,6 +29,9 @@ type SegmentPair struct {
 // Run executes pg_upgrade for the given pair of Segments. By default, a
 // standard master upgrade is performed; this can be changed by passing various
 // Options.
+//
+// If pg_upgrade fails and the cause can be found in its working directory, the
+// returned error is an *Error describing the failure.
 func Run(p SegmentPair, options ...Option) error {
 	opts := newOptionList(options)
 
@@ -82,7 +85,12 @@ func Run(p SegmentPair, options ...Option) error {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("LD_LIBRARY_PATH=%s", path))
 	}
 
-	return cmd.Run()
+	err := cmd.Run()
+	if err != nil {
+		return scanWorkDir(opts.Dir, err)
+	}
+
+	return nil
 }
 
 // Option configures the way Run executes pg_upgrade.
@@ -0,0 +1,117 @@
+package upgrade
+
+import (
+	"github.com/golang/protobuf/proto"
+	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// Errors returns every *Error contained in err. It looks through wrapped
+// errors, multierrors, and gRPC statuses created by StatusError, so that
+// failures from agents can be recovered on the hub, and failures from the hub
+// can be recovered by the CLI.
+func Errors(err error) []*Error {
+	var found []*Error
+
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			return append(found, e)
+
+		case *multierror.Error:
+			for _, sub := range e.Errors {
+				found = append(found, Errors(sub)...)
+			}
+			return found
+
+		case interface{ GRPCStatus() *status.Status }:
+			for _, detail := range e.GRPCStatus().Details() {
+				if failure, ok := detail.(*idl.PgUpgradeFailure); ok {
+					found = append(found, fromProto(failure))
+				}
+			}
+			return found
+		}
+
+		// Both xerrors and github.com/pkg/errors are used to wrap errors.
+		if causer, ok := err.(interface{ Cause() error }); ok {
+			err = causer.Cause()
+		} else {
+			err = xerrors.Unwrap(err)
+		}
+	}
+
+	return found
+}
+
+// StatusError converts err into a gRPC status error that carries any pg_upgrade
+// failures contained in err, so that they survive the trip across the wire. If
+// there are no such failures, err is returned unchanged.
+func StatusError(err error) error {
+	failures := Errors(err)
+	if len(failures) == 0 {
+		return err
+	}
+
+	details := make([]proto.Message, 0, len(failures))
+	for _, f := range failures {
+		details = append(details, toProto(f))
+	}
+
+	st, derr := status.New(codes.Unknown, err.Error()).WithDetails(details...)
+	if derr != nil {
+		return err
+	}
+
+	return st.Err()
+}
+
+func toProto(e *Error) *idl.PgUpgradeFailure {
+	failure := &idl.PgUpgradeFailure{
+		Host:    e.Host,
+		WorkDir: e.WorkDir,
+		Check:   e.Check,
+		Message: e.Message,
+	}
+
+	if e.Err != nil {
+		failure.Error = e.Err.Error()
+	}
+
+	for _, r := range e.Reports {
+		failure.Reports = append(failure.Reports, &idl.PgUpgradeReport{
+			Path:       r.Path,
+			Lines:      r.Lines,
+			TotalLines: int32(r.TotalLines),
+		})
+	}
+
+	return failure
+}
+
+func fromProto(failure *idl.PgUpgradeFailure) *Error {
+	e := &Error{
+		Host:    failure.Host,
+		WorkDir: failure.WorkDir,
+		Check:   failure.Check,
+		Message: failure.Message,
+	}
+
+	if failure.Error != "" {
+		e.Err = xerrors.New(failure.Error)
+	}
+
+	for _, r := range failure.Reports {
+		e.Reports = append(e.Reports, Report{
+			Path:       r.Path,
+			Lines:      r.Lines,
+			TotalLines: int(r.TotalLines),
+		})
+	}
+
+	return e
+}
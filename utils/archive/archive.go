@@ -0,0 +1,289 @@
+// Package archive writes the gzipped tarballs used to collect diagnostic
+// information from the cluster.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// SkippedFileName is the name of the archive entry that lists any files that
+// could not be added to the archive.
+const SkippedFileName = "SKIPPED.txt"
+
+// ChunkSize is a reasonable size for the messages used to stream an archive
+// over gRPC.
+const ChunkSize = 64 * 1024
+
+// Redacted replaces the values of sensitive keys in JSON files.
+const Redacted = "REDACTED"
+
+// sensitiveKey matches JSON keys whose values must not leave the cluster.
+var sensitiveKey = regexp.MustCompile(`(?i)pass(word|wd)?|secret|token|credential`)
+
+// Writer adds files to a gzipped tar archive. Files that would push the total
+// uncompressed size of the archive over its limit, or that can't be read, are
+// left out and listed in a SKIPPED.txt entry when the Writer is closed.
+//
+// Since gpupgrade's configuration is stored as JSON, any sensitive values in
+// .json files added from disk are redacted.
+type Writer struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+
+	limit   uint64
+	size    uint64
+	skipped []string
+}
+
+// NewWriter returns a Writer that writes an archive of at most limit
+// uncompressed bytes to w. A limit of zero means there is no limit.
+func NewWriter(w io.Writer, limit uint64) *Writer {
+	gz := gzip.NewWriter(w)
+
+	return &Writer{
+		gz:    gz,
+		tw:    tar.NewWriter(gz),
+		limit: limit,
+	}
+}
+
+// AddDir recursively adds the contents of root to the archive, underneath the
+// given prefix. A root that does not exist is silently ignored, since not every
+// host has every directory.
+//
+// Postgres data directories, such as the master backups kept in the state
+// directory, are far too large to collect and are skipped.
+func (w *Writer) AddDir(root, prefix string) error {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			w.skip(path, err.Error())
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(prefix, rel))
+
+		switch {
+		case info.IsDir():
+			if isDataDir(path) {
+				w.skip(path, "data directory")
+				return filepath.SkipDir
+			}
+
+			return w.writeHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     name + "/",
+				Mode:     int64(info.Mode().Perm()),
+				ModTime:  info.ModTime(),
+			})
+
+		case info.Mode().IsRegular():
+			return w.addFile(path, name, info)
+
+		default:
+			// Sockets, pipes, symlinks and the like aren't useful diagnostics.
+			return nil
+		}
+	})
+}
+
+// AddBytes adds an in-memory file to the archive.
+func (w *Writer) AddBytes(name string, contents []byte) error {
+	return w.add(name, bytes.NewReader(contents), int64(len(contents)), 0600, time.Now())
+}
+
+// AddReader adds a file of the given size, read from r, to the archive.
+func (w *Writer) AddReader(name string, r io.Reader, size int64) error {
+	return w.add(name, r, size, 0600, time.Now())
+}
+
+// Close writes the list of skipped files, if any, and flushes the archive. It
+// does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if len(w.skipped) > 0 {
+		contents := []byte(strings.Join(w.skipped, "\n") + "\n")
+
+		w.limit = 0 // always record what was skipped
+		if err := w.AddBytes(SkippedFileName, contents); err != nil {
+			return err
+		}
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return xerrors.Errorf("closing tar archive: %w", err)
+	}
+
+	if err := w.gz.Close(); err != nil {
+		return xerrors.Errorf("closing gzip stream: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Writer) addFile(path, name string, info os.FileInfo) error {
+	if filepath.Ext(path) == ".json" {
+		return w.addJSON(path, name, info)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		w.skip(path, err.Error())
+		return nil
+	}
+	defer file.Close()
+
+	return w.add(name, file, info.Size(), info.Mode().Perm(), info.ModTime())
+}
+
+func (w *Writer) addJSON(path, name string, info os.FileInfo) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		w.skip(path, err.Error())
+		return nil
+	}
+
+	redacted, err := RedactJSON(contents)
+	if err != nil {
+		// We can't tell what's in the file, so err on the side of caution.
+		w.skip(path, fmt.Sprintf("could not redact: %v", err))
+		return nil
+	}
+
+	return w.add(name, bytes.NewReader(redacted), int64(len(redacted)), info.Mode().Perm(), info.ModTime())
+}
+
+// RedactJSON replaces the value of every key in the given JSON document that
+// looks like it could hold a credential.
+func RedactJSON(contents []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(contents, &doc); err != nil {
+		return nil, xerrors.Errorf("parsing JSON: %w", err)
+	}
+
+	redacted, err := json.MarshalIndent(redact(doc), "", "  ")
+	if err != nil {
+		return nil, xerrors.Errorf("formatting JSON: %w", err)
+	}
+
+	return append(redacted, '\n'), nil
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if sensitiveKey.MatchString(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redact(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val)
+		}
+	}
+
+	return value
+}
+
+func (w *Writer) add(name string, r io.Reader, size int64, mode os.FileMode, modTime time.Time) error {
+	if w.limit > 0 && w.size+uint64(size) > w.limit {
+		w.skip(name, fmt.Sprintf("%d bytes would exceed the %d byte limit", size, w.limit))
+		return nil
+	}
+
+	err := w.writeHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     int64(mode),
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log files may be truncated while we read them. The tar header has already
+	// been written, so pad any short read with zeroes to keep the archive valid.
+	padded := io.MultiReader(io.LimitReader(r, size), zeroes{})
+	if _, err := io.CopyN(w.tw, padded, size); err != nil {
+		return xerrors.Errorf("archiving %s: %w", name, err)
+	}
+
+	w.size += uint64(size)
+	return nil
+}
+
+func (w *Writer) writeHeader(hdr *tar.Header) error {
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return xerrors.Errorf("archiving %s: %w", hdr.Name, err)
+	}
+
+	return nil
+}
+
+func (w *Writer) skip(name, reason string) {
+	w.skipped = append(w.skipped, fmt.Sprintf("%s: %s", name, reason))
+}
+
+func isDataDir(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "PG_VERSION"))
+	return err == nil
+}
+
+type zeroes struct{}
+
+func (zeroes) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// WriteHostLogs writes a gzipped tarball of a host's gpupgrade state directory,
+// which includes the pg_upgrade working directories, and its log directories,
+// such as gpAdminLogs. Each log directory is named in the tarball after its
+// base name. At most limit uncompressed bytes are collected.
+func WriteHostLogs(w io.Writer, stateDir string, logDirs []string, limit uint64) error {
+	tarball := NewWriter(w, limit)
+
+	if err := tarball.AddDir(stateDir, "state"); err != nil {
+		return xerrors.Errorf("collecting state directory: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for _, dir := range logDirs {
+		name := filepath.Base(dir)
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s-%d", filepath.Base(dir), i)
+		}
+		names[name] = true
+
+		if err := tarball.AddDir(dir, name); err != nil {
+			return xerrors.Errorf("collecting log directory %s: %w", dir, err)
+		}
+	}
+
+	return tarball.Close()
+}
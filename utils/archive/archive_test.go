@@ -0,0 +1,212 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gpupgrade/utils/archive"
+)
+
+func TestWriter(t *testing.T) {
+	root, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(root)
+
+	mustWriteFile(t, filepath.Join(root, "execute.log"), "execute in progress\n")
+	mustWriteFile(t, filepath.Join(root, "pg_upgrade", "seg-1", "pg_upgrade_internal.log"), "internal\n")
+	mustWriteFile(t, filepath.Join(root, "config.json"), `{"Source": {"BinDir": "/old"}, "Password": "hunter2"}`)
+	mustWriteFile(t, filepath.Join(root, "master.bak", "PG_VERSION"), "9.4\n")
+	mustWriteFile(t, filepath.Join(root, "master.bak", "base", "1", "1234"), "data")
+
+	t.Run("archives a directory tree under a prefix", func(t *testing.T) {
+		contents := writeArchive(t, 0, func(w *archive.Writer) error {
+			return w.AddDir(root, "state")
+		})
+
+		expected := map[string]string{
+			"state/execute.log": "execute in progress\n",
+			"state/pg_upgrade/seg-1/pg_upgrade_internal.log": "internal\n",
+		}
+		for name, want := range expected {
+			if got, ok := contents[name]; !ok || got != want {
+				t.Errorf("entry %q = %q, want %q", name, got, want)
+			}
+		}
+	})
+
+	t.Run("redacts credentials from JSON files", func(t *testing.T) {
+		contents := writeArchive(t, 0, func(w *archive.Writer) error {
+			return w.AddDir(root, "state")
+		})
+
+		var config map[string]interface{}
+		if err := json.Unmarshal([]byte(contents["state/config.json"]), &config); err != nil {
+			t.Fatalf("parsing archived config: %+v", err)
+		}
+
+		expected := map[string]interface{}{
+			"Source":   map[string]interface{}{"BinDir": "/old"},
+			"Password": archive.Redacted,
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("archived config was %v, want %v", config, expected)
+		}
+	})
+
+	t.Run("skips data directories", func(t *testing.T) {
+		contents := writeArchive(t, 0, func(w *archive.Writer) error {
+			return w.AddDir(root, "state")
+		})
+
+		for name := range contents {
+			if strings.HasPrefix(name, "state/master.bak") {
+				t.Errorf("data directory entry %q was archived", name)
+			}
+		}
+
+		if !strings.Contains(contents[archive.SkippedFileName], "master.bak: data directory") {
+			t.Errorf("%s was %q, want it to list the data directory",
+				archive.SkippedFileName, contents[archive.SkippedFileName])
+		}
+	})
+
+	t.Run("skips files that would exceed the size limit", func(t *testing.T) {
+		contents := writeArchive(t, 10, func(w *archive.Writer) error {
+			if err := w.AddBytes("small", []byte("12345")); err != nil {
+				return err
+			}
+			if err := w.AddBytes("large", []byte("1234567890")); err != nil {
+				return err
+			}
+			return w.AddBytes("fits", []byte("12345"))
+		})
+
+		if _, ok := contents["large"]; ok {
+			t.Errorf("file exceeding the size limit was archived")
+		}
+
+		for _, name := range []string{"small", "fits"} {
+			if _, ok := contents[name]; !ok {
+				t.Errorf("file %q was not archived", name)
+			}
+		}
+
+		if !strings.Contains(contents[archive.SkippedFileName], "large:") {
+			t.Errorf("%s was %q, want it to list the large file",
+				archive.SkippedFileName, contents[archive.SkippedFileName])
+		}
+	})
+
+	t.Run("ignores a missing directory", func(t *testing.T) {
+		contents := writeArchive(t, 0, func(w *archive.Writer) error {
+			return w.AddDir(filepath.Join(root, "does-not-exist"), "missing")
+		})
+
+		if len(contents) != 0 {
+			t.Errorf("archive contained %v, want no entries", contents)
+		}
+	})
+}
+
+func TestRedactJSON(t *testing.T) {
+	input := `{"Hooks": [{"URL": "http://example.com", "Secret": "s3cr3t"}], "db_passwd": "x", "Port": 1}`
+
+	output, err := archive.RedactJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("RedactJSON returned error %+v", err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(output, &actual); err != nil {
+		t.Fatalf("parsing redacted JSON: %+v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Hooks":     []interface{}{map[string]interface{}{"URL": "http://example.com", "Secret": archive.Redacted}},
+		"db_passwd": archive.Redacted,
+		"Port":      float64(1),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("RedactJSON() = %v, want %v", actual, expected)
+	}
+
+	if _, err := archive.RedactJSON([]byte("not json")); err == nil {
+		t.Errorf("RedactJSON() returned nil error for invalid JSON")
+	}
+}
+
+// writeArchive creates an archive using the given function and returns the
+// contents of its regular files, keyed by name.
+func writeArchive(t *testing.T, limit uint64, f func(*archive.Writer) error) map[string]string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := archive.NewWriter(&buf, limit)
+
+	if err := f(w); err != nil {
+		t.Fatalf("writing archive: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("closing archive: %+v", err)
+	}
+
+	return readArchive(t, &buf)
+}
+
+// readArchive returns the contents of the regular files in a gzipped tarball,
+// keyed by name.
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("opening gzip stream: %+v", err)
+	}
+
+	contents := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading archive: %+v", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("reading %s: %+v", hdr.Name, err)
+		}
+		contents[hdr.Name] = string(data)
+	}
+
+	return contents
+}
+
+func mustWriteFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("creating directory: %+v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("writing file: %+v", err)
+	}
+}
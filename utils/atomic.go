@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+)
+
+// AtomicallyWrite replaces the file at path with the contents produced by
+// write. The contents are written to a temporary file in the same directory,
+// which is synced and then renamed over path; the directory is synced
+// afterwards so that the rename survives a crash. Readers see either the old
+// file or the complete new one, never a partial write. If write fails or
+// panics, the original file is left untouched.
+func AtomicallyWrite(path string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return xerrors.Errorf("creating temporary file: %w", err)
+	}
+
+	renamed := false
+	defer func() {
+		if renamed {
+			return
+		}
+
+		// Ignore close errors, since the file may already be closed.
+		tmp.Close()
+		if rErr := os.Remove(tmp.Name()); rErr != nil {
+			err = multierror.Append(err, xerrors.Errorf("removing temporary file: %w", rErr)).ErrorOrNil()
+		}
+	}()
+
+	if err := tmp.Chmod(perm); err != nil {
+		return xerrors.Errorf("setting permissions: %w", err)
+	}
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return xerrors.Errorf("syncing %s: %w", path, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return xerrors.Errorf("closing %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return xerrors.Errorf("replacing %s: %w", path, err)
+	}
+	renamed = true
+
+	return SyncDir(dir)
+}
+
+// AtomicallyWriteFile is like ioutil.WriteFile, but uses AtomicallyWrite to
+// replace the file.
+func AtomicallyWriteFile(path string, data []byte, perm os.FileMode) error {
+	return AtomicallyWrite(path, perm, func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewReader(data))
+		return err
+	})
+}
+
+// SyncDir flushes the directory entries of dir to disk.
+func SyncDir(dir string) (err error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return xerrors.Errorf("syncing directory: %w", err)
+	}
+	defer func() {
+		if cErr := d.Close(); cErr != nil {
+			err = multierror.Append(err, cErr).ErrorOrNil()
+		}
+	}()
+
+	if err := d.Sync(); err != nil {
+		return xerrors.Errorf("syncing directory %s: %w", dir, err)
+	}
+
+	return nil
+}
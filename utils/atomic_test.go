@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestAtomicallyWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+
+	expectContents := func(t *testing.T, expected string) {
+		t.Helper()
+
+		actual, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading file: %+v", err)
+		}
+		if string(actual) != expected {
+			t.Errorf("file contains %q, want %q", actual, expected)
+		}
+
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("reading directory: %+v", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("directory has %d entries, want only the written file", len(entries))
+		}
+	}
+
+	t.Run("creates and replaces files", func(t *testing.T) {
+		for _, contents := range []string{"first", "second"} {
+			if err := AtomicallyWriteFile(path, []byte(contents), 0640); err != nil {
+				t.Fatalf("AtomicallyWriteFile() returned error %+v", err)
+			}
+
+			expectContents(t, contents)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %+v", err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Errorf("file has mode %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+		}
+	})
+
+	t.Run("leaves the original file alone when the write fails", func(t *testing.T) {
+		expected := errors.New("ahhhh")
+
+		err := AtomicallyWrite(path, 0600, func(w io.Writer) error {
+			io.WriteString(w, "partial")
+			return expected
+		})
+		if !xerrors.Is(err, expected) {
+			t.Errorf("returned error %#v, want %#v", err, expected)
+		}
+
+		expectContents(t, "second")
+	})
+
+	t.Run("leaves the original file alone when the write panics", func(t *testing.T) {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+
+			AtomicallyWrite(path, 0600, func(w io.Writer) error {
+				io.WriteString(w, "partial")
+				panic("ahhhh")
+			})
+		}()
+
+		expectContents(t, "second")
+	})
+}
@@ -0,0 +1,278 @@
+// Package audit keeps an append-only log of operator actions, one JSON entry
+// per line. Each entry records the hash of the entry before it, and its own
+// hash covers that link, so editing, removing or reordering entries breaks
+// the chain and is detected by Verify.
+//
+// The chain is not keyed, so anyone who can write the log can also rebuild it
+// after altering it, as can someone who removes entries from its end. It is
+// only evidence against a record of its head, the sequence number and hash of
+// its last entry, kept off the host: Verify returns the head, which "gpupgrade
+// audit verify" prints and the hub sends with its webhook events. A log whose
+// entry at an anchored sequence number still has the anchored hash has not
+// been altered up to that entry.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UserKey and HostKey are the gRPC metadata keys with which the CLI identifies
+// the operator's OS user and host.
+const (
+	UserKey = "gpupgrade-user"
+	HostKey = "gpupgrade-host"
+)
+
+// Change records a configuration setting that was changed.
+type Change struct {
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Entry is a single action in the log. Seq, Prev and Hash are filled in by
+// Append.
+type Entry struct {
+	Seq    int64           `json:"seq"`
+	Time   time.Time       `json:"time"`
+	User   string          `json:"user,omitempty"`
+	Host   string          `json:"host,omitempty"`
+	Peer   string          `json:"peer,omitempty"`
+	Method string          `json:"method"`
+	Args   json.RawMessage `json:"args,omitempty"`
+	Result string          `json:"result,omitempty"`
+	Change *Change         `json:"change,omitempty"`
+	Prev   string          `json:"prev"`
+	Hash   string          `json:"hash"`
+}
+
+// digest returns the hash of the entry, which covers every field but Hash.
+func (e Entry) digest() (string, error) {
+	e.Hash = ""
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Head identifies the last entry of a log. The zero Head is that of an empty
+// log.
+type Head struct {
+	Seq  int64
+	Hash string
+}
+
+func (h Head) String() string {
+	return fmt.Sprintf("%d:%s", h.Seq, h.Hash)
+}
+
+// Log appends entries to an audit log file.
+type Log struct {
+	path string
+
+	mu   sync.Mutex
+	seq  int64
+	last string // hash of the last entry
+}
+
+// Open opens the audit log at path, which need not exist yet. Entries are
+// appended after the last one already in the log; the rest of the log is not
+// checked.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(contents), []byte("\n"))
+	if last := lines[len(lines)-1]; len(last) > 0 {
+		var e Entry
+		if err := json.Unmarshal(last, &e); err != nil {
+			return nil, xerrors.Errorf("reading last entry of audit log %s: %w", path, err)
+		}
+
+		l.seq = e.Seq
+		l.last = e.Hash
+	}
+
+	return l, nil
+}
+
+// Append chains e to the log and writes it, syncing it to disk before
+// returning.
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.Seq = l.seq + 1
+	e.Prev = l.last
+
+	var err error
+	e.Hash, err = e.digest()
+	if err != nil {
+		return xerrors.Errorf("hashing audit log entry: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return xerrors.Errorf("encoding audit log entry: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return xerrors.Errorf("writing audit log %s: %w", l.path, err)
+	}
+
+	l.seq = e.Seq
+	l.last = e.Hash
+	return nil
+}
+
+// Head returns the head of the log, as of the last entry appended.
+func (l *Log) Head() Head {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Head{Seq: l.seq, Hash: l.last}
+}
+
+// ChainError is returned by Verify for the first entry that breaks the chain.
+type ChainError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log line %d: %s", e.Line, e.Reason)
+}
+
+// Verify checks every entry in the audit log at path, returning the head of
+// the chain if it is intact, or a *ChainError for the first entry that is not
+// along with the head of the entries before it.
+func Verify(path string) (Head, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Head{}, err
+	}
+	defer f.Close()
+
+	var count int
+	var prev string
+	head := func() Head { return Head{Seq: int64(count), Hash: prev} }
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return head(), &ChainError{line, fmt.Sprintf("not a valid entry: %v", err)}
+		}
+
+		if e.Seq != int64(count+1) {
+			return head(), &ChainError{line, fmt.Sprintf("sequence number %d, want %d", e.Seq, count+1)}
+		}
+
+		if e.Prev != prev {
+			return head(), &ChainError{line, "does not follow the previous entry"}
+		}
+
+		digest, err := e.digest()
+		if err != nil {
+			return head(), err
+		}
+		if digest != e.Hash {
+			return head(), &ChainError{line, "contents do not match the entry's hash"}
+		}
+
+		prev = e.Hash
+		count++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return head(), xerrors.Errorf("reading audit log %s: %w", path, err)
+	}
+
+	return head(), nil
+}
+
+// Caller returns the user and host sent by the CLI in the metadata of an
+// incoming request.
+func Caller(ctx context.Context) (username, host string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	return get(UserKey), get(HostKey)
+}
+
+// identify adds the current OS user and host to the metadata of an outgoing
+// request.
+func identify(ctx context.Context) context.Context {
+	var username string
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	} else {
+		username = os.Getenv("USER")
+	}
+
+	host, _ := os.Hostname()
+
+	return metadata.AppendToOutgoingContext(ctx, UserKey, username, HostKey, host)
+}
+
+// UnaryClientInterceptor identifies the caller on every unary request.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(identify(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamClientInterceptor identifies the caller on every streaming request.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(identify(ctx), desc, cc, method, opts...)
+}
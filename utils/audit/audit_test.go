@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+
+	appendEntries := func(t *testing.T, entries ...Entry) {
+		t.Helper()
+
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() returned error %+v", err)
+		}
+
+		for _, e := range entries {
+			if err := l.Append(e); err != nil {
+				t.Fatalf("Append() returned error %+v", err)
+			}
+		}
+	}
+
+	expectIntact := func(t *testing.T, expected int) {
+		t.Helper()
+
+		head, err := Verify(path)
+		if err != nil {
+			t.Errorf("Verify() returned error %+v", err)
+		}
+		if head.Seq != int64(expected) {
+			t.Errorf("Verify() counted %d entries, want %d", head.Seq, expected)
+		}
+
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() returned error %+v", err)
+		}
+		if l.Head() != head {
+			t.Errorf("Head() returned %v, want %v", l.Head(), head)
+		}
+	}
+
+	t.Run("chains entries", func(t *testing.T) {
+		appendEntries(t,
+			Entry{User: "gpadmin", Host: "mdw", Method: "Initialize", Args: []byte(`{"OldBinDir": "/usr/local/gpdb5/bin"}`), Result: "OK"},
+			Entry{User: "gpadmin", Host: "mdw", Method: "SetConfig", Change: &Change{"copy-fanout", "4", "8"}},
+		)
+
+		expectIntact(t, 2)
+	})
+
+	t.Run("continues the chain when reopened", func(t *testing.T) {
+		appendEntries(t, Entry{User: "gpadmin", Host: "mdw", Method: "Execute", Result: "OK"})
+
+		expectIntact(t, 3)
+	})
+
+	original, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading audit log: %+v", err)
+	}
+	lines := bytes.SplitAfter(original, []byte("\n"))
+
+	tamper := func(t *testing.T, contents []byte, line int) {
+		t.Helper()
+
+		if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+			t.Fatalf("writing audit log: %+v", err)
+		}
+		defer ioutil.WriteFile(path, original, 0600)
+
+		_, err := Verify(path)
+
+		var chainErr *ChainError
+		if !xerrors.As(err, &chainErr) {
+			t.Fatalf("Verify() returned error %#v, want a ChainError", err)
+		}
+		if chainErr.Line != line {
+			t.Errorf("Verify() reported line %d, want %d: %v", chainErr.Line, line, err)
+		}
+	}
+
+	t.Run("detects edited entries", func(t *testing.T) {
+		tamper(t, bytes.Replace(original, []byte(`"after":"8"`), []byte(`"after":"2"`), 1), 2)
+	})
+
+	t.Run("detects removed entries", func(t *testing.T) {
+		tamper(t, bytes.Join([][]byte{lines[0], lines[2]}, nil), 2)
+	})
+
+	t.Run("detects reordered entries", func(t *testing.T) {
+		tamper(t, bytes.Join([][]byte{lines[1], lines[0], lines[2]}, nil), 1)
+	})
+
+	t.Run("detects entries that are not JSON", func(t *testing.T) {
+		tamper(t, append(append([]byte{}, original...), "garbage\n"...), 4)
+	})
+}
+
+func TestCaller(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(UserKey, "gpadmin", HostKey, "mdw"))
+
+	username, host := Caller(ctx)
+	if username != "gpadmin" || host != "mdw" {
+		t.Errorf("Caller() returned %q, %q", username, host)
+	}
+
+	username, host = Caller(context.Background())
+	if username != "" || host != "" {
+		t.Errorf("Caller() without metadata returned %q, %q", username, host)
+	}
+}
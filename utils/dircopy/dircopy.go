@@ -0,0 +1,343 @@
+// Package dircopy copies data directories without an external rsync. It
+// implements the behavior of
+//
+//	rsync --archive --hard-links --delete --exclude=PATTERN... SRC/ DST
+//
+// that gpupgrade relies on. Regular files are copied in parallel, each to a
+// temporary file that is renamed into place. Permissions, modification times
+// and symlinks are preserved, as is ownership when running as root, and files
+// that are hard links of one another in the source are hard links of one
+// another in the destination. Device files, sockets and named pipes are
+// skipped.
+//
+// A copy may also be split between two hosts: the receiving side pulls the
+// directory from a FileServer on the other; see Pull.
+//
+// However a directory was copied, ComputeManifest and Verify check the copy
+// against the checksums of the original, locally or on an agent's host with
+// VerifyRemote.
+package dircopy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+)
+
+// The copy engines that gpupgrade can use to copy data directories.
+const (
+	EngineRsync  = "rsync"  // an external rsync, over ssh between hosts
+	EngineNative = "native" // this package, over gRPC between hosts
+)
+
+// ValidateEngine returns an error if engine does not name a copy engine. The
+// empty string selects the default, EngineRsync.
+func ValidateEngine(engine string) error {
+	switch engine {
+	case "", EngineRsync, EngineNative:
+		return nil
+	default:
+		return xerrors.Errorf("unknown copy engine %q: must be %q or %q", engine, EngineRsync, EngineNative)
+	}
+}
+
+// DefaultParallelism is the number of files that are copied at once when
+// Options.Parallelism is not set.
+const DefaultParallelism = 4
+
+// Type is the kind of file that an Entry describes.
+type Type int
+
+const (
+	File Type = iota
+	Dir
+	Symlink
+	HardLink
+)
+
+// Entry describes a file in a directory being copied.
+type Entry struct {
+	// Path is relative to the top of the directory and slash-separated. The
+	// top itself is ".".
+	Path string
+	Type Type
+
+	Mode     os.FileMode // permission bits, including setuid, setgid and sticky
+	UID, GID int
+	ModTime  time.Time
+	Size     int64 // for regular files
+
+	// Target is the target of a Symlink, or the Path of the File that a
+	// HardLink shares.
+	Target string
+}
+
+// Error describes an operation that failed on a single path. Copies continue
+// past such errors, as rsync does, and report them together.
+type Error struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Options control a copy.
+type Options struct {
+	// Exclude lists rsync exclude patterns; see Excludes. Excluded files are
+	// neither copied nor deleted from the destination.
+	Exclude []string
+
+	// Delete removes files from the destination that are not in the source.
+	// As with rsync, nothing is deleted if any file failed to copy.
+	Delete bool
+
+	// Parallelism is the number of files to copy at once.
+	Parallelism int
+
+	// BandwidthLimit is the greatest rate, in bytes per second, at which Pull
+	// receives data. Zero is unlimited.
+	BandwidthLimit int64
+
+	// Progress, if set, is called after each regular file has been copied.
+	// Calls are serialized.
+	Progress func(Progress)
+}
+
+func (o Options) parallelism() int {
+	if o.Parallelism > 0 {
+		return o.Parallelism
+	}
+
+	return DefaultParallelism
+}
+
+// Progress reports how much of a copy is complete. The totals are unknown, and
+// zero, when pulling.
+type Progress struct {
+	Files      int
+	TotalFiles int
+	Bytes      int64
+	TotalBytes int64
+}
+
+// Stats summarizes a completed copy.
+type Stats struct {
+	Files   int   // regular files copied
+	Bytes   int64 // bytes copied
+	Deleted int   // files removed from the destination
+}
+
+// List walks the directory root and returns an Entry for everything in it that
+// is not excluded, parents before children, beginning with root itself.
+// Entries that could not be read are reported in the returned error, which may
+// accompany a partial list.
+func List(root string, excludes *Excludes) ([]Entry, error) {
+	var entries []Entry
+	var mErr *multierror.Error
+
+	type inode struct{ dev, ino uint64 }
+	links := make(map[inode]string)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			mErr = multierror.Append(mErr, &Error{Op: "read", Path: path, Err: err})
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel != "." && excludes.Match(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		entry := Entry{
+			Path:    rel,
+			Mode:    info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky),
+			ModTime: info.ModTime(),
+		}
+
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if ok {
+			entry.UID = int(stat.Uid)
+			entry.GID = int(stat.Gid)
+		}
+
+		switch {
+		case info.IsDir():
+			entry.Type = Dir
+
+		case info.Mode()&os.ModeSymlink != 0:
+			entry.Type = Symlink
+			entry.Target, err = os.Readlink(path)
+			if err != nil {
+				mErr = multierror.Append(mErr, &Error{Op: "read", Path: path, Err: err})
+				return nil
+			}
+
+		case info.Mode().IsRegular():
+			entry.Type = File
+			entry.Size = info.Size()
+
+			if ok && stat.Nlink > 1 {
+				key := inode{uint64(stat.Dev), uint64(stat.Ino)}
+				if first, seen := links[key]; seen {
+					entry.Type = HardLink
+					entry.Target = first
+					entry.Size = 0
+				} else {
+					links[key] = rel
+				}
+			}
+
+		default:
+			return nil // device files, sockets and pipes are skipped
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+
+	return entries, mErr.ErrorOrNil()
+}
+
+// Copy copies the contents of the directory src into the directory dst, which
+// is created if necessary. Regular files that have the same size and
+// modification time in both directories are assumed to be up to date and are
+// not copied again, as with rsync's default quick check.
+func Copy(src, dst string, opts Options) (Stats, error) {
+	var stats Stats
+
+	excludes, err := NewExcludes(opts.Exclude)
+	if err != nil {
+		return stats, err
+	}
+
+	entries, listErr := List(src, excludes)
+
+	r, err := NewReceiver(dst)
+	if err != nil {
+		return stats, err
+	}
+
+	files, progress := regularFiles(entries)
+
+	var mu sync.Mutex
+	var transferErr *multierror.Error
+
+	work := make(chan Entry)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.parallelism(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for e := range work {
+				copied, err := copyFile(r, src, e)
+
+				mu.Lock()
+				if err != nil {
+					transferErr = multierror.Append(transferErr, err)
+				}
+				if copied {
+					stats.Files++
+					stats.Bytes += e.Size
+				}
+				progress.Files++
+				progress.Bytes += e.Size
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, e := range files {
+		work <- e
+	}
+	close(work)
+	wg.Wait()
+
+	var mErr *multierror.Error
+	mErr = multierror.Append(mErr, listErr)
+	mErr = multierror.Append(mErr, transferErr.ErrorOrNil())
+
+	deleteExtraneous := opts.Delete && mErr.ErrorOrNil() == nil
+	stats.Deleted, err = r.Finish(entries, excludes, deleteExtraneous)
+	mErr = multierror.Append(mErr, err)
+
+	return stats, mErr.ErrorOrNil()
+}
+
+// copyFile copies the regular file e from src using r, unless the destination
+// is already up to date, in which case only its attributes are updated.
+func copyFile(r *Receiver, src string, e Entry) (bool, error) {
+	if r.UpToDate(e) {
+		return false, r.SetAttributes(e)
+	}
+
+	path := filepath.Join(src, filepath.FromSlash(e.Path))
+	file, err := readFile(path)
+	if err != nil {
+		return false, &Error{Op: "read", Path: path, Err: err}
+	}
+	defer file.Close()
+
+	if err := r.WriteFile(e, file); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// regularFiles returns the File entries, largest first so that the biggest
+// transfers start earliest, along with the initial Progress of copying them.
+func regularFiles(entries []Entry) ([]Entry, Progress) {
+	var files []Entry
+	var progress Progress
+
+	for _, e := range entries {
+		if e.Type == File {
+			files = append(files, e)
+			progress.TotalFiles++
+			progress.TotalBytes += e.Size
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	return files, progress
+}
+
+// readFile is used to open source files; it is a variable so that tests can
+// simulate read failures.
+var readFile = func(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
@@ -0,0 +1,373 @@
+package dircopy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+)
+
+// The source tree used by the tests resembles a master data directory. See
+// makeSource.
+var excludes = []string{"pg_log/*", "postmaster.opts"}
+
+func TestCopy(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	src := makeSource(t, dir)
+
+	t.Run("copies a directory like rsync", func(t *testing.T) {
+		dst := filepath.Join(dir, "copy")
+		makeStaleDestination(t, dst)
+
+		var progress []Progress
+		stats, err := Copy(src, dst, Options{
+			Exclude:     excludes,
+			Delete:      true,
+			Parallelism: 3,
+			Progress:    func(p Progress) { progress = append(progress, p) },
+		})
+		if err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		expectCopied(t, src, dst)
+
+		expectedStats := Stats{Files: 6, Bytes: 3*ChunkSize + 3 + 8 + 25 + 14, Deleted: 2}
+		if stats != expectedStats {
+			t.Errorf("got stats %+v, want %+v", stats, expectedStats)
+		}
+
+		last := progress[len(progress)-1]
+		if len(progress) != 6 || last.Files != last.TotalFiles || last.Bytes != last.TotalBytes {
+			t.Errorf("got progress %+v", progress)
+		}
+	})
+
+	t.Run("skips files that are up to date", func(t *testing.T) {
+		dst := filepath.Join(dir, "copy")
+
+		// A quick check compares only sizes and modification times, so a
+		// change that preserves both goes unnoticed, as it does with rsync.
+		path := filepath.Join(dst, "PG_VERSION")
+		writeFile(t, path, "9.X", 0600)
+		setTime(t, path)
+
+		stats, err := Copy(src, dst, Options{Exclude: excludes, Delete: true})
+		if err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		if stats.Files != 0 {
+			t.Errorf("copied %d files, want 0", stats.Files)
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading PG_VERSION: %+v", err)
+		}
+		if string(contents) != "9.X" {
+			t.Errorf("PG_VERSION was copied again")
+		}
+	})
+
+	t.Run("does not delete anything when a file cannot be copied", func(t *testing.T) {
+		dst := filepath.Join(dir, "failed-copy")
+		makeStaleDestination(t, dst)
+
+		expected := xerrors.New("disk on fire")
+		readFile = func(path string) (io.ReadCloser, error) {
+			if strings.HasSuffix(path, "PG_VERSION") {
+				return nil, expected
+			}
+			return os.Open(path)
+		}
+		defer func() { readFile = defaultReadFile }()
+
+		_, err := Copy(src, dst, Options{Exclude: excludes, Delete: true})
+
+		var mErr *multierror.Error
+		if !xerrors.As(err, &mErr) || len(mErr.Errors) != 1 {
+			t.Fatalf("returned error %#v, want one error", err)
+		}
+
+		var copyErr *Error
+		if !xerrors.As(mErr.Errors[0], &copyErr) || !xerrors.Is(copyErr, expected) {
+			t.Fatalf("returned error %#v, want %#v", mErr.Errors[0], expected)
+		}
+		if copyErr.Path != filepath.Join(src, "PG_VERSION") {
+			t.Errorf("got error for path %q", copyErr.Path)
+		}
+
+		if _, err := os.Stat(filepath.Join(dst, "extraneous")); err != nil {
+			t.Errorf("extraneous file was deleted: %+v", err)
+		}
+	})
+
+	t.Run("matches rsync", func(t *testing.T) {
+		rsync, err := exec.LookPath("rsync")
+		if err != nil {
+			t.Skip("rsync is not installed")
+		}
+
+		native := filepath.Join(dir, "native")
+		makeStaleDestination(t, native)
+		if _, err := Copy(src, native, Options{Exclude: excludes, Delete: true}); err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		rsynced := filepath.Join(dir, "rsync")
+		makeStaleDestination(t, rsynced)
+		args := []string{"--archive", "--hard-links", "--delete"}
+		for _, e := range excludes {
+			args = append(args, "--exclude", e)
+		}
+		args = append(args, src+"/", rsynced)
+
+		if out, err := exec.Command(rsync, args...).CombinedOutput(); err != nil {
+			t.Fatalf("rsync: %+v: %s", err, out)
+		}
+
+		if actual, expected := snapshot(t, native), snapshot(t, rsynced); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("native copy differs from rsync:\n%s", diff(actual, expected))
+		}
+	})
+}
+
+var defaultReadFile = readFile
+
+// makeSource creates a directory tree containing regular files large and
+// small, hard links, a symlink, unusual permissions and files to be excluded.
+func makeSource(t *testing.T, dir string) string {
+	t.Helper()
+
+	src := filepath.Join(dir, "source")
+
+	mkdir(t, src, 0700)
+	writeFile(t, filepath.Join(src, "PG_VERSION"), "9.4", 0600)
+	writeFile(t, filepath.Join(src, "postmaster.opts"), "excluded", 0600)
+
+	mkdir(t, filepath.Join(src, "base", "1"), 0700)
+	writeFile(t, filepath.Join(src, "base", "1", "1234"), strings.Repeat("x", 3*ChunkSize), 0600)
+	writeFile(t, filepath.Join(src, "base", "1", "1235"), "", 0600)
+
+	mkdir(t, filepath.Join(src, "global"), 0750)
+	writeFile(t, filepath.Join(src, "global", "pg_control"), "control!", 0600)
+	if err := os.Link(filepath.Join(src, "global", "pg_control"), filepath.Join(src, "global", "pg_control.link")); err != nil {
+		t.Fatalf("linking: %+v", err)
+	}
+
+	mkdir(t, filepath.Join(src, "pg_log"), 0700)
+	writeFile(t, filepath.Join(src, "pg_log", "gpdb.csv"), "excluded", 0600)
+
+	mkdir(t, filepath.Join(src, "pg_tblspc"), 0700)
+	if err := os.Symlink("/data/tablespaces/16385", filepath.Join(src, "pg_tblspc", "16385")); err != nil {
+		t.Fatalf("symlinking: %+v", err)
+	}
+
+	mkdir(t, filepath.Join(src, "scripts"), 0755)
+	writeFile(t, filepath.Join(src, "scripts", "run.sh"), "#!/bin/sh\necho upgrading\n", 0755)
+	writeFile(t, filepath.Join(src, "scripts", "README"), "run run.sh\n\n\n\n", 0444)
+
+	// Set times last, deepest first, since creating files changes the
+	// modification time of their directories.
+	var paths []string
+	filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if info.Mode()&os.ModeSymlink == 0 {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	for i := len(paths) - 1; i >= 0; i-- {
+		setTime(t, paths[i])
+	}
+
+	return src
+}
+
+// makeStaleDestination creates a destination containing files that rsync
+// would delete, a file that is protected from deletion by an exclude, and a
+// file where the source has a directory.
+func makeStaleDestination(t *testing.T, dst string) {
+	t.Helper()
+
+	mkdir(t, filepath.Join(dst, "pg_log"), 0700)
+	writeFile(t, filepath.Join(dst, "pg_log", "protected.csv"), "keep me", 0600)
+	writeFile(t, filepath.Join(dst, "extraneous"), "delete me", 0600)
+	mkdir(t, filepath.Join(dst, "extraneous_dir", "sub"), 0700)
+	writeFile(t, filepath.Join(dst, "extraneous_dir", "sub", "file"), "delete me", 0600)
+	writeFile(t, filepath.Join(dst, "base"), "in the way", 0600)
+}
+
+// expectCopied verifies that dst matches src, less the excluded files, plus
+// the excluded file in the stale destination.
+func expectCopied(t *testing.T, src, dst string) {
+	t.Helper()
+
+	expected := snapshot(t, src)
+	delete(expected, "pg_log/gpdb.csv")
+	delete(expected, "postmaster.opts")
+
+	actual := snapshot(t, dst)
+	if _, ok := actual["pg_log/protected.csv"]; !ok {
+		t.Errorf("excluded file was deleted from the destination")
+	}
+	delete(actual, "pg_log/protected.csv")
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("copy differs from source:\n%s", diff(actual, expected))
+	}
+
+	var control, link syscall.Stat_t
+	syscall.Stat(filepath.Join(dst, "global", "pg_control"), &control)
+	syscall.Stat(filepath.Join(dst, "global", "pg_control.link"), &link)
+	if control.Ino != link.Ino {
+		t.Errorf("hard link was not preserved")
+	}
+}
+
+type fileState struct {
+	Mode    os.FileMode
+	ModTime time.Time
+	Digest  string // contents of regular files, or the target of symlinks
+}
+
+// snapshot records the state of every file under dir.
+func snapshot(t *testing.T, dir string) map[string]fileState {
+	t.Helper()
+
+	files := make(map[string]fileState)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		state := fileState{Mode: info.Mode()}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			state.Digest, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+		case info.Mode().IsRegular():
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			state.Digest = fmt.Sprintf("%x", sha256.Sum256(contents))
+			state.ModTime = info.ModTime()
+
+		default:
+			state.ModTime = info.ModTime()
+		}
+
+		files[filepath.ToSlash(rel)] = state
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking %s: %+v", dir, err)
+	}
+
+	return files
+}
+
+func diff(actual, expected map[string]fileState) string {
+	var paths []string
+	for path := range actual {
+		paths = append(paths, path)
+	}
+	for path := range expected {
+		if _, ok := actual[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var b bytes.Buffer
+	for _, path := range paths {
+		a, aOK := actual[path]
+		e, eOK := expected[path]
+		switch {
+		case !aOK:
+			fmt.Fprintf(&b, "  missing %s\n", path)
+		case !eOK:
+			fmt.Fprintf(&b, "  unexpected %s\n", path)
+		case a != e:
+			fmt.Fprintf(&b, "  %s: got %+v, want %+v\n", path, a, e)
+		}
+	}
+
+	return b.String()
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "dircopy")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+
+	return dir, func() {
+		// Make sure read-only directories can be removed.
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				os.Chmod(path, 0700)
+			}
+			return nil
+		})
+		os.RemoveAll(dir)
+	}
+}
+
+func mkdir(t *testing.T, path string, perm os.FileMode) {
+	t.Helper()
+
+	if err := os.MkdirAll(path, 0700); err != nil {
+		t.Fatalf("creating %s: %+v", path, err)
+	}
+
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatalf("chmod %s: %+v", path, err)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string, perm os.FileMode) {
+	t.Helper()
+
+	os.Remove(path)
+	if err := ioutil.WriteFile(path, []byte(contents), perm); err != nil {
+		t.Fatalf("writing %s: %+v", path, err)
+	}
+}
+
+func setTime(t *testing.T, path string) {
+	t.Helper()
+
+	mtime := time.Date(2019, time.December, 25, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("setting time of %s: %+v", path, err)
+	}
+}
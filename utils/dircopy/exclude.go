@@ -0,0 +1,166 @@
+package dircopy
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Excludes matches paths against rsync exclude patterns. As in rsync:
+//
+//   - a pattern without a slash matches the final component of a path, at any
+//     depth
+//   - a pattern containing a slash matches the end of a path, beginning at a
+//     component boundary, unless it starts with a slash, which anchors it to
+//     the top of the copied directory
+//   - a trailing slash matches only directories
+//   - * matches anything but a slash, ** matches anything, ? matches a single
+//     character other than a slash, and [...] matches a character class
+//
+// The contents of an excluded directory are excluded along with it. The zero
+// value, and a nil *Excludes, match nothing.
+type Excludes struct {
+	patterns []string
+	matchers []matcher
+}
+
+type matcher struct {
+	re       *regexp.Regexp
+	dirOnly  bool
+	basename bool // match the final path component only
+}
+
+// NewExcludes compiles the given patterns. Empty patterns are ignored.
+func NewExcludes(patterns []string) (*Excludes, error) {
+	e := new(Excludes)
+
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+
+		m, err := compile(p)
+		if err != nil {
+			return nil, xerrors.Errorf("exclude pattern %q: %w", p, err)
+		}
+
+		e.patterns = append(e.patterns, p)
+		e.matchers = append(e.matchers, m)
+	}
+
+	return e, nil
+}
+
+// Patterns returns the patterns that e was created with.
+func (e *Excludes) Patterns() []string {
+	if e == nil {
+		return nil
+	}
+
+	return e.patterns
+}
+
+// Match returns whether the given slash-separated path, relative to the top of
+// the copied directory, is excluded.
+func (e *Excludes) Match(relPath string, isDir bool) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, m := range e.matchers {
+		if m.dirOnly && !isDir {
+			continue
+		}
+
+		subject := relPath
+		if m.basename {
+			subject = path.Base(relPath)
+		}
+
+		if m.re.MatchString(subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compile(pattern string) (matcher, error) {
+	var m matcher
+
+	if strings.HasSuffix(pattern, "/") {
+		m.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimLeft(pattern, "/")
+
+	if pattern == "" {
+		return m, xerrors.New("pattern matches nothing")
+	}
+
+	m.basename = !anchored && !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**")
+
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return m, err
+	}
+
+	switch {
+	case anchored || m.basename:
+		expr = "^" + expr + "$"
+	default:
+		expr = "(^|/)" + expr + "$"
+	}
+
+	m.re, err = regexp.Compile(expr)
+	return m, err
+}
+
+func globToRegexp(glob string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+
+		case '?':
+			b.WriteString("[^/]")
+
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", xerrors.New("unterminated character class")
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String(), nil
+}
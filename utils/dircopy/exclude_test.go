@@ -0,0 +1,75 @@
+package dircopy
+
+import "testing"
+
+func TestExcludes(t *testing.T) {
+	// The expected results follow rsync's --exclude semantics.
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		match   bool
+	}{
+		{"postgresql.conf", "postgresql.conf", false, true},
+		{"postgresql.conf", "conf.d/postgresql.conf", false, true},
+		{"postgresql.conf", "postgresql.conf.bak", false, false},
+		{"gpperfmon", "gpperfmon", true, true},
+		{"*.conf", "pg_hba.conf", false, true},
+		{"*.conf", "conf.d/extra.conf", false, true},
+		{"pg_log/*", "pg_log/gpdb.csv", false, true},
+		{"pg_log/*", "pg_log", true, false},
+		{"pg_log/*", "old/pg_log/gpdb.csv", false, true},
+		{"pg_log/*", "pg_log/archive/gpdb.csv", false, false},
+		{"pg_log/*", "xpg_log/gpdb.csv", false, false},
+		{"/pg_log", "pg_log", true, true},
+		{"/pg_log", "base/pg_log", true, false},
+		{"pg_log/", "pg_log", true, true},
+		{"pg_log/", "pg_log", false, false},
+		{"base/**/t_*", "base/1/2/t_1234", false, true},
+		{"**/pgsql_tmp", "base/pgsql_tmp", true, true},
+		{"gp_dbi?", "gp_dbid", false, true},
+		{"gp_dbi?", "gp_dbi/d", false, false},
+		{"core.[0-9]*", "core.1234", false, true},
+		{"core.[!0-9]*", "core.1234", false, false},
+		{`file\*`, "file*", false, true},
+		{`file\*`, "files", false, false},
+	}
+
+	for _, c := range cases {
+		excludes, err := NewExcludes([]string{c.pattern})
+		if err != nil {
+			t.Fatalf("NewExcludes(%q) returned error %+v", c.pattern, err)
+		}
+
+		if match := excludes.Match(c.path, c.isDir); match != c.match {
+			t.Errorf("pattern %q matching %q (directory %t) returned %t, want %t",
+				c.pattern, c.path, c.isDir, match, c.match)
+		}
+	}
+
+	t.Run("ignores empty patterns", func(t *testing.T) {
+		excludes, err := NewExcludes([]string{""})
+		if err != nil {
+			t.Fatalf("NewExcludes() returned error %+v", err)
+		}
+
+		if excludes.Match("anything", false) {
+			t.Errorf("empty pattern matched")
+		}
+	})
+
+	t.Run("nil Excludes match nothing", func(t *testing.T) {
+		var excludes *Excludes
+		if excludes.Match("anything", false) {
+			t.Errorf("nil Excludes matched")
+		}
+	})
+
+	t.Run("rejects bad patterns", func(t *testing.T) {
+		for _, pattern := range []string{"/", "core.[0-9"} {
+			if _, err := NewExcludes([]string{pattern}); err == nil {
+				t.Errorf("NewExcludes(%q) returned no error", pattern)
+			}
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package dircopy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// ManifestEntry records the size and SHA-256 checksum of a regular file.
+type ManifestEntry struct {
+	Path   string // relative to the top of the directory and slash-separated
+	Size   int64
+	SHA256 string // hex-encoded
+}
+
+// Mismatch describes a file that differs from its manifest.
+type Mismatch struct {
+	Path   string
+	Reason string
+}
+
+// VerifyError is returned when a directory does not match its manifest.
+type VerifyError struct {
+	Dir        string
+	Mismatches []Mismatch
+}
+
+func (e *VerifyError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d files in %s do not match the manifest:", len(e.Mismatches), e.Dir)
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&b, "\n  %s: %s", m.Path, m.Reason)
+	}
+
+	return b.String()
+}
+
+// ComputeManifest returns a ManifestEntry for every regular file in the
+// directory root, sorted by path. Files that are hard links of one another are
+// listed separately.
+func ComputeManifest(root string) ([]ManifestEntry, error) {
+	_, sums, err := checksums(root)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := make([]ManifestEntry, 0, len(sums))
+	for _, sum := range sums {
+		manifest = append(manifest, sum)
+	}
+
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+	return manifest, nil
+}
+
+// Verify compares the directory dir against a manifest computed by
+// ComputeManifest, returning the files that are missing, differ, or are not in
+// the manifest, sorted by path. An error is returned only if dir could not be
+// read.
+func Verify(dir string, manifest []ManifestEntry) ([]Mismatch, error) {
+	entries, sums, err := checksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]Type, len(entries))
+	for _, e := range entries {
+		types[e.Path] = e.Type
+	}
+
+	var mismatches []Mismatch
+	expected := make(map[string]bool, len(manifest))
+
+	for _, want := range manifest {
+		expected[want.Path] = true
+
+		got, ok := sums[want.Path]
+		_, exists := types[want.Path]
+
+		switch {
+		case !ok && exists:
+			mismatches = append(mismatches, Mismatch{want.Path, "not a regular file"})
+		case !ok:
+			mismatches = append(mismatches, Mismatch{want.Path, "missing"})
+		case got.Size != want.Size:
+			mismatches = append(mismatches, Mismatch{want.Path, fmt.Sprintf("size %d, want %d", got.Size, want.Size)})
+		case got.SHA256 != want.SHA256:
+			mismatches = append(mismatches, Mismatch{want.Path, fmt.Sprintf("checksum %s, want %s", got.SHA256, want.SHA256)})
+		}
+	}
+
+	for path := range sums {
+		if !expected[path] {
+			mismatches = append(mismatches, Mismatch{path, "not in the manifest"})
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Path < mismatches[j].Path })
+	return mismatches, nil
+}
+
+// checksums lists the directory root and computes the checksum of each regular
+// file in it, keyed by path.
+func checksums(root string) ([]Entry, map[string]ManifestEntry, error) {
+	entries, listErr := List(root, nil)
+	files, _ := regularFiles(entries)
+
+	var mu sync.Mutex
+	var mErr *multierror.Error
+	mErr = multierror.Append(mErr, listErr)
+
+	sums := make(map[string]ManifestEntry, len(files))
+
+	work := make(chan Entry)
+	var wg sync.WaitGroup
+	for i := 0; i < DefaultParallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for e := range work {
+				size, sum, err := checksum(filepath.Join(root, filepath.FromSlash(e.Path)))
+
+				mu.Lock()
+				if err != nil {
+					mErr = multierror.Append(mErr, err)
+				} else {
+					sums[e.Path] = ManifestEntry{Path: e.Path, Size: size, SHA256: sum}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, e := range files {
+		work <- e
+	}
+	close(work)
+	wg.Wait()
+
+	for _, e := range entries {
+		if e.Type != HardLink {
+			continue
+		}
+
+		if sum, ok := sums[e.Target]; ok {
+			sum.Path = e.Path
+			sums[e.Path] = sum
+		}
+	}
+
+	return entries, sums, mErr.ErrorOrNil()
+}
+
+func checksum(path string) (int64, string, error) {
+	file, err := readFile(path)
+	if err != nil {
+		return 0, "", &Error{Op: "read", Path: path, Err: err}
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", &Error{Op: "read", Path: path, Err: err}
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// entriesPerMessage bounds the size of each message of a VerifyManifest stream.
+const entriesPerMessage = 1000
+
+// VerifyRemote sends manifest to the agent's VerifyManifest, which compares it
+// against the directory dir on the agent's host. A *VerifyError is returned if
+// they differ.
+func VerifyRemote(ctx context.Context, client idl.AgentClient, dir string, manifest []ManifestEntry) error {
+	stream, err := client.VerifyManifest(ctx)
+	if err != nil {
+		return xerrors.Errorf("opening verify stream: %w", err)
+	}
+
+	request := &idl.VerifyManifestRequest{Dir: dir}
+	for i := 0; i == 0 || i < len(manifest); i += entriesPerMessage {
+		end := i + entriesPerMessage
+		if end > len(manifest) {
+			end = len(manifest)
+		}
+
+		for _, e := range manifest[i:end] {
+			request.Entries = append(request.Entries, &idl.ManifestEntry{Path: e.Path, Size: e.Size, SHA256: e.SHA256})
+		}
+
+		if err := stream.Send(request); err != nil {
+			break // CloseAndRecv returns the reason
+		}
+		request = &idl.VerifyManifestRequest{}
+	}
+
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return xerrors.Errorf("verifying %s: %w", dir, err)
+	}
+
+	if len(reply.Mismatches) == 0 {
+		return nil
+	}
+
+	verifyErr := &VerifyError{Dir: dir}
+	for _, m := range reply.Mismatches {
+		verifyErr.Mismatches = append(verifyErr.Mismatches, Mismatch{Path: m.Path, Reason: m.Reason})
+	}
+
+	return verifyErr
+}
+
+// VerifyManifest verifies a directory against the manifest sent by VerifyRemote
+// over a VerifyManifest stream. See Verify.
+func VerifyManifest(stream idl.VerifyManifestReceiver) ([]Mismatch, error) {
+	var dir string
+	var manifest []ManifestEntry
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if dir == "" {
+			dir = msg.Dir
+		}
+
+		for _, e := range msg.Entries {
+			manifest = append(manifest, ManifestEntry{Path: e.Path, Size: e.Size, SHA256: e.SHA256})
+		}
+	}
+
+	if dir == "" {
+		return nil, xerrors.New("no directory to verify")
+	}
+
+	return Verify(dir, manifest)
+}
@@ -0,0 +1,226 @@
+package dircopy
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+func TestManifest(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	src := makeSource(t, dir)
+
+	manifest, err := ComputeManifest(src)
+	if err != nil {
+		t.Fatalf("ComputeManifest() returned error %+v", err)
+	}
+
+	t.Run("lists every regular file", func(t *testing.T) {
+		var paths []string
+		for _, e := range manifest {
+			paths = append(paths, e.Path)
+		}
+
+		expected := []string{
+			"PG_VERSION",
+			"base/1/1234",
+			"base/1/1235",
+			"global/pg_control",
+			"global/pg_control.link",
+			"pg_log/gpdb.csv",
+			"postmaster.opts",
+			"scripts/README",
+			"scripts/run.sh",
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("got paths %q, want %q", paths, expected)
+		}
+
+		expectedEntry := ManifestEntry{
+			Path:   "PG_VERSION",
+			Size:   3,
+			SHA256: "2b640621567d2a99502a5360c501dabf48c83144bda7829b9f7378f357b452fb",
+		}
+		if manifest[0] != expectedEntry {
+			t.Errorf("got entry %+v, want %+v", manifest[0], expectedEntry)
+		}
+
+		if manifest[3].SHA256 != manifest[4].SHA256 {
+			t.Errorf("hard links have different checksums")
+		}
+	})
+
+	t.Run("verifies an identical copy", func(t *testing.T) {
+		dst := filepath.Join(dir, "identical")
+		if _, err := Copy(src, dst, Options{}); err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		mismatches, err := Verify(dst, manifest)
+		if err != nil {
+			t.Fatalf("Verify() returned error %+v", err)
+		}
+
+		if len(mismatches) != 0 {
+			t.Errorf("got mismatches %+v", mismatches)
+		}
+	})
+
+	t.Run("reports every file that differs", func(t *testing.T) {
+		dst := filepath.Join(dir, "corrupted")
+		if _, err := Copy(src, dst, Options{}); err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		// Corruption that preserves the size is caught by the checksum.
+		writeFile(t, filepath.Join(dst, "PG_VERSION"), "9.5", 0600)
+		writeFile(t, filepath.Join(dst, "scripts", "README"), "truncated", 0600)
+		writeFile(t, filepath.Join(dst, "stray"), "", 0600)
+		if err := os.Remove(filepath.Join(dst, "postmaster.opts")); err != nil {
+			t.Fatalf("removing postmaster.opts: %+v", err)
+		}
+		if err := os.Remove(filepath.Join(dst, "base", "1", "1235")); err != nil {
+			t.Fatalf("removing 1235: %+v", err)
+		}
+		mkdir(t, filepath.Join(dst, "base", "1", "1235"), 0700)
+
+		mismatches, err := Verify(dst, manifest)
+		if err != nil {
+			t.Fatalf("Verify() returned error %+v", err)
+		}
+
+		expected := []Mismatch{
+			{"PG_VERSION", "checksum e0b04046fbfbc96f3437e4718a8a15e7eb5936298a36a8c2039226d1c5f41845, want 2b640621567d2a99502a5360c501dabf48c83144bda7829b9f7378f357b452fb"},
+			{"base/1/1235", "not a regular file"},
+			{"postmaster.opts", "missing"},
+			{"scripts/README", "size 9, want 14"},
+			{"stray", "not in the manifest"},
+		}
+		if !reflect.DeepEqual(mismatches, expected) {
+			t.Errorf("got mismatches %+v, want %+v", mismatches, expected)
+		}
+	})
+
+	t.Run("returns an error when the directory cannot be read", func(t *testing.T) {
+		missing := filepath.Join(dir, "does-not-exist")
+
+		_, err := Verify(missing, manifest)
+
+		var mErr *multierror.Error
+		if !xerrors.As(err, &mErr) || len(mErr.Errors) != 1 {
+			t.Fatalf("returned error %#v, want one error", err)
+		}
+
+		var copyErr *Error
+		if !xerrors.As(mErr.Errors[0], &copyErr) || !os.IsNotExist(copyErr.Err) {
+			t.Errorf("returned error %#v, want a not-exist error", mErr.Errors[0])
+		}
+	})
+}
+
+// verifyingAgent implements the agent RPC used by VerifyRemote. Calling any
+// other RPC panics.
+type verifyingAgent struct {
+	idl.AgentServer
+}
+
+func (a verifyingAgent) VerifyManifest(stream idl.Agent_VerifyManifestServer) error {
+	mismatches, err := VerifyManifest(stream)
+	if err != nil {
+		return err
+	}
+
+	reply := &idl.VerifyManifestReply{}
+	for _, m := range mismatches {
+		reply.Mismatches = append(reply.Mismatches, &idl.ManifestMismatch{Path: m.Path, Reason: m.Reason})
+	}
+
+	return stream.SendAndClose(reply)
+}
+
+func TestVerifyRemote(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	src := makeSource(t, dir)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("listening: %+v", err)
+	}
+
+	server := grpc.NewServer()
+	idl.RegisterAgentServer(server, verifyingAgent{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("dialing: %+v", err)
+	}
+	defer conn.Close()
+
+	client := idl.NewAgentClient(conn)
+
+	manifest, err := ComputeManifest(src)
+	if err != nil {
+		t.Fatalf("ComputeManifest() returned error %+v", err)
+	}
+
+	dst := filepath.Join(dir, "verified")
+	if _, err := Copy(src, dst, Options{}); err != nil {
+		t.Fatalf("Copy() returned error %+v", err)
+	}
+
+	if err := VerifyRemote(context.Background(), client, dst, manifest); err != nil {
+		t.Errorf("VerifyRemote() returned error %+v", err)
+	}
+
+	writeFile(t, filepath.Join(dst, "global", "pg_control"), "corrupt!", 0600)
+
+	err = VerifyRemote(context.Background(), client, dst, manifest)
+
+	var verifyErr *VerifyError
+	if !xerrors.As(err, &verifyErr) {
+		t.Fatalf("returned error %#v, want a VerifyError", err)
+	}
+
+	var paths []string
+	for _, m := range verifyErr.Mismatches {
+		paths = append(paths, m.Path)
+	}
+
+	expected := []string{"global/pg_control"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got mismatches %+v, want %q", verifyErr.Mismatches, expected)
+	}
+}
+
+func TestVerifyError(t *testing.T) {
+	err := &VerifyError{
+		Dir: "/data/upgraded-master.bak",
+		Mismatches: []Mismatch{
+			{"PG_VERSION", "missing"},
+			{"global/pg_control", "size 0, want 8192"},
+		},
+	}
+
+	expected := `2 files in /data/upgraded-master.bak do not match the manifest:
+  PG_VERSION: missing
+  global/pg_control: size 0, want 8192`
+
+	if err.Error() != expected {
+		t.Errorf("got %q, want %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,230 @@
+package dircopy
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// FileServer implements idl.FileServerServer, serving the directories for
+// which Allow returns true to agents calling Pull.
+type FileServer struct {
+	Allow func(dir string) bool
+}
+
+func (f *FileServer) ServeFiles(request *idl.ServeFilesRequest, stream idl.FileServer_ServeFilesServer) error {
+	dir := filepath.Clean(request.Dir)
+	if f.Allow == nil || !f.Allow(dir) {
+		return status.Errorf(codes.PermissionDenied, "%s may not be served", request.Dir)
+	}
+
+	return Serve(stream, dir, request.Excludes)
+}
+
+// Within returns an Allow function for a FileServer that serves only root and
+// the directories beneath it.
+func Within(root string) func(dir string) bool {
+	root = filepath.Clean(root)
+
+	return func(dir string) bool {
+		rel, err := filepath.Rel(root, dir)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+	}
+}
+
+// Serve sends the contents of the directory dir over a ServeFiles stream. A
+// directory that cannot be listed completely is not sent at all, since the
+// receiver would delete whatever is missing.
+func Serve(sender idl.FileChunkSender, dir string, exclude []string) error {
+	excludes, err := NewExcludes(exclude)
+	if err != nil {
+		return err
+	}
+
+	entries, err := List(dir, excludes)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, ChunkSize)
+	for _, e := range entries {
+		chunk := &idl.FileChunk{Entry: entryToProto(e)}
+
+		if e.Type != File {
+			if err := sender.Send(chunk); err != nil {
+				return xerrors.Errorf("sending %s: %w", e.Path, err)
+			}
+			continue
+		}
+
+		if err := sendFile(sender, filepath.Join(dir, filepath.FromSlash(e.Path)), chunk, buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pull copies the directory src served by a FileServer into the local
+// directory dst. As with CopyTo, every regular file is transferred, since the
+// server cannot see the destination. Files are received one at a time, no
+// faster than opts.BandwidthLimit; opts.Parallelism is ignored.
+func Pull(ctx context.Context, client idl.FileServerClient, src, dst string, opts Options) (stats Stats, err error) {
+	excludes, err := NewExcludes(opts.Exclude)
+	if err != nil {
+		return stats, err
+	}
+
+	r, err := NewReceiver(dst)
+	if err != nil {
+		return stats, err
+	}
+
+	stream, err := client.ServeFiles(ctx, &idl.ServeFilesRequest{Dir: src, Excludes: excludes.Patterns()})
+	if err != nil {
+		return stats, xerrors.Errorf("opening file stream: %w", err)
+	}
+
+	var entries []Entry
+	var current *FileWriter
+	defer func() {
+		if current != nil {
+			current.Abort()
+		}
+	}()
+
+	// finishFile closes the file being received, if any.
+	finishFile := func() error {
+		f := current
+		current = nil
+		if err := closeReceived(f, &stats); err != nil || f == nil {
+			return err
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(Progress{Files: stats.Files, Bytes: stats.Bytes})
+		}
+		return nil
+	}
+
+	limit := newLimiter(opts.BandwidthLimit)
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, xerrors.Errorf("pulling %s: %w", src, err)
+		}
+
+		limit.wait(len(chunk.Data))
+
+		if chunk.Entry != nil {
+			if err := finishFile(); err != nil {
+				return stats, err
+			}
+
+			e, err := entryFromProto(chunk.Entry)
+			if err != nil {
+				return stats, err
+			}
+			entries = append(entries, e)
+
+			if e.Type == File {
+				current, err = r.Create(e)
+				if err != nil {
+					return stats, err
+				}
+			}
+		}
+
+		if len(chunk.Data) == 0 {
+			continue
+		}
+
+		if current == nil {
+			return stats, xerrors.New("received file data before a file entry")
+		}
+
+		if _, err := current.Write(chunk.Data); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := finishFile(); err != nil {
+		return stats, err
+	}
+
+	stats.Deleted, err = r.Finish(entries, excludes, opts.Delete)
+	return stats, err
+}
+
+// limiter paces a transfer to a number of bytes per second.
+type limiter struct {
+	rate  int64
+	start time.Time
+	total int64
+}
+
+func newLimiter(rate int64) *limiter {
+	return &limiter{rate: rate, start: now()}
+}
+
+// wait accounts for n more bytes, sleeping until the transfer is back within
+// the rate.
+func (l *limiter) wait(n int) {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.total += int64(n)
+	due := l.start.Add(time.Duration(float64(l.total) / float64(l.rate) * float64(time.Second)))
+	if d := due.Sub(now()); d > 0 {
+		sleep(d)
+	}
+}
+
+// now and sleep are variables so that tests can fake the passage of time.
+var (
+	now   = time.Now
+	sleep = time.Sleep
+)
+
+// ParseBandwidth parses a rate in bytes per second, optionally followed by a
+// K, M or G suffix for units of 1024, 1024^2 or 1024^3 bytes per second, as
+// with rsync's --bwlimit. Zero means unlimited.
+func ParseBandwidth(s string) (int64, error) {
+	multiplier := int64(1)
+
+	trimmed := strings.TrimSpace(s)
+	if trimmed != "" {
+		switch strings.ToUpper(trimmed[len(trimmed)-1:]) {
+		case "K":
+			multiplier = 1 << 10
+		case "M":
+			multiplier = 1 << 20
+		case "G":
+			multiplier = 1 << 30
+		}
+		if multiplier != 1 {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(trimmed, 10, 64)
+	if err != nil || n < 0 {
+		return 0, xerrors.Errorf("invalid bandwidth %q: must be a non-negative number of bytes per second, optionally followed by K, M or G", s)
+	}
+
+	return n * multiplier, nil
+}
@@ -0,0 +1,171 @@
+package dircopy
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+func TestPull(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	src := makeSource(t, dir)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("listening: %+v", err)
+	}
+
+	server := grpc.NewServer()
+	idl.RegisterFileServerServer(server, &FileServer{Allow: Within(dir)})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("dialing: %+v", err)
+	}
+	defer conn.Close()
+
+	client := idl.NewFileServerClient(conn)
+
+	t.Run("pulls a directory from a file server", func(t *testing.T) {
+		dst := filepath.Join(dir, "pulled")
+		makeStaleDestination(t, dst)
+
+		var progress []Progress
+		stats, err := Pull(context.Background(), client, src, dst, Options{
+			Exclude:  excludes,
+			Delete:   true,
+			Progress: func(p Progress) { progress = append(progress, p) },
+		})
+		if err != nil {
+			t.Fatalf("Pull() returned error %+v", err)
+		}
+
+		expectCopied(t, src, dst)
+
+		expectedStats := Stats{Files: 6, Bytes: 3*ChunkSize + 3 + 8 + 25 + 14, Deleted: 2}
+		if stats != expectedStats {
+			t.Errorf("got stats %+v, want %+v", stats, expectedStats)
+		}
+
+		last := Progress{Files: stats.Files, Bytes: stats.Bytes}
+		if len(progress) != 6 || progress[5] != last {
+			t.Errorf("got progress %+v", progress)
+		}
+	})
+
+	t.Run("matches a local copy", func(t *testing.T) {
+		local := filepath.Join(dir, "local")
+		if _, err := Copy(src, local, Options{Delete: true}); err != nil {
+			t.Fatalf("Copy() returned error %+v", err)
+		}
+
+		pulled := filepath.Join(dir, "pulled-everything")
+		if _, err := Pull(context.Background(), client, src, pulled, Options{Delete: true}); err != nil {
+			t.Fatalf("Pull() returned error %+v", err)
+		}
+
+		if actual, expected := snapshot(t, pulled), snapshot(t, local); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("pulled copy differs from local copy:\n%s", diff(actual, expected))
+		}
+	})
+
+	t.Run("refuses to serve directories that are not allowed", func(t *testing.T) {
+		dst := filepath.Join(dir, "not-pulled")
+
+		_, err := Pull(context.Background(), client, "/etc", dst, Options{})
+		if status.Code(xerrors.Unwrap(err)) != codes.PermissionDenied {
+			t.Errorf("returned error %#v, want code %v", err, codes.PermissionDenied)
+		}
+	})
+}
+
+func TestWithin(t *testing.T) {
+	allow := Within("/home/gpadmin/.gpupgrade/")
+
+	cases := map[string]bool{
+		"/home/gpadmin/.gpupgrade":                     true,
+		"/home/gpadmin/.gpupgrade/upgraded-master.bak": true,
+		"/home/gpadmin/.gpupgrade-other":               false,
+		"/home/gpadmin":                                false,
+		"/etc":                                         false,
+	}
+
+	for dir, expected := range cases {
+		if allow(dir) != expected {
+			t.Errorf("Within() allowed %q: %t, want %t", dir, !expected, expected)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var elapsed time.Duration
+	start := time.Date(2019, time.December, 25, 12, 0, 0, 0, time.UTC)
+
+	now = func() time.Time { return start.Add(elapsed) }
+	sleep = func(d time.Duration) { elapsed += d }
+	defer func() {
+		now = time.Now
+		sleep = time.Sleep
+	}()
+
+	l := newLimiter(1000)
+	for i := 0; i < 10; i++ {
+		l.wait(500)
+	}
+
+	if elapsed != 5*time.Second {
+		t.Errorf("sending 5000 bytes at 1000 bytes per second took %v", elapsed)
+	}
+
+	t.Run("does not wait without a limit", func(t *testing.T) {
+		elapsed = 0
+
+		newLimiter(0).wait(1 << 30)
+		if elapsed != 0 {
+			t.Errorf("waited %v", elapsed)
+		}
+	})
+}
+
+func TestParseBandwidth(t *testing.T) {
+	cases := map[string]int64{
+		"0":     0,
+		"1000":  1000,
+		"512K":  512 << 10,
+		"100M":  100 << 20,
+		"100m":  100 << 20,
+		"1G":    1 << 30,
+		" 10M ": 10 << 20,
+	}
+
+	for s, expected := range cases {
+		bandwidth, err := ParseBandwidth(s)
+		if err != nil {
+			t.Errorf("ParseBandwidth(%q) returned error %+v", s, err)
+		}
+		if bandwidth != expected {
+			t.Errorf("ParseBandwidth(%q) returned %d, want %d", s, bandwidth, expected)
+		}
+	}
+
+	for _, s := range []string{"", "M", "-1", "10MB", "fast"} {
+		if _, err := ParseBandwidth(s); err == nil || !strings.Contains(err.Error(), "invalid bandwidth") {
+			t.Errorf("ParseBandwidth(%q) returned error %v", s, err)
+		}
+	}
+}
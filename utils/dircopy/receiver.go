@@ -0,0 +1,431 @@
+package dircopy
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/xerrors"
+)
+
+// preserveOwner is set when running as root, since only root may give files to
+// other users. As with rsync, ownership is otherwise left to the receiving
+// user.
+var preserveOwner = os.Geteuid() == 0
+
+// Receiver creates the files of a copy in a destination directory. WriteFile
+// may be called concurrently for different files; Finish must be called once
+// every file has been written.
+type Receiver struct {
+	root string
+}
+
+// NewReceiver returns a Receiver that writes into the directory root, creating
+// it if necessary.
+func NewReceiver(root string) (*Receiver, error) {
+	if root == "" {
+		return nil, xerrors.New("no destination directory")
+	}
+
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, &Error{Op: "create", Path: root, Err: err}
+	}
+
+	return &Receiver{root: filepath.Clean(root)}, nil
+}
+
+// path returns the destination path of a slash-separated path relative to the
+// root, refusing any path that would escape it.
+func (r *Receiver) path(rel string) (string, error) {
+	clean := path.Clean(rel)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", &Error{Op: "write", Path: rel, Err: xerrors.New("path is outside of the destination directory")}
+	}
+
+	return filepath.Join(r.root, filepath.FromSlash(clean)), nil
+}
+
+// UpToDate returns whether the destination of the regular file e already has
+// its size and modification time.
+func (r *Receiver) UpToDate(e Entry) bool {
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return false
+	}
+
+	info, err := os.Lstat(dst)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().IsRegular() && info.Size() == e.Size && info.ModTime().Equal(e.ModTime)
+}
+
+// FileWriter writes a regular file for a Receiver. Its contents replace the
+// destination only when it is closed.
+type FileWriter struct {
+	e    Entry
+	dst  string
+	tmp  *os.File
+	size int64
+}
+
+// Create begins writing the regular file e. The caller must Close or Abort the
+// returned FileWriter.
+func (r *Receiver) Create(e Entry) (*FileWriter, error) {
+	if e.Type != File {
+		return nil, &Error{Op: "write", Path: e.Path, Err: xerrors.New("not a regular file")}
+	}
+
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.makeParent(dst); err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Lstat(dst); err == nil && info.IsDir() {
+		if err := os.RemoveAll(dst); err != nil {
+			return nil, &Error{Op: "replace", Path: dst, Err: err}
+		}
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+".")
+	if err != nil {
+		return nil, &Error{Op: "write", Path: dst, Err: err}
+	}
+
+	return &FileWriter{e: e, dst: dst, tmp: tmp}, nil
+}
+
+func (f *FileWriter) Write(p []byte) (int, error) {
+	n, err := f.tmp.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, &Error{Op: "write", Path: f.dst, Err: err}
+	}
+
+	return n, nil
+}
+
+// Size returns the number of bytes written so far.
+func (f *FileWriter) Size() int64 {
+	return f.size
+}
+
+// Close sets the attributes of the file and renames it into place.
+func (f *FileWriter) Close() error {
+	err := f.close()
+	if err != nil {
+		os.Remove(f.tmp.Name())
+		return &Error{Op: "write", Path: f.dst, Err: err}
+	}
+
+	return nil
+}
+
+func (f *FileWriter) close() error {
+	if err := f.tmp.Chmod(f.e.Mode); err != nil {
+		f.tmp.Close()
+		return err
+	}
+
+	if preserveOwner {
+		if err := f.tmp.Chown(f.e.UID, f.e.GID); err != nil {
+			f.tmp.Close()
+			return err
+		}
+	}
+
+	if err := f.tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(f.tmp.Name(), f.e.ModTime, f.e.ModTime); err != nil {
+		return err
+	}
+
+	return os.Rename(f.tmp.Name(), f.dst)
+}
+
+// Abort discards the file, leaving any existing destination untouched.
+func (f *FileWriter) Abort() {
+	f.tmp.Close()
+	os.Remove(f.tmp.Name())
+}
+
+// WriteFile writes the regular file e with the contents of data.
+func (r *Receiver) WriteFile(e Entry, data io.Reader) error {
+	f, err := r.Create(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, data); err != nil {
+		f.Abort()
+
+		var copyErr *Error
+		if xerrors.As(err, &copyErr) {
+			return err
+		}
+		return &Error{Op: "read", Path: e.Path, Err: err}
+	}
+
+	return f.Close()
+}
+
+// SetAttributes updates the permissions, ownership and modification time of
+// the destination of e.
+func (r *Receiver) SetAttributes(e Entry) error {
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := setAttributes(dst, e); err != nil {
+		return &Error{Op: "set attributes of", Path: dst, Err: err}
+	}
+
+	return nil
+}
+
+func setAttributes(dst string, e Entry) error {
+	if e.Type == Symlink {
+		// Symlinks have no permissions of their own, and their times cannot be
+		// set portably.
+		if preserveOwner {
+			return os.Lchown(dst, e.UID, e.GID)
+		}
+		return nil
+	}
+
+	if err := os.Chmod(dst, e.Mode); err != nil {
+		return err
+	}
+
+	if preserveOwner {
+		if err := os.Chown(dst, e.UID, e.GID); err != nil {
+			return err
+		}
+	}
+
+	return os.Chtimes(dst, e.ModTime, e.ModTime)
+}
+
+// Finish completes a copy once every regular file has been written. entries
+// must list the entire source directory, as returned by List. Directories,
+// symlinks and hard links are created; if deleteExtraneous is set, anything in
+// the destination that is neither in entries nor excluded is removed; and
+// finally the attributes of directories are set, deepest first, so that
+// creating their contents does not disturb them. It returns the number of
+// files deleted. Finish continues past errors and returns them together.
+func (r *Receiver) Finish(entries []Entry, excludes *Excludes, deleteExtraneous bool) (int, error) {
+	var mErr *multierror.Error
+	var dirs []Entry
+
+	for _, e := range entries {
+		var err error
+
+		switch e.Type {
+		case Dir:
+			err = r.makeDir(e)
+			dirs = append(dirs, e)
+		case Symlink:
+			err = r.makeSymlink(e)
+		case HardLink:
+			err = r.makeHardLink(e)
+		}
+
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+
+	deleted := 0
+	if deleteExtraneous {
+		var err error
+		deleted, err = r.deleteExtraneous(entries, excludes)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := r.SetAttributes(dirs[i]); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+
+	return deleted, mErr.ErrorOrNil()
+}
+
+func (r *Receiver) makeDir(e Entry) error {
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(dst)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+
+	if err == nil {
+		if err := os.Remove(dst); err != nil {
+			return &Error{Op: "replace", Path: dst, Err: err}
+		}
+	}
+
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return &Error{Op: "create", Path: dst, Err: err}
+	}
+
+	return nil
+}
+
+func (r *Receiver) makeSymlink(e Entry) error {
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return err
+	}
+
+	if target, err := os.Readlink(dst); err == nil && target == e.Target {
+		return r.SetAttributes(e)
+	}
+
+	if err := r.replace(dst); err != nil {
+		return err
+	}
+
+	if err := os.Symlink(e.Target, dst); err != nil {
+		return &Error{Op: "create", Path: dst, Err: err}
+	}
+
+	return r.SetAttributes(e)
+}
+
+func (r *Receiver) makeHardLink(e Entry) error {
+	dst, err := r.path(e.Path)
+	if err != nil {
+		return err
+	}
+
+	target, err := r.path(e.Target)
+	if err != nil {
+		return err
+	}
+
+	targetInfo, err := os.Lstat(target)
+	if err != nil {
+		return &Error{Op: "link", Path: dst, Err: err}
+	}
+
+	if info, err := os.Lstat(dst); err == nil && os.SameFile(info, targetInfo) {
+		return nil
+	}
+
+	if err := r.replace(dst); err != nil {
+		return err
+	}
+
+	if err := os.Link(target, dst); err != nil {
+		return &Error{Op: "link", Path: dst, Err: err}
+	}
+
+	return nil
+}
+
+// replace prepares dst to be replaced by removing anything already there and
+// making sure that its parent exists.
+func (r *Receiver) replace(dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return &Error{Op: "replace", Path: dst, Err: err}
+	}
+
+	return r.makeParent(dst)
+}
+
+// makeParent creates the parent directories of dst, removing any file in the
+// destination that is in the way of one. Their attributes are set later by
+// Finish.
+func (r *Receiver) makeParent(dst string) error {
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		return nil
+	}
+
+	for p := dir; p != r.root && strings.HasPrefix(p, r.root); p = filepath.Dir(p) {
+		info, err := os.Lstat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		if err := os.Remove(p); err != nil {
+			return &Error{Op: "replace", Path: p, Err: err}
+		}
+		break
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return &Error{Op: "create", Path: dir, Err: err}
+	}
+
+	return nil
+}
+
+func (r *Receiver) deleteExtraneous(entries []Entry, excludes *Excludes) (int, error) {
+	keep := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		keep[e.Path] = true
+	}
+
+	deleted := 0
+	var mErr *multierror.Error
+
+	err := filepath.Walk(r.root, func(dst string, info os.FileInfo, err error) error {
+		if err != nil {
+			mErr = multierror.Append(mErr, &Error{Op: "read", Path: dst, Err: err})
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.root, dst)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == "." || keep[rel] {
+			return nil
+		}
+
+		// Excluded files in the destination are protected from deletion.
+		if excludes.Match(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if err := os.RemoveAll(dst); err != nil {
+			mErr = multierror.Append(mErr, &Error{Op: "delete", Path: dst, Err: err})
+		} else {
+			deleted++
+		}
+
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+
+	return deleted, mErr.ErrorOrNil()
+}
@@ -0,0 +1,29 @@
+package dircopy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReceiver(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	r, err := NewReceiver(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatalf("NewReceiver() returned error %+v", err)
+	}
+
+	t.Run("refuses paths outside of the destination", func(t *testing.T) {
+		for _, path := range []string{"../escaped", "/escaped", "base/../../escaped"} {
+			if _, err := r.Create(Entry{Path: path, Type: File, Mode: 0600}); err == nil {
+				t.Errorf("Create(%q) returned no error", path)
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "escaped")); !os.IsNotExist(err) {
+			t.Errorf("file was written outside of the destination")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package dircopy
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+)
+
+// ChunkSize is the amount of file data sent in each message of a ServeFiles
+// stream.
+const ChunkSize = 256 * 1024
+
+func sendFile(sender idl.FileChunkSender, path string, chunk *idl.FileChunk, buf []byte) error {
+	file, err := readFile(path)
+	if err != nil {
+		return &Error{Op: "read", Path: path, Err: err}
+	}
+	defer file.Close()
+
+	for {
+		n, err := io.ReadFull(file, buf)
+		if n > 0 || chunk.Entry != nil {
+			chunk.Data = buf[:n]
+			if sendErr := sender.Send(chunk); sendErr != nil {
+				return xerrors.Errorf("sending %s: %w", path, sendErr)
+			}
+			chunk = &idl.FileChunk{}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return &Error{Op: "read", Path: path, Err: err}
+		}
+	}
+}
+
+func closeReceived(f *FileWriter, stats *Stats) error {
+	if f == nil {
+		return nil
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	stats.Files++
+	stats.Bytes += f.Size()
+	return nil
+}
+
+var typeToProto = map[Type]idl.FileEntry_Type{
+	File:     idl.FileEntry_FILE,
+	Dir:      idl.FileEntry_DIRECTORY,
+	Symlink:  idl.FileEntry_SYMLINK,
+	HardLink: idl.FileEntry_HARDLINK,
+}
+
+func entryToProto(e Entry) *idl.FileEntry {
+	return &idl.FileEntry{
+		Path:    e.Path,
+		Type:    typeToProto[e.Type],
+		Mode:    uint32(e.Mode),
+		Uid:     uint32(e.UID),
+		Gid:     uint32(e.GID),
+		ModTime: e.ModTime.UnixNano(),
+		Size:    e.Size,
+		Target:  e.Target,
+	}
+}
+
+func entryFromProto(p *idl.FileEntry) (Entry, error) {
+	e := Entry{
+		Path:    p.Path,
+		Mode:    os.FileMode(p.Mode) & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky),
+		UID:     int(p.Uid),
+		GID:     int(p.Gid),
+		ModTime: time.Unix(0, p.ModTime),
+		Size:    p.Size,
+		Target:  p.Target,
+	}
+
+	for t, pt := range typeToProto {
+		if pt == p.Type {
+			e.Type = t
+			return e, nil
+		}
+	}
+
+	return e, xerrors.Errorf("%s: unknown file type %v", p.Path, p.Type)
+}
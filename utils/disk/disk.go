@@ -1,6 +1,9 @@
 package disk
 
 import (
+	"os"
+	"path/filepath"
+
 	"golang.org/x/sys/unix"
 	"golang.org/x/xerrors"
 
@@ -92,6 +95,33 @@ func CheckUsage(d Disk, requiredRatio float64, paths ...string) (SpaceFailures,
 	return failures, nil
 }
 
+// Scan returns the total size of the regular files under dir, and how many
+// there are. Tablespaces and other directories linked from dir are not
+// followed. Files that are removed during the scan, as the server of a data
+// directory may do, are skipped.
+func Scan(dir string) (bytes int64, files int64, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path != dir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			bytes += info.Size()
+			files++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, xerrors.Errorf("scanning %s: %w", dir, err)
+	}
+
+	return bytes, files, nil
+}
+
 // Local is a standard implementation of the Disk interface that uses gosigar
 // and unix.Stat to obtain statistics for the local machine.
 var Local = local{}
@@ -1,7 +1,9 @@
 package disk_test
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -289,6 +291,36 @@ func TestLocal(t *testing.T) {
 	}
 }
 
+func TestScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpupgrade")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "base", "1"), 0700); err != nil {
+		t.Fatalf("creating directories: %+v", err)
+	}
+	for path, size := range map[string]int{"PG_VERSION": 3, "base/1/1259": 8192, "base/1/1249": 100} {
+		if err := ioutil.WriteFile(filepath.Join(dir, path), make([]byte, size), 0600); err != nil {
+			t.Fatalf("writing %s: %+v", path, err)
+		}
+	}
+
+	bytes, files, err := disk.Scan(dir)
+	if err != nil {
+		t.Errorf("Scan() returned error %+v", err)
+	}
+	if bytes != 8295 || files != 3 {
+		t.Errorf("Scan() returned %d bytes in %d files, want 8295 bytes in 3 files", bytes, files)
+	}
+
+	_, _, err = disk.Scan(filepath.Join(dir, "missing"))
+	if !xerrors.Is(err, os.ErrNotExist) {
+		t.Errorf("Scan() of a missing directory returned %#v, want %#v", err, os.ErrNotExist)
+	}
+}
+
 // testDisk is a stub implementation of disk.Disk.
 type testDisk struct {
 	err error // returned whenever one of the below functions is unimplemented
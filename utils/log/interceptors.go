@@ -0,0 +1,59 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"google.golang.org/grpc"
+)
+
+// healthPrefix starts the full method name of health checks, which are logged
+// only at DEBUG level since checkers make them often.
+const healthPrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor returns an interceptor that passes each unary request
+// on to next, logging how long it took and any error it returned, and writing
+// any panic in its handler with WritePanics.
+func UnaryServerInterceptor(next grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer WritePanics()
+
+		start := time.Now()
+		gplog.Debug("%s started", info.FullMethod)
+
+		resp, err = next(ctx, req, info, handler)
+		logRequest(info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the UnaryServerInterceptor for streaming
+// requests, such as those that run steps.
+func StreamServerInterceptor(next grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer WritePanics()
+
+		start := time.Now()
+		gplog.Debug("%s started", info.FullMethod)
+
+		err := next(srv, ss, info, handler)
+		logRequest(info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+func logRequest(method string, elapsed time.Duration, err error) {
+	logf := gplog.Info
+	if strings.HasPrefix(method, healthPrefix) {
+		logf = gplog.Debug
+	}
+
+	if err != nil {
+		logf("%s failed after %s: %v", method, elapsed, err)
+		return
+	}
+
+	logf("%s finished in %s", method, elapsed)
+}
@@ -0,0 +1,68 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gpupgrade/utils/log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("server interceptors", func() {
+	var oldLogger *gplog.Logger
+	var testlog *Buffer
+
+	BeforeEach(func() {
+		oldLogger = gplog.GetLogger()
+		_, _, testlog = testhelper.SetupTestLogger()
+	})
+
+	AfterEach(func() {
+		gplog.SetLogger(oldLogger)
+	})
+
+	passUnary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+	passStream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+
+	It("logs unary requests and their errors", func() {
+		interceptor := log.UnaryServerInterceptor(passUnary)
+		info := &grpc.UnaryServerInfo{FullMethod: "/idl.CliToHub/GetConfig"}
+
+		resp, err := interceptor(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "reply", nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp).To(Equal("reply"))
+		Expect(testlog).To(Say(`/idl.CliToHub/GetConfig finished in `))
+
+		_, err = interceptor(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.New("no such key")
+		})
+		Expect(err).To(MatchError("no such key"))
+		Expect(testlog).To(Say(`/idl.CliToHub/GetConfig failed after .*: no such key`))
+	})
+
+	It("writes panics in streaming requests to the log", func() {
+		interceptor := log.StreamServerInterceptor(passStream)
+		info := &grpc.StreamServerInfo{FullMethod: "/idl.CliToHub/Execute"}
+
+		Expect(func() {
+			interceptor(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+				panic("ahhh")
+			})
+		}).To(Panic())
+
+		Expect(testlog).To(Say(`encountered panic \("ahhh"\); stack trace follows`))
+	})
+})
@@ -0,0 +1,221 @@
+// Package metrics contains the Prometheus collectors shared by the hub and
+// agent processes, along with the HTTP server used to expose them.
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/xerrors"
+)
+
+const namespace = "gpupgrade"
+
+// Path is the HTTP path under which metrics are served.
+const Path = "/metrics"
+
+var (
+	// SubstepDuration tracks how long each substep took to run, labeled by
+	// step, substep, and final status.
+	SubstepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "substep_duration_seconds",
+		Help:      "Time taken to run a substep.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 16), // 0.5s to ~4.5h
+	}, []string{"step", "substep", "status"})
+
+	// SegmentUpgradeDuration tracks how long pg_upgrade took for a single
+	// segment, labeled by content ID and mode (check or upgrade).
+	SegmentUpgradeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "segment_upgrade_duration_seconds",
+		Help:      "Time taken to run pg_upgrade for a segment.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 16),
+	}, []string{"content", "mode"})
+
+	// SegmentUpgradeExitStatus records the exit code of the last pg_upgrade
+	// run for a segment. A value of -1 indicates that pg_upgrade could not be
+	// run at all.
+	SegmentUpgradeExitStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "segment_upgrade_exit_status",
+		Help:      "Exit status of the last pg_upgrade run for a segment.",
+	}, []string{"content", "mode"})
+
+	// RsyncBytes counts the bytes sent by rsync, as reported by --stats.
+	RsyncBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rsync_sent_bytes_total",
+		Help:      "Bytes sent by rsync.",
+	}, []string{"operation"})
+
+	// RsyncDuration tracks how long each rsync invocation took.
+	RsyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rsync_duration_seconds",
+		Help:      "Time taken by an rsync invocation.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 16),
+	}, []string{"operation", "status"})
+
+	// CopyBytes counts the bytes copied by the native copy engine.
+	CopyBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "copy_bytes_total",
+		Help:      "Bytes copied by the native copy engine.",
+	}, []string{"operation"})
+
+	// CopyDuration tracks how long each copy by the native copy engine took.
+	CopyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "copy_duration_seconds",
+		Help:      "Time taken by a copy using the native copy engine.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 16),
+	}, []string{"operation", "status"})
+
+	// GRPCServer instruments the gRPC servers. Callers must install its
+	// interceptors and call InitializeMetrics after registering services.
+	GRPCServer = grpc_prometheus.NewServerMetrics()
+)
+
+// Registry contains all of the process-wide collectors above.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		SubstepDuration,
+		SegmentUpgradeDuration,
+		SegmentUpgradeExitStatus,
+		RsyncBytes,
+		RsyncDuration,
+		CopyBytes,
+		CopyDuration,
+		GRPCServer,
+	)
+}
+
+// Status returns the label value used to record the outcome of an operation.
+func Status(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "complete"
+}
+
+// ExitStatus returns the exit code of the process that produced err, 0 if err
+// is nil, or -1 if the process could not be run to completion.
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if xerrors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// ObserveRsync records the duration and outcome of an rsync invocation that
+// started at the given time. If stats contains the output of rsync --stats, the
+// number of bytes sent is also recorded.
+func ObserveRsync(operation string, start time.Time, stats []byte, err error) {
+	RsyncDuration.WithLabelValues(operation, Status(err)).Observe(time.Since(start).Seconds())
+
+	if sent, ok := rsyncBytesSent(stats); ok {
+		RsyncBytes.WithLabelValues(operation).Add(float64(sent))
+	}
+}
+
+// ObserveCopy records the duration, outcome and size of a copy by the native
+// copy engine that started at the given time.
+func ObserveCopy(operation string, start time.Time, bytes int64, err error) {
+	CopyDuration.WithLabelValues(operation, Status(err)).Observe(time.Since(start).Seconds())
+	CopyBytes.WithLabelValues(operation).Add(float64(bytes))
+}
+
+// rsyncBytesSent parses the "Total bytes sent" line from rsync --stats output.
+// Newer rsync versions group digits with commas; older ones don't.
+func rsyncBytesSent(stats []byte) (uint64, bool) {
+	const prefix = "Total bytes sent:"
+
+	scanner := bufio.NewScanner(bytes.NewReader(stats))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		value := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		value = strings.Replace(value, ",", "", -1)
+
+		sent, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return sent, true
+	}
+
+	return 0, false
+}
+
+// Server serves the contents of Registry, along with any additional
+// collectors, over HTTP.
+type Server struct {
+	server *http.Server
+	lis    net.Listener
+}
+
+// Listen opens a metrics listener on the given port. Any extra collectors are
+// registered only with this Server, which allows per-instance collectors to be
+// used without conflicting with other instances in the same process.
+func Listen(port int, extra ...prometheus.Collector) (*Server, error) {
+	local := prometheus.NewRegistry()
+	for _, c := range extra {
+		if err := local.Register(c); err != nil {
+			return nil, xerrors.Errorf("registering metrics collector: %w", err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return nil, xerrors.Errorf("listening for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(prometheus.Gatherers{Registry, local}, promhttp.HandlerOpts{}))
+
+	return &Server{
+		server: &http.Server{Handler: mux},
+		lis:    lis,
+	}, nil
+}
+
+// Addr returns the address that the Server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Serve blocks, serving metrics until Stop is called. It always returns a
+// non-nil error; http.ErrServerClosed indicates a normal shutdown.
+func (s *Server) Serve() error {
+	return s.server.Serve(s.lis)
+}
+
+// Stop shuts down the metrics server.
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
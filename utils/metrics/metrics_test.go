@@ -0,0 +1,121 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils/metrics"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("serves process-wide and extra collectors", func(t *testing.T) {
+		extra := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gpupgrade_test_extra",
+			Help: "A collector registered only with this server.",
+		})
+		extra.Set(42)
+
+		metrics.SubstepDuration.WithLabelValues("initialize", "CONFIG", "complete").Observe(1)
+		metrics.ObserveRsync("test", time.Now(), []byte(rsyncStats), nil)
+
+		server, err := metrics.Listen(0, extra)
+		if err != nil {
+			t.Fatalf("Listen() returned error %+v", err)
+		}
+		defer server.Stop()
+
+		go server.Serve()
+
+		body := scrape(t, server)
+
+		expected := []string{
+			`gpupgrade_test_extra 42`,
+			`gpupgrade_substep_duration_seconds_count{status="complete",step="initialize",substep="CONFIG"} 1`,
+			`gpupgrade_rsync_sent_bytes_total{operation="test"} 1.234567e+06`,
+			`gpupgrade_rsync_duration_seconds_count{operation="test",status="complete"} 1`,
+		}
+		for _, e := range expected {
+			if !strings.Contains(body, e) {
+				t.Errorf("metrics output did not contain %q", e)
+			}
+		}
+
+		if t.Failed() {
+			t.Logf("metrics output:\n%s", body)
+		}
+	})
+
+	t.Run("servers in the same process may use different extra collectors", func(t *testing.T) {
+		newCollector := func() prometheus.Collector {
+			return prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "gpupgrade_test_duplicate",
+				Help: "A collector registered with more than one server.",
+			})
+		}
+
+		for i := 0; i < 2; i++ {
+			server, err := metrics.Listen(0, newCollector())
+			if err != nil {
+				t.Fatalf("Listen() returned error %+v", err)
+			}
+			server.Stop()
+		}
+	})
+}
+
+func TestExitStatus(t *testing.T) {
+	if metrics.ExitStatus(nil) != 0 {
+		t.Errorf("ExitStatus(nil) = %d, want 0", metrics.ExitStatus(nil))
+	}
+
+	err := exec.Command("bash", "-c", "exit 3").Run()
+	err = xerrors.Errorf("wrapped: %w", err)
+	if status := metrics.ExitStatus(err); status != 3 {
+		t.Errorf("ExitStatus(%v) = %d, want 3", err, status)
+	}
+
+	err = xerrors.New("not an exit error")
+	if status := metrics.ExitStatus(err); status != -1 {
+		t.Errorf("ExitStatus(%v) = %d, want -1", err, status)
+	}
+}
+
+func scrape(t *testing.T, server *metrics.Server) string {
+	t.Helper()
+
+	resp, err := http.Get("http://" + server.Addr().String() + metrics.Path)
+	if err != nil {
+		t.Fatalf("scraping metrics: %+v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scraping metrics: got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading metrics: %+v", err)
+	}
+
+	return string(body)
+}
+
+const rsyncStats = `
+Number of files: 1,540 (reg: 1,497, dir: 43)
+Number of created files: 0
+Total file size: 41,534,203 bytes
+Total transferred file size: 0 bytes
+Total bytes sent: 1,234,567
+Total bytes received: 1,052
+
+sent 1,234,567 bytes  received 1,052 bytes  3,292.00 bytes/sec
+total size is 41,534,203  speedup is 1,270.39
+`
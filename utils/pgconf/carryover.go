@@ -0,0 +1,278 @@
+package pgconf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/idl"
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// Class describes how a setting from the source cluster relates to the target
+// cluster's major version.
+type Class int
+
+const (
+	Portable   Class = iota // the setting means the same thing in the target
+	Renamed                 // the setting exists in the target under NewName
+	Removed                 // the target has no such setting
+	Unportable              // the setting names libraries or paths of the source installation
+)
+
+func (c Class) String() string {
+	switch c {
+	case Portable:
+		return "portable"
+	case Renamed:
+		return "renamed"
+	case Removed:
+		return "removed"
+	case Unportable:
+		return "unportable"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a setting whose value in the source configuration differs from
+// its value in the target configuration.
+type Change struct {
+	Name        string
+	SourceValue string
+	TargetValue string // empty if the target does not set it
+	Class       Class
+	NewName     string // set for Renamed settings
+}
+
+// managed settings are specific to each segment's data directory, or are set by
+// gpinitsystem and gpupgrade, and are never carried over.
+var managed = map[string]bool{
+	"port":                       true,
+	"data_directory":             true,
+	"config_file":                true,
+	"hba_file":                   true,
+	"ident_file":                 true,
+	"external_pid_file":          true,
+	"gp_dbid":                    true,
+	"gp_contentid":               true,
+	"gp_num_contents_in_cluster": true,
+}
+
+// unportable settings load libraries or extensions, run programs, or name files
+// of the source installation. They may refer to things that the target's
+// installation does not have, so they are left for an operator to carry over.
+// Settings with a dotted name belong to an extension, and are unportable too.
+var unportable = map[string]bool{
+	"shared_preload_libraries":  true,
+	"local_preload_libraries":   true,
+	"session_preload_libraries": true,
+	"dynamic_library_path":      true,
+	"gp_external_enable_exec":   true,
+	"archive_command":           true,
+	"krb_server_keyfile":        true,
+	"ssl_cert_file":             true,
+	"ssl_key_file":              true,
+	"ssl_ca_file":               true,
+	"ssl_crl_file":              true,
+	"log_directory":             true,
+	"stats_temp_directory":      true,
+}
+
+// versionChanges records the settings that were renamed or removed in a major
+// version of Greenplum, relative to the previous major version.
+type versionChanges struct {
+	renamed map[string]string
+	removed map[string]bool
+}
+
+var changesByMajorVersion = map[uint64]versionChanges{
+	6: {
+		renamed: map[string]string{
+			"unix_socket_directory":          "unix_socket_directories",
+			"gp_workfile_compress_algorithm": "gp_workfile_compression",
+		},
+		removed: map[string]bool{
+			"add_missing_from":                 true,
+			"custom_variable_classes":          true,
+			"regex_flavor":                     true,
+			"silent_mode":                      true,
+			"max_fsm_pages":                    true,
+			"max_fsm_relations":                true,
+			"gp_max_databases":                 true,
+			"gp_max_filespaces":                true,
+			"gp_max_tablespaces":               true,
+			"gp_hashagg_compress_spill_files":  true,
+			"gp_backup_directio":               true,
+			"gp_backup_directio_read_chunk_mb": true,
+			"gp_connectemc_mode":               true,
+			"gp_email_from":                    true,
+			"gp_email_smtp_password":           true,
+			"gp_email_smtp_server":             true,
+			"gp_email_smtp_userid":             true,
+			"gp_email_to":                      true,
+			"gp_snmp_community":                true,
+			"gp_snmp_monitor_address":          true,
+			"gp_snmp_use_inform_or_trap":       true,
+		},
+	},
+}
+
+// Classify returns how the named setting carries over from a source cluster
+// with the given major version to a target with the given major version, along
+// with its new name if it was renamed. Settings that are not known to have
+// changed are portable, unless they are unportable by their nature.
+func Classify(name string, sourceMajor, targetMajor uint64) (Class, string) {
+	for v := sourceMajor + 1; v <= targetMajor; v++ {
+		changes := changesByMajorVersion[v]
+
+		if newName, ok := changes.renamed[name]; ok {
+			return Renamed, newName
+		}
+
+		if changes.removed[name] {
+			return Removed, ""
+		}
+	}
+
+	if unportable[name] || strings.Contains(name, ".") {
+		return Unportable, ""
+	}
+
+	return Portable, ""
+}
+
+// Diff compares the settings in source with those in target, and returns a
+// Change for every setting whose value differs, sorted by name. Settings that
+// are managed by gpinitsystem or gpupgrade are ignored, as are include
+// directives, which are not followed.
+func Diff(source, target *File, sourceMajor, targetMajor uint64) []Change {
+	var changes []Change
+
+	seen := make(map[string]bool)
+	for _, s := range source.Settings() {
+		if isInclude(s.Name) || managed[s.Name] || seen[s.Name] {
+			continue
+		}
+		seen[s.Name] = true
+
+		sourceValue, _ := source.Get(s.Name)
+		targetValue, _ := target.Get(s.Name)
+		if sourceValue == targetValue {
+			continue
+		}
+
+		class, newName := Classify(s.Name, sourceMajor, targetMajor)
+		changes = append(changes, Change{
+			Name:        s.Name,
+			SourceValue: sourceValue,
+			TargetValue: targetValue,
+			Class:       class,
+			NewName:     newName,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Apply sets every portable change in target. Renamed, removed and unportable
+// settings are left for an operator to review, since their meaning may have
+// changed or they may refer to the source installation.
+func Apply(target *File, changes []Change) {
+	for _, c := range changes {
+		if c.Class == Portable {
+			target.Set(c.Name, c.SourceValue)
+		}
+	}
+}
+
+// Result describes what CarryOver did for one data directory.
+type Result struct {
+	Changes    []Change
+	HBAEntries int // the number of pg_hba.conf entries added
+}
+
+// CarryOver copies the portable settings from the postgresql.conf in
+// sourceDataDir to the one in targetDataDir, and merges the source pg_hba.conf
+// entries into the target's. Both files are replaced atomically. Running it
+// again has no further effect.
+func CarryOver(sourceDataDir, targetDataDir string, sourceMajor, targetMajor uint64) (Result, error) {
+	var result Result
+
+	source, err := ReadFile(filepath.Join(sourceDataDir, "postgresql.conf"))
+	if err != nil {
+		return result, xerrors.Errorf("reading source configuration: %w", err)
+	}
+
+	err = Edit(filepath.Join(targetDataDir, "postgresql.conf"), func(target *File) error {
+		result.Changes = Diff(source, target, sourceMajor, targetMajor)
+		Apply(target, result.Changes)
+		return nil
+	})
+	if err != nil {
+		return result, xerrors.Errorf("updating target configuration: %w", err)
+	}
+
+	result.HBAEntries, err = carryOverHBA(sourceDataDir, targetDataDir)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func carryOverHBA(sourceDataDir, targetDataDir string) (int, error) {
+	source, err := ioutil.ReadFile(filepath.Join(sourceDataDir, "pg_hba.conf"))
+	if err != nil {
+		return 0, xerrors.Errorf("reading source pg_hba.conf: %w", err)
+	}
+
+	path := filepath.Join(targetDataDir, "pg_hba.conf")
+	target, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, xerrors.Errorf("reading target pg_hba.conf: %w", err)
+	}
+
+	merged, added := MergeHBA(source, target)
+	if added == 0 {
+		return 0, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	err = utils.AtomicallyWriteFile(path, merged, info.Mode().Perm())
+	if err != nil {
+		return 0, xerrors.Errorf("updating target pg_hba.conf: %w", err)
+	}
+
+	return added, nil
+}
+
+// Proto converts the result for the given segment content ID into its wire
+// format.
+func (r Result) Proto(content int32) *idl.ConfigurationCarryOver {
+	carryOver := &idl.ConfigurationCarryOver{
+		Content:    content,
+		HBAEntries: int32(r.HBAEntries),
+	}
+
+	for _, c := range r.Changes {
+		carryOver.Changes = append(carryOver.Changes, &idl.GUCChange{
+			Name:        c.Name,
+			SourceValue: c.SourceValue,
+			TargetValue: c.TargetValue,
+			Class:       idl.GUCChange_ChangeClass(c.Class),
+			NewName:     c.NewName,
+		})
+	}
+
+	return carryOver
+}
@@ -0,0 +1,180 @@
+package pgconf_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name        string
+		sourceMajor uint64
+		targetMajor uint64
+		class       pgconf.Class
+		newName     string
+	}{
+		{"gp_vmem_protect_limit", 5, 6, pgconf.Portable, ""},
+		{"unix_socket_directory", 5, 6, pgconf.Renamed, "unix_socket_directories"},
+		{"gp_max_databases", 5, 6, pgconf.Removed, ""},
+		{"gp_max_databases", 6, 6, pgconf.Portable, ""},
+		{"gp_max_databases", 5, 7, pgconf.Removed, ""},
+		{"my_extension.setting", 5, 6, pgconf.Unportable, ""},
+		{"shared_preload_libraries", 5, 6, pgconf.Unportable, ""},
+		{"gp_external_enable_exec", 6, 6, pgconf.Unportable, ""},
+		{"dynamic_library_path", 5, 6, pgconf.Unportable, ""},
+	}
+
+	for _, c := range cases {
+		class, newName := pgconf.Classify(c.name, c.sourceMajor, c.targetMajor)
+		if class != c.class || newName != c.newName {
+			t.Errorf("Classify(%q, %d, %d) = (%v, %q), want (%v, %q)",
+				c.name, c.sourceMajor, c.targetMajor, class, newName, c.class, c.newName)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	source := parse(t, `
+port = 5432
+gp_contentid = 0
+gp_vmem_protect_limit = 8192
+max_connections = 750
+log_statement = 'all'
+unix_socket_directory = '/var/run/gpdb'
+gp_max_databases = 32
+shared_preload_libraries = 'pg_stat_statements'
+include 'tuning.conf'
+`)
+
+	target := parse(t, `
+port = 6000
+gp_contentid = 0
+gp_vmem_protect_limit = 8192
+max_connections = 250
+`)
+
+	changes := pgconf.Diff(source, target, 5, 6)
+
+	expected := []pgconf.Change{
+		{Name: "gp_max_databases", SourceValue: "32", Class: pgconf.Removed},
+		{Name: "log_statement", SourceValue: "all", Class: pgconf.Portable},
+		{Name: "max_connections", SourceValue: "750", TargetValue: "250", Class: pgconf.Portable},
+		{Name: "shared_preload_libraries", SourceValue: "pg_stat_statements", Class: pgconf.Unportable},
+		{Name: "unix_socket_directory", SourceValue: "/var/run/gpdb", Class: pgconf.Renamed, NewName: "unix_socket_directories"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got changes %+v, want %+v", changes, expected)
+	}
+
+	pgconf.Apply(target, changes)
+
+	for name, value := range map[string]string{
+		"port":            "6000",
+		"max_connections": "750",
+		"log_statement":   "all",
+	} {
+		if actual, _ := target.Get(name); actual != value {
+			t.Errorf("%s = %q, want %q", name, actual, value)
+		}
+	}
+
+	for _, name := range []string{"gp_max_databases", "unix_socket_directory", "unix_socket_directories", "shared_preload_libraries"} {
+		if _, ok := target.Get(name); ok {
+			t.Errorf("%s was applied to the target", name)
+		}
+	}
+}
+
+func TestCarryOver(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sourceDir := filepath.Join(dir, "source")
+	targetDir := filepath.Join(dir, "target")
+
+	writeFiles(t, sourceDir, map[string]string{
+		"postgresql.conf": "port = 5432\nstatement_mem = 250MB\n",
+		"pg_hba.conf":     "local all gpadmin ident\nhost all analysts 10.0.0.0/8 md5\n",
+	})
+	writeFiles(t, targetDir, map[string]string{
+		"postgresql.conf": "# written by gpinitsystem\nport = 6000\n",
+		"pg_hba.conf":     "local all gpadmin ident\n",
+	})
+
+	result, err := pgconf.CarryOver(sourceDir, targetDir, 5, 6)
+	if err != nil {
+		t.Fatalf("CarryOver() returned error %+v", err)
+	}
+
+	expected := pgconf.Result{
+		Changes:    []pgconf.Change{{Name: "statement_mem", SourceValue: "250MB", Class: pgconf.Portable}},
+		HBAEntries: 1,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got result %+v, want %+v", result, expected)
+	}
+
+	expectFile(t, filepath.Join(targetDir, "postgresql.conf"),
+		"# written by gpinitsystem\nport = 6000\nstatement_mem = 250MB\n")
+	expectFile(t, filepath.Join(targetDir, "pg_hba.conf"),
+		"local all gpadmin ident\n\n# The following entries were carried over from the source cluster by gpupgrade.\n"+
+			"host all analysts 10.0.0.0/8 md5\n")
+
+	t.Run("has no further effect when run again", func(t *testing.T) {
+		result, err := pgconf.CarryOver(sourceDir, targetDir, 5, 6)
+		if err != nil {
+			t.Fatalf("CarryOver() returned error %+v", err)
+		}
+
+		if len(result.Changes) != 0 || result.HBAEntries != 0 {
+			t.Errorf("got result %+v, want no changes", result)
+		}
+	})
+}
+
+func parse(t *testing.T, contents string) *pgconf.File {
+	t.Helper()
+
+	conf, err := pgconf.Parse(strings.NewReader(contents))
+	if err != nil {
+		t.Fatalf("Parse() returned error %+v", err)
+	}
+
+	return conf
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("creating %s: %+v", dir, err)
+	}
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("writing %s: %+v", name, err)
+		}
+	}
+}
+
+func expectFile(t *testing.T, path, expected string) {
+	t.Helper()
+
+	actual, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %+v", path, err)
+	}
+
+	if string(actual) != expected {
+		t.Errorf("%s contains\n%s\nwant\n%s", path, actual, expected)
+	}
+}
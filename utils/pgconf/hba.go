@@ -0,0 +1,93 @@
+package pgconf
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// hbaHeader introduces the entries that MergeHBA appends to a pg_hba.conf.
+const hbaHeader = "# The following entries were carried over from the source cluster by gpupgrade."
+
+// MergeHBA returns the target pg_hba.conf with every entry from the source
+// pg_hba.conf that it does not already contain appended to it, along with the
+// number of entries added. Entries are compared field by field, so differences
+// in spacing and trailing comments are ignored.
+//
+// The target's own entries, which gpinitsystem writes so that the cluster can
+// be administered, come first and therefore take precedence; the source's
+// entries follow in their original order.
+func MergeHBA(source, target []byte) ([]byte, int) {
+	existing := make(map[string]bool)
+	for _, entry := range hbaEntries(target) {
+		existing[entry.key] = true
+	}
+
+	var added []string
+	for _, entry := range hbaEntries(source) {
+		if existing[entry.key] {
+			continue
+		}
+		existing[entry.key] = true
+		added = append(added, entry.line)
+	}
+
+	if len(added) == 0 {
+		return target, 0
+	}
+
+	var merged bytes.Buffer
+	merged.Write(target)
+	if len(target) > 0 && !bytes.HasSuffix(target, []byte("\n")) {
+		merged.WriteByte('\n')
+	}
+
+	merged.WriteString("\n" + hbaHeader + "\n")
+	for _, line := range added {
+		merged.WriteString(line + "\n")
+	}
+
+	return merged.Bytes(), len(added)
+}
+
+type hbaEntry struct {
+	line string // the original line
+	key  string // the entry's fields, separated by single spaces
+}
+
+func hbaEntries(contents []byte) []hbaEntry {
+	var entries []hbaEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		fields := strings.Fields(stripHBAComment(line))
+		if len(fields) == 0 {
+			continue
+		}
+
+		entries = append(entries, hbaEntry{
+			line: strings.TrimRight(line, " \t\r"),
+			key:  strings.Join(fields, " "),
+		})
+	}
+
+	return entries
+}
+
+// stripHBAComment removes a trailing comment, which begins with a '#' outside
+// of double quotes.
+func stripHBAComment(line string) string {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '#' && !quoted:
+			return line[:i]
+		}
+	}
+
+	return line
+}
@@ -0,0 +1,41 @@
+package pgconf_test
+
+import (
+	"testing"
+
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
+)
+
+func TestMergeHBA(t *testing.T) {
+	source := `# source rules
+local    all   gpadmin   ident
+host     all   gpadmin   10.0.0.1/32   trust  # the master
+host     all   all       0.0.0.0/0     md5
+host     "my db"   all   samenet       md5 # quoted # hash
+`
+	target := `local all gpadmin ident
+host all gpadmin 10.0.0.1/32 trust`
+
+	merged, added := pgconf.MergeHBA([]byte(source), []byte(target))
+
+	expected := `local all gpadmin ident
+host all gpadmin 10.0.0.1/32 trust
+
+# The following entries were carried over from the source cluster by gpupgrade.
+host     all   all       0.0.0.0/0     md5
+host     "my db"   all   samenet       md5 # quoted # hash
+`
+	if string(merged) != expected {
+		t.Errorf("got\n%s\nwant\n%s", merged, expected)
+	}
+	if added != 2 {
+		t.Errorf("added %d entries, want 2", added)
+	}
+
+	t.Run("leaves a target that has every source entry alone", func(t *testing.T) {
+		again, added := pgconf.MergeHBA([]byte(source), merged)
+		if added != 0 || string(again) != string(merged) {
+			t.Errorf("merging again added %d entries:\n%s", added, again)
+		}
+	})
+}
@@ -0,0 +1,364 @@
+// Package pgconf reads and edits postgresql.conf files.
+//
+// A File keeps every line of the original configuration, so that comments,
+// blank lines, include directives and the formatting of untouched settings
+// survive an edit unchanged. The syntax accepted is the one that the server
+// accepts:
+//
+//	name = value
+//	name value              # the equals sign is optional
+//	name = 'quoted value'   # '' or \' embed a quote
+//	custom.name = value     # qualified names for extensions
+//	include 'other.conf'    # as are include_if_exists and include_dir
+//
+// Names are case-insensitive, and when a setting appears more than once the
+// last occurrence wins.
+package pgconf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils"
+)
+
+// Include directive names.
+const (
+	Include         = "include"
+	IncludeIfExists = "include_if_exists"
+	IncludeDir      = "include_dir"
+)
+
+// File is a parsed postgresql.conf.
+type File struct {
+	lines []*line
+}
+
+// line is a single line of a configuration file. For lines that contain a
+// setting or an include directive, name is set and raw[valueStart:valueEnd]
+// is the value exactly as it appears in the file, including any quotes.
+type line struct {
+	raw string
+
+	name       string // lowercased
+	value      string // unquoted
+	valueStart int
+	valueEnd   int
+}
+
+// Setting is a parameter assignment or include directive in a File.
+type Setting struct {
+	Name  string // lowercased
+	Value string // unquoted
+	Line  int    // one-based
+}
+
+// ParseError describes a line that could not be parsed.
+type ParseError struct {
+	Line int // one-based
+	Text string
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s: %q", e.Line, e.Msg, e.Text)
+}
+
+// Parse reads a configuration file.
+func Parse(r io.Reader) (*File, error) {
+	f := new(File)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		l, err := parseLine(scanner.Text())
+		if err != nil {
+			return nil, &ParseError{Line: n, Text: scanner.Text(), Msg: err.Error()}
+		}
+
+		f.lines = append(f.lines, l)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// ReadFile parses the configuration file at path.
+func ReadFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	conf, err := Parse(file)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing %s: %w", path, err)
+	}
+
+	return conf, nil
+}
+
+// Edit atomically applies edit to the configuration file at path. The file is
+// left untouched if it cannot be parsed or edit returns an error.
+func Edit(path string, edit func(*File) error) error {
+	conf, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := edit(conf); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	return utils.AtomicallyWrite(path, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := conf.WriteTo(w)
+		return err
+	})
+}
+
+// Get returns the value of the named setting, and whether it is set. Include
+// directives are not followed.
+func (f *File) Get(name string) (string, bool) {
+	l := f.find(name)
+	if l == nil {
+		return "", false
+	}
+
+	return l.value, true
+}
+
+// Set changes the value of the named setting. The last occurrence of the
+// setting is rewritten in place, keeping its formatting and any trailing
+// comment; if the setting is not present it is appended to the file. Values
+// that are not simple words or numbers are quoted.
+func (f *File) Set(name, value string) {
+	formatted := Quote(value)
+
+	l := f.find(name)
+	if l == nil {
+		l = &line{raw: fmt.Sprintf("%s = %s", name, formatted)}
+		l.name = strings.ToLower(name)
+		l.valueStart = len(l.raw) - len(formatted)
+		l.valueEnd = len(l.raw)
+		l.value = value
+
+		f.lines = append(f.lines, l)
+		return
+	}
+
+	l.raw = l.raw[:l.valueStart] + formatted + l.raw[l.valueEnd:]
+	l.valueEnd = l.valueStart + len(formatted)
+	l.value = value
+}
+
+// Settings returns every setting and include directive in the file, in order.
+func (f *File) Settings() []Setting {
+	var settings []Setting
+	for i, l := range f.lines {
+		if l.name != "" {
+			settings = append(settings, Setting{Name: l.name, Value: l.value, Line: i + 1})
+		}
+	}
+
+	return settings
+}
+
+// Includes returns the include, include_if_exists and include_dir directives
+// in the file, in order.
+func (f *File) Includes() []Setting {
+	var includes []Setting
+	for _, s := range f.Settings() {
+		if isInclude(s.Name) {
+			includes = append(includes, s)
+		}
+	}
+
+	return includes
+}
+
+// WriteTo writes the configuration to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+
+	for _, l := range f.lines {
+		n, err := io.WriteString(w, l.raw+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+func (f *File) find(name string) *line {
+	name = strings.ToLower(name)
+
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if f.lines[i].name == name && !isInclude(name) {
+			return f.lines[i]
+		}
+	}
+
+	return nil
+}
+
+func isInclude(name string) bool {
+	return name == Include || name == IncludeIfExists || name == IncludeDir
+}
+
+// Quote returns value formatted for a configuration file. Simple words and
+// numbers are returned unchanged; anything else is single-quoted.
+func Quote(value string) string {
+	if value != "" && isUnquoted(value) {
+		return value
+	}
+
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+func isUnquoted(value string) bool {
+	for _, r := range value {
+		if !isValueChar(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseLine(raw string) (*line, error) {
+	l := &line{raw: raw}
+
+	i := skipSpace(raw, 0)
+	if i == len(raw) || raw[i] == '#' {
+		return l, nil // blank or comment
+	}
+
+	start := i
+	for i < len(raw) && isNameChar(rune(raw[i])) {
+		i++
+	}
+	if i == start {
+		return nil, xerrors.New("syntax error")
+	}
+	l.name = strings.ToLower(raw[start:i])
+
+	i = skipSpace(raw, i)
+	if i < len(raw) && raw[i] == '=' {
+		i = skipSpace(raw, i+1)
+	}
+
+	if i == len(raw) || raw[i] == '#' {
+		return nil, xerrors.Errorf("missing value for %q", l.name)
+	}
+
+	l.valueStart = i
+	if raw[i] == '\'' {
+		value, end, err := unquote(raw, i)
+		if err != nil {
+			return nil, err
+		}
+		l.value = value
+		i = end
+	} else {
+		for i < len(raw) && isValueChar(rune(raw[i])) {
+			i++
+		}
+		if i == l.valueStart {
+			return nil, xerrors.Errorf("syntax error in value for %q", l.name)
+		}
+		l.value = raw[l.valueStart:i]
+	}
+	l.valueEnd = i
+
+	i = skipSpace(raw, i)
+	if i < len(raw) && raw[i] != '#' {
+		return nil, xerrors.Errorf("syntax error after value for %q", l.name)
+	}
+
+	return l, nil
+}
+
+// unquote parses the quoted string beginning at raw[start], returning its
+// contents and the index just past the closing quote. As in the server, a
+// quote may be escaped by doubling it or with a backslash, and backslash
+// escapes such as \n are interpreted.
+func unquote(raw string, start int) (string, int, error) {
+	var b strings.Builder
+
+	for i := start + 1; i < len(raw); i++ {
+		c := raw[i]
+
+		switch {
+		case c == '\'' && i+1 < len(raw) && raw[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+
+		case c == '\'':
+			return b.String(), i + 1, nil
+
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				// up to three octal digits
+				val, j := 0, i
+				for ; j < len(raw) && j < i+3 && raw[j] >= '0' && raw[j] <= '7'; j++ {
+					val = val*8 + int(raw[j]-'0')
+				}
+				b.WriteByte(byte(val))
+				i = j - 1
+			default:
+				b.WriteByte(raw[i])
+			}
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, xerrors.New("unterminated quoted string")
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\r' || s[i] == '\f' || s[i] == '\v') {
+		i++
+	}
+	return i
+}
+
+func isNameChar(r rune) bool {
+	return r == '_' || r == '.' || r == '$' ||
+		('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') ||
+		r >= 0x80
+}
+
+// isValueChar matches the characters that may appear in an unquoted value:
+// identifiers, numbers with units, and simple paths.
+func isValueChar(r rune) bool {
+	return isNameChar(r) || r == '-' || r == '+' || r == ':' || r == '/'
+}
@@ -0,0 +1,277 @@
+package pgconf_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils/pgconf"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		setting  string
+		expected string
+	}{
+		{"without spaces", "port=5432", "port", "5432"},
+		{"with spaces", "port = 5432", "port", "5432"},
+		{"with tabs and padding", "  port\t=\t5432  ", "port", "5432"},
+		{"without an equals sign", "port 5432", "port", "5432"},
+		{"with a trailing comment", "port = 5432 # the port", "port", "5432"},
+		{"with a comment directly after the value", "port = 5432#the port", "port", "5432"},
+		{"with an uppercase name", "PORT = 5432", "port", "5432"},
+		{"with a quoted value", "port = '5432'", "port", "5432"},
+		{"with a negative number", "statement_timeout = -1", "statement_timeout", "-1"},
+		{"with a real number", "checkpoint_completion_target = 0.9", "checkpoint_completion_target", "0.9"},
+		{"with units", "shared_buffers = 125MB", "shared_buffers", "125MB"},
+		{"with a hexadecimal number", "gp_debug_linger = 0x1f", "gp_debug_linger", "0x1f"},
+		{"with a boolean word", "fsync = on", "fsync", "on"},
+		{"with an unquoted path", "log_directory = pg_log/archive", "log_directory", "pg_log/archive"},
+		{"with a qualified name", "gp_custom.setting = value", "gp_custom.setting", "value"},
+		{"with an empty quoted value", "search_path = ''", "search_path", ""},
+		{"with doubled quotes", "application_name = 'it''s'", "application_name", "it's"},
+		{"with a backslash-escaped quote", `application_name = 'it\'s'`, "application_name", "it's"},
+		{"with backslash escapes", `application_name = 'a\tb\\c\101'`, "application_name", "a\tb\\cA"},
+		{"with a hash inside quotes", "log_line_prefix = '%m #%p '", "log_line_prefix", "%m #%p "},
+		{"with spaces inside quotes", "datestyle = 'iso, mdy'", "datestyle", "iso, mdy"},
+		{"with a carriage return", "port = 5432\r", "port", "5432"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conf, err := pgconf.Parse(strings.NewReader(c.line + "\n"))
+			if err != nil {
+				t.Fatalf("Parse() returned error %+v", err)
+			}
+
+			value, ok := conf.Get(c.setting)
+			if !ok {
+				t.Fatalf("%s is not set", c.setting)
+			}
+			if value != c.expected {
+				t.Errorf("got %q, want %q", value, c.expected)
+			}
+		})
+	}
+
+	t.Run("ignores blank lines and comments", func(t *testing.T) {
+		conf, err := pgconf.Parse(strings.NewReader("\n   \n# comment\n\t#port = 5432\n"))
+		if err != nil {
+			t.Fatalf("Parse() returned error %+v", err)
+		}
+
+		if settings := conf.Settings(); len(settings) != 0 {
+			t.Errorf("got settings %+v, want none", settings)
+		}
+	})
+
+	t.Run("uses the last occurrence of a setting", func(t *testing.T) {
+		conf, err := pgconf.Parse(strings.NewReader("port = 5432\nPort = 6000\n"))
+		if err != nil {
+			t.Fatalf("Parse() returned error %+v", err)
+		}
+
+		if value, _ := conf.Get("port"); value != "6000" {
+			t.Errorf("got %q, want %q", value, "6000")
+		}
+	})
+
+	t.Run("reports include directives", func(t *testing.T) {
+		contents := strings.Join([]string{
+			"include 'shared.conf'",
+			"include_if_exists = 'optional.conf'",
+			"port = 5432",
+			"include_dir 'conf.d'",
+		}, "\n")
+
+		conf, err := pgconf.Parse(strings.NewReader(contents))
+		if err != nil {
+			t.Fatalf("Parse() returned error %+v", err)
+		}
+
+		expected := []pgconf.Setting{
+			{Name: pgconf.Include, Value: "shared.conf", Line: 1},
+			{Name: pgconf.IncludeIfExists, Value: "optional.conf", Line: 2},
+			{Name: pgconf.IncludeDir, Value: "conf.d", Line: 4},
+		}
+		if includes := conf.Includes(); !reflect.DeepEqual(includes, expected) {
+			t.Errorf("got includes %+v, want %+v", includes, expected)
+		}
+	})
+
+	errorCases := []struct {
+		name string
+		line string
+	}{
+		{"missing value", "port ="},
+		{"missing value before a comment", "port = # no value"},
+		{"unterminated quote", "datestyle = 'iso, mdy"},
+		{"unquoted spaces", "datestyle = iso mdy"},
+		{"no name", "= 5432"},
+		{"two equals signs", "port = = 5432"},
+	}
+
+	for _, c := range errorCases {
+		t.Run("rejects "+c.name, func(t *testing.T) {
+			_, err := pgconf.Parse(strings.NewReader("# ok\n" + c.line + "\n"))
+
+			var parseErr *pgconf.ParseError
+			if !xerrors.As(err, &parseErr) {
+				t.Fatalf("returned error %#v, want type %T", err, parseErr)
+			}
+			if parseErr.Line != 2 {
+				t.Errorf("got error on line %d, want line 2", parseErr.Line)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		setting  string
+		value    string
+		expected string
+	}{
+		{
+			"replaces a value in place",
+			"port=5432\n",
+			"port", "6000",
+			"port=6000\n",
+		}, {
+			"keeps spacing and trailing comments",
+			"port  =  5432   # the port\n",
+			"port", "6000",
+			"port  =  6000   # the port\n",
+		}, {
+			"replaces a quoted value",
+			"port = '5432'\n",
+			"port", "6000",
+			"port = 6000\n",
+		}, {
+			"replaces only the last occurrence",
+			"port = 5432\nPORT 5433\n",
+			"port", "6000",
+			"port = 5432\nPORT 6000\n",
+		}, {
+			"leaves commented-out settings alone and appends",
+			"#port = 5432\nmax_connections = 100\n",
+			"port", "6000",
+			"#port = 5432\nmax_connections = 100\nport = 6000\n",
+		}, {
+			"quotes values that need it",
+			"max_connections = 100\n",
+			"datestyle", "it's iso",
+			"max_connections = 100\ndatestyle = 'it''s iso'\n",
+		}, {
+			"preserves includes and comments",
+			"# header\ninclude 'shared.conf'\n\nport = 5432\n",
+			"port", "6000",
+			"# header\ninclude 'shared.conf'\n\nport = 6000\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conf, err := pgconf.Parse(strings.NewReader(c.contents))
+			if err != nil {
+				t.Fatalf("Parse() returned error %+v", err)
+			}
+
+			conf.Set(c.setting, c.value)
+
+			var buf bytes.Buffer
+			if _, err := conf.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() returned error %+v", err)
+			}
+
+			if buf.String() != c.expected {
+				t.Errorf("wrote %q, want %q", buf.String(), c.expected)
+			}
+
+			// The result should round-trip.
+			reparsed, err := pgconf.Parse(&buf)
+			if err != nil {
+				t.Fatalf("reparsing returned error %+v", err)
+			}
+			if value, _ := reparsed.Get(c.setting); value != c.value {
+				t.Errorf("reparsed %q, want %q", value, c.value)
+			}
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "postgresql.conf")
+	original := "# settings\nport = 5432 # master\n"
+	if err := ioutil.WriteFile(path, []byte(original), 0640); err != nil {
+		t.Fatalf("writing configuration: %+v", err)
+	}
+
+	t.Run("updates the file in place", func(t *testing.T) {
+		err := pgconf.Edit(path, func(conf *pgconf.File) error {
+			conf.Set("port", "6000")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Edit() returned error %+v", err)
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading configuration: %+v", err)
+		}
+
+		expected := "# settings\nport = 6000 # master\n"
+		if string(contents) != expected {
+			t.Errorf("got %q, want %q", contents, expected)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %+v", err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+		}
+	})
+
+	t.Run("leaves the file alone when the edit fails", func(t *testing.T) {
+		before, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading configuration: %+v", err)
+		}
+
+		expected := xerrors.New("ahhhh")
+		err = pgconf.Edit(path, func(conf *pgconf.File) error {
+			conf.Set("port", "7000")
+			return expected
+		})
+		if !xerrors.Is(err, expected) {
+			t.Errorf("returned error %#v, want %#v", err, expected)
+		}
+
+		after, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading configuration: %+v", err)
+		}
+		if !bytes.Equal(before, after) {
+			t.Errorf("configuration changed to %q", after)
+		}
+	})
+}
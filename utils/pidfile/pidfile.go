@@ -0,0 +1,201 @@
+// Package pidfile implements the hub's exclusive lock on a state directory.
+//
+// The lock is an advisory flock(2) held on a file in the state directory for
+// as long as the hub runs. The same file records the hub's pid, port and start
+// time, so that clients can find the hub without scanning the process table.
+// Since the kernel releases the lock when the hub exits, a pidfile left behind
+// by a hub that crashed is recognized as stale.
+package pidfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// FileName is the name of the pidfile within the state directory.
+const FileName = "hub.pid"
+
+// Info is the content of the pidfile.
+type Info struct {
+	Pid       int
+	Port      int
+	StartTime time.Time
+}
+
+// LockedError is returned by Acquire when another process holds the lock.
+type LockedError struct {
+	Path string
+	Info Info
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("another gpupgrade hub (pid %d, port %d, started %s) is already using %s",
+		e.Info.Pid, e.Info.Port, e.Info.StartTime.Format(time.RFC3339), filepath.Dir(e.Path))
+}
+
+// File is a held lock on a state directory. It must be released with Release.
+type File struct {
+	path string
+	file *os.File
+}
+
+// Path returns the location of the pidfile within stateDir.
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// Acquire takes the exclusive lock on stateDir and records the current process
+// in the pidfile. If another process already holds the lock, a *LockedError
+// describing it is returned.
+func Acquire(stateDir string, port int) (*File, error) {
+	path := Path(stateDir)
+
+	file, err := lock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := Info{
+		Pid:       os.Getpid(),
+		Port:      port,
+		StartTime: time.Now(),
+	}
+
+	err = write(file, info)
+	if err != nil {
+		file.Close()
+		return nil, xerrors.Errorf("writing pidfile: %w", err)
+	}
+
+	return &File{path: path, file: file}, nil
+}
+
+// lock opens and exclusively locks the pidfile. Release unlinks the file while
+// it is still locked, so a file that was locked after a concurrent Release may
+// no longer be the one at path; in that case we start over.
+func lock(path string) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, xerrors.Errorf("opening pidfile: %w", err)
+		}
+
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == syscall.EWOULDBLOCK {
+			info, _ := read(file)
+			file.Close()
+			return nil, &LockedError{Path: path, Info: info}
+		}
+		if err != nil {
+			file.Close()
+			return nil, xerrors.Errorf("locking pidfile: %w", err)
+		}
+
+		locked, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, xerrors.Errorf("locking pidfile: %w", err)
+		}
+
+		current, err := os.Stat(path)
+		if err == nil && os.SameFile(locked, current) {
+			return file, nil
+		}
+
+		file.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, xerrors.Errorf("locking pidfile: %w", err)
+		}
+	}
+}
+
+// Release removes the pidfile and releases the lock.
+func (f *File) Release() error {
+	err := os.Remove(f.path)
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// Read returns the contents of the pidfile in stateDir, and whether the
+// process that wrote it still holds the lock. A missing pidfile is not an
+// error; running is simply false.
+func Read(stateDir string) (info Info, running bool, err error) {
+	file, err := os.Open(Path(stateDir))
+	if os.IsNotExist(err) {
+		return Info{}, false, nil
+	}
+	if err != nil {
+		return Info{}, false, xerrors.Errorf("opening pidfile: %w", err)
+	}
+	defer file.Close()
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	switch {
+	case err == syscall.EWOULDBLOCK:
+		running = true
+	case err != nil:
+		return Info{}, false, xerrors.Errorf("checking pidfile lock: %w", err)
+	default:
+		// Nobody holds the lock, so the pidfile is stale.
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}
+
+	info, err = read(file)
+	if err != nil && !running {
+		// A stale pidfile may have been left half-written; it doesn't matter.
+		return Info{}, false, nil
+	}
+	if err != nil {
+		return Info{}, running, xerrors.Errorf("reading pidfile: %w", err)
+	}
+
+	return info, running, nil
+}
+
+func read(file *os.File) (Info, error) {
+	var info Info
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return info, err
+	}
+
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return info, err
+	}
+
+	if len(contents) == 0 {
+		// The holder of the lock has not written its information yet.
+		return info, nil
+	}
+
+	err = json.Unmarshal(contents, &info)
+	return info, err
+}
+
+func write(file *os.File, info Info) error {
+	contents, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := file.WriteAt(append(contents, '\n'), 0); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
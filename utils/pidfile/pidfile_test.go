@@ -0,0 +1,103 @@
+package pidfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils/pidfile"
+)
+
+func TestPidfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatalf("creating temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("no hub is running without a pidfile", func(t *testing.T) {
+		_, running, err := pidfile.Read(dir)
+		if err != nil {
+			t.Fatalf("Read returned error %+v", err)
+		}
+		if running {
+			t.Error("expected no hub to be running")
+		}
+	})
+
+	t.Run("records the process holding the lock", func(t *testing.T) {
+		lock, err := pidfile.Acquire(dir, 7527)
+		if err != nil {
+			t.Fatalf("Acquire returned error %+v", err)
+		}
+		defer lock.Release()
+
+		info, running, err := pidfile.Read(dir)
+		if err != nil {
+			t.Fatalf("Read returned error %+v", err)
+		}
+		if !running {
+			t.Error("expected the hub to be running")
+		}
+		if info.Pid != os.Getpid() || info.Port != 7527 || info.StartTime.IsZero() {
+			t.Errorf("got pidfile contents %+v", info)
+		}
+	})
+
+	t.Run("rejects a second lock on the same state directory", func(t *testing.T) {
+		lock, err := pidfile.Acquire(dir, 7527)
+		if err != nil {
+			t.Fatalf("Acquire returned error %+v", err)
+		}
+		defer lock.Release()
+
+		_, err = pidfile.Acquire(dir, 7528)
+
+		var lockedErr *pidfile.LockedError
+		if !xerrors.As(err, &lockedErr) {
+			t.Fatalf("returned error %#v, want type %T", err, lockedErr)
+		}
+		if lockedErr.Info.Pid != os.Getpid() || lockedErr.Info.Port != 7527 {
+			t.Errorf("got lock holder %+v", lockedErr.Info)
+		}
+	})
+
+	t.Run("releasing the lock removes the pidfile", func(t *testing.T) {
+		lock, err := pidfile.Acquire(dir, 7527)
+		if err != nil {
+			t.Fatalf("Acquire returned error %+v", err)
+		}
+
+		if err := lock.Release(); err != nil {
+			t.Fatalf("Release returned error %+v", err)
+		}
+
+		if _, err := os.Stat(pidfile.Path(dir)); !os.IsNotExist(err) {
+			t.Errorf("pidfile still exists after release (stat error %v)", err)
+		}
+	})
+
+	t.Run("treats a pidfile that is not locked as stale", func(t *testing.T) {
+		contents := `{"Pid": 12345, "Port": 7527}`
+		if err := ioutil.WriteFile(pidfile.Path(dir), []byte(contents), 0600); err != nil {
+			t.Fatalf("writing pidfile: %+v", err)
+		}
+		defer os.Remove(pidfile.Path(dir))
+
+		_, running, err := pidfile.Read(dir)
+		if err != nil {
+			t.Fatalf("Read returned error %+v", err)
+		}
+		if running {
+			t.Error("expected a stale pidfile to be ignored")
+		}
+
+		lock, err := pidfile.Acquire(dir, 7527)
+		if err != nil {
+			t.Fatalf("Acquire returned error %+v", err)
+		}
+		lock.Release()
+	})
+}
@@ -0,0 +1,153 @@
+// Package schema versions the JSON files that gpupgrade keeps in its state
+// directory, and upgrades files written by older versions of gpupgrade.
+//
+// Every versioned file carries a top-level SchemaVersion field. Files written
+// before versioning was introduced have no such field and are treated as
+// version zero. A Registry holds the migrations for a single file format; each
+// migration transforms a document from one version to the next, and the
+// registry stamps the new version on the result.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// VersionKey is the name of the top-level field that records the version of a
+// state file.
+const VersionKey = "SchemaVersion"
+
+// Migration transforms a JSON document from one schema version into the
+// format of the next version. It does not need to update the SchemaVersion
+// field.
+type Migration func(doc []byte) ([]byte, error)
+
+// Registry upgrades documents of a single format to its current version.
+type Registry struct {
+	// Name identifies the file in error messages, e.g. "config.json".
+	Name string
+
+	// Current is the version written by this version of gpupgrade.
+	Current int
+
+	migrations map[int]Migration
+}
+
+// NewRegistry returns an empty Registry for documents whose current version is
+// current. A migration must be registered for every earlier version.
+func NewRegistry(name string, current int) *Registry {
+	return &Registry{
+		Name:       name,
+		Current:    current,
+		migrations: make(map[int]Migration),
+	}
+}
+
+// Register adds the migration from version from to version from+1. It panics
+// if a migration for that version has already been registered, or if from is
+// not older than the current version.
+func (r *Registry) Register(from int, m Migration) {
+	if from < 0 || from >= r.Current {
+		panic(fmt.Sprintf("%s: cannot register a migration from version %d (current version is %d)",
+			r.Name, from, r.Current))
+	}
+
+	if _, ok := r.migrations[from]; ok {
+		panic(fmt.Sprintf("%s: migration from version %d registered twice", r.Name, from))
+	}
+
+	r.migrations[from] = m
+}
+
+// NewerVersionError is returned by Migrate when a document was written by a
+// newer version of gpupgrade than this one.
+type NewerVersionError struct {
+	Name      string
+	Version   int
+	Supported int
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("%s has schema version %d, but this gpupgrade only understands up to version %d; "+
+		"it was written by a newer gpupgrade, which must be used to continue this upgrade",
+		e.Name, e.Version, e.Supported)
+}
+
+// Version returns the schema version of doc, which is zero if doc has no
+// version field.
+func Version(doc []byte) (int, error) {
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &header); err != nil {
+		return 0, err
+	}
+
+	raw, ok := header[VersionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, xerrors.Errorf("invalid %s: %w", VersionKey, err)
+	}
+
+	if version < 0 {
+		return 0, xerrors.Errorf("invalid %s %d", VersionKey, version)
+	}
+
+	return version, nil
+}
+
+// Migrate upgrades doc to the current version by applying each registered
+// migration in turn. A document that is already current is returned as-is. A
+// *NewerVersionError is returned if doc is newer than the current version.
+func (r *Registry) Migrate(doc []byte) ([]byte, error) {
+	version, err := Version(doc)
+	if err != nil {
+		return nil, xerrors.Errorf("reading %s: %w", r.Name, err)
+	}
+
+	if version > r.Current {
+		return nil, &NewerVersionError{Name: r.Name, Version: version, Supported: r.Current}
+	}
+
+	for ; version < r.Current; version++ {
+		migrate, ok := r.migrations[version]
+		if !ok {
+			return nil, xerrors.Errorf("%s: no migration from schema version %d", r.Name, version)
+		}
+
+		doc, err = migrate(doc)
+		if err != nil {
+			return nil, xerrors.Errorf("migrating %s from schema version %d: %w", r.Name, version, err)
+		}
+
+		doc, err = setVersion(doc, version+1)
+		if err != nil {
+			return nil, xerrors.Errorf("migrating %s from schema version %d: %w", r.Name, version, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func setVersion(doc []byte, version int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	raw, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	fields[VersionKey] = raw
+
+	return json.Marshal(fields)
+}
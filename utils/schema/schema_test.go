@@ -0,0 +1,95 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/greenplum-db/gpupgrade/utils/schema"
+)
+
+func TestMigrate(t *testing.T) {
+	registry := schema.NewRegistry("test.json", 2)
+	registry.Register(0, func(doc []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(doc, &fields); err != nil {
+			return nil, err
+		}
+		fields["Renamed"] = fields["Original"]
+		delete(fields, "Original")
+		return json.Marshal(fields)
+	})
+	registry.Register(1, func(doc []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(doc, &fields); err != nil {
+			return nil, err
+		}
+		fields["Added"] = true
+		return json.Marshal(fields)
+	})
+
+	type current struct {
+		SchemaVersion int
+		Renamed       string
+		Added         bool
+	}
+
+	cases := []struct {
+		name string
+		doc  string
+	}{
+		{"migrates an unversioned document", `{"Original": "value"}`},
+		{"migrates from an intermediate version", `{"SchemaVersion": 1, "Renamed": "value"}`},
+		{"leaves a current document alone", `{"SchemaVersion": 2, "Renamed": "value", "Added": true}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := registry.Migrate([]byte(c.doc))
+			if err != nil {
+				t.Fatalf("Migrate() returned error %+v", err)
+			}
+
+			var actual current
+			if err := json.Unmarshal(doc, &actual); err != nil {
+				t.Fatalf("decoding migrated document: %+v", err)
+			}
+
+			expected := current{SchemaVersion: 2, Renamed: "value", Added: true}
+			if actual != expected {
+				t.Errorf("migrated to %+v, want %+v", actual, expected)
+			}
+		})
+	}
+
+	t.Run("refuses documents from newer versions", func(t *testing.T) {
+		_, err := registry.Migrate([]byte(`{"SchemaVersion": 3}`))
+
+		var versionErr *schema.NewerVersionError
+		if !xerrors.As(err, &versionErr) {
+			t.Fatalf("returned error %#v, want type %T", err, versionErr)
+		}
+
+		expected := schema.NewerVersionError{Name: "test.json", Version: 3, Supported: 2}
+		if *versionErr != expected {
+			t.Errorf("got %+v, want %+v", *versionErr, expected)
+		}
+	})
+
+	t.Run("rejects invalid versions", func(t *testing.T) {
+		for _, doc := range []string{`{"SchemaVersion": "1"}`, `{"SchemaVersion": -1}`, `[]`} {
+			if _, err := registry.Migrate([]byte(doc)); err == nil {
+				t.Errorf("Migrate(%s) returned nil error", doc)
+			}
+		}
+	})
+
+	t.Run("requires a migration for every older version", func(t *testing.T) {
+		incomplete := schema.NewRegistry("test.json", 1)
+
+		if _, err := incomplete.Migrate([]byte(`{}`)); err == nil {
+			t.Error("Migrate() returned nil error")
+		}
+	})
+}
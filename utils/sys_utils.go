@@ -99,6 +99,28 @@ func GetStateDir() string {
 	return stateDir
 }
 
+// GetLogDir returns the directory used by gplog when no log directory is
+// specified, which is also where gpinitsystem and other management utilities
+// write their logs.
+func GetLogDir() string {
+	return filepath.Join(os.Getenv("HOME"), "gpAdminLogs")
+}
+
+// LogDirs returns the directories that hold this host's logs: GetLogDir, and
+// the directory that this process logs to if its --log-directory flag chose
+// another one.
+func LogDirs() []string {
+	dirs := []string{GetLogDir()}
+
+	if path := gplog.GetLogFilePath(); path != "" {
+		if dir := filepath.Dir(path); filepath.Clean(dir) != filepath.Clean(dirs[0]) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
 func CreateDataDirectory(dataDir string) error {
 	file := filepath.Join(dataDir, markerFile)
 	_, err := System.Stat(file)
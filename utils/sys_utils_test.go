@@ -1,11 +1,15 @@
 package utils
 
 import (
+	"io/ioutil"
 	"os"
 	"os/user"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
@@ -280,3 +284,26 @@ func TestCreateAllDataDirectories(t *testing.T) {
 		}
 	})
 }
+
+func TestLogDirs(t *testing.T) {
+	logger := gplog.GetLogger()
+	defer gplog.SetLogger(logger)
+
+	t.Run("includes the directory this process logs to", func(t *testing.T) {
+		gplog.SetLogger(gplog.NewLogger(os.Stdout, os.Stderr, ioutil.Discard, "/var/log/gpupgrade/gpupgrade_hub_20200101.log", gplog.LOGINFO, "gpupgrade hub"))
+
+		expected := []string{GetLogDir(), "/var/log/gpupgrade"}
+		if dirs := LogDirs(); !reflect.DeepEqual(dirs, expected) {
+			t.Errorf("LogDirs() returned %q, want %q", dirs, expected)
+		}
+	})
+
+	t.Run("includes gpAdminLogs only once", func(t *testing.T) {
+		gplog.SetLogger(gplog.NewLogger(os.Stdout, os.Stderr, ioutil.Discard, filepath.Join(GetLogDir(), "gpupgrade_hub_20200101.log"), gplog.LOGINFO, "gpupgrade hub"))
+
+		expected := []string{GetLogDir()}
+		if dirs := LogDirs(); !reflect.DeepEqual(dirs, expected) {
+			t.Errorf("LogDirs() returned %q, want %q", dirs, expected)
+		}
+	})
+}
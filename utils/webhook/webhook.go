@@ -0,0 +1,246 @@
+// Package webhook posts upgrade status events to HTTP endpoints, so that
+// operators can follow a long upgrade without watching the terminal.
+//
+// Events are delivered in the background and in order. A delivery that fails
+// is retried with exponential backoff and is otherwise only logged: webhooks
+// must never hold up or fail the upgrade.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Event is the JSON body posted for a status change. Substep is empty for the
+// status of a whole step. AuditSeq and AuditHash are the head of the hub's
+// audit log when the event was sent, so that it is recorded off the host.
+type Event struct {
+	Step      string    `json:"step"`
+	Substep   string    `json:"substep,omitempty"`
+	Status    string    `json:"status"`
+	Time      time.Time `json:"time"`
+	AuditSeq  int64     `json:"audit_seq,omitempty"`
+	AuditHash string    `json:"audit_hash,omitempty"`
+}
+
+func (e Event) String() string {
+	if e.Substep == "" {
+		return fmt.Sprintf("gpupgrade %s: %s", e.Step, e.Status)
+	}
+
+	return fmt.Sprintf("gpupgrade %s: %s %s", e.Step, e.Substep, e.Status)
+}
+
+// Format selects the body that is posted for an event.
+type Format string
+
+const (
+	JSON  Format = "json"  // the Event itself
+	Slack Format = "slack" // a Slack incoming webhook message
+)
+
+// ParseFormat parses a Format, where the empty string means JSON.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", JSON:
+		return JSON, nil
+	case Slack:
+		return Slack, nil
+	default:
+		return "", xerrors.Errorf("invalid webhook format %q: must be %q or %q", s, JSON, Slack)
+	}
+}
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the request body,
+// keyed with the sender's secret and prefixed with "sha256=".
+const SignatureHeader = "X-Gpupgrade-Signature"
+
+// Sign returns the value of SignatureHeader for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// MaxQueued is the number of undelivered events a Sender holds before it
+// starts dropping new ones.
+const MaxQueued = 1000
+
+// Sender posts events to each of URLs. The zero values of Client, Attempts
+// and Backoff are replaced by defaults.
+type Sender struct {
+	URLs   []string
+	Secret string // signs each request if set
+	Format Format
+
+	Client   *http.Client
+	Attempts int           // attempts per URL before giving up
+	Backoff  time.Duration // delay after the first failed attempt, doubled after each
+
+	// Log, if set, reports deliveries that are abandoned or dropped.
+	Log func(format string, args ...interface{})
+
+	mu      sync.Mutex
+	queue   []Event
+	running bool
+	wg      sync.WaitGroup
+}
+
+const (
+	DefaultAttempts = 5
+	DefaultBackoff  = time.Second
+	DefaultTimeout  = 10 * time.Second
+)
+
+// Send queues an event for delivery and returns immediately.
+func (s *Sender) Send(e Event) {
+	if len(s.URLs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) >= MaxQueued {
+		s.logf("dropping webhook event %q: %d events are already queued", e, len(s.queue))
+		return
+	}
+	s.queue = append(s.queue, e)
+
+	if !s.running {
+		s.running = true
+		s.wg.Add(1)
+		go s.run()
+	}
+}
+
+// Wait blocks until every queued event has been delivered or abandoned.
+func (s *Sender) Wait() {
+	s.wg.Wait()
+}
+
+// run delivers queued events in order until the queue is empty.
+func (s *Sender) run() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		e := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		s.deliver(e)
+	}
+}
+
+func (s *Sender) deliver(e Event) {
+	body, err := s.body(e)
+	if err != nil {
+		s.logf("encoding webhook event %q: %v", e, err)
+		return
+	}
+
+	for _, url := range s.URLs {
+		if err := s.post(url, body); err != nil {
+			s.logf("abandoning webhook event %q: %v", e, err)
+		}
+	}
+}
+
+func (s *Sender) body(e Event) ([]byte, error) {
+	if s.Format == Slack {
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{e.String()})
+	}
+
+	return json.Marshal(e)
+}
+
+// post sends body to url, retrying with backoff after network errors, server
+// errors and throttling. Other client errors are not retried.
+func (s *Sender) post(url string, body []byte) error {
+	attempts := s.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = s.attempt(url, body)
+		if err == nil || !retry {
+			break
+		}
+	}
+
+	if err != nil {
+		return xerrors.Errorf("posting to %s: %w", url, err)
+	}
+
+	return nil
+}
+
+func (s *Sender) attempt(url string, body []byte) (retry bool, err error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(s.Secret, body))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body) // allow the connection to be reused
+
+	if response.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	retry = response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+	return retry, xerrors.Errorf("server responded %s", response.Status)
+}
+
+func (s *Sender) logf(format string, args ...interface{}) {
+	if s.Log != nil {
+		s.Log(format, args...)
+	}
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is an HTTP handler that records the requests it receives, failing
+// the first failures of them with status.
+type recorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	failures int
+	status   int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header)
+
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(r.status)
+	}
+}
+
+func TestSender(t *testing.T) {
+	start := time.Date(2019, time.December, 25, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Step: "execute", Substep: "UPGRADE_MASTER", Status: "RUNNING", Time: start},
+		{Step: "execute", Substep: "UPGRADE_MASTER", Status: "COMPLETE", Time: start.Add(time.Minute)},
+		{Step: "execute", Status: "COMPLETE", Time: start.Add(time.Hour)},
+	}
+
+	t.Run("posts every event in order", func(t *testing.T) {
+		r := &recorder{}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		s := &Sender{URLs: []string{server.URL}}
+		for _, e := range events {
+			s.Send(e)
+		}
+		s.Wait()
+
+		var received []Event
+		for _, body := range r.bodies {
+			var e Event
+			if err := json.Unmarshal([]byte(body), &e); err != nil {
+				t.Fatalf("decoding %q: %+v", body, err)
+			}
+			received = append(received, e)
+		}
+
+		if !reflect.DeepEqual(received, events) {
+			t.Errorf("received %+v, want %+v", received, events)
+		}
+
+		if sig := r.headers[0].Get(SignatureHeader); sig != "" {
+			t.Errorf("unsigned request has signature %q", sig)
+		}
+	})
+
+	t.Run("signs requests with the secret", func(t *testing.T) {
+		r := &recorder{}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		s := &Sender{URLs: []string{server.URL}, Secret: "shh"}
+		s.Send(events[0])
+		s.Wait()
+
+		expected := Sign("shh", []byte(r.bodies[0]))
+		if sig := r.headers[0].Get(SignatureHeader); sig != expected {
+			t.Errorf("got signature %q, want %q", sig, expected)
+		}
+	})
+
+	t.Run("sends Slack messages", func(t *testing.T) {
+		r := &recorder{}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		s := &Sender{URLs: []string{server.URL}, Format: Slack}
+		s.Send(events[1])
+		s.Send(events[2])
+		s.Wait()
+
+		expected := []string{
+			`{"text":"gpupgrade execute: UPGRADE_MASTER COMPLETE"}`,
+			`{"text":"gpupgrade execute: COMPLETE"}`,
+		}
+		if !reflect.DeepEqual(r.bodies, expected) {
+			t.Errorf("got bodies %q, want %q", r.bodies, expected)
+		}
+	})
+
+	t.Run("retries server errors with backoff", func(t *testing.T) {
+		r := &recorder{failures: 2, status: http.StatusServiceUnavailable}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		s := &Sender{URLs: []string{server.URL}, Backoff: 10 * time.Millisecond}
+
+		begin := time.Now()
+		s.Send(events[0])
+		s.Wait()
+
+		if len(r.bodies) != 3 {
+			t.Errorf("got %d attempts, want 3", len(r.bodies))
+		}
+
+		if elapsed := time.Since(begin); elapsed < 30*time.Millisecond {
+			t.Errorf("retried after %v, want at least 30ms of backoff", elapsed)
+		}
+	})
+
+	t.Run("gives up and reports the failure", func(t *testing.T) {
+		failing := &recorder{failures: 10, status: http.StatusInternalServerError}
+		failingServer := httptest.NewServer(failing)
+		defer failingServer.Close()
+
+		rejecting := &recorder{failures: 10, status: http.StatusForbidden}
+		rejectingServer := httptest.NewServer(rejecting)
+		defer rejectingServer.Close()
+
+		ok := &recorder{}
+		okServer := httptest.NewServer(ok)
+		defer okServer.Close()
+
+		var logs []string
+		s := &Sender{
+			URLs:     []string{failingServer.URL, rejectingServer.URL, okServer.URL},
+			Attempts: 3,
+			Backoff:  time.Millisecond,
+			Log:      func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) },
+		}
+		s.Send(events[0])
+		s.Wait()
+
+		if len(failing.bodies) != 3 {
+			t.Errorf("got %d attempts after server errors, want 3", len(failing.bodies))
+		}
+		if len(rejecting.bodies) != 1 {
+			t.Errorf("got %d attempts after a client error, want 1", len(rejecting.bodies))
+		}
+		if len(ok.bodies) != 1 {
+			t.Errorf("got %d deliveries to the working URL, want 1", len(ok.bodies))
+		}
+		if len(logs) != 2 {
+			t.Errorf("got logs %q, want two", logs)
+		}
+	})
+
+	t.Run("does nothing without URLs", func(t *testing.T) {
+		s := &Sender{}
+		s.Send(events[0])
+		s.Wait()
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"": JSON, "json": JSON, "Slack": Slack}
+	for s, expected := range cases {
+		f, err := ParseFormat(s)
+		if err != nil || f != expected {
+			t.Errorf("ParseFormat(%q) returned %q, %v, want %q", s, f, err, expected)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("ParseFormat(%q) returned no error", "xml")
+	}
+}